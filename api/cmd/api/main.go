@@ -2,18 +2,25 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
+	// Embedded so submission exports can use any IANA time zone even where the
+	// host has no zoneinfo database.
+	_ "time/tzdata"
 
 	"github.com/evanisnor/quotecraft/api/internal/auth"
 	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/cdn"
 	"github.com/evanisnor/quotecraft/api/internal/config"
 	"github.com/evanisnor/quotecraft/api/internal/db"
 	"github.com/evanisnor/quotecraft/api/internal/server"
 	"github.com/evanisnor/quotecraft/api/internal/storage"
+	"github.com/evanisnor/quotecraft/api/internal/submission"
 )
 
 func main() {
@@ -43,22 +50,120 @@ func main() {
 		authService.WithGoogleOAuth(userRepo, googleExchanger, googleExchanger)
 	}
 
-	calcRepo := calculator.NewPostgresCalculatorRepository(dbConn.DB())
-	calcService := calculator.NewService(calcRepo, calcRepo, calcRepo, calcRepo, calcRepo, calcRepo, calcRepo)
-
 	storageAdapter, err := initStorage(context.Background(), logger, cfg)
 	if err != nil {
 		logger.Error("failed to initialize storage", "error", err)
 		os.Exit(1)
 	}
 
+	cdnPurger, err := initCDNPurger(cfg)
+	if err != nil {
+		logger.Error("failed to initialize CDN purger", "error", err)
+		os.Exit(1)
+	}
+
+	calcRepo := calculator.NewPostgresCalculatorRepository(dbConn.DB())
+	calcService := calculator.NewService(calcRepo, calcRepo, calcRepo, calcRepo, calcRepo, calcRepo, calcRepo).
+		WithRevisions(calcRepo, calcRepo, calcRepo).
+		WithPatcher(calcRepo).
+		WithTrash(calcRepo, calcRepo).
+		WithMetadata(calcRepo, calcRepo).
+		WithPublisher(calcRepo).
+		WithCachePurger(cdnPurger, logger).
+		WithTemplates(calcRepo, calcRepo).
+		WithBundles(calcRepo, storageAdapter).
+		WithBulkJobs(calcRepo).
+		WithTransfers(calcRepo, calculator.NewLogTransferEmailSender(logger)).
+		WithEmbedDomains(calcRepo)
+
+	// A failed sync leaves the previous library in place, so it is not fatal.
+	if n, err := calculator.SyncTemplates(context.Background(), calcRepo); err != nil {
+		logger.Error("failed to sync calculator templates", "error", err)
+	} else {
+		logger.Info("synced calculator templates", "count", n)
+	}
+
+	// A zero retention would purge calculators the moment they are deleted,
+	// so the job only runs when both settings are present.
+	if cfg.Trash.PurgeInterval > 0 && cfg.Trash.Retention > 0 {
+		purger := calculator.NewPurger(calcRepo, storageAdapter, cfg.Trash.Retention, logger)
+		go purger.Run(context.Background(), cfg.Trash.PurgeInterval)
+	} else {
+		logger.Warn("trash purge disabled", "retention", cfg.Trash.Retention, "purge_interval", cfg.Trash.PurgeInterval)
+	}
+
+	if cfg.Bulk.PollInterval > 0 && cfg.Bulk.Lease > 0 {
+		bulkWorker := calculator.NewBulkWorker(calcService, calcRepo, cfg.Bulk.Lease, logger)
+		go bulkWorker.Run(context.Background(), cfg.Bulk.PollInterval)
+	} else {
+		logger.Warn("bulk job worker disabled", "poll_interval", cfg.Bulk.PollInterval, "lease", cfg.Bulk.Lease)
+	}
+
+	// Submissions are written in batches by a single goroutine; each request
+	// still waits for its own row to commit before it is acknowledged.
+	submissionRepo := submission.NewPostgresSubmissionRepository(dbConn.DB())
+	submissionBatcher := submission.NewBatcher(submissionRepo, logger)
+	go submissionBatcher.Run(context.Background())
+	retentionPolicies := submissionRetentionPolicies(cfg)
+	submissionService := submission.NewService(submissionBatcher).
+		WithHistory(submissionRepo, submissionRepo, calcService, submission.NewPlanHistoryWindows(submissionRepo, retentionPolicies)).
+		WithExport(submissionRepo)
+
+	if r := cfg.Submissions.Retention; r.Interval > 0 && r.BatchSize > 0 {
+		retainer := submission.NewRetainer(submissionRepo, retentionPolicies, submission.RetentionSchedule{
+			BatchSize:      r.BatchSize,
+			PurgeDelay:     r.PurgeDelay,
+			PurgeStartHour: r.PurgeStartHour,
+			PurgeEndHour:   r.PurgeEndHour,
+		}, submission.NewLogRetentionMetrics(logger), logger)
+		go retainer.Run(context.Background(), r.Interval)
+	} else {
+		logger.Warn("submission retention disabled", "interval", r.Interval, "batch_size", r.BatchSize)
+	}
+
+	// The filter both issues the submission challenge the widget fetches and
+	// checks submissions against it.
+	var submissionChallenges server.SubmissionChallenger
+	if sp := cfg.Submissions.Spam; sp.Enabled {
+		secret := sp.Secret
+		if secret == "" {
+			// A random key only verifies challenges on the instance that
+			// issued them, which is enough for a single local server.
+			if !cfg.CDN.ServeLocal {
+				logger.Error("submissions.spam.secret must be set when the spam filter is enabled")
+				os.Exit(1)
+			}
+			logger.Warn("submissions.spam.secret not set; using a random key for local development")
+			secret = rand.Text()
+		}
+		spamFilter := submission.NewSpamFilter([]byte(secret), sp.MinFillTime, sp.ProofOfWorkBits, sp.HourlyLimit)
+		submissionService.WithSpamFilter(spamFilter)
+		submissionChallenges = spamFilter
+	} else {
+		logger.Warn("submission spam filter disabled")
+	}
+
 	srv := server.New(&cfg.API, logger, dbConn)
 	srv.MountAuth(authService)
 	if cfg.API.GoogleOAuth.ClientID != "" {
 		srv.MountGoogleOAuth(authService)
 	}
 	srv.MountCalculators(authService, calcService)
+	srv.MountCalculatorRevisions(authService, calcService)
+	srv.MountCalculatorTrash(authService, calcService)
+	srv.MountCalculatorMetadata(authService, calcService)
+	srv.MountCalculatorPublishing(authService, calcService)
+	srv.MountCalculatorBundles(authService, calcService)
+	srv.MountCalculatorBulk(authService, calcService)
+	srv.MountCalculatorTransfers(authService, calcService)
+	srv.MountCalculatorEmbedDomains(authService, calcService)
+	srv.MountCalculatorLint(authService, calcService)
 	srv.MountPublicCalculators(calcService)
+	srv.MountSubmissions(calcService, submissionService, submissionChallenges)
+	srv.MountSubmissionLog(authService, submissionService)
+	srv.MountStandalonePages(calcService, cfg.CDN.BaseURL)
+	srv.MountOEmbed(calcService)
+	srv.MountTemplates(authService, calcService)
 	srv.MountAssets(authService, storageAdapter)
 	if cfg.CDN.ServeLocal {
 		srv.MountStaticFiles(cfg.CDN.WidgetDir)
@@ -73,6 +178,16 @@ func main() {
 	}
 }
 
+// submissionRetentionPolicies converts the per-plan retention config to the
+// policies the submission package applies.
+func submissionRetentionPolicies(cfg *config.Config) map[string]submission.RetentionPolicy {
+	policies := make(map[string]submission.RetentionPolicy, len(cfg.Submissions.Retention.Plans))
+	for plan, p := range cfg.Submissions.Retention.Plans {
+		policies[plan] = submission.RetentionPolicy{Window: p.Window, Purge: p.Purge}
+	}
+	return policies
+}
+
 // initStorage selects and initialises the configured object storage adapter.
 // For "s3": when cfg.Storage.S3.Endpoint is non-empty (MinIO dev mode) the base
 // URL is endpoint + "/" + bucket; when the endpoint is empty (production AWS S3)
@@ -99,6 +214,23 @@ func initStorage(ctx context.Context, logger *slog.Logger, cfg *config.Config) (
 	}
 }
 
+// initCDNPurger selects the configured CDN purge implementation.
+// An empty provider is treated as "none".
+// Returns an error if the provider is unrecognised or "http" has no URL.
+func initCDNPurger(cfg *config.Config) (cdn.Purger, error) {
+	switch cfg.CDN.Purge.Provider {
+	case "", "none":
+		return cdn.NoopPurger{}, nil
+	case "http":
+		if cfg.CDN.Purge.URL == "" {
+			return nil, errors.New("http CDN purge provider requires a url")
+		}
+		return cdn.NewHTTPPurger(cfg.CDN.Purge.URL, cfg.CDN.Purge.Headers, &http.Client{Timeout: 10 * time.Second}), nil
+	default:
+		return nil, fmt.Errorf("unknown CDN purge provider: %q", cfg.CDN.Purge.Provider)
+	}
+}
+
 // loadConfig resolves configuration from these sources, in order:
 //  1. File path from the CONFIG_PATH environment variable.
 //  2. ../config.yaml relative to the current working directory (works when
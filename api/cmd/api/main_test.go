@@ -277,3 +277,35 @@ func TestInitStorage_S3_ProductionMode(t *testing.T) {
 		t.Errorf("expected URL to start with CDN prefix, got %q", url)
 	}
 }
+
+// TestInitCDNPurger verifies provider selection for CDN purging.
+func TestInitCDNPurger(t *testing.T) {
+	tests := []struct {
+		name    string
+		purge   config.CDNPurgeConfig
+		wantErr bool
+	}{
+		{"empty provider", config.CDNPurgeConfig{}, false},
+		{"none", config.CDNPurgeConfig{Provider: "none"}, false},
+		{"http", config.CDNPurgeConfig{Provider: "http", URL: "https://cdn.example.com/purge"}, false},
+		{"http without url", config.CDNPurgeConfig{Provider: "http"}, true},
+		{"unknown", config.CDNPurgeConfig{Provider: "unknown"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purger, err := initCDNPurger(&config.Config{CDN: config.CDNConfig{Purge: tt.purge}})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if purger == nil {
+				t.Fatal("expected non-nil purger")
+			}
+		})
+	}
+}
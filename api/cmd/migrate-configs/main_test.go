@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// stubStore serves a single page of rows and records writes.
+type stubStore struct {
+	rows    []*calculator.StaleConfig
+	listErr error
+	written int
+}
+
+func (s *stubStore) ListStaleConfigs(_ context.Context, _ int, afterID string, _ int) ([]*calculator.StaleConfig, error) {
+	if s.listErr != nil || afterID != "" {
+		return nil, s.listErr
+	}
+	return s.rows, nil
+}
+
+func (s *stubStore) WriteMigratedConfig(_ context.Context, _ *calculator.StaleConfig) (bool, error) {
+	s.written++
+	return true, nil
+}
+
+func testLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, nil))
+}
+
+func TestRun_Success(t *testing.T) {
+	store := &stubStore{rows: []*calculator.StaleConfig{{ID: "calc-a", Config: []byte(`{}`)}}}
+	var logs bytes.Buffer
+
+	if err := run(context.Background(), store, calculator.MigrateOptions{}, testLogger(&logs)); err != nil {
+		t.Fatalf("run() returned unexpected error: %v", err)
+	}
+	if store.written != 1 {
+		t.Errorf("expected 1 write, got %d", store.written)
+	}
+	if !strings.Contains(logs.String(), "migrated=1") {
+		t.Errorf("expected summary logged, got %q", logs.String())
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	store := &stubStore{rows: []*calculator.StaleConfig{{ID: "calc-a", Config: []byte(`{}`)}}}
+	var logs bytes.Buffer
+
+	if err := run(context.Background(), store, calculator.MigrateOptions{DryRun: true}, testLogger(&logs)); err != nil {
+		t.Fatalf("run() returned unexpected error: %v", err)
+	}
+	if store.written != 0 {
+		t.Errorf("expected no writes in a dry run, got %d", store.written)
+	}
+	if !strings.Contains(logs.String(), "dry_run=true") {
+		t.Errorf("expected dry run noted in summary, got %q", logs.String())
+	}
+}
+
+func TestRun_FailuresReported(t *testing.T) {
+	store := &stubStore{rows: []*calculator.StaleConfig{
+		{ID: "calc-a", Config: []byte(`{}`)},
+		{ID: "calc-b", Config: []byte(`{"fields":"broken"}`)},
+	}}
+	var logs bytes.Buffer
+
+	err := run(context.Background(), store, calculator.MigrateOptions{}, testLogger(&logs))
+	if err == nil || !strings.Contains(err.Error(), "1 calculator configs") {
+		t.Errorf("expected failure count error, got: %v", err)
+	}
+	if store.written != 1 {
+		t.Errorf("expected the valid row still written, got %d writes", store.written)
+	}
+	if !strings.Contains(logs.String(), "calculator_id=calc-b") {
+		t.Errorf("expected failed row logged, got %q", logs.String())
+	}
+}
+
+func TestRun_ListError(t *testing.T) {
+	store := &stubStore{listErr: errors.New("db down")}
+	var logs bytes.Buffer
+
+	if err := run(context.Background(), store, calculator.MigrateOptions{}, testLogger(&logs)); err == nil {
+		t.Error("expected error when listing fails")
+	}
+}
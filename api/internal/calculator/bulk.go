@@ -0,0 +1,408 @@
+package calculator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/jsonpatch"
+)
+
+// MaxBulkCalculators is the most calculators a single bulk job may target.
+const MaxBulkCalculators = 500
+
+// ErrBulkJobNotFound is returned when a bulk job does not exist or belongs to a
+// different user. The two cases are not distinguished.
+var ErrBulkJobNotFound = errors.New("bulk job not found")
+
+// BulkOperation names what a bulk job does to each calculator.
+type BulkOperation string
+
+// Supported bulk operations.
+const (
+	// BulkMergePatch applies an RFC 7386 merge patch to each draft config.
+	BulkMergePatch BulkOperation = "merge_patch"
+	// BulkJSONPatch applies an RFC 6902 JSON patch to each draft config.
+	BulkJSONPatch BulkOperation = "json_patch"
+	// BulkPublish publishes each calculator's current draft.
+	BulkPublish BulkOperation = "publish"
+)
+
+// BulkJobStatus is the lifecycle state of a bulk job.
+type BulkJobStatus string
+
+// Bulk job states. A job is completed once every item has an outcome.
+const (
+	BulkJobPending   BulkJobStatus = "pending"
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobCompleted BulkJobStatus = "completed"
+)
+
+// BulkItemStatus is the outcome of a bulk job for one calculator.
+type BulkItemStatus string
+
+// Bulk item outcomes.
+const (
+	BulkItemPending   BulkItemStatus = "pending"
+	BulkItemSucceeded BulkItemStatus = "succeeded"
+	BulkItemFailed    BulkItemStatus = "failed"
+)
+
+// BulkRequestError reports a bulk request field that failed validation.
+type BulkRequestError struct {
+	Field   string
+	Message string
+}
+
+func (e *BulkRequestError) Error() string {
+	return "invalid bulk request: " + e.Field + ": " + e.Message
+}
+
+// BulkFilter selects calculators the way List does.
+type BulkFilter struct {
+	Search   string
+	Tags     []string
+	FolderID string
+	Unfiled  bool
+}
+
+// BulkRequest describes a bulk job to submit. Exactly one of IDs and Filter
+// selects the calculators. Patch is required for the patch operations; Publish
+// additionally publishes each calculator once its patch is applied.
+type BulkRequest struct {
+	IDs       []string
+	Filter    *BulkFilter
+	Operation BulkOperation
+	Patch     []byte
+	Publish   bool
+}
+
+// BulkJob is a submitted bulk job and the outcome for each calculator so far.
+type BulkJob struct {
+	ID         string
+	UserID     string
+	Operation  BulkOperation
+	Patch      []byte
+	Publish    bool
+	Status     BulkJobStatus
+	Items      []*BulkItem
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// BulkItem is the outcome of a bulk job for one calculator.
+type BulkItem struct {
+	CalculatorID string
+	Status       BulkItemStatus
+	Error        string
+	// PreviousVersion is the config_version the patch was applied on top of.
+	// Restoring that revision undoes the change. Zero for publish jobs.
+	PreviousVersion int
+	// ConfigVersion is the calculator's config_version after the operation.
+	ConfigVersion int
+}
+
+// BulkJobStore persists bulk jobs and their per-calculator outcomes.
+type BulkJobStore interface {
+	// CreateBulkJob inserts job with a pending item per calculator, in order.
+	CreateBulkJob(ctx context.Context, job *BulkJob) (*BulkJob, error)
+	// GetBulkJob returns the job owned by userID with all of its items.
+	// Returns ErrBulkJobNotFound if no such job exists.
+	GetBulkJob(ctx context.Context, id, userID string) (*BulkJob, error)
+	// ClaimBulkJob marks the oldest pending job, or the oldest running job
+	// with no progress since staleBefore, as running and returns it with its
+	// items. It returns nil when there is nothing to claim.
+	ClaimBulkJob(ctx context.Context, staleBefore time.Time) (*BulkJob, error)
+	// CompleteBulkItem records the outcome of a pending item and refreshes
+	// the job's claim.
+	CompleteBulkItem(ctx context.Context, jobID string, item *BulkItem) error
+	// FinishBulkJob marks the job completed.
+	FinishBulkJob(ctx context.Context, id string) error
+}
+
+// WithBulkJobs configures bulk job support on the Service and returns the same
+// Service pointer for chained calls.
+func (s *Service) WithBulkJobs(store BulkJobStore) *Service {
+	s.bulkJobs = store
+	return s
+}
+
+// SubmitBulkJob validates req, resolves the calculators it targets, and queues
+// a job to apply the operation to each of them. The job runs in the background
+// on a BulkWorker; poll GetBulkJob for its progress.
+//
+// Calculators named by ID are not checked here: one that is missing or owned
+// by someone else fails on its own when the job runs.
+// Returns a *BulkRequestError if req is malformed or selects no calculators or
+// more than MaxBulkCalculators.
+func (s *Service) SubmitBulkJob(ctx context.Context, userID string, req BulkRequest) (*BulkJob, error) {
+	if err := validateBulkOperation(req); err != nil {
+		return nil, err
+	}
+	var ids []string
+	switch {
+	case req.IDs != nil && req.Filter != nil:
+		return nil, &BulkRequestError{Field: "ids", Message: "must not be combined with filter"}
+	case req.Filter != nil:
+		var err error
+		ids, err = s.resolveBulkFilter(ctx, userID, *req.Filter)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		seen := make(map[string]bool, len(req.IDs))
+		for _, id := range req.IDs {
+			if !idPattern.MatchString(id) {
+				return nil, &BulkRequestError{Field: "ids", Message: fmt.Sprintf("%q is not a calculator ID", id)}
+			}
+			id = strings.ToLower(id)
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, &BulkRequestError{Field: "ids", Message: "must select at least one calculator"}
+	}
+	if len(ids) > MaxBulkCalculators {
+		return nil, &BulkRequestError{Field: "ids", Message: fmt.Sprintf("must select at most %d calculators", MaxBulkCalculators)}
+	}
+
+	job := &BulkJob{
+		UserID:    userID,
+		Operation: req.Operation,
+		Patch:     req.Patch,
+		Publish:   req.Publish,
+		Status:    BulkJobPending,
+		Items:     make([]*BulkItem, len(ids)),
+	}
+	for i, id := range ids {
+		job.Items[i] = &BulkItem{CalculatorID: id, Status: BulkItemPending}
+	}
+	created, err := s.bulkJobs.CreateBulkJob(ctx, job)
+	if err != nil {
+		return nil, fmt.Errorf("creating bulk job: %w", err)
+	}
+	return created, nil
+}
+
+// GetBulkJob returns the bulk job identified by id if it belongs to userID.
+// Returns ErrBulkJobNotFound if the job does not exist or belongs to someone else.
+func (s *Service) GetBulkJob(ctx context.Context, id, userID string) (*BulkJob, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrBulkJobNotFound
+	}
+	job, err := s.bulkJobs.GetBulkJob(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting bulk job: %w", err)
+	}
+	return job, nil
+}
+
+// validateBulkOperation checks the operation and its patch document. The patch
+// is checked for shape only; whether it applies is decided per calculator.
+func validateBulkOperation(req BulkRequest) error {
+	switch req.Operation {
+	case BulkMergePatch:
+		if !isJSONKind(req.Patch, '{') {
+			return &BulkRequestError{Field: "patch", Message: "must be a JSON object for merge_patch"}
+		}
+	case BulkJSONPatch:
+		var ops []jsonpatch.Operation
+		if !isJSONKind(req.Patch, '[') || json.Unmarshal(req.Patch, &ops) != nil || len(ops) == 0 {
+			return &BulkRequestError{Field: "patch", Message: "must be a non-empty array of operations for json_patch"}
+		}
+	case BulkPublish:
+		if len(req.Patch) > 0 {
+			return &BulkRequestError{Field: "patch", Message: "must be omitted for publish"}
+		}
+		if req.Publish {
+			return &BulkRequestError{Field: "publish", Message: "must be omitted for publish"}
+		}
+	default:
+		return &BulkRequestError{Field: "operation", Message: "must be one of merge_patch, json_patch, publish"}
+	}
+	return nil
+}
+
+// isJSONKind reports whether raw is valid JSON starting with the delimiter open.
+func isJSONKind(raw []byte, open byte) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && trimmed[0] == open && json.Valid(trimmed)
+}
+
+// resolveBulkFilter lists the IDs of every calculator matching f, stopping
+// with a *BulkRequestError once more than MaxBulkCalculators match.
+func (s *Service) resolveBulkFilter(ctx context.Context, userID string, f BulkFilter) ([]string, error) {
+	if f.FolderID != "" && !ValidFolderID(f.FolderID) {
+		return nil, &BulkRequestError{Field: "filter.folder", Message: `must be a folder ID or "none"`}
+	}
+	opts := ListOptions{
+		Sort:     SortByCreatedAt,
+		Search:   f.Search,
+		Tags:     f.Tags,
+		FolderID: f.FolderID,
+		Unfiled:  f.Unfiled,
+		Limit:    MaxPageSize,
+	}
+	var ids []string
+	for {
+		page, err := s.List(ctx, userID, opts)
+		if err != nil {
+			var merr *MetadataError
+			if errors.As(err, &merr) {
+				return nil, &BulkRequestError{Field: "filter.tags", Message: merr.Message}
+			}
+			return nil, err
+		}
+		for _, c := range page.Calculators {
+			ids = append(ids, c.ID)
+		}
+		if len(ids) > MaxBulkCalculators {
+			return nil, &BulkRequestError{Field: "filter", Message: fmt.Sprintf("matches more than %d calculators", MaxBulkCalculators)}
+		}
+		if page.NextCursor == "" {
+			return ids, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+// applyBulkItem applies job's operation to one calculator and returns its
+// outcome. Errors a user can act on become the item's message; anything else
+// is returned so the worker can log it, and the item is reported as an
+// internal error.
+func (s *Service) applyBulkItem(ctx context.Context, job *BulkJob, id string) (*BulkItem, error) {
+	item := &BulkItem{CalculatorID: id, Status: BulkItemSucceeded}
+	var calc *Calculator
+	var err error
+	switch job.Operation {
+	case BulkMergePatch, BulkJSONPatch:
+		format := MergePatch
+		if job.Operation == BulkJSONPatch {
+			format = JSONPatch
+		}
+		calc, err = s.Patch(ctx, id, job.UserID, 0, format, job.Patch)
+		if err == nil {
+			item.PreviousVersion = calc.ConfigVersion - 1
+			item.ConfigVersion = calc.ConfigVersion
+			if job.Publish {
+				// Publish exactly the patched draft, not a later edit.
+				calc, err = s.Publish(ctx, id, job.UserID, calc.ConfigVersion)
+			}
+		}
+	case BulkPublish:
+		calc, err = s.Publish(ctx, id, job.UserID, 0)
+	default:
+		err = fmt.Errorf("unsupported bulk operation %q", job.Operation)
+	}
+	if err == nil {
+		item.ConfigVersion = calc.ConfigVersion
+		return item, nil
+	}
+
+	item.Status = BulkItemFailed
+	var verr *configschema.ValidationError
+	var perr *jsonpatch.Error
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrForbidden):
+		item.Error = "calculator not found"
+	case errors.As(err, &verr):
+		item.Error = verr.Error()
+	case errors.As(err, &perr):
+		item.Error = "patch could not be applied: " + perr.Error()
+	case errors.Is(err, ErrVersionConflict):
+		item.Error = "patched, but not published: the calculator was edited while the job ran"
+	default:
+		item.Error = "internal error"
+		return item, err
+	}
+	return item, nil
+}
+
+// BulkWorker runs queued bulk jobs.
+type BulkWorker struct {
+	svc    *Service
+	store  BulkJobStore
+	lease  time.Duration
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewBulkWorker creates a BulkWorker that applies jobs through svc. A running
+// job that makes no progress for lease is assumed abandoned by the instance
+// that claimed it and is picked up again.
+func NewBulkWorker(svc *Service, store BulkJobStore, lease time.Duration, logger *slog.Logger) *BulkWorker {
+	return &BulkWorker{svc: svc, store: store, lease: lease, logger: logger, now: time.Now}
+}
+
+// RunPending claims and runs jobs until none are left, returning how many were
+// completed. Items that already have an outcome are skipped, so a reclaimed
+// job resumes where it stopped.
+func (w *BulkWorker) RunPending(ctx context.Context) (int, error) {
+	done := 0
+	for {
+		job, err := w.store.ClaimBulkJob(ctx, w.now().Add(-w.lease))
+		if err != nil {
+			return done, fmt.Errorf("claiming bulk job: %w", err)
+		}
+		if job == nil {
+			return done, nil
+		}
+		if err := w.runJob(ctx, job); err != nil {
+			return done, err
+		}
+		done++
+	}
+}
+
+// runJob applies job to each pending item, then marks it completed.
+func (w *BulkWorker) runJob(ctx context.Context, job *BulkJob) error {
+	for _, item := range job.Items {
+		if item.Status != BulkItemPending {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		outcome, err := w.svc.applyBulkItem(ctx, job, item.CalculatorID)
+		if err != nil {
+			w.logger.Error("applying bulk job item", "job_id", job.ID, "calculator_id", item.CalculatorID, "error", err)
+		}
+		if err := w.store.CompleteBulkItem(ctx, job.ID, outcome); err != nil {
+			return fmt.Errorf("recording bulk job item: %w", err)
+		}
+	}
+	if err := w.store.FinishBulkJob(ctx, job.ID); err != nil {
+		return fmt.Errorf("finishing bulk job: %w", err)
+	}
+	return nil
+}
+
+// Run calls RunPending immediately and then every interval until ctx is
+// cancelled. Errors are logged and retried on the next tick.
+func (w *BulkWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := w.RunPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.Error("running bulk jobs", "error", err)
+		} else if n > 0 {
+			w.logger.Info("ran bulk jobs", "jobs", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
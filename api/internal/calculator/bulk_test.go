@@ -0,0 +1,453 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	bulkCalcA = "00000000-0000-4000-8000-00000000000a"
+	bulkCalcB = "00000000-0000-4000-8000-00000000000b"
+	bulkCalcC = "00000000-0000-4000-8000-00000000000c"
+)
+
+// bulkCalcStore is an in-memory Getter, Patcher, and Publisher keyed by ID.
+type bulkCalcStore struct {
+	calcs     map[string]*Calculator
+	published []string
+}
+
+func (s *bulkCalcStore) GetCalculator(_ context.Context, id, userID string) (*Calculator, error) {
+	c, ok := s.calcs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if c.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return c, nil
+}
+
+func (s *bulkCalcStore) PatchCalculator(_ context.Context, id, _ string, _ int, apply func([]byte) ([]byte, error)) (*Calculator, error) {
+	c := s.calcs[id]
+	patched, err := apply(c.Config)
+	if err != nil {
+		return nil, err
+	}
+	c.Config, c.ConfigVersion = patched, c.ConfigVersion+1
+	return c, nil
+}
+
+func (s *bulkCalcStore) PublishCalculator(_ context.Context, id string, expectedVersion int) (*Calculator, error) {
+	c := s.calcs[id]
+	if expectedVersion != 0 && expectedVersion != c.ConfigVersion {
+		return nil, &VersionConflictError{CurrentVersion: c.ConfigVersion}
+	}
+	c.PublishedVersion = c.ConfigVersion
+	s.published = append(s.published, id)
+	return c, nil
+}
+
+// stubBulkJobStore holds at most one claimable job and records outcomes.
+type stubBulkJobStore struct {
+	created   *BulkJob
+	claimable *BulkJob
+	completed []*BulkItem
+	finished  []string
+	err       error
+}
+
+func (s *stubBulkJobStore) CreateBulkJob(_ context.Context, job *BulkJob) (*BulkJob, error) {
+	s.created = job
+	if s.err != nil {
+		return nil, s.err
+	}
+	created := *job
+	created.ID = "job-1"
+	return &created, nil
+}
+
+func (s *stubBulkJobStore) GetBulkJob(_ context.Context, _, _ string) (*BulkJob, error) {
+	return s.created, s.err
+}
+
+func (s *stubBulkJobStore) ClaimBulkJob(_ context.Context, _ time.Time) (*BulkJob, error) {
+	job := s.claimable
+	s.claimable = nil
+	return job, s.err
+}
+
+func (s *stubBulkJobStore) CompleteBulkItem(_ context.Context, _ string, item *BulkItem) error {
+	s.completed = append(s.completed, item)
+	return nil
+}
+
+func (s *stubBulkJobStore) FinishBulkJob(_ context.Context, id string) error {
+	s.finished = append(s.finished, id)
+	return nil
+}
+
+func newBulkService(lister *stubLister, calcs *bulkCalcStore, jobs *stubBulkJobStore) *Service {
+	return NewService(&stubCreator{}, lister, calcs, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithPatcher(calcs).
+		WithPublisher(calcs).
+		WithBulkJobs(jobs)
+}
+
+func TestSubmitBulkJob_Rejects(t *testing.T) {
+	tooMany := make([]string, MaxBulkCalculators+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+	}
+	cases := []struct {
+		name      string
+		req       BulkRequest
+		wantField string
+	}{
+		{"unknown operation", BulkRequest{IDs: []string{bulkCalcA}, Operation: "delete"}, "operation"},
+		{"merge patch not an object", BulkRequest{IDs: []string{bulkCalcA}, Operation: BulkMergePatch, Patch: []byte(`[]`)}, "patch"},
+		{"json patch empty", BulkRequest{IDs: []string{bulkCalcA}, Operation: BulkJSONPatch, Patch: []byte(`[]`)}, "patch"},
+		{"publish with patch", BulkRequest{IDs: []string{bulkCalcA}, Operation: BulkPublish, Patch: []byte(`{}`)}, "patch"},
+		{"ids and filter", BulkRequest{IDs: []string{bulkCalcA}, Filter: &BulkFilter{}, Operation: BulkPublish}, "ids"},
+		{"malformed id", BulkRequest{IDs: []string{"calc-1"}, Operation: BulkPublish}, "ids"},
+		{"no calculators", BulkRequest{IDs: []string{}, Operation: BulkPublish}, "ids"},
+		{"too many calculators", BulkRequest{IDs: tooMany, Operation: BulkPublish}, "ids"},
+		{"bad folder", BulkRequest{Filter: &BulkFilter{FolderID: "x"}, Operation: BulkPublish}, "filter.folder"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			jobs := &stubBulkJobStore{}
+			svc := newBulkService(&stubLister{}, &bulkCalcStore{}, jobs)
+
+			_, err := svc.SubmitBulkJob(context.Background(), "user-xyz", tc.req)
+			var berr *BulkRequestError
+			if !errors.As(err, &berr) {
+				t.Fatalf("expected *BulkRequestError, got: %v", err)
+			}
+			if berr.Field != tc.wantField {
+				t.Errorf("expected field %q, got %q (%s)", tc.wantField, berr.Field, berr.Message)
+			}
+			if jobs.created != nil {
+				t.Error("expected no job created")
+			}
+		})
+	}
+}
+
+func TestSubmitBulkJob_DeduplicatesIDs(t *testing.T) {
+	jobs := &stubBulkJobStore{}
+	svc := newBulkService(&stubLister{}, &bulkCalcStore{}, jobs)
+
+	job, err := svc.SubmitBulkJob(context.Background(), "user-xyz", BulkRequest{
+		IDs:       []string{bulkCalcA, bulkCalcB, strings.ToUpper(bulkCalcA)},
+		Operation: BulkMergePatch,
+		Patch:     []byte(`{"theme":null}`),
+	})
+	if err != nil {
+		t.Fatalf("SubmitBulkJob() returned unexpected error: %v", err)
+	}
+	if job.ID != "job-1" || job.Status != BulkJobPending || len(job.Items) != 2 {
+		t.Errorf("expected a pending job with two items, got %+v", job)
+	}
+	if jobs.created.UserID != "user-xyz" || jobs.created.Items[1].CalculatorID != bulkCalcB {
+		t.Errorf("unexpected job stored: %+v", jobs.created)
+	}
+}
+
+func TestSubmitBulkJob_ResolvesFilter(t *testing.T) {
+	lister := &stubLister{calcs: []*Calculator{{ID: bulkCalcA}, {ID: bulkCalcB}}}
+	jobs := &stubBulkJobStore{}
+	svc := newBulkService(lister, &bulkCalcStore{}, jobs)
+
+	job, err := svc.SubmitBulkJob(context.Background(), "user-xyz", BulkRequest{
+		Filter:    &BulkFilter{Tags: []string{"Agency"}, Unfiled: true},
+		Operation: BulkPublish,
+	})
+	if err != nil {
+		t.Fatalf("SubmitBulkJob() returned unexpected error: %v", err)
+	}
+	if len(job.Items) != 2 || job.Items[0].CalculatorID != bulkCalcA {
+		t.Errorf("expected both matching calculators targeted, got %+v", job.Items)
+	}
+	if lister.query.UserID != "user-xyz" || !lister.query.Unfiled || lister.query.Tags[0] != "agency" {
+		t.Errorf("expected filter passed to the lister, got %+v", lister.query)
+	}
+}
+
+func TestSubmitBulkJob_FilterTooBroad(t *testing.T) {
+	lister := &stubLister{calcs: calcsN(MaxPageSize + 1)}
+	jobs := &stubBulkJobStore{}
+	svc := newBulkService(lister, &bulkCalcStore{}, jobs)
+
+	_, err := svc.SubmitBulkJob(context.Background(), "user-xyz", BulkRequest{Filter: &BulkFilter{}, Operation: BulkPublish})
+	var berr *BulkRequestError
+	if !errors.As(err, &berr) || berr.Field != "filter" {
+		t.Errorf("expected filter *BulkRequestError, got: %v", err)
+	}
+}
+
+func TestGetBulkJob_MalformedID(t *testing.T) {
+	svc := newBulkService(&stubLister{}, &bulkCalcStore{}, &stubBulkJobStore{})
+
+	if _, err := svc.GetBulkJob(context.Background(), "job-1", "user-xyz"); !errors.Is(err, ErrBulkJobNotFound) {
+		t.Errorf("expected ErrBulkJobNotFound, got: %v", err)
+	}
+}
+
+func TestBulkWorker_ReportsPerCalculator(t *testing.T) {
+	calcs := &bulkCalcStore{calcs: map[string]*Calculator{
+		bulkCalcA: {ID: bulkCalcA, UserID: "user-xyz", Config: []byte(`{"layoutMode":"single-page"}`), ConfigVersion: 3},
+		bulkCalcB: {ID: bulkCalcB, UserID: "user-xyz", Config: []byte(`{}`), ConfigVersion: 1},
+		bulkCalcC: {ID: bulkCalcC, UserID: "user-other", Config: []byte(`{}`), ConfigVersion: 1},
+	}}
+	jobs := &stubBulkJobStore{claimable: &BulkJob{
+		ID:        "job-1",
+		UserID:    "user-xyz",
+		Operation: BulkJSONPatch,
+		Patch:     []byte(`[{"op":"replace","path":"/layoutMode","value":"multi-step"}]`),
+		Publish:   true,
+		Items: []*BulkItem{
+			{CalculatorID: bulkCalcA, Status: BulkItemPending},
+			{CalculatorID: bulkCalcB, Status: BulkItemPending},
+			{CalculatorID: bulkCalcC, Status: BulkItemPending},
+		},
+	}}
+	svc := newBulkService(&stubLister{}, calcs, jobs)
+	worker := NewBulkWorker(svc, jobs, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	n, err := worker.RunPending(context.Background())
+	if err != nil {
+		t.Fatalf("RunPending() returned unexpected error: %v", err)
+	}
+	if n != 1 || len(jobs.finished) != 1 {
+		t.Fatalf("expected one job run and finished, got %d and %v", n, jobs.finished)
+	}
+	if len(jobs.completed) != 3 {
+		t.Fatalf("expected 3 item outcomes, got %d", len(jobs.completed))
+	}
+	a, b, c := jobs.completed[0], jobs.completed[1], jobs.completed[2]
+	if a.Status != BulkItemSucceeded || a.PreviousVersion != 3 || a.ConfigVersion != 4 {
+		t.Errorf("expected A patched from version 3 to 4, got %+v", a)
+	}
+	if calcs.calcs[bulkCalcA].PublishedVersion != 4 {
+		t.Errorf("expected A published at version 4, got %d", calcs.calcs[bulkCalcA].PublishedVersion)
+	}
+	if b.Status != BulkItemFailed || !strings.Contains(b.Error, "patch could not be applied") {
+		t.Errorf("expected B to fail applying the patch, got %+v", b)
+	}
+	if c.Status != BulkItemFailed || c.Error != "calculator not found" {
+		t.Errorf("expected C to fail as not found, got %+v", c)
+	}
+	if len(calcs.published) != 1 {
+		t.Errorf("expected only A published, got %v", calcs.published)
+	}
+}
+
+func TestBulkWorker_ResumesPendingItems(t *testing.T) {
+	calcs := &bulkCalcStore{calcs: map[string]*Calculator{
+		bulkCalcA: {ID: bulkCalcA, UserID: "user-xyz", Config: []byte(`{}`), ConfigVersion: 2},
+		bulkCalcB: {ID: bulkCalcB, UserID: "user-xyz", Config: []byte(`{}`), ConfigVersion: 5},
+	}}
+	jobs := &stubBulkJobStore{claimable: &BulkJob{
+		ID:        "job-1",
+		UserID:    "user-xyz",
+		Operation: BulkPublish,
+		Items: []*BulkItem{
+			{CalculatorID: bulkCalcA, Status: BulkItemSucceeded, ConfigVersion: 2},
+			{CalculatorID: bulkCalcB, Status: BulkItemPending},
+		},
+	}}
+	svc := newBulkService(&stubLister{}, calcs, jobs)
+	worker := NewBulkWorker(svc, jobs, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if _, err := worker.RunPending(context.Background()); err != nil {
+		t.Fatalf("RunPending() returned unexpected error: %v", err)
+	}
+	if len(jobs.completed) != 1 || jobs.completed[0].CalculatorID != bulkCalcB || jobs.completed[0].ConfigVersion != 5 {
+		t.Errorf("expected only B processed, got %+v", jobs.completed)
+	}
+}
+
+func TestBulkWorker_PatchKeepsAuthorsRecentRevision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	saved := []byte(`{"layoutMode":"single-page"}`)
+	// The owner's autosave folds into their revision 4 ...
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs(bulkCalcA, saved, 0).
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow(bulkCalcA, "user-xyz", "", saved, 4, false, now, now, "", "{}", nil, 1, 0, nil))
+	mock.ExpectExec(`UPDATE calculator_revisions`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// ... and the bulk patch a moment later, as the same user, adds revision
+	// 5 rather than overwriting it.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT config, config_version FROM calculators .* FOR UPDATE`).
+		WithArgs(bulkCalcA).
+		WillReturnRows(sqlmock.NewRows([]string{"config", "config_version"}).AddRow(saved, 4))
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs(bulkCalcA, sqlmock.AnyArg(), 0).
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow(bulkCalcA, "user-xyz", "", []byte(`{}`), 5, false, now, now, "", "{}", nil, 1, 0, nil))
+	mock.ExpectExec(`INSERT INTO calculator_revisions`).
+		WithArgs(bulkCalcA, 5, sqlmock.AnyArg(), "user-xyz", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM calculator_revisions`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT id, calculator_id, config_version, config`).
+		WithArgs(bulkCalcA, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "calculator_id", "config_version", "config", "author_id", "restored_from_version", "created_at"}).
+			AddRow("rev-4", bulkCalcA, 4, saved, "user-xyz", nil, now))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.UpdateCalculator(context.Background(), bulkCalcA, "user-xyz", 0, true, saved); err != nil {
+		t.Fatalf("UpdateCalculator() returned unexpected error: %v", err)
+	}
+
+	calcs := &bulkCalcStore{calcs: map[string]*Calculator{
+		bulkCalcA: {ID: bulkCalcA, UserID: "user-xyz", Config: saved, ConfigVersion: 4},
+	}}
+	jobs := &stubBulkJobStore{claimable: &BulkJob{
+		ID:        "job-1",
+		UserID:    "user-xyz",
+		Operation: BulkJSONPatch,
+		Patch:     []byte(`[{"op":"replace","path":"/layoutMode","value":"multi-step"}]`),
+		Items:     []*BulkItem{{CalculatorID: bulkCalcA, Status: BulkItemPending}},
+	}}
+	svc := newBulkService(&stubLister{}, calcs, jobs).WithPatcher(repo)
+	worker := NewBulkWorker(svc, jobs, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := worker.RunPending(context.Background()); err != nil {
+		t.Fatalf("RunPending() returned unexpected error: %v", err)
+	}
+	if len(jobs.completed) != 1 || jobs.completed[0].PreviousVersion != 4 {
+		t.Fatalf("expected the patch to record previous version 4, got %+v", jobs.completed)
+	}
+
+	rev, err := repo.GetRevision(context.Background(), bulkCalcA, jobs.completed[0].PreviousVersion)
+	if err != nil {
+		t.Fatalf("GetRevision() returned unexpected error: %v", err)
+	}
+	if string(rev.Config) != string(saved) {
+		t.Errorf("expected the previous revision to hold the autosaved config, got %s", rev.Config)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresCreateBulkJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO calculator_bulk_jobs \(user_id, operation, patch, publish\)`).
+		WithArgs("user-id", BulkPublish, nil, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "operation", "patch", "publish", "status", "created_at", "started_at", "finished_at"}).
+			AddRow("job-1", "user-id", "publish", nil, false, "pending", now, nil, nil))
+	mock.ExpectExec(`INSERT INTO calculator_bulk_job_items \(job_id, calculator_id, position\)\s+SELECT \$1, t.id, t.ord - 1\s+FROM unnest\(\$2::uuid\[\]\) WITH ORDINALITY`).
+		WithArgs("job-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	job, err := repo.CreateBulkJob(context.Background(), &BulkJob{
+		UserID:    "user-id",
+		Operation: BulkPublish,
+		Items:     []*BulkItem{{CalculatorID: bulkCalcA}, {CalculatorID: bulkCalcB}},
+	})
+	if err != nil {
+		t.Fatalf("CreateBulkJob() returned unexpected error: %v", err)
+	}
+	if job.ID != "job-1" || job.Status != BulkJobPending || len(job.Items) != 2 || job.Items[0].Status != BulkItemPending {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresClaimBulkJob_NoneClaimable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	staleBefore := time.Now().Add(-time.Minute)
+	mock.ExpectQuery(`UPDATE calculator_bulk_jobs\s+SET status = 'running'.*FOR UPDATE SKIP LOCKED`).
+		WithArgs(staleBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	job, err := repo.ClaimBulkJob(context.Background(), staleBefore)
+	if err != nil || job != nil {
+		t.Errorf("expected no job and no error, got %+v, %v", job, err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresGetBulkJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mock.ExpectQuery(`FROM calculator_bulk_jobs WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("job-1", "user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "operation", "patch", "publish", "status", "created_at", "started_at", "finished_at"}).
+			AddRow("job-1", "user-id", "merge_patch", []byte(`{"theme":null}`), true, "completed", now, now, now))
+	mock.ExpectQuery(`FROM calculator_bulk_job_items\s+WHERE job_id = \$1\s+ORDER BY position`).
+		WithArgs("job-1").
+		WillReturnRows(sqlmock.NewRows([]string{"calculator_id", "status", "error", "previous_version", "config_version"}).
+			AddRow(bulkCalcA, "succeeded", nil, 3, 4).
+			AddRow(bulkCalcB, "failed", "calculator not found", nil, nil))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	job, err := repo.GetBulkJob(context.Background(), "job-1", "user-id")
+	if err != nil {
+		t.Fatalf("GetBulkJob() returned unexpected error: %v", err)
+	}
+	if job.Operation != BulkMergePatch || !job.Publish || job.FinishedAt == nil || len(job.Items) != 2 {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job.Items[0].PreviousVersion != 3 || job.Items[1].Error != "calculator not found" || job.Items[1].ConfigVersion != 0 {
+		t.Errorf("unexpected items: %+v, %+v", job.Items[0], job.Items[1])
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,395 @@
+package calculator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// BundleFormatVersion is the version of the bundle layout written by Export.
+// Import accepts bundles of this version and every earlier one.
+const BundleFormatVersion = 1
+
+// Import limits. They bound the memory an import can use; a bundle is read
+// fully before anything is stored.
+const (
+	MaxBundleAssets     = 200
+	MaxBundleAssetSize  = 5 << 20  // the asset upload limit
+	MaxBundleAssetBytes = 64 << 20 // all assets together, decompressed
+	MaxBundleConfigSize = 1 << 20
+)
+
+// Entry names inside a bundle.
+const (
+	bundleManifestName = "manifest.json"
+	bundleConfigName   = "config.json"
+	bundleAssetDir     = "assets/"
+)
+
+// BundleError reports an import bundle that is malformed, fails an integrity
+// check, or uses a format this server does not support.
+type BundleError struct {
+	Message string
+}
+
+func (e *BundleError) Error() string {
+	return "invalid bundle: " + e.Message
+}
+
+// bundleManifest is the manifest.json entry of a bundle. It describes the
+// calculator and lists every asset stored under assets/.
+type bundleManifest struct {
+	FormatVersion       int              `json:"format_version"`
+	ConfigSchemaVersion int              `json:"config_schema_version"`
+	ExportedAt          time.Time        `json:"exported_at"`
+	Calculator          bundleCalculator `json:"calculator"`
+	Assets              []bundleAsset    `json:"assets"`
+}
+
+// bundleCalculator is the calculator metadata carried by a bundle. Folders
+// belong to an account, so they are not exported.
+type bundleCalculator struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Tags          []string `json:"tags"`
+	ConfigVersion int      `json:"config_version"`
+}
+
+// bundleAsset describes one uploaded image in a bundle. Key is the
+// content-addressed storage key, and the data is stored at assets/<key>.
+type bundleAsset struct {
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// Importer creates a calculator owned by userID from imported metadata and
+// config. The new calculator starts at config_version 1 and is unpublished.
+type Importer interface {
+	ImportCalculator(ctx context.Context, userID string, m Metadata, config []byte) (*Calculator, error)
+}
+
+// BundleAssetStore reads and writes uploaded assets for export and import.
+type BundleAssetStore interface {
+	// Download opens the asset at key. If there is no such asset the error
+	// matches fs.ErrNotExist.
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	GetURL(key string) string
+}
+
+// WithBundles configures export and import support on the Service and returns
+// the same Service pointer for chained calls.
+func (s *Service) WithBundles(importer Importer, assets BundleAssetStore) *Service {
+	s.importer = importer
+	s.bundleAssets = assets
+	return s
+}
+
+// assetContentTypes maps the extension of an asset key to the only content
+// type an asset with that key may have.
+var assetContentTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".gif":  "image/gif",
+}
+
+// assetKeyExact matches a whole string that is an asset key.
+var assetKeyExact = regexp.MustCompile(`^` + assetKeyPattern.String() + `$`)
+
+// Export verifies ownership of the calculator, then writes it to w as a zip
+// bundle: manifest.json, the draft config as config.json, and every uploaded
+// asset the config references under assets/. Assets missing from storage are
+// left out and their URLs kept as they are.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) Export(ctx context.Context, id, userID string, w io.Writer) error {
+	calc, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	manifest := bundleManifest{
+		FormatVersion:       BundleFormatVersion,
+		ConfigSchemaVersion: configschema.Version,
+		ExportedAt:          time.Now().UTC(),
+		Calculator: bundleCalculator{
+			Name:          calc.Name,
+			Description:   calc.Description,
+			Tags:          calc.Tags,
+			ConfigVersion: calc.ConfigVersion,
+		},
+		Assets: []bundleAsset{},
+	}
+	var assets [][]byte
+	for _, key := range AssetKeys(calc.Config) {
+		data, err := s.downloadAsset(ctx, key)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		manifest.Assets = append(manifest.Assets, bundleAsset{
+			Key:         key,
+			ContentType: assetContentTypes[path.Ext(key)],
+			Size:        int64(len(data)),
+		})
+		assets = append(assets, data)
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	zw := zip.NewWriter(w)
+	if err := writeZipEntry(zw, bundleManifestName, zip.Deflate, manifestJSON); err != nil {
+		return err
+	}
+	if err := writeZipEntry(zw, bundleConfigName, zip.Deflate, calc.Config); err != nil {
+		return err
+	}
+	for i, a := range manifest.Assets {
+		// Images are already compressed.
+		if err := writeZipEntry(zw, bundleAssetDir+a.Key, zip.Store, assets[i]); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finishing bundle: %w", err)
+	}
+	return nil
+}
+
+// downloadAsset reads the asset at key, failing if it exceeds MaxBundleAssetSize.
+func (s *Service) downloadAsset(ctx context.Context, key string) ([]byte, error) {
+	rc, err := s.bundleAssets.Download(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("downloading asset %q: %w", key, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, MaxBundleAssetSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading asset %q: %w", key, err)
+	}
+	if len(data) > MaxBundleAssetSize {
+		return nil, fmt.Errorf("asset %q exceeds %d bytes", key, MaxBundleAssetSize)
+	}
+	return data, nil
+}
+
+func writeZipEntry(zw *zip.Writer, name string, method uint16, data []byte) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("adding %s to bundle: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("writing %s to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// Import creates a calculator for userID from a bundle produced by Export,
+// possibly on another server. Each bundled asset is checked against its
+// content-addressed key, uploaded to this server's storage, and every URL in
+// the config that points at it is rewritten to this server's URL. Nothing is
+// stored unless the whole bundle is valid.
+// Returns a *BundleError if the bundle is malformed or its format is newer
+// than this server supports.
+// Returns a *configschema.ValidationError if the bundled config is invalid.
+// Returns a *MetadataError if the bundled name, description, or tags are invalid.
+func (s *Service) Import(ctx context.Context, userID string, r io.ReaderAt, size int64) (*Calculator, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, &BundleError{Message: "not a zip archive"}
+	}
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	var manifest bundleManifest
+	raw, err := readZipEntry(entries, bundleManifestName, MaxBundleConfigSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, &BundleError{Message: "manifest.json is not valid JSON"}
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > BundleFormatVersion {
+		return nil, &BundleError{Message: fmt.Sprintf("unsupported format_version %d", manifest.FormatVersion)}
+	}
+	if manifest.ConfigSchemaVersion < 1 {
+		return nil, &BundleError{Message: "config_schema_version must be a positive integer"}
+	}
+	if manifest.ConfigSchemaVersion > configschema.Version {
+		return nil, &BundleError{Message: fmt.Sprintf("config_schema_version %d is newer than this server supports", manifest.ConfigSchemaVersion)}
+	}
+	if len(manifest.Assets) > MaxBundleAssets {
+		return nil, &BundleError{Message: fmt.Sprintf("bundle contains more than %d assets", MaxBundleAssets)}
+	}
+
+	config, err := readZipEntry(entries, bundleConfigName, MaxBundleConfigSize)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make(map[string][]byte, len(manifest.Assets))
+	var assetBytes int
+	for _, a := range manifest.Assets {
+		data, err := readBundleAsset(entries, a.Key)
+		if err != nil {
+			return nil, err
+		}
+		// Entries compress well, so the total is checked after
+		// decompression, like each entry's own limit.
+		assetBytes += len(data)
+		if assetBytes > MaxBundleAssetBytes {
+			return nil, &BundleError{Message: fmt.Sprintf("assets exceed %d bytes in total", MaxBundleAssetBytes)}
+		}
+		assets[a.Key] = data
+	}
+
+	config, err = rewriteAssetURLs(config, func(key string) (string, bool) {
+		if _, ok := assets[key]; !ok {
+			return "", false
+		}
+		return s.bundleAssets.GetURL(key), true
+	})
+	if err != nil {
+		return nil, &BundleError{Message: "config.json is not valid JSON"}
+	}
+	// Bundles from servers on an older schema version are upgraded here. The
+	// manifest says which version a config without its own key is at.
+	config, err = upgradeForWriteFrom(config, manifest.ConfigSchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	m, err := normalizeMetadata(Metadata{
+		Name:        manifest.Calculator.Name,
+		Description: manifest.Calculator.Description,
+		Tags:        manifest.Calculator.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are content-addressed, so uploading an asset this server already
+	// has rewrites identical bytes.
+	for key, data := range assets {
+		contentType := assetContentTypes[path.Ext(key)]
+		if err := s.bundleAssets.Upload(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+			return nil, fmt.Errorf("uploading asset %q: %w", key, err)
+		}
+	}
+	calc, err := s.importer.ImportCalculator(ctx, userID, m, config)
+	if err != nil {
+		return nil, fmt.Errorf("importing calculator: %w", err)
+	}
+	return calc, nil
+}
+
+// readZipEntry reads the named bundle entry, which must exist and decompress
+// to at most limit bytes.
+func readZipEntry(entries map[string]*zip.File, name string, limit int64) ([]byte, error) {
+	f, ok := entries[name]
+	if !ok {
+		return nil, &BundleError{Message: "missing " + name}
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, &BundleError{Message: "cannot read " + name}
+	}
+	defer rc.Close()
+	// The size in the zip header is supplied by the client, so the limit is
+	// enforced on the decompressed bytes themselves.
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, &BundleError{Message: "cannot read " + name}
+	}
+	if int64(len(data)) > limit {
+		return nil, &BundleError{Message: fmt.Sprintf("%s exceeds %d bytes", name, limit)}
+	}
+	return data, nil
+}
+
+// readBundleAsset reads the asset with the given key and checks that its
+// content matches the key: the key's hash must be the SHA-256 of the data and
+// its extension must match the sniffed image type.
+func readBundleAsset(entries map[string]*zip.File, key string) ([]byte, error) {
+	if !assetKeyExact.MatchString(key) {
+		return nil, &BundleError{Message: fmt.Sprintf("invalid asset key %q", key)}
+	}
+	data, err := readZipEntry(entries, bundleAssetDir+key, MaxBundleAssetSize)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != strings.TrimSuffix(key, path.Ext(key)) {
+		return nil, &BundleError{Message: fmt.Sprintf("asset %q does not match its checksum", key)}
+	}
+	if http.DetectContentType(data) != assetContentTypes[path.Ext(key)] {
+		return nil, &BundleError{Message: fmt.Sprintf("asset %q is not a %s image", key, strings.TrimPrefix(path.Ext(key), "."))}
+	}
+	return data, nil
+}
+
+// rewriteAssetURLs replaces every string in config that is a URL ending in an
+// asset key with the URL returned by urlFor, when urlFor reports one.
+func rewriteAssetURLs(config []byte, urlFor func(key string) (string, bool)) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(config))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	doc = rewriteStrings(doc, func(s string) string {
+		key := assetKeyPattern.FindString(s)
+		if key == "" || !strings.HasSuffix(s, "/"+key) {
+			return s
+		}
+		if u, ok := urlFor(key); ok {
+			return u
+		}
+		return s
+	})
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// rewriteStrings applies fn to every string value in a decoded JSON document.
+func rewriteStrings(v any, fn func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case []any:
+		for i := range v {
+			v[i] = rewriteStrings(v[i], fn)
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = rewriteStrings(v[k], fn)
+		}
+	}
+	return v
+}
@@ -0,0 +1,294 @@
+package calculator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// memAssetStore is an in-memory BundleAssetStore serving URLs under baseURL.
+type memAssetStore struct {
+	baseURL string
+	objects map[string][]byte
+}
+
+func (m *memAssetStore) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("opening %q: %w", key, fs.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memAssetStore) Upload(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memAssetStore) GetURL(key string) string {
+	return m.baseURL + "/" + key
+}
+
+type stubImporter struct {
+	gotUserID string
+	gotMeta   Metadata
+	gotConfig []byte
+}
+
+func (s *stubImporter) ImportCalculator(_ context.Context, userID string, m Metadata, config []byte) (*Calculator, error) {
+	s.gotUserID, s.gotMeta, s.gotConfig = userID, m, config
+	return &Calculator{ID: "calc-new", UserID: userID, Name: m.Name, Config: config, ConfigVersion: 1}, nil
+}
+
+// testPNG returns distinct PNG-sniffable bytes and their content-addressed key.
+func testPNG(seed string) ([]byte, string) {
+	data := append([]byte("\x89PNG\r\n\x1a\n"), seed...)
+	return data, fmt.Sprintf("%x.png", sha256.Sum256(data))
+}
+
+func newBundleService(getter Getter, importer Importer, assets BundleAssetStore) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithBundles(importer, assets)
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	logo, logoKey := testPNG("logo")
+	_, missingKey := testPNG("missing")
+	config := fmt.Sprintf(`{"fields":[{"id":"f1","type":"image_select","label":"Style","required":true,"variableName":"style","options":[`+
+		`{"id":"o1","label":"A","value":"1","imageUrl":"https://staging.example.com/assets/%s"},`+
+		`{"id":"o2","label":"B","value":"2","imageUrl":"https://staging.example.com/assets/%s"}]}]}`, logoKey, missingKey)
+	source := &memAssetStore{baseURL: "https://staging.example.com/assets", objects: map[string][]byte{logoKey: logo}}
+	exporter := newBundleService(&stubGetter{calc: &Calculator{
+		ID: "calc-abc", Name: "Roof quote", Description: "Estimates roofing", Tags: []string{"roofing"}, Config: []byte(config), ConfigVersion: 7,
+	}}, &stubImporter{}, source)
+
+	var bundle bytes.Buffer
+	if err := exporter.Export(context.Background(), "calc-abc", "user-xyz", &bundle); err != nil {
+		t.Fatalf("Export() returned unexpected error: %v", err)
+	}
+
+	target := &memAssetStore{baseURL: "https://cdn.example.com", objects: map[string][]byte{}}
+	importer := &stubImporter{}
+	svc := newBundleService(&stubGetter{}, importer, target)
+	calc, err := svc.Import(context.Background(), "user-new", bytes.NewReader(bundle.Bytes()), int64(bundle.Len()))
+	if err != nil {
+		t.Fatalf("Import() returned unexpected error: %v", err)
+	}
+	if calc.ID != "calc-new" || importer.gotUserID != "user-new" {
+		t.Errorf("expected calculator imported for user-new, got %+v", calc)
+	}
+	if importer.gotMeta.Name != "Roof quote" || importer.gotMeta.Description != "Estimates roofing" || len(importer.gotMeta.Tags) != 1 {
+		t.Errorf("expected metadata carried over, got %+v", importer.gotMeta)
+	}
+	if !bytes.Equal(target.objects[logoKey], logo) {
+		t.Error("expected bundled asset uploaded to target storage")
+	}
+	got := string(importer.gotConfig)
+	if !strings.Contains(got, "https://cdn.example.com/"+logoKey) {
+		t.Errorf("expected bundled asset URL rewritten, got %s", got)
+	}
+	// The missing asset could not be bundled, so its URL is left alone.
+	if !strings.Contains(got, "https://staging.example.com/assets/"+missingKey) {
+		t.Errorf("expected unbundled asset URL unchanged, got %s", got)
+	}
+}
+
+func TestExport_ManifestVersions(t *testing.T) {
+	svc := newBundleService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{}`)}}, &stubImporter{}, &memAssetStore{objects: map[string][]byte{}})
+
+	var bundle bytes.Buffer
+	if err := svc.Export(context.Background(), "calc-abc", "user-xyz", &bundle); err != nil {
+		t.Fatalf("Export() returned unexpected error: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(bundle.Bytes()), int64(bundle.Len()))
+	if err != nil {
+		t.Fatalf("expected a zip archive: %v", err)
+	}
+	entries := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+	raw, err := readZipEntry(entries, bundleManifestName, MaxBundleConfigSize)
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("decoding manifest: %v", err)
+	}
+	if manifest.FormatVersion != BundleFormatVersion || manifest.ConfigSchemaVersion != configschema.Version {
+		t.Errorf("expected format %d and schema %d, got %d and %d",
+			BundleFormatVersion, configschema.Version, manifest.FormatVersion, manifest.ConfigSchemaVersion)
+	}
+}
+
+func TestExport_OwnershipChecked(t *testing.T) {
+	svc := newBundleService(&stubGetter{err: ErrForbidden}, &stubImporter{}, &memAssetStore{})
+
+	if err := svc.Export(context.Background(), "calc-abc", "user-xyz", io.Discard); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got: %v", err)
+	}
+}
+
+// buildBundle writes a bundle with the given manifest and extra entries.
+func buildBundle(t *testing.T, manifest any, entries map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if manifest != nil {
+		raw, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatalf("encoding manifest: %v", err)
+		}
+		if err := writeZipEntry(zw, bundleManifestName, zip.Deflate, raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range entries {
+		if err := writeZipEntry(zw, name, zip.Deflate, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImport_Rejects(t *testing.T) {
+	logo, logoKey := testPNG("logo")
+	_, otherKey := testPNG("other")
+	gif := []byte("GIF89a-not-a-png")
+	gifKey := fmt.Sprintf("%x.png", sha256.Sum256(gif))
+	valid := bundleManifest{FormatVersion: 1, ConfigSchemaVersion: 1, Calculator: bundleCalculator{Name: "Quote"}}
+	withAsset := func(key string) bundleManifest {
+		m := valid
+		m.Assets = []bundleAsset{{Key: key}}
+		return m
+	}
+	newer := valid
+	newer.FormatVersion = BundleFormatVersion + 1
+	newerSchema := valid
+	newerSchema.ConfigSchemaVersion = configschema.Version + 1
+	noSchema := valid
+	noSchema.ConfigSchemaVersion = 0
+	config := []byte(`{}`)
+
+	tests := []struct {
+		name    string
+		bundle  []byte
+		wantErr string
+	}{
+		{"not a zip", []byte("plain text"), "not a zip archive"},
+		{"missing manifest", buildBundle(t, nil, map[string][]byte{bundleConfigName: config}), "missing manifest.json"},
+		{"newer format", buildBundle(t, newer, map[string][]byte{bundleConfigName: config}), "unsupported format_version"},
+		{"newer schema", buildBundle(t, newerSchema, map[string][]byte{bundleConfigName: config}), "config_schema_version"},
+		{"missing schema", buildBundle(t, noSchema, map[string][]byte{bundleConfigName: config}), "config_schema_version"},
+		{"missing config", buildBundle(t, valid, nil), "missing config.json"},
+		{"missing asset data", buildBundle(t, withAsset(logoKey), map[string][]byte{bundleConfigName: config}), "missing assets/"},
+		{"checksum mismatch", buildBundle(t, withAsset(otherKey), map[string][]byte{bundleConfigName: config, bundleAssetDir + otherKey: logo}), "checksum"},
+		{"wrong image type", buildBundle(t, withAsset(gifKey), map[string][]byte{bundleConfigName: config, bundleAssetDir + gifKey: gif}), "not a png image"},
+		{"path traversal key", buildBundle(t, withAsset("../etc/passwd"), map[string][]byte{bundleConfigName: config}), "invalid asset key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memAssetStore{objects: map[string][]byte{}}
+			svc := newBundleService(&stubGetter{}, &stubImporter{}, store)
+
+			_, err := svc.Import(context.Background(), "user-xyz", bytes.NewReader(tt.bundle), int64(len(tt.bundle)))
+			var berr *BundleError
+			if !errors.As(err, &berr) {
+				t.Fatalf("expected *BundleError, got: %v", err)
+			}
+			if !strings.Contains(berr.Message, tt.wantErr) {
+				t.Errorf("expected message containing %q, got %q", tt.wantErr, berr.Message)
+			}
+			if len(store.objects) != 0 {
+				t.Error("expected nothing uploaded for a rejected bundle")
+			}
+		})
+	}
+}
+
+func TestImport_AssetsTooLarge(t *testing.T) {
+	manifest := bundleManifest{FormatVersion: 1, ConfigSchemaVersion: 1, Calculator: bundleCalculator{Name: "Quote"}}
+	entries := map[string][]byte{bundleConfigName: []byte(`{}`)}
+	// Each asset is within MaxBundleAssetSize and compresses to almost
+	// nothing, but together they decompress past MaxBundleAssetBytes.
+	for i := 0; i <= MaxBundleAssetBytes/MaxBundleAssetSize; i++ {
+		data, key := testPNG(fmt.Sprintf("%d%s", i, make([]byte, MaxBundleAssetSize-16)))
+		manifest.Assets = append(manifest.Assets, bundleAsset{Key: key})
+		entries[bundleAssetDir+key] = data
+	}
+	bundle := buildBundle(t, manifest, entries)
+	store := &memAssetStore{objects: map[string][]byte{}}
+	svc := newBundleService(&stubGetter{}, &stubImporter{}, store)
+
+	_, err := svc.Import(context.Background(), "user-xyz", bytes.NewReader(bundle), int64(len(bundle)))
+	var berr *BundleError
+	if !errors.As(err, &berr) || !strings.Contains(berr.Message, "in total") {
+		t.Fatalf("expected a *BundleError for the total asset size, got: %v", err)
+	}
+	if len(store.objects) != 0 {
+		t.Error("expected nothing uploaded for a rejected bundle")
+	}
+}
+
+func TestImport_InvalidConfig(t *testing.T) {
+	bundle := buildBundle(t, bundleManifest{FormatVersion: 1, ConfigSchemaVersion: 1}, map[string][]byte{
+		bundleConfigName: []byte(`{"fields":"not an array"}`),
+	})
+	svc := newBundleService(&stubGetter{}, &stubImporter{}, &memAssetStore{objects: map[string][]byte{}})
+
+	_, err := svc.Import(context.Background(), "user-xyz", bytes.NewReader(bundle), int64(len(bundle)))
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("expected *configschema.ValidationError, got: %v", err)
+	}
+}
+
+func TestPostgresImportCalculator(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rows := sqlmock.NewRows(listColumns).AddRow("calc-new", "user-id", "Quote", []byte(`{}`), 1, false, now, now, "", "{roofing}", nil, 1, 0, nil)
+	mock.ExpectQuery(`INSERT INTO calculators \(user_id, name, description, tags, config\)`).
+		WithArgs("user-id", "Quote", "", sqlmock.AnyArg(), []byte(`{}`)).
+		WillReturnRows(rows)
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.ImportCalculator(context.Background(), "user-id", Metadata{Name: "Quote", Tags: []string{"roofing"}}, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("ImportCalculator() returned unexpected error: %v", err)
+	}
+	if calc.ID != "calc-new" || len(calc.Tags) != 1 {
+		t.Errorf("unexpected calculator: %+v", calc)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package calculator
+
+import (
+	"context"
+	"log/slog"
+)
+
+// CachePurger evicts cached public responses by surrogate key, so a change to
+// a calculator is visible to widgets before cached copies expire.
+type CachePurger interface {
+	Purge(ctx context.Context, keys ...string) error
+}
+
+// CacheKey returns the surrogate key that tags every cached public response
+// for the calculator with the given ID.
+func CacheKey(id string) string {
+	return "calculator-" + id
+}
+
+// WithCachePurger configures CDN purging on the Service and returns the same
+// Service pointer for chained calls. Purge failures are logged to logger and
+// never fail the operation that triggered them; the cached copy then expires
+// on its own.
+func (s *Service) WithCachePurger(purger CachePurger, logger *slog.Logger) *Service {
+	s.cachePurger = purger
+	s.logger = logger
+	return s
+}
+
+// purgeCache evicts cached public responses for the calculator with the given
+// ID. It is called after every change to what the public endpoints serve:
+// publishing, deleting, and restoring from the trash. Draft edits are not
+// public, so they do not purge.
+func (s *Service) purgeCache(ctx context.Context, id string) {
+	if s.cachePurger == nil {
+		return
+	}
+	// The change has already been committed, so the purge must not be
+	// abandoned if the client disconnects.
+	if err := s.cachePurger.Purge(context.WithoutCancel(ctx), CacheKey(id)); err != nil {
+		s.logger.Warn("purging calculator from CDN cache", "calculator_id", id, "error", err)
+	}
+}
@@ -0,0 +1,86 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+type stubCachePurger struct {
+	keys []string
+	err  error
+}
+
+func (s *stubCachePurger) Purge(_ context.Context, keys ...string) error {
+	s.keys = append(s.keys, keys...)
+	return s.err
+}
+
+func newCacheService(getter *stubGetter, deleter *stubDeleter, purger *stubCachePurger) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, deleter, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithPublisher(&stubPublisher{calc: &Calculator{ID: "calc-abc"}}).
+		WithCachePurger(purger, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestCachePurge_OnPublishAndDelete(t *testing.T) {
+	purger := &stubCachePurger{}
+	svc := newCacheService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{}`)}}, &stubDeleter{}, purger)
+
+	if _, err := svc.Publish(context.Background(), "calc-abc", "user-xyz", 0); err != nil {
+		t.Fatalf("Publish() returned unexpected error: %v", err)
+	}
+	if err := svc.Delete(context.Background(), "calc-abc", "user-xyz"); err != nil {
+		t.Fatalf("Delete() returned unexpected error: %v", err)
+	}
+	want := []string{"calculator-calc-abc", "calculator-calc-abc"}
+	if !reflect.DeepEqual(purger.keys, want) {
+		t.Errorf("expected purges %v, got %v", want, purger.keys)
+	}
+}
+
+func TestCachePurge_OnMetadataUpdate(t *testing.T) {
+	purger := &stubCachePurger{}
+	store := &stubMetadataStore{calc: &Calculator{ID: "calc-abc", MetadataVersion: 2}}
+	svc := newCacheService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, &stubDeleter{}, purger).
+		WithMetadata(store, store)
+
+	if _, err := svc.UpdateMetadata(context.Background(), "calc-abc", "user-xyz", 0, Metadata{Name: "Renamed"}); err != nil {
+		t.Fatalf("UpdateMetadata() returned unexpected error: %v", err)
+	}
+	// The standalone page shows the name and description.
+	if want := []string{"calculator-calc-abc"}; !reflect.DeepEqual(purger.keys, want) {
+		t.Errorf("expected purges %v, got %v", want, purger.keys)
+	}
+}
+
+func TestCachePurge_NotOnFailure(t *testing.T) {
+	purger := &stubCachePurger{}
+	svc := newCacheService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, &stubDeleter{err: errors.New("db down")}, purger)
+
+	if err := svc.Delete(context.Background(), "calc-abc", "user-xyz"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(purger.keys) != 0 {
+		t.Errorf("expected no purge after a failed delete, got %v", purger.keys)
+	}
+}
+
+func TestCachePurge_FailureIgnored(t *testing.T) {
+	purger := &stubCachePurger{err: errors.New("cdn unavailable")}
+	svc := newCacheService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, &stubDeleter{}, purger)
+
+	if err := svc.Delete(context.Background(), "calc-abc", "user-xyz"); err != nil {
+		t.Errorf("expected purge failure not to fail Delete, got: %v", err)
+	}
+}
+
+func TestCachePurge_NotConfigured(t *testing.T) {
+	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: &Calculator{ID: "calc-abc"}}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+
+	if err := svc.Delete(context.Background(), "calc-abc", "user-xyz"); err != nil {
+		t.Errorf("Delete() returned unexpected error: %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 )
 
@@ -14,6 +16,26 @@ var ErrNotFound = errors.New("calculator not found")
 // ErrForbidden is returned when the authenticated user does not own the calculator.
 var ErrForbidden = errors.New("access forbidden")
 
+// ErrVersionConflict is returned when a conditional update names a config_version
+// that is no longer current. Use errors.As with *VersionConflictError to read
+// the current version.
+var ErrVersionConflict = errors.New("config version conflict")
+
+// VersionConflictError reports the calculator's current config_version when a
+// conditional update fails. It matches ErrVersionConflict under errors.Is.
+type VersionConflictError struct {
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("config version conflict: current version is %d", e.CurrentVersion)
+}
+
+// Is reports whether target is ErrVersionConflict.
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
 // Calculator represents a stored calculator definition.
 type Calculator struct {
 	ID            string
@@ -24,6 +46,23 @@ type Calculator struct {
 	IsDeleted     bool
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	DeletedAt     *time.Time // set only in trash listings
+
+	// Metadata is versioned by MetadataVersion, independently of Config, so
+	// metadata edits never change ConfigVersion.
+	Description     string
+	Tags            []string
+	FolderID        string // "" when the calculator is not in a folder
+	MetadataVersion int
+
+	// PublishedVersion is the config_version served to widgets, or 0 if the
+	// calculator has never been published. Config is the draft.
+	PublishedVersion int
+	PublishedAt      *time.Time
+
+	// EmbedDomains is the embed allow-list. It is set only by
+	// GetPublicConfig, which is where it is enforced.
+	EmbedDomains []string
 }
 
 // Creator creates new calculator records.
@@ -31,9 +70,9 @@ type Creator interface {
 	CreateCalculator(ctx context.Context, userID string) (*Calculator, error)
 }
 
-// Lister fetches lists of calculator records.
+// Lister fetches pages of calculator records matching a ListQuery.
 type Lister interface {
-	ListCalculators(ctx context.Context, userID string) ([]*Calculator, error)
+	ListCalculators(ctx context.Context, q ListQuery) ([]*Calculator, error)
 }
 
 // Getter fetches a single calculator record.
@@ -41,17 +80,23 @@ type Getter interface {
 	GetCalculator(ctx context.Context, id, userID string) (*Calculator, error)
 }
 
-// Updater updates the config of an existing calculator record.
+// Updater updates the config of an existing calculator record, recording the
+// new config as a revision authored by authorID. When expectedVersion is
+// non-zero the update only applies if it matches the current config_version.
+// An autosave may replace the author's own recent revision rather than adding
+// one.
 type Updater interface {
-	UpdateCalculator(ctx context.Context, id string, config []byte) (*Calculator, error)
+	UpdateCalculator(ctx context.Context, id, authorID string, expectedVersion int, autosave bool, config []byte) (*Calculator, error)
 }
 
-// Deleter soft-deletes a calculator record.
+// Deleter soft-deletes a calculator record, recording when it was deleted.
 type Deleter interface {
 	DeleteCalculator(ctx context.Context, id string) error
 }
 
-// PublicConfigGetter fetches calculator config without ownership checks, for public widget rendering.
+// PublicConfigGetter fetches the published calculator config without ownership
+// checks, for public widget rendering. The returned Config and ConfigVersion
+// are the published snapshot rather than the draft.
 type PublicConfigGetter interface {
 	GetPublicCalculatorConfig(ctx context.Context, id string) (*Calculator, error)
 }
@@ -63,13 +108,33 @@ type Duplicator interface {
 
 // Service handles calculator business logic.
 type Service struct {
-	creator            Creator
-	lister             Lister
-	getter             Getter
-	updater            Updater
-	deleter            Deleter
-	publicConfigGetter PublicConfigGetter
-	duplicator         Duplicator
+	creator              Creator
+	lister               Lister
+	getter               Getter
+	updater              Updater
+	deleter              Deleter
+	publicConfigGetter   PublicConfigGetter
+	duplicator           Duplicator
+	revisionLister       RevisionLister
+	revisionGetter       RevisionGetter
+	revisionRestorer     RevisionRestorer
+	patcher              Patcher
+	trashLister          TrashLister
+	trashRestorer        TrashRestorer
+	metadataUpdater      MetadataUpdater
+	folders              FolderStore
+	publisher            Publisher
+	cachePurger          CachePurger
+	templates            TemplateStore
+	templateInstantiator TemplateInstantiator
+	importer             Importer
+	bundleAssets         BundleAssetStore
+	bulkJobs             BulkJobStore
+	transfers            TransferStore
+	transferEmails       TransferEmailSender
+	genTransferToken     func() (token, tokenHash string, err error)
+	embedDomains         EmbedDomainStore
+	logger               *slog.Logger
 }
 
 // NewService creates a calculator Service with the given creator, lister, getter, updater, deleter, publicConfigGetter, and duplicator.
@@ -86,16 +151,61 @@ func (s *Service) Create(ctx context.Context, userID string) (*Calculator, error
 	return calc, nil
 }
 
-// List returns all non-deleted calculators owned by userID, ordered by updated_at descending.
-func (s *Service) List(ctx context.Context, userID string) ([]*Calculator, error) {
-	calcs, err := s.lister.ListCalculators(ctx, userID)
+// List returns one page of the non-deleted calculators owned by userID,
+// ordered and filtered according to opts. Ties on the sort field are broken by
+// ID so pages never overlap or skip rows.
+// Returns a *MetadataError if opts.Tags contains an invalid tag.
+// Returns ErrInvalidCursor if opts.Cursor is malformed or was issued for a
+// different sort order or filter.
+func (s *Service) List(ctx context.Context, userID string, opts ListOptions) (*Page, error) {
+	tags, err := NormalizeTags(opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+	q := ListQuery{
+		UserID:     userID,
+		Sort:       opts.Sort,
+		Descending: opts.Descending,
+		Search:     strings.TrimSpace(opts.Search),
+		Tags:       tags,
+		FolderID:   opts.FolderID,
+		Unfiled:    opts.Unfiled,
+	}
+	if q.Sort == "" {
+		q.Sort = SortByUpdatedAt
+	}
+	if !q.Sort.Valid() {
+		return nil, fmt.Errorf("unsupported sort field %q", q.Sort)
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor, q)
+		if err != nil {
+			return nil, err
+		}
+		q.After = after
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	q.Limit = limit + 1
+	calcs, err := s.lister.ListCalculators(ctx, q)
 	if err != nil {
 		return nil, fmt.Errorf("listing calculators: %w", err)
 	}
-	return calcs, nil
+	page := &Page{Calculators: calcs}
+	if len(calcs) > limit {
+		page.Calculators = calcs[:limit]
+		page.NextCursor = encodeCursor(q, calcs[limit-1])
+	}
+	return page, nil
 }
 
-// Get returns the calculator identified by id if it is owned by userID.
+// Get returns the calculator identified by id if it is owned by userID, with
+// its config upgraded to the current schema version.
 // Returns ErrNotFound if the calculator does not exist or is soft-deleted.
 // Returns ErrForbidden if the calculator exists but is owned by a different user.
 func (s *Service) Get(ctx context.Context, id, userID string) (*Calculator, error) {
@@ -103,35 +213,57 @@ func (s *Service) Get(ctx context.Context, id, userID string) (*Calculator, erro
 	if err != nil {
 		return nil, fmt.Errorf("getting calculator: %w", err)
 	}
+	if err := upgradeConfig(calc); err != nil {
+		return nil, err
+	}
 	return calc, nil
 }
 
-// Update verifies ownership of the calculator then applies the new config.
+// Update upgrades the new config to the current schema version, validates it
+// against the calculator config schema, verifies ownership of the calculator,
+// then applies the new config and records it as a revision authored by userID.
+// When expectedVersion is non-zero the write is conditional on it matching the
+// calculator's current config_version; zero applies the update unconditionally.
+// autosave marks a save the editor made on its own, which may be folded into
+// the user's previous revision (see RevisionCoalesceWindow).
+// Returns a *configschema.ValidationError if the config violates the schema.
+// Returns a *VersionConflictError (matching ErrVersionConflict) if expectedVersion is stale.
 // Returns ErrNotFound if the calculator does not exist or is soft-deleted.
 // Returns ErrForbidden if the calculator exists but is owned by a different user.
-func (s *Service) Update(ctx context.Context, id, userID string, config []byte) (*Calculator, error) {
+func (s *Service) Update(ctx context.Context, id, userID string, expectedVersion int, autosave bool, config []byte) (*Calculator, error) {
+	config, err := upgradeForWrite(config)
+	if err != nil {
+		return nil, err
+	}
 	if _, err := s.getter.GetCalculator(ctx, id, userID); err != nil {
 		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
 	}
-	calc, err := s.updater.UpdateCalculator(ctx, id, config)
+	calc, err := s.updater.UpdateCalculator(ctx, id, userID, expectedVersion, autosave, config)
 	if err != nil {
 		return nil, fmt.Errorf("updating calculator: %w", err)
 	}
 	return calc, nil
 }
 
-// GetPublicConfig returns the calculator config for public widget rendering.
-// No ownership check is performed — any non-deleted calculator is accessible.
-// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// GetPublicConfig returns the published calculator config for public widget
+// rendering, upgraded to the current schema version. No ownership check is
+// performed — any published, non-deleted calculator is accessible. Unpublished
+// draft changes are never returned.
+// Returns ErrNotFound if the calculator does not exist, is soft-deleted, or has
+// never been published.
 func (s *Service) GetPublicConfig(ctx context.Context, id string) (*Calculator, error) {
 	calc, err := s.publicConfigGetter.GetPublicCalculatorConfig(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("getting public calculator config: %w", err)
 	}
+	if err := upgradeConfig(calc); err != nil {
+		return nil, err
+	}
 	return calc, nil
 }
 
-// Delete verifies ownership of the calculator then soft-deletes it.
+// Delete verifies ownership of the calculator then soft-deletes it, moving it
+// to the trash until it is restored or purged.
 // Returns ErrNotFound if the calculator does not exist or is soft-deleted.
 // Returns ErrForbidden if the calculator exists but is owned by a different user.
 func (s *Service) Delete(ctx context.Context, id, userID string) error {
@@ -141,6 +273,7 @@ func (s *Service) Delete(ctx context.Context, id, userID string) error {
 	if err := s.deleter.DeleteCalculator(ctx, id); err != nil {
 		return fmt.Errorf("deleting calculator: %w", err)
 	}
+	s.purgeCache(ctx, id)
 	return nil
 }
 
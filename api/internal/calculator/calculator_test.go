@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
 )
 
 type stubCreator struct {
@@ -19,9 +21,11 @@ func (s *stubCreator) CreateCalculator(_ context.Context, _ string) (*Calculator
 type stubLister struct {
 	calcs []*Calculator
 	err   error
+	query ListQuery
 }
 
-func (s *stubLister) ListCalculators(_ context.Context, _ string) ([]*Calculator, error) {
+func (s *stubLister) ListCalculators(_ context.Context, q ListQuery) ([]*Calculator, error) {
+	s.query = q
 	return s.calcs, s.err
 }
 
@@ -37,9 +41,12 @@ func (s *stubGetter) GetCalculator(_ context.Context, _, _ string) (*Calculator,
 type stubUpdater struct {
 	calc *Calculator
 	err  error
+	// gotConfig records the config passed to UpdateCalculator.
+	gotConfig []byte
 }
 
-func (s *stubUpdater) UpdateCalculator(_ context.Context, _ string, _ []byte) (*Calculator, error) {
+func (s *stubUpdater) UpdateCalculator(_ context.Context, _, _ string, _ int, _ bool, config []byte) (*Calculator, error) {
+	s.gotConfig = config
 	return s.calc, s.err
 }
 
@@ -100,10 +107,11 @@ func TestList_Success(t *testing.T) {
 		{ID: "calc-2", UserID: "user-xyz", CreatedAt: now, UpdatedAt: now},
 	}
 	svc := NewService(&stubCreator{}, &stubLister{calcs: want}, &stubGetter{}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
-	got, err := svc.List(context.Background(), "user-xyz")
+	page, err := svc.List(context.Background(), "user-xyz", ListOptions{})
 	if err != nil {
 		t.Fatalf("List() returned unexpected error: %v", err)
 	}
+	got := page.Calculators
 	if len(got) != 2 {
 		t.Fatalf("expected 2 calculators, got %d", len(got))
 	}
@@ -117,10 +125,11 @@ func TestList_Success(t *testing.T) {
 
 func TestList_Empty(t *testing.T) {
 	svc := NewService(&stubCreator{}, &stubLister{calcs: []*Calculator{}}, &stubGetter{}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
-	got, err := svc.List(context.Background(), "user-xyz")
+	page, err := svc.List(context.Background(), "user-xyz", ListOptions{})
 	if err != nil {
 		t.Fatalf("List() returned unexpected error: %v", err)
 	}
+	got := page.Calculators
 	if got == nil {
 		t.Fatal("expected empty slice, got nil")
 	}
@@ -132,7 +141,7 @@ func TestList_Empty(t *testing.T) {
 func TestList_RepositoryError(t *testing.T) {
 	wantErr := errors.New("db failure")
 	svc := NewService(&stubCreator{}, &stubLister{err: wantErr}, &stubGetter{}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
-	_, err := svc.List(context.Background(), "user-xyz")
+	_, err := svc.List(context.Background(), "user-xyz", ListOptions{})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -213,7 +222,7 @@ func TestUpdate_Success(t *testing.T) {
 		UpdatedAt:     now,
 	}
 	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: existing}, &stubUpdater{calc: updated}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
-	got, err := svc.Update(context.Background(), "calc-abc", "user-xyz", []byte(`{"key":"value"}`))
+	got, err := svc.Update(context.Background(), "calc-abc", "user-xyz", 0, false, []byte(`{"key":"value"}`))
 	if err != nil {
 		t.Fatalf("Update() returned unexpected error: %v", err)
 	}
@@ -227,7 +236,7 @@ func TestUpdate_Success(t *testing.T) {
 
 func TestUpdate_GetterError_NotFound(t *testing.T) {
 	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{err: ErrNotFound}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
-	_, err := svc.Update(context.Background(), "calc-missing", "user-xyz", []byte(`{}`))
+	_, err := svc.Update(context.Background(), "calc-missing", "user-xyz", 0, false, []byte(`{}`))
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -238,7 +247,7 @@ func TestUpdate_GetterError_NotFound(t *testing.T) {
 
 func TestUpdate_GetterError_Forbidden(t *testing.T) {
 	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{err: ErrForbidden}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
-	_, err := svc.Update(context.Background(), "calc-abc", "other-user", []byte(`{}`))
+	_, err := svc.Update(context.Background(), "calc-abc", "other-user", 0, false, []byte(`{}`))
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -252,7 +261,7 @@ func TestUpdate_UpdaterError(t *testing.T) {
 	existing := &Calculator{ID: "calc-abc", UserID: "user-xyz", CreatedAt: now, UpdatedAt: now}
 	wantErr := errors.New("db failure")
 	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: existing}, &stubUpdater{err: wantErr}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
-	_, err := svc.Update(context.Background(), "calc-abc", "user-xyz", []byte(`{}`))
+	_, err := svc.Update(context.Background(), "calc-abc", "user-xyz", 0, false, []byte(`{}`))
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -261,6 +270,24 @@ func TestUpdate_UpdaterError(t *testing.T) {
 	}
 }
 
+func TestUpdate_InvalidConfig(t *testing.T) {
+	now := time.Now()
+	existing := &Calculator{ID: "calc-abc", UserID: "user-xyz", CreatedAt: now, UpdatedAt: now}
+	updater := &stubUpdater{calc: existing}
+	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: existing}, updater, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+	_, err := svc.Update(context.Background(), "calc-abc", "user-xyz", 0, false, []byte(`{"layoutMode":"sideways"}`))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected wrapped *configschema.ValidationError, got: %v", err)
+	}
+	if len(verr.Errors) != 1 || verr.Errors[0].Path != "layoutMode" {
+		t.Errorf("expected a single layoutMode error, got: %+v", verr.Errors)
+	}
+}
+
 func TestDelete_Success(t *testing.T) {
 	now := time.Now()
 	existing := &Calculator{ID: "calc-abc", UserID: "user-xyz", CreatedAt: now, UpdatedAt: now}
@@ -416,3 +443,17 @@ func TestDuplicate_DuplicatorError(t *testing.T) {
 		t.Errorf("expected wrapped wantErr, got: %v", err)
 	}
 }
+
+func TestUpdate_VersionConflict(t *testing.T) {
+	now := time.Now()
+	existing := &Calculator{ID: "calc-abc", UserID: "user-xyz", CreatedAt: now, UpdatedAt: now}
+	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: existing}, &stubUpdater{err: &VersionConflictError{CurrentVersion: 7}}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+	_, err := svc.Update(context.Background(), "calc-abc", "user-xyz", 3, false, []byte(`{}`))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected wrapped ErrVersionConflict, got: %v", err)
+	}
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.CurrentVersion != 7 {
+		t.Errorf("expected current version 7, got %+v", conflict)
+	}
+}
@@ -0,0 +1,23 @@
+package calculator
+
+// minorUnitExceptions lists the ISO 4217 currencies whose minor unit is not
+// two decimal places.
+var minorUnitExceptions = map[string]int{
+	// No minor unit.
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// Thousandths.
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// Ten-thousandths.
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits returns the number of decimal places in the minor unit of the
+// ISO 4217 currency code, such as 2 for USD (cents) and 0 for JPY. Codes not
+// known to have a different minor unit are assumed to use two decimals.
+func MinorUnits(code string) int {
+	if n, ok := minorUnitExceptions[code]; ok {
+		return n
+	}
+	return 2
+}
@@ -0,0 +1,28 @@
+package calculator
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogTransferEmailSender is a development implementation of TransferEmailSender
+// that logs the transfer instead of sending a real email. It is intended for
+// use in local development and should not be used in production.
+type LogTransferEmailSender struct {
+	logger *slog.Logger
+}
+
+// NewLogTransferEmailSender creates a LogTransferEmailSender backed by logger.
+func NewLogTransferEmailSender(logger *slog.Logger) *LogTransferEmailSender {
+	return &LogTransferEmailSender{logger: logger}
+}
+
+// SendTransferEmail logs the transfer to stdout.
+func (s *LogTransferEmailSender) SendTransferEmail(ctx context.Context, toEmail, calculatorName, rawToken string) error {
+	s.logger.InfoContext(ctx, "calculator transfer started",
+		"to", toEmail,
+		"calculator", calculatorName,
+		"token_present", rawToken != "",
+	)
+	return nil
+}
@@ -0,0 +1,149 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxEmbedDomains is the most host patterns an embed allow-list may hold.
+const MaxEmbedDomains = 50
+
+// EmbedDomainError reports an embed allow-list entry that failed validation.
+// Index is the position of the offending entry, or -1 for the list as a whole.
+type EmbedDomainError struct {
+	Index   int
+	Message string
+}
+
+func (e *EmbedDomainError) Error() string {
+	if e.Index < 0 {
+		return "invalid embed domains: " + e.Message
+	}
+	return fmt.Sprintf("invalid embed domain %d: %s", e.Index, e.Message)
+}
+
+// EmbedDomainStore reads and replaces a calculator's embed allow-list.
+type EmbedDomainStore interface {
+	// GetEmbedDomains returns the allow-list of the calculator identified by
+	// id. Returns ErrNotFound if no matching, non-deleted row exists.
+	GetEmbedDomains(ctx context.Context, id string) ([]string, error)
+	// SetEmbedDomains replaces the allow-list of the calculator identified by
+	// id. Returns ErrNotFound if no matching, non-deleted row exists.
+	SetEmbedDomains(ctx context.Context, id string, domains []string) error
+}
+
+// WithEmbedDomains configures embed allow-list support on the Service and
+// returns the same Service pointer for chained calls.
+func (s *Service) WithEmbedDomains(store EmbedDomainStore) *Service {
+	s.embedDomains = store
+	return s
+}
+
+// GetEmbedDomains verifies ownership of the calculator and returns its embed
+// allow-list. An empty list means the calculator may be embedded anywhere.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) GetEmbedDomains(ctx context.Context, id, userID string) ([]string, error) {
+	if _, err := s.getter.GetCalculator(ctx, id, userID); err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	domains, err := s.embedDomains.GetEmbedDomains(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting embed domains: %w", err)
+	}
+	return domains, nil
+}
+
+// SetEmbedDomains normalizes and validates domains, verifies ownership of the
+// calculator, and replaces its embed allow-list. The change applies to the
+// published calculator straight away, so its cached public config is purged.
+// An empty list removes the restriction.
+// Returns an *EmbedDomainError if an entry is not a valid host pattern.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) SetEmbedDomains(ctx context.Context, id, userID string, domains []string) ([]string, error) {
+	normalized, err := NormalizeEmbedDomains(domains)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getter.GetCalculator(ctx, id, userID); err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	if err := s.embedDomains.SetEmbedDomains(ctx, id, normalized); err != nil {
+		return nil, fmt.Errorf("setting embed domains: %w", err)
+	}
+	s.purgeCache(ctx, id)
+	return normalized, nil
+}
+
+// NormalizeEmbedDomains lowercases and trims each host pattern, drops
+// duplicates, and checks that every entry is a host name such as
+// "example.com", optionally prefixed with "*." to match any of its
+// subdomains. Ports, schemes, and paths are not part of a pattern.
+// Returns an *EmbedDomainError describing the first invalid entry.
+func NormalizeEmbedDomains(domains []string) ([]string, error) {
+	if len(domains) > MaxEmbedDomains {
+		return nil, &EmbedDomainError{Index: -1, Message: fmt.Sprintf("must have at most %d entries", MaxEmbedDomains)}
+	}
+	out := make([]string, 0, len(domains))
+	seen := make(map[string]bool, len(domains))
+	for i, d := range domains {
+		d = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+		host := strings.TrimPrefix(d, "*.")
+		if !validHostName(host) {
+			return nil, &EmbedDomainError{Index: i, Message: "must be a host name such as example.com or *.example.com"}
+		}
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// validHostName reports whether host is a DNS name or IPv4 address: dot
+// separated labels of 1-63 letters, digits, and inner hyphens.
+func validHostName(host string) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EmbedAllowed reports whether a page on host may embed a calculator with
+// the given allow-list. An empty list allows every host, including an unknown
+// one (""). "*.example.com" matches any subdomain of example.com at any depth
+// but not example.com itself.
+func EmbedAllowed(domains []string, host string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return false
+	}
+	for _, d := range domains {
+		if suffix, ok := strings.CutPrefix(d, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == d {
+			return true
+		}
+	}
+	return false
+}
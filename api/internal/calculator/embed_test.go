@@ -0,0 +1,139 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type stubEmbedDomainStore struct {
+	domains []string
+	err     error
+	set     []string
+}
+
+func (s *stubEmbedDomainStore) GetEmbedDomains(_ context.Context, _ string) ([]string, error) {
+	return s.domains, s.err
+}
+
+func (s *stubEmbedDomainStore) SetEmbedDomains(_ context.Context, _ string, domains []string) error {
+	s.set = domains
+	return s.err
+}
+
+func newEmbedService(getter *stubGetter, store *stubEmbedDomainStore, purger *stubCachePurger) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithCachePurger(purger, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithEmbedDomains(store)
+}
+
+func TestNormalizeEmbedDomains(t *testing.T) {
+	got, err := NormalizeEmbedDomains([]string{" Example.COM ", "*.shop.example.com.", "example.com", "localhost", "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("NormalizeEmbedDomains() returned unexpected error: %v", err)
+	}
+	want := []string{"example.com", "*.shop.example.com", "localhost", "127.0.0.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestNormalizeEmbedDomains_Invalid(t *testing.T) {
+	for _, d := range []string{"", "https://example.com", "example.com/path", "example.com:8080", "*", "*.", "shop.*.example.com", "-bad.example.com", "exa_mple.com"} {
+		t.Run(d, func(t *testing.T) {
+			_, err := NormalizeEmbedDomains([]string{"ok.example.com", d})
+			var derr *EmbedDomainError
+			if !errors.As(err, &derr) || derr.Index != 1 {
+				t.Errorf("expected *EmbedDomainError at index 1, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmbedDomains_TooMany(t *testing.T) {
+	domains := make([]string, MaxEmbedDomains+1)
+	for i := range domains {
+		domains[i] = "example.com"
+	}
+	var derr *EmbedDomainError
+	if _, err := NormalizeEmbedDomains(domains); !errors.As(err, &derr) || derr.Index != -1 {
+		t.Errorf("expected list-level *EmbedDomainError, got: %v", err)
+	}
+}
+
+func TestEmbedAllowed(t *testing.T) {
+	domains := []string{"example.com", "*.agency.test"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.com.", true},
+		{"www.example.com", false},
+		{"client.agency.test", true},
+		{"a.b.agency.test", true},
+		{"agency.test", false},
+		{"evilagency.test", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := EmbedAllowed(domains, tt.host); got != tt.want {
+			t.Errorf("EmbedAllowed(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+	if !EmbedAllowed(nil, "") {
+		t.Error("expected an empty allow-list to allow any host")
+	}
+}
+
+func TestSetEmbedDomains_StoresAndPurges(t *testing.T) {
+	store := &stubEmbedDomainStore{}
+	purger := &stubCachePurger{}
+	svc := newEmbedService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, store, purger)
+
+	got, err := svc.SetEmbedDomains(context.Background(), "calc-abc", "user-xyz", []string{"Example.com"})
+	if err != nil {
+		t.Fatalf("SetEmbedDomains() returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"example.com"}) || !reflect.DeepEqual(store.set, got) {
+		t.Errorf("expected normalized list stored, got %v (stored %v)", got, store.set)
+	}
+	if !reflect.DeepEqual(purger.keys, []string{"calculator-calc-abc"}) {
+		t.Errorf("expected cache purge, got %v", purger.keys)
+	}
+}
+
+func TestSetEmbedDomains_OwnershipChecked(t *testing.T) {
+	store := &stubEmbedDomainStore{}
+	svc := newEmbedService(&stubGetter{err: ErrForbidden}, store, &stubCachePurger{})
+
+	if _, err := svc.SetEmbedDomains(context.Background(), "calc-abc", "user-xyz", nil); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got: %v", err)
+	}
+	if store.set != nil {
+		t.Error("expected store not to be called")
+	}
+}
+
+func TestPostgresSetEmbedDomains_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	mock.ExpectExec(`UPDATE calculators SET embed_domains = \$2 WHERE id = \$1 AND is_deleted = FALSE`).
+		WithArgs("calc-id", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewPostgresCalculatorRepository(db)
+	if err := repo.SetEmbedDomains(context.Background(), "calc-id", []string{"example.com"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
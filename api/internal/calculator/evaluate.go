@@ -0,0 +1,147 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/formula"
+)
+
+// maxOutputDecimals is the precision of an output without a currency.
+const maxOutputDecimals = 10
+
+// OutputResult is the evaluated value of a single calculator output.
+// Decimal is the exact result, rounded as the output specifies and written in
+// plain decimal notation; Value is the same number as a float64 for clients
+// that do not need exactness. Both are empty when the expression failed to
+// evaluate, and Error then describes why.
+type OutputResult struct {
+	ID      string
+	Label   string
+	Value   *float64
+	Decimal string
+	Error   string
+}
+
+// Evaluate computes every output of the calculator identified by id from the
+// supplied input values, using the grammar of the widget's formula engine but
+// exact decimal arithmetic (see formula.EvaluateExact). Each result is then
+// rounded with the output's rounding mode to its currency's minor unit, or to
+// ten decimal places when it has no currency. Fields missing from values take
+// their configured defaults.
+// No ownership check is performed — any non-deleted calculator is evaluable.
+// Returns a *configschema.ValidationError if values names a variable that no
+// field defines.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+func (s *Service) Evaluate(ctx context.Context, id string, values map[string]float64) ([]OutputResult, error) {
+	calc, err := s.GetPublicConfig(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := configschema.Parse(calc.Config)
+	if err != nil {
+		return nil, fmt.Errorf("parsing calculator config: %w", err)
+	}
+
+	vars := FieldDefaults(cfg.Fields)
+	var verr configschema.ValidationError
+	for name, value := range values {
+		if _, ok := vars[name]; !ok {
+			verr.Errors = append(verr.Errors, configschema.FieldError{
+				Path:    "values." + name,
+				Message: "does not match any field variable",
+			})
+			continue
+		}
+		vars[name] = value
+	}
+	if len(verr.Errors) > 0 {
+		return nil, &verr
+	}
+
+	results := make([]OutputResult, len(cfg.Outputs))
+	for i, out := range cfg.Outputs {
+		results[i] = evaluateOutput(out, vars)
+	}
+	return results, nil
+}
+
+// evaluateOutput evaluates out exactly and rounds the result as out specifies.
+func evaluateOutput(out configschema.Output, vars map[string]float64) OutputResult {
+	res := OutputResult{ID: out.ID, Label: out.Label}
+	exact, err := formula.EvaluateExact(out.Expression, vars)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	mode := formula.RoundingMode(out.Rounding)
+	if !formula.IsRoundingMode(mode) {
+		mode = formula.RoundHalfUp
+	}
+	decimals := maxOutputDecimals
+	if out.Currency != "" {
+		decimals = MinorUnits(out.Currency)
+	}
+	rounded := formula.Round(exact, decimals, mode)
+	value, _ := rounded.Float64()
+	res.Value = &value
+	res.Decimal = rounded.FloatString(decimals)
+	if out.Currency == "" && decimals > 0 {
+		// Without a currency there is no conventional number of places to show.
+		res.Decimal = strings.TrimSuffix(strings.TrimRight(res.Decimal, "0"), ".")
+	}
+	return res
+}
+
+// FieldDefaults builds a map of variableName → default numeric value for the
+// given fields, mirroring buildFieldDefaults in the dashboard. Number and
+// slider fields use their defaultValue (sliders fall back to min), dropdown
+// and radio fields use the numeric value of their first option, and all other
+// field types default to 0.
+func FieldDefaults(fields []configschema.Field) map[string]float64 {
+	defaults := make(map[string]float64, len(fields))
+	for _, f := range fields {
+		defaults[f.VariableName] = fieldDefault(f)
+	}
+	return defaults
+}
+
+func fieldDefault(f configschema.Field) float64 {
+	switch f.Type {
+	case configschema.FieldTypeNumber:
+		if f.DefaultValue != nil {
+			return *f.DefaultValue
+		}
+	case configschema.FieldTypeSlider:
+		if f.DefaultValue != nil {
+			return *f.DefaultValue
+		}
+		if f.Min != nil {
+			return *f.Min
+		}
+	case configschema.FieldTypeDropdown, configschema.FieldTypeRadio:
+		if len(f.Options) > 0 {
+			return parseLeadingFloat(f.Options[0].Value)
+		}
+	}
+	return 0
+}
+
+// leadingFloat matches the numeric prefix JavaScript's parseFloat accepts.
+var leadingFloat = regexp.MustCompile(`^\s*[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?`)
+
+// parseLeadingFloat mirrors `parseFloat(s) || 0`: it parses the longest
+// numeric prefix of s and returns 0 when there is none.
+func parseLeadingFloat(s string) float64 {
+	m := leadingFloat.FindString(s)
+	if m == "" {
+		return 0
+	}
+	// Out-of-range prefixes overflow to ±Inf, as in JavaScript.
+	v, _ := strconv.ParseFloat(strings.TrimSpace(m), 64)
+	return v
+}
@@ -0,0 +1,159 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+const evaluateConfig = `{
+	"fields": [
+		{"id": "f1", "type": "number", "label": "Square feet", "required": true, "variableName": "sqft", "defaultValue": 100},
+		{"id": "f2", "type": "dropdown", "label": "Finish", "required": false, "variableName": "finish",
+		 "options": [{"id": "o1", "label": "Basic", "value": "1.5"}, {"id": "o2", "label": "Premium", "value": "3"}]},
+		{"id": "f3", "type": "slider", "label": "Coats", "required": false, "variableName": "coats", "min": 2, "max": 5}
+	],
+	"outputs": [
+		{"id": "out1", "label": "Total", "expression": "{sqft} * {finish} * {coats}"},
+		{"id": "out2", "label": "Broken", "expression": "{sqftt}"},
+		{"id": "out3", "label": "Infinite", "expression": "1 / 0"}
+	]
+}`
+
+func newEvaluateService(getter *stubPublicConfigGetter) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, &stubGetter{}, &stubUpdater{}, &stubDeleter{}, getter, &stubDuplicator{})
+}
+
+func TestEvaluate_UsesDefaultsAndOverrides(t *testing.T) {
+	svc := newEvaluateService(&stubPublicConfigGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(evaluateConfig)}})
+
+	results, err := svc.Evaluate(context.Background(), "calc-abc", map[string]float64{"sqft": 200})
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	total := results[0]
+	if total.ID != "out1" || total.Label != "Total" {
+		t.Errorf("unexpected output identity: %+v", total)
+	}
+	// 200 (override) * 1.5 (first option) * 2 (slider min)
+	if total.Value == nil || *total.Value != 600 {
+		t.Errorf("expected total 600, got %v (error %q)", total.Value, total.Error)
+	}
+
+	broken := results[1]
+	if broken.Value != nil || broken.Error != "Unknown variable: {sqftt}. Did you mean {sqft}?" {
+		t.Errorf("unexpected broken result: value=%v error=%q", broken.Value, broken.Error)
+	}
+
+	infinite := results[2]
+	if infinite.Value != nil || infinite.Error == "" {
+		t.Errorf("expected non-finite result to be reported as an error, got %+v", infinite)
+	}
+}
+
+func TestEvaluate_ExactDecimalRounding(t *testing.T) {
+	config := `{
+		"fields": [{"id": "f1", "type": "number", "label": "Price", "required": true, "variableName": "price", "defaultValue": 0.1}],
+		"outputs": [
+			{"id": "sum", "label": "Sum", "expression": "{price} + 0.2"},
+			{"id": "usd", "label": "USD", "expression": "2.675", "currency": "USD"},
+			{"id": "even", "label": "Even", "expression": "2.665", "currency": "USD", "rounding": "half_even"},
+			{"id": "jpy", "label": "JPY", "expression": "1234.5", "currency": "JPY", "rounding": "floor"},
+			{"id": "kwd", "label": "KWD", "expression": "10 / 3", "currency": "KWD", "rounding": "ceil"},
+			{"id": "third", "label": "Third", "expression": "1 / 3"},
+			{"id": "zero", "label": "Zero", "expression": "1 / ({price} - 0.1)"}
+		]
+	}`
+	svc := newEvaluateService(&stubPublicConfigGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(config)}})
+
+	results, err := svc.Evaluate(context.Background(), "calc-abc", nil)
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"sum":   "0.3",
+		"usd":   "2.68",
+		"even":  "2.66",
+		"jpy":   "1234",
+		"kwd":   "3.334",
+		"third": "0.3333333333",
+	}
+	for _, res := range results {
+		if res.ID == "zero" {
+			if res.Error != "Division by zero" || res.Value != nil {
+				t.Errorf("expected division by zero error, got %+v", res)
+			}
+			continue
+		}
+		if res.Decimal != want[res.ID] {
+			t.Errorf("output %s: expected decimal %q, got %q (error %q)", res.ID, want[res.ID], res.Decimal, res.Error)
+		}
+	}
+	if sum := results[0]; sum.Value == nil || *sum.Value != 0.3 {
+		t.Errorf("expected float value exactly 0.3, got %v", sum.Value)
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	for code, want := range map[string]int{"USD": 2, "EUR": 2, "JPY": 0, "KWD": 3, "CLF": 4, "XYZ": 2} {
+		if got := MinorUnits(code); got != want {
+			t.Errorf("MinorUnits(%q) = %d, want %d", code, got, want)
+		}
+	}
+}
+
+func TestEvaluate_UnknownValue(t *testing.T) {
+	svc := newEvaluateService(&stubPublicConfigGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(evaluateConfig)}})
+
+	_, err := svc.Evaluate(context.Background(), "calc-abc", map[string]float64{"bogus": 1})
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *configschema.ValidationError, got %v", err)
+	}
+	if len(verr.Errors) != 1 || verr.Errors[0].Path != "values.bogus" {
+		t.Errorf("unexpected validation errors: %+v", verr.Errors)
+	}
+}
+
+func TestEvaluate_NotFound(t *testing.T) {
+	svc := newEvaluateService(&stubPublicConfigGetter{err: ErrNotFound})
+
+	_, err := svc.Evaluate(context.Background(), "calc-missing", nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected wrapped ErrNotFound, got: %v", err)
+	}
+}
+
+func TestEvaluate_InvalidStoredConfig(t *testing.T) {
+	svc := newEvaluateService(&stubPublicConfigGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{"fields": 42}`)}})
+
+	if _, err := svc.Evaluate(context.Background(), "calc-abc", nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestParseLeadingFloat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"12", 12},
+		{"  3.5", 3.5},
+		{"12abc", 12},
+		{"-.5", -0.5},
+		{"1e3", 1000},
+		{"abc", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseLeadingFloat(tt.in); got != tt.want {
+			t.Errorf("parseLeadingFloat(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/formula"
+)
+
+// FormulaDiagnostic is a formula.Diagnostic located within a calculator
+// config. Path addresses the expression, e.g. "outputs[2].expression", and
+// Pos is a byte offset within it.
+type FormulaDiagnostic struct {
+	OutputID string
+	Path     string
+	formula.Diagnostic
+}
+
+// LintFormulas analyses every output expression in config against the
+// variables its fields define. It returns nil when no problems are found.
+func LintFormulas(config *configschema.Config) []FormulaDiagnostic {
+	variables := make([]string, 0, len(config.Fields))
+	for _, f := range config.Fields {
+		variables = append(variables, f.VariableName)
+	}
+	var diags []FormulaDiagnostic
+	for i, out := range config.Outputs {
+		for _, d := range formula.Lint(out.Expression, variables) {
+			diags = append(diags, FormulaDiagnostic{
+				OutputID:   out.ID,
+				Path:       fmt.Sprintf("outputs[%d].expression", i),
+				Diagnostic: d,
+			})
+		}
+	}
+	return diags
+}
+
+// LintConfig decodes raw and analyses its formulas with LintFormulas.
+func LintConfig(raw []byte) ([]FormulaDiagnostic, error) {
+	config, err := configschema.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing calculator config: %w", err)
+	}
+	return LintFormulas(config), nil
+}
+
+// Lint verifies ownership of the calculator and analyses the formulas of
+// config, or of the calculator's saved draft when config is nil, so the
+// editor can check changes before saving them.
+// Returns a *configschema.ValidationError if config violates the schema.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) Lint(ctx context.Context, id, userID string, config []byte) ([]FormulaDiagnostic, error) {
+	if config != nil {
+		upgraded, err := upgradeForWrite(config)
+		if err != nil {
+			return nil, err
+		}
+		config = upgraded
+	}
+	calc, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = calc.Config
+	}
+	return LintConfig(config)
+}
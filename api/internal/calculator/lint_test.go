@@ -0,0 +1,73 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/formula"
+)
+
+func newLintService(getter *stubGetter) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+}
+
+func TestLintFormulas_LocatesDiagnostics(t *testing.T) {
+	cfg, err := configschema.Parse([]byte(evaluateConfig))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	diags := LintFormulas(cfg)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", diags)
+	}
+	if diags[0].OutputID != "out2" || diags[0].Path != "outputs[1].expression" || diags[0].Code != formula.CodeUnknownVariable || diags[0].Suggestion != "sqft" {
+		t.Errorf("unexpected first diagnostic %+v", diags[0])
+	}
+	if diags[1].OutputID != "out3" || diags[1].Code != formula.CodeDivisionByZero {
+		t.Errorf("unexpected second diagnostic %+v", diags[1])
+	}
+}
+
+func TestLint_SavedDraft(t *testing.T) {
+	svc := newLintService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(evaluateConfig)}})
+
+	diags, err := svc.Lint(context.Background(), "calc-abc", "user-xyz", nil)
+	if err != nil {
+		t.Fatalf("Lint() returned unexpected error: %v", err)
+	}
+	if len(diags) != 2 {
+		t.Errorf("expected the draft's 2 diagnostics, got %+v", diags)
+	}
+}
+
+func TestLint_UnsavedConfig(t *testing.T) {
+	svc := newLintService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(evaluateConfig)}})
+
+	diags, err := svc.Lint(context.Background(), "calc-abc", "user-xyz", []byte(`{"outputs":[{"id":"o","label":"Total","expression":"ROUD(1)"}]}`))
+	if err != nil {
+		t.Fatalf("Lint() returned unexpected error: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Code != formula.CodeUnknownFunction || diags[0].Suggestion != "ROUND" {
+		t.Errorf("expected the submitted config to be linted, got %+v", diags)
+	}
+}
+
+func TestLint_InvalidConfig(t *testing.T) {
+	svc := newLintService(&stubGetter{calc: &Calculator{ID: "calc-abc"}})
+
+	_, err := svc.Lint(context.Background(), "calc-abc", "user-xyz", []byte(`{"layoutMode":"sideways"}`))
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("expected *configschema.ValidationError, got: %v", err)
+	}
+}
+
+func TestLint_OwnershipChecked(t *testing.T) {
+	svc := newLintService(&stubGetter{err: ErrForbidden})
+
+	if _, err := svc.Lint(context.Background(), "calc-abc", "other-user", nil); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package calculator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+// Page size bounds for List.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// was issued for a different sort order or filter.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortField is a column calculators can be listed by.
+type SortField string
+
+// Supported sort fields.
+const (
+	SortByUpdatedAt SortField = "updated_at"
+	SortByCreatedAt SortField = "created_at"
+	SortByName      SortField = "name"
+)
+
+// Valid reports whether f is a supported sort field.
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByUpdatedAt, SortByCreatedAt, SortByName:
+		return true
+	}
+	return false
+}
+
+// ListOptions controls the order, filtering, and pagination of List.
+type ListOptions struct {
+	// Sort is the field to order by. Zero means SortByUpdatedAt.
+	Sort SortField
+	// Descending reverses the sort order.
+	Descending bool
+	// Search, when non-empty, restricts results to calculators whose name or
+	// description contains it, case-insensitively.
+	Search string
+	// Tags, when non-empty, restricts results to calculators carrying every
+	// one of these tags.
+	Tags []string
+	// FolderID, when non-empty, restricts results to calculators in that folder.
+	FolderID string
+	// Unfiled restricts results to calculators that are not in any folder.
+	Unfiled bool
+	// Cursor is the NextCursor of the previous page, or empty for the first page.
+	Cursor string
+	// Limit is the maximum page size. Zero means DefaultPageSize; values above
+	// MaxPageSize are clamped.
+	Limit int
+}
+
+// Page is one page of List results. NextCursor is empty on the last page.
+type Page struct {
+	Calculators []*Calculator
+	NextCursor  string
+}
+
+// PageKey identifies the last row of a page so the next page can resume
+// strictly after it. Exactly one of Name or Time is meaningful, depending on
+// the sort field.
+type PageKey struct {
+	Name string
+	Time time.Time
+	ID   string
+}
+
+// ListQuery is the fully resolved query passed to a Lister.
+type ListQuery struct {
+	UserID     string
+	Sort       SortField
+	Descending bool
+	Search     string
+	Tags       []string // normalized with NormalizeTags
+	FolderID   string
+	Unfiled    bool
+	// After, when non-nil, restricts results to rows that sort after this key.
+	After *PageKey
+	Limit int
+}
+
+// cursor is the decoded form of an opaque pagination cursor. It records the
+// query it was issued for so it cannot be replayed against a different one.
+type cursor struct {
+	Sort       SortField `json:"s"`
+	Descending bool      `json:"d"`
+	Search     string    `json:"q,omitempty"`
+	Tags       []string  `json:"g,omitempty"`
+	FolderID   string    `json:"f,omitempty"`
+	Unfiled    bool      `json:"u,omitempty"`
+	Name       string    `json:"n,omitempty"`
+	Time       time.Time `json:"t,omitempty"`
+	ID         string    `json:"id"`
+}
+
+// encodeCursor returns the cursor that resumes q after calc.
+func encodeCursor(q ListQuery, calc *Calculator) string {
+	c := cursor{
+		Sort:       q.Sort,
+		Descending: q.Descending,
+		Search:     q.Search,
+		Tags:       q.Tags,
+		FolderID:   q.FolderID,
+		Unfiled:    q.Unfiled,
+		ID:         calc.ID,
+	}
+	switch q.Sort {
+	case SortByName:
+		c.Name = calc.Name
+	case SortByCreatedAt:
+		c.Time = calc.CreatedAt
+	default:
+		c.Time = calc.UpdatedAt
+	}
+	// Marshalling a struct of strings, bools, and a time cannot fail.
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses s and checks it was issued for q.
+func decodeCursor(s string, q ListQuery) (*PageKey, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != q.Sort || c.Descending != q.Descending || c.Search != q.Search ||
+		!slices.Equal(c.Tags, q.Tags) || c.FolderID != q.FolderID || c.Unfiled != q.Unfiled {
+		return nil, ErrInvalidCursor
+	}
+	return &PageKey{Name: c.Name, Time: c.Time, ID: c.ID}, nil
+}
@@ -0,0 +1,244 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func newListService(lister *stubLister) *Service {
+	return NewService(&stubCreator{}, lister, &stubGetter{}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+}
+
+// calcsN returns n calculators with distinct IDs, names, and timestamps.
+func calcsN(n int) []*Calculator {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	calcs := make([]*Calculator, n)
+	for i := range calcs {
+		ts := base.Add(time.Duration(i) * time.Minute)
+		calcs[i] = &Calculator{ID: fmt.Sprintf("calc-%d", i), Name: fmt.Sprintf("Calc %d", i), CreatedAt: ts, UpdatedAt: ts}
+	}
+	return calcs
+}
+
+func TestList_Defaults(t *testing.T) {
+	lister := &stubLister{calcs: []*Calculator{}}
+	if _, err := newListService(lister).List(context.Background(), "user-xyz", ListOptions{Search: "  quote  "}); err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	q := lister.query
+	if q.UserID != "user-xyz" || q.Sort != SortByUpdatedAt || q.Search != "quote" || q.After != nil {
+		t.Errorf("unexpected query: %+v", q)
+	}
+	if q.Limit != DefaultPageSize+1 {
+		t.Errorf("expected limit %d (page size plus one), got %d", DefaultPageSize+1, q.Limit)
+	}
+}
+
+func TestList_ClampsLimit(t *testing.T) {
+	lister := &stubLister{calcs: []*Calculator{}}
+	if _, err := newListService(lister).List(context.Background(), "user-xyz", ListOptions{Limit: 1000}); err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if lister.query.Limit != MaxPageSize+1 {
+		t.Errorf("expected limit %d, got %d", MaxPageSize+1, lister.query.Limit)
+	}
+}
+
+func TestList_NextCursorRoundTrip(t *testing.T) {
+	lister := &stubLister{calcs: calcsN(3)}
+	svc := newListService(lister)
+	opts := ListOptions{Sort: SortByName, Search: "calc", Limit: 2}
+
+	page, err := svc.List(context.Background(), "user-xyz", opts)
+	if err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if len(page.Calculators) != 2 || page.NextCursor == "" {
+		t.Fatalf("expected a full page with a next cursor, got %d rows and cursor %q", len(page.Calculators), page.NextCursor)
+	}
+
+	lister.calcs = calcsN(1)
+	opts.Cursor = page.NextCursor
+	page, err = svc.List(context.Background(), "user-xyz", opts)
+	if err != nil {
+		t.Fatalf("List() with cursor returned unexpected error: %v", err)
+	}
+	after := lister.query.After
+	if after == nil || after.ID != "calc-1" || after.Name != "Calc 1" {
+		t.Errorf("expected query to resume after calc-1, got %+v", after)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("expected no next cursor on the last page, got %q", page.NextCursor)
+	}
+}
+
+func TestList_CursorCarriesTimestamp(t *testing.T) {
+	calcs := calcsN(2)
+	lister := &stubLister{calcs: calcs}
+	svc := newListService(lister)
+
+	page, err := svc.List(context.Background(), "user-xyz", ListOptions{Sort: SortByCreatedAt, Descending: true, Limit: 1})
+	if err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	_, err = svc.List(context.Background(), "user-xyz", ListOptions{Sort: SortByCreatedAt, Descending: true, Limit: 1, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("List() with cursor returned unexpected error: %v", err)
+	}
+	if after := lister.query.After; after == nil || !after.Time.Equal(calcs[0].CreatedAt) {
+		t.Errorf("expected query to resume after %v, got %+v", calcs[0].CreatedAt, after)
+	}
+}
+
+func TestList_InvalidCursor(t *testing.T) {
+	svc := newListService(&stubLister{calcs: calcsN(3)})
+	page, err := svc.List(context.Background(), "user-xyz", ListOptions{Sort: SortByName, Limit: 1})
+	if err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts ListOptions
+	}{
+		{"garbage", ListOptions{Sort: SortByName, Cursor: "not-a-cursor!"}},
+		{"different sort", ListOptions{Sort: SortByUpdatedAt, Cursor: page.NextCursor}},
+		{"different direction", ListOptions{Sort: SortByName, Descending: true, Cursor: page.NextCursor}},
+		{"different search", ListOptions{Sort: SortByName, Search: "x", Cursor: page.NextCursor}},
+		{"different tags", ListOptions{Sort: SortByName, Tags: []string{"roofing"}, Cursor: page.NextCursor}},
+		{"different folder", ListOptions{Sort: SortByName, Unfiled: true, Cursor: page.NextCursor}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.List(context.Background(), "user-xyz", tt.opts)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("expected ErrInvalidCursor, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestList_NormalizesTags(t *testing.T) {
+	lister := &stubLister{calcs: []*Calculator{}}
+	_, err := newListService(lister).List(context.Background(), "user-xyz", ListOptions{Tags: []string{" Roofing", "deck", "roofing"}})
+	if err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if want := []string{"deck", "roofing"}; !slices.Equal(lister.query.Tags, want) {
+		t.Errorf("expected tags %v, got %v", want, lister.query.Tags)
+	}
+}
+
+func TestList_InvalidTag(t *testing.T) {
+	_, err := newListService(&stubLister{}).List(context.Background(), "user-xyz", ListOptions{Tags: []string{" "}})
+	var merr *MetadataError
+	if !errors.As(err, &merr) {
+		t.Errorf("expected *MetadataError, got: %v", err)
+	}
+}
+
+func TestList_InvalidSort(t *testing.T) {
+	_, err := newListService(&stubLister{}).List(context.Background(), "user-xyz", ListOptions{Sort: "config"})
+	if err == nil {
+		t.Fatal("expected error for unsupported sort field, got nil")
+	}
+}
+
+func TestListCalculators_SearchAndKeyset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectQuery(`AND \(name ILIKE \$2 OR description ILIKE \$2\)\s+AND \(name, id\) > \(\$3, \$4\)\s+ORDER BY name ASC, id ASC\s+LIMIT \$5`).
+		WithArgs("user-id", `%50\%\_off%`, "Calc 1", "calc-1", 11).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.ListCalculators(context.Background(), ListQuery{
+		UserID: "user-id",
+		Sort:   SortByName,
+		Search: "50%_off",
+		After:  &PageKey{Name: "Calc 1", ID: "calc-1"},
+		Limit:  11,
+	})
+	if err != nil {
+		t.Fatalf("ListCalculators() returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListCalculators_TagAndFolderFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectQuery(`AND tags @> \$2\s+AND folder_id = \$3\s+ORDER BY`).
+		WithArgs("user-id", pq.Array([]string{"deck", "roofing"}), "folder-id", 51).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectQuery(`AND folder_id IS NULL\s+ORDER BY`).
+		WithArgs("user-id", 51).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	q := ListQuery{UserID: "user-id", Sort: SortByUpdatedAt, Tags: []string{"deck", "roofing"}, FolderID: "folder-id", Limit: 51}
+	if _, err := repo.ListCalculators(context.Background(), q); err != nil {
+		t.Fatalf("ListCalculators() returned unexpected error: %v", err)
+	}
+	q = ListQuery{UserID: "user-id", Sort: SortByUpdatedAt, Unfiled: true, Limit: 51}
+	if _, err := repo.ListCalculators(context.Background(), q); err != nil {
+		t.Fatalf("ListCalculators() unfiled returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListCalculators_DescendingTimeKeyset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`AND \(created_at, id\) < \(\$2, \$3\)\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$4`).
+		WithArgs("user-id", after, "calc-1", 6).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.ListCalculators(context.Background(), ListQuery{
+		UserID:     "user-id",
+		Sort:       SortByCreatedAt,
+		Descending: true,
+		After:      &PageKey{Time: after, ID: "calc-1"},
+		Limit:      6,
+	})
+	if err != nil {
+		t.Fatalf("ListCalculators() returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Metadata limits, in characters.
+const (
+	MaxNameLength        = 200
+	MaxDescriptionLength = 2000
+	MaxTags              = 20
+	MaxTagLength         = 50
+	MaxFolderNameLength  = 100
+)
+
+// ErrFolderNotFound is returned when a folder does not exist or is owned by a
+// different user. The two cases are not distinguished, so folder IDs belonging
+// to other users cannot be probed.
+var ErrFolderNotFound = errors.New("folder not found")
+
+// ErrFolderExists is returned when a folder name is already used by another of
+// the user's folders.
+var ErrFolderExists = errors.New("folder name already in use")
+
+// ErrMetadataVersionConflict is returned when a conditional metadata update
+// names a metadata_version that is no longer current. Use errors.As with
+// *MetadataVersionConflictError to read the current version.
+var ErrMetadataVersionConflict = errors.New("metadata version conflict")
+
+// MetadataVersionConflictError reports the calculator's current
+// metadata_version when a conditional metadata update fails. It matches
+// ErrMetadataVersionConflict under errors.Is.
+type MetadataVersionConflictError struct {
+	CurrentVersion int
+}
+
+func (e *MetadataVersionConflictError) Error() string {
+	return fmt.Sprintf("metadata version conflict: current version is %d", e.CurrentVersion)
+}
+
+// Is reports whether target is ErrMetadataVersionConflict.
+func (e *MetadataVersionConflictError) Is(target error) bool {
+	return target == ErrMetadataVersionConflict
+}
+
+// MetadataError reports a metadata or folder field that failed validation.
+type MetadataError struct {
+	Field   string
+	Message string
+}
+
+func (e *MetadataError) Error() string {
+	return "invalid metadata: " + e.Field + ": " + e.Message
+}
+
+// Metadata is the user-editable description of a calculator, stored and
+// versioned separately from its config.
+type Metadata struct {
+	Name        string
+	Description string
+	Tags        []string
+	FolderID    string // "" leaves the calculator unfiled
+}
+
+// Folder groups a user's calculators.
+type Folder struct {
+	ID        string
+	UserID    string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MetadataUpdater replaces the metadata of a calculator and increments its
+// metadata_version without touching config or config_version. When
+// expectedVersion is non-zero the update only applies if it matches the current
+// metadata_version. A non-empty m.FolderID must name a folder owned by ownerID.
+type MetadataUpdater interface {
+	UpdateCalculatorMetadata(ctx context.Context, id, ownerID string, expectedVersion int, m Metadata) (*Calculator, error)
+}
+
+// FolderStore persists a user's folders. Every method is scoped to userID and
+// returns ErrFolderNotFound for folders owned by anyone else.
+type FolderStore interface {
+	ListFolders(ctx context.Context, userID string) ([]*Folder, error)
+	CreateFolder(ctx context.Context, userID, name string) (*Folder, error)
+	RenameFolder(ctx context.Context, id, userID, name string) (*Folder, error)
+	DeleteFolder(ctx context.Context, id, userID string) error
+}
+
+// WithMetadata configures metadata and folder support on the Service.
+// It sets the MetadataUpdater and FolderStore dependencies and returns the same
+// Service pointer for chained calls.
+func (s *Service) WithMetadata(updater MetadataUpdater, folders FolderStore) *Service {
+	s.metadataUpdater = updater
+	s.folders = folders
+	return s
+}
+
+// UpdateMetadata validates and normalizes m, verifies ownership of the
+// calculator, then replaces its metadata. config and config_version are left
+// unchanged, so published widgets are unaffected, but the standalone page
+// shows the name and description, so the calculator is purged from the CDN.
+// When expectedVersion is non-zero the write is conditional on it matching the
+// calculator's current metadata_version.
+// Returns a *MetadataError if a field is invalid.
+// Returns a *MetadataVersionConflictError (matching ErrMetadataVersionConflict) if expectedVersion is stale.
+// Returns ErrFolderNotFound if m.FolderID does not name one of the user's folders.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) UpdateMetadata(ctx context.Context, id, userID string, expectedVersion int, m Metadata) (*Calculator, error) {
+	m, err := normalizeMetadata(m)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getter.GetCalculator(ctx, id, userID); err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	calc, err := s.metadataUpdater.UpdateCalculatorMetadata(ctx, id, userID, expectedVersion, m)
+	if err != nil {
+		return nil, fmt.Errorf("updating calculator metadata: %w", err)
+	}
+	s.purgeCache(ctx, id)
+	return calc, nil
+}
+
+// ListFolders returns the folders owned by userID, ordered by name.
+func (s *Service) ListFolders(ctx context.Context, userID string) ([]*Folder, error) {
+	folders, err := s.folders.ListFolders(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing folders: %w", err)
+	}
+	return folders, nil
+}
+
+// CreateFolder creates a folder named name for userID.
+// Returns a *MetadataError if the name is invalid.
+// Returns ErrFolderExists if the user already has a folder with that name.
+func (s *Service) CreateFolder(ctx context.Context, userID, name string) (*Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.folders.CreateFolder(ctx, userID, name)
+	if err != nil {
+		return nil, fmt.Errorf("creating folder: %w", err)
+	}
+	return f, nil
+}
+
+// RenameFolder renames one of userID's folders.
+// Returns a *MetadataError if the name is invalid.
+// Returns ErrFolderExists if the user already has another folder with that name.
+// Returns ErrFolderNotFound if the folder does not exist or is owned by a different user.
+func (s *Service) RenameFolder(ctx context.Context, id, userID, name string) (*Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.folders.RenameFolder(ctx, id, userID, name)
+	if err != nil {
+		return nil, fmt.Errorf("renaming folder: %w", err)
+	}
+	return f, nil
+}
+
+// DeleteFolder deletes one of userID's folders. Calculators in it become
+// unfiled; they are not deleted.
+// Returns ErrFolderNotFound if the folder does not exist or is owned by a different user.
+func (s *Service) DeleteFolder(ctx context.Context, id, userID string) error {
+	if err := s.folders.DeleteFolder(ctx, id, userID); err != nil {
+		return fmt.Errorf("deleting folder: %w", err)
+	}
+	return nil
+}
+
+// idPattern matches the canonical text form of a UUID, the type of every ID
+// column. Checking the shape up front turns a malformed folder ID into a
+// validation error instead of a database error.
+var idPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidFolderID reports whether id is shaped like a folder ID.
+func ValidFolderID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// normalizeMetadata trims every field, normalizes tags with NormalizeTags, and
+// checks the length limits.
+func normalizeMetadata(m Metadata) (Metadata, error) {
+	m.Name = strings.TrimSpace(m.Name)
+	m.Description = strings.TrimSpace(m.Description)
+	m.FolderID = strings.TrimSpace(m.FolderID)
+	if utf8.RuneCountInString(m.Name) > MaxNameLength {
+		return m, &MetadataError{Field: "name", Message: fmt.Sprintf("must be at most %d characters", MaxNameLength)}
+	}
+	if utf8.RuneCountInString(m.Description) > MaxDescriptionLength {
+		return m, &MetadataError{Field: "description", Message: fmt.Sprintf("must be at most %d characters", MaxDescriptionLength)}
+	}
+	if m.FolderID != "" && !ValidFolderID(m.FolderID) {
+		return m, &MetadataError{Field: "folder_id", Message: "must be a folder ID"}
+	}
+	tags, err := NormalizeTags(m.Tags)
+	if err != nil {
+		return m, err
+	}
+	m.Tags = tags
+	return m, nil
+}
+
+// NormalizeTags trims and lower-cases each tag, drops duplicates, and sorts
+// the result, so tags compare and filter case-insensitively. It returns a
+// *MetadataError if a tag is empty or too long, or there are too many.
+func NormalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, &MetadataError{Field: "tags", Message: "must not contain empty tags"}
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, &MetadataError{Field: "tags", Message: fmt.Sprintf("each tag must be at most %d characters", MaxTagLength)}
+		}
+		out = append(out, tag)
+	}
+	slices.Sort(out)
+	out = slices.Compact(out)
+	if len(out) > MaxTags {
+		return nil, &MetadataError{Field: "tags", Message: fmt.Sprintf("must contain at most %d tags", MaxTags)}
+	}
+	return out, nil
+}
+
+// normalizeFolderName trims name and checks it is non-empty and within
+// MaxFolderNameLength.
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", &MetadataError{Field: "name", Message: "is required"}
+	}
+	if utf8.RuneCountInString(name) > MaxFolderNameLength {
+		return "", &MetadataError{Field: "name", Message: fmt.Sprintf("must be at most %d characters", MaxFolderNameLength)}
+	}
+	return name, nil
+}
@@ -0,0 +1,297 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+const testFolderID = "7d3c9a4e-0b1f-4c2a-9e8d-5f6a7b8c9d0e"
+
+type stubMetadataStore struct {
+	calc   *Calculator
+	folder *Folder
+	err    error
+
+	gotExpected int
+	gotMetadata Metadata
+	gotName     string
+}
+
+func (s *stubMetadataStore) UpdateCalculatorMetadata(_ context.Context, _, _ string, expectedVersion int, m Metadata) (*Calculator, error) {
+	s.gotExpected = expectedVersion
+	s.gotMetadata = m
+	return s.calc, s.err
+}
+
+func (s *stubMetadataStore) ListFolders(_ context.Context, _ string) ([]*Folder, error) {
+	if s.folder == nil {
+		return nil, s.err
+	}
+	return []*Folder{s.folder}, s.err
+}
+
+func (s *stubMetadataStore) CreateFolder(_ context.Context, _, name string) (*Folder, error) {
+	s.gotName = name
+	return s.folder, s.err
+}
+
+func (s *stubMetadataStore) RenameFolder(_ context.Context, _, _, name string) (*Folder, error) {
+	s.gotName = name
+	return s.folder, s.err
+}
+
+func (s *stubMetadataStore) DeleteFolder(_ context.Context, _, _ string) error {
+	return s.err
+}
+
+func newMetadataService(getter *stubGetter, store *stubMetadataStore) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithMetadata(store, store)
+}
+
+func TestUpdateMetadata_NormalizesFields(t *testing.T) {
+	store := &stubMetadataStore{calc: &Calculator{ID: "calc-abc", MetadataVersion: 3}}
+	svc := newMetadataService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, store)
+
+	calc, err := svc.UpdateMetadata(context.Background(), "calc-abc", "user-xyz", 2, Metadata{
+		Name:        "  Roof quote ",
+		Description: " Estimates roofing jobs\n",
+		Tags:        []string{"Roofing", " residential ", "roofing"},
+		FolderID:    testFolderID,
+	})
+	if err != nil {
+		t.Fatalf("UpdateMetadata() returned unexpected error: %v", err)
+	}
+	if calc.MetadataVersion != 3 {
+		t.Errorf("expected MetadataVersion 3, got %d", calc.MetadataVersion)
+	}
+	got := store.gotMetadata
+	if got.Name != "Roof quote" || got.Description != "Estimates roofing jobs" || got.FolderID != testFolderID {
+		t.Errorf("unexpected normalized metadata: %+v", got)
+	}
+	if want := []string{"residential", "roofing"}; !slices.Equal(got.Tags, want) {
+		t.Errorf("expected tags %v, got %v", want, got.Tags)
+	}
+	if store.gotExpected != 2 {
+		t.Errorf("expected version 2 passed through, got %d", store.gotExpected)
+	}
+}
+
+func TestUpdateMetadata_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     Metadata
+		field string
+	}{
+		{"long name", Metadata{Name: strings.Repeat("n", MaxNameLength+1)}, "name"},
+		{"long description", Metadata{Description: strings.Repeat("d", MaxDescriptionLength+1)}, "description"},
+		{"empty tag", Metadata{Tags: []string{"ok", "  "}}, "tags"},
+		{"long tag", Metadata{Tags: []string{strings.Repeat("t", MaxTagLength+1)}}, "tags"},
+		{"too many tags", Metadata{Tags: strings.Split("a b c d e f g h i j k l m n o p q r s t u", " ")}, "tags"},
+		{"malformed folder", Metadata{FolderID: "inbox"}, "folder_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &stubMetadataStore{}
+			svc := newMetadataService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, store)
+			_, err := svc.UpdateMetadata(context.Background(), "calc-abc", "user-xyz", 0, tt.m)
+			var merr *MetadataError
+			if !errors.As(err, &merr) {
+				t.Fatalf("expected *MetadataError, got: %v", err)
+			}
+			if merr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, merr.Field)
+			}
+		})
+	}
+}
+
+func TestUpdateMetadata_OwnershipChecked(t *testing.T) {
+	store := &stubMetadataStore{}
+	svc := newMetadataService(&stubGetter{err: ErrForbidden}, store)
+	_, err := svc.UpdateMetadata(context.Background(), "calc-abc", "user-xyz", 0, Metadata{Name: "x"})
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got: %v", err)
+	}
+	if store.gotMetadata.Name != "" {
+		t.Error("expected store not to be called")
+	}
+}
+
+func TestCreateFolder_TrimsAndValidatesName(t *testing.T) {
+	store := &stubMetadataStore{folder: &Folder{ID: "folder-1"}}
+	svc := newMetadataService(&stubGetter{}, store)
+
+	if _, err := svc.CreateFolder(context.Background(), "user-xyz", "  Clients "); err != nil {
+		t.Fatalf("CreateFolder() returned unexpected error: %v", err)
+	}
+	if store.gotName != "Clients" {
+		t.Errorf("expected trimmed name %q, got %q", "Clients", store.gotName)
+	}
+
+	var merr *MetadataError
+	if _, err := svc.CreateFolder(context.Background(), "user-xyz", "   "); !errors.As(err, &merr) {
+		t.Errorf("expected *MetadataError for blank name, got: %v", err)
+	}
+	if _, err := svc.RenameFolder(context.Background(), "folder-1", "user-xyz", strings.Repeat("f", MaxFolderNameLength+1)); !errors.As(err, &merr) {
+		t.Errorf("expected *MetadataError for long name, got: %v", err)
+	}
+}
+
+func TestDeleteFolder_NotFound(t *testing.T) {
+	svc := newMetadataService(&stubGetter{}, &stubMetadataStore{err: ErrFolderNotFound})
+	if err := svc.DeleteFolder(context.Background(), "folder-1", "user-xyz"); !errors.Is(err, ErrFolderNotFound) {
+		t.Errorf("expected ErrFolderNotFound, got: %v", err)
+	}
+}
+
+func TestPostgresUpdateCalculatorMetadata_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	tags := []string{"roofing"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM calculator_folders`).
+		WithArgs(testFolderID, "user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`UPDATE calculators\s+SET name = \$2, description = \$3, tags = \$4, folder_id = \$5, metadata_version = metadata_version \+ 1`).
+		WithArgs("calc-id", "Roof", "Roofing jobs", pq.Array(tags), testFolderID, 4).
+		WillReturnRows(sqlmock.NewRows(listColumns).
+			AddRow("calc-id", "user-id", "Roof", []byte(`{}`), 7, false, now, now, "Roofing jobs", "{roofing}", testFolderID, 5, 0, nil))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.UpdateCalculatorMetadata(context.Background(), "calc-id", "user-id", 4, Metadata{
+		Name: "Roof", Description: "Roofing jobs", Tags: tags, FolderID: testFolderID,
+	})
+	if err != nil {
+		t.Fatalf("UpdateCalculatorMetadata() returned unexpected error: %v", err)
+	}
+	if calc.MetadataVersion != 5 || calc.ConfigVersion != 7 {
+		t.Errorf("expected metadata version 5 and config version 7, got %d and %d", calc.MetadataVersion, calc.ConfigVersion)
+	}
+	if !slices.Equal(calc.Tags, tags) || calc.FolderID != testFolderID {
+		t.Errorf("unexpected tags %v or folder %q", calc.Tags, calc.FolderID)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresUpdateCalculatorMetadata_FolderNotOwned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(testFolderID, "user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.UpdateCalculatorMetadata(context.Background(), "calc-id", "user-id", 0, Metadata{FolderID: testFolderID})
+	if !errors.Is(err, ErrFolderNotFound) {
+		t.Errorf("expected ErrFolderNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresUpdateCalculatorMetadata_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectQuery(`SELECT metadata_version FROM calculators`).
+		WithArgs("calc-id").
+		WillReturnRows(sqlmock.NewRows([]string{"metadata_version"}).AddRow(9))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.UpdateCalculatorMetadata(context.Background(), "calc-id", "user-id", 8, Metadata{Name: "x", Tags: []string{}})
+	var conflict *MetadataVersionConflictError
+	if !errors.As(err, &conflict) || conflict.CurrentVersion != 9 {
+		t.Fatalf("expected *MetadataVersionConflictError with version 9, got: %v", err)
+	}
+	if !errors.Is(err, ErrMetadataVersionConflict) {
+		t.Error("expected error to match ErrMetadataVersionConflict")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresCreateFolder_Duplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectQuery(`INSERT INTO calculator_folders`).
+		WithArgs("user-id", "Clients").
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.CreateFolder(context.Background(), "user-id", "Clients"); !errors.Is(err, ErrFolderExists) {
+		t.Errorf("expected ErrFolderExists, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresDeleteFolder_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectExec(`DELETE FROM calculator_folders WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("folder-id", "user-id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if err := repo.DeleteFolder(context.Background(), "folder-id", "user-id"); !errors.Is(err, ErrFolderNotFound) {
+		t.Errorf("expected ErrFolderNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
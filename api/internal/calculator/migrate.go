@@ -0,0 +1,193 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// defaultMigrationBatchSize is the number of rows MigrateConfigs reads per
+// page when MigrateOptions.BatchSize is zero.
+const defaultMigrationBatchSize = 100
+
+// StaleConfig is a calculator row whose draft or published config predates
+// configschema.Version. PublishedConfig is nil for an unpublished calculator.
+// ConfigVersion and PublishedVersion identify the row state the configs were
+// read at, so a write-back cannot overwrite a concurrent edit.
+type StaleConfig struct {
+	ID               string
+	Config           []byte
+	ConfigVersion    int
+	PublishedConfig  []byte
+	PublishedVersion int
+}
+
+// ConfigMigrationStore reads and rewrites calculator configs for MigrateConfigs.
+type ConfigMigrationStore interface {
+	// ListStaleConfigs returns up to limit rows, soft-deleted ones included,
+	// with a config older than version, ordered by id and starting strictly
+	// after afterID ("" for the first page).
+	ListStaleConfigs(ctx context.Context, version int, afterID string, limit int) ([]*StaleConfig, error)
+	// WriteMigratedConfig stores the upgraded configs of c, provided the row
+	// is still at c.ConfigVersion and c.PublishedVersion. It reports whether
+	// the row was written.
+	WriteMigratedConfig(ctx context.Context, c *StaleConfig) (bool, error)
+}
+
+// MigrateOptions controls a MigrateConfigs run.
+type MigrateOptions struct {
+	// DryRun upgrades and validates every stale config without writing.
+	DryRun bool
+	// BatchSize is the number of rows read per page; zero uses a default.
+	BatchSize int
+}
+
+// MigrationFailure records a row MigrateConfigs could not upgrade.
+type MigrationFailure struct {
+	ID  string
+	Err error
+}
+
+// MigrationReport summarises a MigrateConfigs run.
+type MigrationReport struct {
+	// Scanned counts stale rows read.
+	Scanned int
+	// Migrated counts rows written, or that would be written in a dry run.
+	Migrated int
+	// Skipped counts rows edited while the run was in progress. They are
+	// served upgraded on read and picked up by the next run.
+	Skipped int
+	// Failures lists rows whose upgrade, validation, or write failed.
+	Failures []MigrationFailure
+}
+
+// MigrateConfigs upgrades every stored draft and published config to
+// configschema.Version, so the read-time upgrade in Get and GetPublicConfig
+// becomes a no-op for them.
+//
+// Each row is upgraded, validated, and written on its own: a row that fails is
+// recorded in the report and the run moves on. Writes leave config_version
+// alone — an upgraded document means the same thing as the original, and
+// readers have been served the upgraded form all along. Revisions are not
+// rewritten; they carry their own schema version and are upgraded when read.
+//
+// The returned error is non-nil only if reading the next page fails; the
+// report then covers the rows processed so far.
+func MigrateConfigs(ctx context.Context, store ConfigMigrationStore, opts MigrateOptions) (*MigrationReport, error) {
+	limit := opts.BatchSize
+	if limit <= 0 {
+		limit = defaultMigrationBatchSize
+	}
+	report := &MigrationReport{}
+	afterID := ""
+	for {
+		rows, err := store.ListStaleConfigs(ctx, configschema.Version, afterID, limit)
+		if err != nil {
+			return report, fmt.Errorf("listing stale configs: %w", err)
+		}
+		for _, row := range rows {
+			report.Scanned++
+			written, err := migrateRow(ctx, store, row, opts.DryRun)
+			switch {
+			case err != nil:
+				report.Failures = append(report.Failures, MigrationFailure{ID: row.ID, Err: err})
+			case written:
+				report.Migrated++
+			default:
+				report.Skipped++
+			}
+		}
+		if len(rows) < limit {
+			return report, nil
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
+
+// migrateRow upgrades and validates both configs of row, then writes them
+// unless dryRun is set. It reports whether the row was (or would be) written.
+func migrateRow(ctx context.Context, store ConfigMigrationStore, row *StaleConfig, dryRun bool) (bool, error) {
+	config, err := upgradeAndValidate(row.Config)
+	if err != nil {
+		return false, fmt.Errorf("draft config: %w", err)
+	}
+	var published []byte
+	if row.PublishedConfig != nil {
+		published, err = upgradeAndValidate(row.PublishedConfig)
+		if err != nil {
+			return false, fmt.Errorf("published config: %w", err)
+		}
+	}
+	if dryRun {
+		return true, nil
+	}
+	upgraded := *row
+	upgraded.Config, upgraded.PublishedConfig = config, published
+	written, err := store.WriteMigratedConfig(ctx, &upgraded)
+	if err != nil {
+		return false, fmt.Errorf("writing upgraded config: %w", err)
+	}
+	return written, nil
+}
+
+// upgradeAndValidate upgrades raw and checks the result against the current
+// schema, so a faulty upgrade step is caught before anything is stored.
+func upgradeAndValidate(raw []byte) ([]byte, error) {
+	upgraded, _, err := configschema.Migrate(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := configschema.Validate(upgraded); err != nil {
+		return nil, err
+	}
+	return upgraded, nil
+}
+
+// upgradeForWrite upgrades a config submitted by a client, which may have been
+// written against an older schema version, and validates the result. Clients
+// send back the shape they read, so a config without configschema.VersionKey
+// is taken to be at the current version and is stored with the key; a client
+// writing an older document must say which version it is. A document too
+// malformed to upgrade is reported as a *configschema.ValidationError rather
+// than an upgrade failure.
+func upgradeForWrite(raw []byte) ([]byte, error) {
+	return upgradeForWriteFrom(raw, configschema.Version)
+}
+
+// upgradeStoredForWrite is upgradeForWrite for a document read back from
+// storage, such as a revision, where a missing configschema.VersionKey means
+// version 1.
+func upgradeStoredForWrite(raw []byte) ([]byte, error) {
+	return upgradeForWriteFrom(raw, 1)
+}
+
+// upgradeForWriteFrom is upgradeForWrite for a document whose missing
+// configschema.VersionKey means version unversioned.
+func upgradeForWriteFrom(raw []byte, unversioned int) ([]byte, error) {
+	upgraded, _, err := configschema.MigrateFrom(raw, unversioned)
+	if err != nil {
+		if verr := configschema.Validate(raw); verr != nil {
+			return nil, fmt.Errorf("validating calculator config: %w", verr)
+		}
+		return nil, fmt.Errorf("upgrading calculator config: %w", err)
+	}
+	if err := configschema.Validate(upgraded); err != nil {
+		return nil, fmt.Errorf("validating calculator config: %w", err)
+	}
+	return upgraded, nil
+}
+
+// upgradeConfig upgrades c.Config in place to configschema.Version. Stored
+// configs are upgraded lazily like this on read until MigrateConfigs has
+// rewritten them.
+func upgradeConfig(c *Calculator) error {
+	config, changed, err := configschema.Migrate(c.Config)
+	if err != nil {
+		return fmt.Errorf("upgrading config of calculator %s: %w", c.ID, err)
+	}
+	if changed {
+		c.Config = config
+	}
+	return nil
+}
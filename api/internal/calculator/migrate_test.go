@@ -0,0 +1,222 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// stubMigrationStore serves rows in pages and records writes.
+type stubMigrationStore struct {
+	rows     []*StaleConfig
+	listErr  error
+	writeErr map[string]error
+	stale    map[string]bool
+	written  []*StaleConfig
+	pages    int
+}
+
+func (s *stubMigrationStore) ListStaleConfigs(_ context.Context, _ int, afterID string, limit int) ([]*StaleConfig, error) {
+	s.pages++
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	page := make([]*StaleConfig, 0, limit)
+	for _, row := range s.rows {
+		if row.ID > afterID && len(page) < limit {
+			page = append(page, row)
+		}
+	}
+	return page, nil
+}
+
+func (s *stubMigrationStore) WriteMigratedConfig(_ context.Context, c *StaleConfig) (bool, error) {
+	if err := s.writeErr[c.ID]; err != nil {
+		return false, err
+	}
+	if s.stale[c.ID] {
+		return false, nil
+	}
+	s.written = append(s.written, c)
+	return true, nil
+}
+
+func newMigrationStore() *stubMigrationStore {
+	return &stubMigrationStore{
+		rows: []*StaleConfig{
+			{ID: "a", Config: []byte(`{}`), PublishedConfig: []byte(`{"fields":[]}`), PublishedVersion: 1},
+			{ID: "b", Config: []byte(`{"fields":"broken"}`)},
+			{ID: "c", Config: []byte(`{"schemaVersion":99}`)},
+			{ID: "d", Config: []byte(`{}`)},
+			{ID: "e", Config: []byte(`{}`), PublishedConfig: []byte(`{"layoutMode":"sideways"}`)},
+		},
+		writeErr: map[string]error{},
+		stale:    map[string]bool{},
+	}
+}
+
+func TestMigrateConfigs_IsolatesFailures(t *testing.T) {
+	store := newMigrationStore()
+	store.stale["d"] = true
+
+	report, err := MigrateConfigs(context.Background(), store, MigrateOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("MigrateConfigs() returned unexpected error: %v", err)
+	}
+	if report.Scanned != 5 || report.Migrated != 1 || report.Skipped != 1 || len(report.Failures) != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(store.written) != 1 || store.written[0].ID != "a" || store.written[0].PublishedConfig == nil {
+		t.Errorf("expected only row a written with its published config, got %+v", store.written)
+	}
+	failed := map[string]error{}
+	for _, f := range report.Failures {
+		failed[f.ID] = f.Err
+	}
+	var verr *configschema.ValidationError
+	if !errors.As(failed["b"], &verr) {
+		t.Errorf("expected validation failure for b, got: %v", failed["b"])
+	}
+	if !errors.Is(failed["c"], configschema.ErrNewerVersion) {
+		t.Errorf("expected ErrNewerVersion for c, got: %v", failed["c"])
+	}
+	if failed["e"] == nil {
+		t.Error("expected failure for e's invalid published config")
+	}
+	// Three pages of two, the last one short.
+	if store.pages != 3 {
+		t.Errorf("expected 3 pages read, got %d", store.pages)
+	}
+}
+
+func TestMigrateConfigs_DryRunWritesNothing(t *testing.T) {
+	store := newMigrationStore()
+
+	report, err := MigrateConfigs(context.Background(), store, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("MigrateConfigs() returned unexpected error: %v", err)
+	}
+	if len(store.written) != 0 {
+		t.Errorf("expected no writes in a dry run, got %d", len(store.written))
+	}
+	if report.Migrated != 2 || len(report.Failures) != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestMigrateConfigs_WriteErrorIsolated(t *testing.T) {
+	store := newMigrationStore()
+	store.writeErr["a"] = errors.New("connection reset")
+
+	report, err := MigrateConfigs(context.Background(), store, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("MigrateConfigs() returned unexpected error: %v", err)
+	}
+	if report.Migrated != 1 || len(report.Failures) != 4 {
+		t.Errorf("expected d migrated despite a's write error, got %+v", report)
+	}
+}
+
+func TestMigrateConfigs_ListError(t *testing.T) {
+	store := &stubMigrationStore{listErr: errors.New("db down")}
+
+	report, err := MigrateConfigs(context.Background(), store, MigrateOptions{})
+	if err == nil || report == nil {
+		t.Errorf("expected an error and a partial report, got %v, %v", report, err)
+	}
+}
+
+func TestGet_RejectsNewerSchemaVersion(t *testing.T) {
+	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{"schemaVersion":99}`)}},
+		&stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+
+	if _, err := svc.Get(context.Background(), "calc-abc", "user-xyz"); !errors.Is(err, configschema.ErrNewerVersion) {
+		t.Errorf("expected ErrNewerVersion, got: %v", err)
+	}
+}
+
+func TestUpdate_RejectsNewerSchemaVersion(t *testing.T) {
+	updater := &stubUpdater{}
+	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: &Calculator{}}, updater, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+
+	_, err := svc.Update(context.Background(), "calc-abc", "user-xyz", 0, false, []byte(`{"schemaVersion":99}`))
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("expected *configschema.ValidationError, got: %v", err)
+	}
+}
+
+func TestUpdate_UnversionedConfigIsCurrent(t *testing.T) {
+	updater := &stubUpdater{calc: &Calculator{ID: "calc-abc"}}
+	svc := NewService(&stubCreator{}, &stubLister{}, &stubGetter{calc: &Calculator{}}, updater, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{})
+
+	if _, err := svc.Update(context.Background(), "calc-abc", "user-xyz", 0, false, []byte(`{"fields":[]}`)); err != nil {
+		t.Fatalf("Update() returned unexpected error: %v", err)
+	}
+	// Stored without the key, the config would later be read as version 1.
+	want := fmt.Sprintf(`{"fields":[],"schemaVersion":%d}`, configschema.Version)
+	if string(updater.gotConfig) != want {
+		t.Errorf("expected the config stored as %s, got %s", want, updater.gotConfig)
+	}
+}
+
+func TestPostgresListStaleConfigs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "config", "config_version", "published_config", "published_version"}).
+		AddRow("calc-b", []byte(`{}`), 4, nil, 0)
+	mock.ExpectQuery(`SELECT id, config, config_version, published_config, published_version\s+FROM calculators\s+WHERE \(CASE WHEN jsonb_typeof\(config->'schemaVersion'\).*AND id > \$2\s+ORDER BY id\s+LIMIT \$3`).
+		WithArgs(2, "calc-a", 50).
+		WillReturnRows(rows)
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	stale, err := repo.ListStaleConfigs(context.Background(), 2, "calc-a", 50)
+	if err != nil {
+		t.Fatalf("ListStaleConfigs() returned unexpected error: %v", err)
+	}
+	if len(stale) != 1 || stale[0].ID != "calc-b" || stale[0].ConfigVersion != 4 || stale[0].PublishedConfig != nil {
+		t.Errorf("unexpected rows: %+v", stale)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresWriteMigratedConfig_Concurrent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectExec(`UPDATE calculators\s+SET config = \$2, published_config = COALESCE\(\$3, published_config\)\s+WHERE id = \$1 AND config_version = \$4 AND published_version = \$5`).
+		WithArgs("calc-a", []byte(`{}`), nil, 3, 0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	written, err := repo.WriteMigratedConfig(context.Background(), &StaleConfig{ID: "calc-a", Config: []byte(`{}`), ConfigVersion: 3})
+	if err != nil {
+		t.Fatalf("WriteMigratedConfig() returned unexpected error: %v", err)
+	}
+	if written {
+		t.Error("expected no write when the row changed concurrently")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
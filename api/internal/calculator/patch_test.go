@@ -0,0 +1,263 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/jsonpatch"
+)
+
+// stubPatcher runs apply against config as the repository would, without a database.
+type stubPatcher struct {
+	config          []byte
+	err             error
+	patched         []byte
+	expectedVersion int
+}
+
+func (s *stubPatcher) PatchCalculator(_ context.Context, id, _ string, expectedVersion int, apply func([]byte) ([]byte, error)) (*Calculator, error) {
+	s.expectedVersion = expectedVersion
+	if s.err != nil {
+		return nil, s.err
+	}
+	patched, err := apply(s.config)
+	if err != nil {
+		return nil, err
+	}
+	s.patched = patched
+	return &Calculator{ID: id, Config: patched, ConfigVersion: 2}, nil
+}
+
+func newPatchService(getter *stubGetter, patcher *stubPatcher) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithPatcher(patcher)
+}
+
+func TestPatch_MergePatch(t *testing.T) {
+	patcher := &stubPatcher{config: []byte(`{"layoutMode":"single"}`)}
+	svc := newPatchService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, patcher)
+
+	calc, err := svc.Patch(context.Background(), "calc-abc", "user-xyz", 1, MergePatch, []byte(`{"layoutMode":"multi-step"}`))
+	if err != nil {
+		t.Fatalf("Patch() returned unexpected error: %v", err)
+	}
+	if want := fmt.Sprintf(`{"layoutMode":"multi-step","schemaVersion":%d}`, configschema.Version); string(calc.Config) != want {
+		t.Errorf("unexpected config: %s", calc.Config)
+	}
+	if patcher.expectedVersion != 1 {
+		t.Errorf("expected version 1 passed to patcher, got %d", patcher.expectedVersion)
+	}
+}
+
+func TestPatch_JSONPatch(t *testing.T) {
+	patcher := &stubPatcher{config: []byte(`{"layoutMode":"single"}`)}
+	svc := newPatchService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, patcher)
+
+	patch := `[{"op":"test","path":"/layoutMode","value":"single"},{"op":"remove","path":"/layoutMode"}]`
+	calc, err := svc.Patch(context.Background(), "calc-abc", "user-xyz", 0, JSONPatch, []byte(patch))
+	if err != nil {
+		t.Fatalf("Patch() returned unexpected error: %v", err)
+	}
+	if want := fmt.Sprintf(`{"schemaVersion":%d}`, configschema.Version); string(calc.Config) != want {
+		t.Errorf("unexpected config: %s", calc.Config)
+	}
+}
+
+func TestPatch_RemovedSchemaVersionIsRestamped(t *testing.T) {
+	patcher := &stubPatcher{config: []byte(fmt.Sprintf(`{"layoutMode":"single-page","schemaVersion":%d}`, configschema.Version))}
+	svc := newPatchService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, patcher)
+
+	// Stored without the key, the config would later be read as version 1.
+	calc, err := svc.Patch(context.Background(), "calc-abc", "user-xyz", 0, JSONPatch, []byte(`[{"op":"remove","path":"/schemaVersion"}]`))
+	if err != nil {
+		t.Fatalf("Patch() returned unexpected error: %v", err)
+	}
+	if want := fmt.Sprintf(`{"layoutMode":"single-page","schemaVersion":%d}`, configschema.Version); string(calc.Config) != want {
+		t.Errorf("expected the current schema version stamped, got %s", calc.Config)
+	}
+}
+
+func TestPatch_TestOpFails(t *testing.T) {
+	patcher := &stubPatcher{config: []byte(`{"layoutMode":"single"}`)}
+	svc := newPatchService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, patcher)
+
+	patch := `[{"op":"test","path":"/layoutMode","value":"multi-step"}]`
+	_, err := svc.Patch(context.Background(), "calc-abc", "user-xyz", 0, JSONPatch, []byte(patch))
+	if !errors.Is(err, jsonpatch.ErrTestFailed) {
+		t.Fatalf("expected wrapped jsonpatch.ErrTestFailed, got: %v", err)
+	}
+	if patcher.patched != nil {
+		t.Error("expected nothing to be written")
+	}
+}
+
+func TestPatch_ResultFailsValidation(t *testing.T) {
+	patcher := &stubPatcher{config: []byte(`{}`)}
+	svc := newPatchService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, patcher)
+
+	_, err := svc.Patch(context.Background(), "calc-abc", "user-xyz", 0, MergePatch, []byte(`{"layoutMode":"sideways"}`))
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *configschema.ValidationError, got: %v", err)
+	}
+	if patcher.patched != nil {
+		t.Error("expected nothing to be written")
+	}
+}
+
+func TestPatch_OwnershipError(t *testing.T) {
+	patcher := &stubPatcher{}
+	svc := newPatchService(&stubGetter{err: ErrForbidden}, patcher)
+
+	_, err := svc.Patch(context.Background(), "calc-abc", "user-other", 0, MergePatch, []byte(`{}`))
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected wrapped ErrForbidden, got: %v", err)
+	}
+}
+
+func TestPatch_VersionConflict(t *testing.T) {
+	patcher := &stubPatcher{err: &VersionConflictError{CurrentVersion: 4}}
+	svc := newPatchService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, patcher)
+
+	_, err := svc.Patch(context.Background(), "calc-abc", "user-xyz", 3, MergePatch, []byte(`{}`))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected wrapped ErrVersionConflict, got: %v", err)
+	}
+}
+
+func TestPostgresPatchCalculator_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	patched := []byte(`{"layoutMode":"multi-step"}`)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT config, config_version FROM calculators .* FOR UPDATE`).
+		WithArgs("calc-id").
+		WillReturnRows(sqlmock.NewRows([]string{"config", "config_version"}).AddRow([]byte(`{}`), 4))
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", patched, 0).
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow("calc-id", "user-id", "", patched, 5, false, now, now, "", "{}", nil, 1, 0, nil))
+	mock.ExpectExec(`INSERT INTO calculator_revisions`).
+		WithArgs("calc-id", 5, patched, "user-id", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM calculator_revisions`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.PatchCalculator(context.Background(), "calc-id", "user-id", 4, func(config []byte) ([]byte, error) {
+		if string(config) != `{}` {
+			t.Errorf("apply received unexpected config: %s", config)
+		}
+		return patched, nil
+	})
+	if err != nil {
+		t.Fatalf("PatchCalculator() returned unexpected error: %v", err)
+	}
+	if calc.ConfigVersion != 5 {
+		t.Errorf("expected ConfigVersion 5, got %d", calc.ConfigVersion)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresPatchCalculator_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT config, config_version FROM calculators`).
+		WithArgs("calc-id").
+		WillReturnRows(sqlmock.NewRows([]string{"config", "config_version"}).AddRow([]byte(`{}`), 7))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.PatchCalculator(context.Background(), "calc-id", "user-id", 3, func(config []byte) ([]byte, error) {
+		t.Error("apply should not be called on a version conflict")
+		return config, nil
+	})
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.CurrentVersion != 7 {
+		t.Fatalf("expected *VersionConflictError with current version 7, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresPatchCalculator_ApplyErrorRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT config, config_version FROM calculators`).
+		WithArgs("calc-id").
+		WillReturnRows(sqlmock.NewRows([]string{"config", "config_version"}).AddRow([]byte(`{}`), 1))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	wantErr := errors.New("invalid patch")
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.PatchCalculator(context.Background(), "calc-id", "user-id", 0, func([]byte) ([]byte, error) {
+		return nil, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected apply error, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresPatchCalculator_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT config, config_version FROM calculators`).
+		WithArgs("calc-missing").
+		WillReturnRows(sqlmock.NewRows([]string{"config", "config_version"}))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.PatchCalculator(context.Background(), "calc-missing", "user-id", 0, func(config []byte) ([]byte, error) {
+		return config, nil
+	})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
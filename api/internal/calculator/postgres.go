@@ -5,6 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
 )
 
 // PostgresCalculatorRepository implements Creator against a PostgreSQL database.
@@ -17,15 +23,57 @@ func NewPostgresCalculatorRepository(db *sql.DB) *PostgresCalculatorRepository {
 	return &PostgresCalculatorRepository{db: db}
 }
 
-// ListCalculators returns all non-deleted calculators owned by userID, ordered by updated_at DESC.
-func (r *PostgresCalculatorRepository) ListCalculators(ctx context.Context, userID string) ([]*Calculator, error) {
-	const query = `
-		SELECT id, user_id, name, config, config_version, is_deleted, created_at, updated_at
+// ListCalculators returns the non-deleted calculators owned by q.UserID that
+// match q's search, tag, and folder filters, ordered by q.Sort with id as a
+// tiebreaker, starting strictly after q.After, at most q.Limit rows.
+//
+// Search uses ILIKE, which the name and description trigram indexes serve.
+// Tag filters use array containment, served by calculators_tags_idx.
+// Keyset pagination compares (sort column, id) row values so it stays correct
+// when many rows share a sort value.
+func (r *PostgresCalculatorRepository) ListCalculators(ctx context.Context, q ListQuery) ([]*Calculator, error) {
+	column, ok := sortColumns[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", q.Sort)
+	}
+	direction, cmp := "ASC", ">"
+	if q.Descending {
+		direction, cmp = "DESC", "<"
+	}
+
+	var b strings.Builder
+	args := []any{q.UserID}
+	b.WriteString(`
+		SELECT ` + calculatorColumns + `
 		FROM calculators
-		WHERE user_id = $1 AND is_deleted = FALSE
-		ORDER BY updated_at DESC
-	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+		WHERE user_id = $1 AND is_deleted = FALSE`)
+	if q.Search != "" {
+		args = append(args, "%"+escapeLike(q.Search)+"%")
+		fmt.Fprintf(&b, "\n\t\t\tAND (name ILIKE $%d OR description ILIKE $%d)", len(args), len(args))
+	}
+	if len(q.Tags) > 0 {
+		args = append(args, pq.Array(q.Tags))
+		fmt.Fprintf(&b, "\n\t\t\tAND tags @> $%d", len(args))
+	}
+	if q.FolderID != "" {
+		args = append(args, q.FolderID)
+		fmt.Fprintf(&b, "\n\t\t\tAND folder_id = $%d", len(args))
+	}
+	if q.Unfiled {
+		b.WriteString("\n\t\t\tAND folder_id IS NULL")
+	}
+	if q.After != nil {
+		var value any = q.After.Time
+		if q.Sort == SortByName {
+			value = q.After.Name
+		}
+		args = append(args, value, q.After.ID)
+		fmt.Fprintf(&b, "\n\t\t\tAND (%s, id) %s ($%d, $%d)", column, cmp, len(args)-1, len(args))
+	}
+	args = append(args, q.Limit)
+	fmt.Fprintf(&b, "\n\t\tORDER BY %s %s, id %s\n\t\tLIMIT $%d", column, direction, direction, len(args))
+
+	rows, err := r.db.QueryContext(ctx, b.String(), args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying calculators: %w", err)
 	}
@@ -34,7 +82,7 @@ func (r *PostgresCalculatorRepository) ListCalculators(ctx context.Context, user
 	calcs := make([]*Calculator, 0)
 	for rows.Next() {
 		var c Calculator
-		if err := rows.Scan(&c.ID, &c.UserID, &c.Name, &c.Config, &c.ConfigVersion, &c.IsDeleted, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(calculatorDest(&c)...); err != nil {
 			return nil, fmt.Errorf("scanning calculator: %w", err)
 		}
 		calcs = append(calcs, &c)
@@ -45,6 +93,50 @@ func (r *PostgresCalculatorRepository) ListCalculators(ctx context.Context, user
 	return calcs, nil
 }
 
+// calculatorColumns is the column list every calculator query selects or
+// returns, in the order calculatorDest expects.
+const calculatorColumns = "id, user_id, name, config, config_version, is_deleted, created_at, updated_at, description, tags, folder_id, metadata_version, published_version, published_at"
+
+// calculatorDest returns scan destinations for calculatorColumns.
+func calculatorDest(c *Calculator) []any {
+	return []any{
+		&c.ID, &c.UserID, &c.Name, &c.Config, &c.ConfigVersion, &c.IsDeleted, &c.CreatedAt, &c.UpdatedAt,
+		&c.Description, pq.Array(&c.Tags), emptyIfNull{&c.FolderID}, &c.MetadataVersion,
+		&c.PublishedVersion, &c.PublishedAt,
+	}
+}
+
+// emptyIfNull scans a nullable text column into a string, mapping NULL to "".
+type emptyIfNull struct {
+	dst *string
+}
+
+// Scan implements sql.Scanner.
+func (e emptyIfNull) Scan(src any) error {
+	var ns sql.NullString
+	if err := ns.Scan(src); err != nil {
+		return err
+	}
+	*e.dst = ns.String
+	return nil
+}
+
+// sortColumns maps each SortField to its column. Only these fixed strings are
+// ever interpolated into the ORDER BY clause.
+var sortColumns = map[SortField]string{
+	SortByUpdatedAt: "updated_at",
+	SortByCreatedAt: "created_at",
+	SortByName:      "name",
+}
+
+// likeEscaper escapes the LIKE wildcards and the default escape character so
+// user search text matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
 // GetCalculator fetches the calculator by id that is not soft-deleted.
 // Returns ErrNotFound if no matching, non-deleted row exists.
 // Returns ErrForbidden if the row exists but belongs to a different user.
@@ -55,14 +147,12 @@ func (r *PostgresCalculatorRepository) ListCalculators(ctx context.Context, user
 // the row is fetched.
 func (r *PostgresCalculatorRepository) GetCalculator(ctx context.Context, id, userID string) (*Calculator, error) {
 	const query = `
-		SELECT id, user_id, name, config, config_version, is_deleted, created_at, updated_at
+		SELECT ` + calculatorColumns + `
 		FROM calculators
 		WHERE id = $1 AND is_deleted = FALSE
 	`
 	var c Calculator
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&c.ID, &c.UserID, &c.Name, &c.Config, &c.ConfigVersion, &c.IsDeleted, &c.CreatedAt, &c.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(calculatorDest(&c)...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -75,18 +165,20 @@ func (r *PostgresCalculatorRepository) GetCalculator(ctx context.Context, id, us
 	return &c, nil
 }
 
-// GetPublicCalculatorConfig fetches the calculator by id without an ownership check.
-// Returns ErrNotFound if no matching, non-deleted row exists.
+// GetPublicCalculatorConfig fetches the published snapshot of the calculator
+// by id without an ownership check. The returned Config and ConfigVersion are
+// the published config and version, not the draft. EmbedDomains is set so the
+// caller can enforce the embed allow-list.
+// Returns ErrNotFound if no matching, non-deleted, published row exists.
 func (r *PostgresCalculatorRepository) GetPublicCalculatorConfig(ctx context.Context, id string) (*Calculator, error) {
 	const query = `
-		SELECT id, user_id, name, config, config_version, is_deleted, created_at, updated_at
+		SELECT id, user_id, name, published_config, published_version, is_deleted, created_at, updated_at,
+			description, tags, folder_id, metadata_version, published_version, published_at, embed_domains
 		FROM calculators
-		WHERE id = $1 AND is_deleted = FALSE
+		WHERE id = $1 AND is_deleted = FALSE AND published_config IS NOT NULL
 	`
 	var c Calculator
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&c.ID, &c.UserID, &c.Name, &c.Config, &c.ConfigVersion, &c.IsDeleted, &c.CreatedAt, &c.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(append(calculatorDest(&c), pq.Array(&c.EmbedDomains))...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -96,32 +188,172 @@ func (r *PostgresCalculatorRepository) GetPublicCalculatorConfig(ctx context.Con
 	return &c, nil
 }
 
-// UpdateCalculator updates the config of the calculator identified by id and increments config_version.
+// UpdateCalculator updates the config of the calculator identified by id, increments
+// config_version, and records the new config as a revision authored by authorID.
+// An autosave may replace authorID's latest revision instead (see recordRevisionTx).
+// The update, the revision insert, and revision pruning run in one transaction.
+// When expectedVersion is non-zero the UPDATE is conditional on config_version
+// still matching it.
+// Returns ErrNotFound if no matching, non-deleted row exists.
+// Returns a *VersionConflictError if expectedVersion is not the current version.
+func (r *PostgresCalculatorRepository) UpdateCalculator(ctx context.Context, id, authorID string, expectedVersion int, autosave bool, config []byte) (*Calculator, error) {
+	var c *Calculator
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		c, err = updateConfigTx(ctx, tx, id, expectedVersion, config)
+		if err != nil {
+			return err
+		}
+		return recordRevisionTx(ctx, tx, c, authorID, nil, autosave)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// PatchCalculator locks the calculator identified by id, passes its current
+// config to apply, and writes the returned config as a new head with an
+// incremented config_version, recorded as a revision authored by authorID.
+// Everything runs in one transaction; an error from apply rolls it back and is
+// returned unwrapped.
+// Returns ErrNotFound if no matching, non-deleted row exists.
+// Returns a *VersionConflictError if expectedVersion is non-zero and is not the current version.
+func (r *PostgresCalculatorRepository) PatchCalculator(ctx context.Context, id, authorID string, expectedVersion int, apply func(config []byte) ([]byte, error)) (*Calculator, error) {
+	const query = `
+		SELECT config, config_version FROM calculators
+		WHERE id = $1 AND is_deleted = FALSE
+		FOR UPDATE
+	`
+	var c *Calculator
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var (
+			config  []byte
+			current int
+		)
+		if err := tx.QueryRowContext(ctx, query, id).Scan(&config, &current); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("locking calculator: %w", err)
+		}
+		if expectedVersion != 0 && expectedVersion != current {
+			return &VersionConflictError{CurrentVersion: current}
+		}
+		patched, err := apply(config)
+		if err != nil {
+			return err
+		}
+		// The row lock makes the version check above authoritative.
+		c, err = updateConfigTx(ctx, tx, id, 0, patched)
+		if err != nil {
+			return err
+		}
+		return recordRevisionTx(ctx, tx, c, authorID, nil, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// PublishCalculator copies the draft config of the calculator identified by id
+// to its published snapshot and records the published version and time.
+// When expectedVersion is non-zero the UPDATE is conditional on config_version
+// still matching it.
+// Returns ErrNotFound if no matching, non-deleted row exists.
+// Returns a *VersionConflictError if expectedVersion is not the current version.
+func (r *PostgresCalculatorRepository) PublishCalculator(ctx context.Context, id string, expectedVersion int) (*Calculator, error) {
+	const query = `
+		UPDATE calculators
+		SET published_config = config, published_version = config_version, published_at = NOW()
+		WHERE id = $1 AND is_deleted = FALSE AND ($2 = 0 OR config_version = $2)
+		RETURNING ` + calculatorColumns + `
+	`
+	var c Calculator
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, query, id, expectedVersion).Scan(calculatorDest(&c)...)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("publishing calculator: %w", err)
+		}
+		if expectedVersion == 0 {
+			return ErrNotFound
+		}
+		return versionConflictTx(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// updateConfigTx overwrites the config of the calculator identified by id and
+// increments config_version within tx. A non-zero expectedVersion makes the
+// UPDATE conditional on the current config_version.
 // Returns ErrNotFound if no matching, non-deleted row exists.
-func (r *PostgresCalculatorRepository) UpdateCalculator(ctx context.Context, id string, config []byte) (*Calculator, error) {
+// Returns a *VersionConflictError if expectedVersion is not the current version.
+func updateConfigTx(ctx context.Context, tx *sql.Tx, id string, expectedVersion int, config []byte) (*Calculator, error) {
 	const query = `
 		UPDATE calculators
 		SET config = $2, config_version = config_version + 1
-		WHERE id = $1 AND is_deleted = FALSE
-		RETURNING id, user_id, name, config, config_version, is_deleted, created_at, updated_at
+		WHERE id = $1 AND is_deleted = FALSE AND ($3 = 0 OR config_version = $3)
+		RETURNING ` + calculatorColumns + `
 	`
 	var c Calculator
-	err := r.db.QueryRowContext(ctx, query, id, config).Scan(
-		&c.ID, &c.UserID, &c.Name, &c.Config, &c.ConfigVersion, &c.IsDeleted, &c.CreatedAt, &c.UpdatedAt,
-	)
+	err := tx.QueryRowContext(ctx, query, id, config, expectedVersion).Scan(calculatorDest(&c)...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrNotFound
+			if expectedVersion == 0 {
+				return nil, ErrNotFound
+			}
+			return nil, versionConflictTx(ctx, tx, id)
 		}
 		return nil, fmt.Errorf("updating calculator: %w", err)
 	}
 	return &c, nil
 }
 
+// versionConflictTx explains why a conditional update matched no rows: it
+// returns ErrNotFound if the calculator is gone, or a *VersionConflictError
+// carrying the current config_version otherwise.
+func versionConflictTx(ctx context.Context, tx *sql.Tx, id string) error {
+	const query = `SELECT config_version FROM calculators WHERE id = $1 AND is_deleted = FALSE`
+	var current int
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("querying current config version: %w", err)
+	}
+	return &VersionConflictError{CurrentVersion: current}
+}
+
+// withTx runs fn inside a transaction on db, committing if fn returns nil and
+// rolling back otherwise. Errors from fn are returned unwrapped so sentinel
+// errors survive.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		// The original error is more useful than any rollback failure.
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
 // DeleteCalculator soft-deletes the calculator identified by id.
 // Returns ErrNotFound if no matching, non-deleted row exists.
 func (r *PostgresCalculatorRepository) DeleteCalculator(ctx context.Context, id string) error {
-	const query = `UPDATE calculators SET is_deleted = TRUE WHERE id = $1 AND is_deleted = FALSE`
+	const query = `UPDATE calculators SET is_deleted = TRUE, deleted_at = NOW() WHERE id = $1 AND is_deleted = FALSE`
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("deleting calculator: %w", err)
@@ -140,16 +372,14 @@ func (r *PostgresCalculatorRepository) DeleteCalculator(ctx context.Context, id
 // Returns ErrNotFound if no matching, non-deleted row exists.
 func (r *PostgresCalculatorRepository) DuplicateCalculator(ctx context.Context, id string) (*Calculator, error) {
 	const query = `
-		INSERT INTO calculators (user_id, name, config, config_version)
-		SELECT user_id, name, config, 1
+		INSERT INTO calculators (user_id, name, description, tags, folder_id, config, config_version)
+		SELECT user_id, name, description, tags, folder_id, config, 1
 		FROM calculators
 		WHERE id = $1 AND is_deleted = FALSE
-		RETURNING id, user_id, name, config, config_version, is_deleted, created_at, updated_at
+		RETURNING ` + calculatorColumns + `
 	`
 	var c Calculator
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&c.ID, &c.UserID, &c.Name, &c.Config, &c.ConfigVersion, &c.IsDeleted, &c.CreatedAt, &c.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(calculatorDest(&c)...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -165,21 +395,1019 @@ func (r *PostgresCalculatorRepository) CreateCalculator(ctx context.Context, use
 	const query = `
 		INSERT INTO calculators (user_id)
 		VALUES ($1)
-		RETURNING id, user_id, name, config, config_version, is_deleted, created_at, updated_at
+		RETURNING ` + calculatorColumns + `
 	`
 	var c Calculator
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&c.ID,
-		&c.UserID,
-		&c.Name,
-		&c.Config,
-		&c.ConfigVersion,
-		&c.IsDeleted,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(calculatorDest(&c)...)
 	if err != nil {
 		return nil, fmt.Errorf("inserting calculator: %w", err)
 	}
 	return &c, nil
 }
+
+// recordRevisionTx records c's current config as a revision authored by
+// authorID, all within tx. When coalesce is set, an edit by the author of the
+// latest revision made within RevisionCoalesceWindow of it replaces that
+// revision in place. The latest revision is kept as it is when it was a
+// restore or is the published version, so both stay retrievable. Otherwise a
+// new revision is inserted and the calculator's history is pruned down to
+// MaxRevisionsPerCalculator rows.
+func recordRevisionTx(ctx context.Context, tx *sql.Tx, c *Calculator, authorID string, restoredFrom *int, coalesce bool) error {
+	if coalesce && restoredFrom == nil && authorID != "" {
+		const replace = `
+			UPDATE calculator_revisions AS r
+			SET config_version = $2, config = $3
+			FROM calculators AS c
+			WHERE c.id = r.calculator_id
+			  AND r.calculator_id = $1
+			  AND r.config_version = (
+				SELECT MAX(config_version) FROM calculator_revisions WHERE calculator_id = $1
+			  )
+			  AND r.author_id = $4
+			  AND r.restored_from_version IS NULL
+			  AND r.config_version <> c.published_version
+			  AND r.created_at > NOW() - make_interval(secs => $5)
+		`
+		res, err := tx.ExecContext(ctx, replace, c.ID, c.ConfigVersion, c.Config, authorID, RevisionCoalesceWindow.Seconds())
+		if err != nil {
+			return fmt.Errorf("coalescing revision: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("checking rows affected: %w", err)
+		}
+		if n > 0 {
+			return nil
+		}
+	}
+
+	const insert = `
+		INSERT INTO calculator_revisions (calculator_id, config_version, config, author_id, restored_from_version)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.ExecContext(ctx, insert, c.ID, c.ConfigVersion, c.Config, nullString(authorID), restoredFrom); err != nil {
+		return fmt.Errorf("inserting revision: %w", err)
+	}
+
+	const prune = `
+		DELETE FROM calculator_revisions
+		WHERE calculator_id = $1
+		  AND config_version <= (
+			SELECT config_version FROM calculator_revisions
+			WHERE calculator_id = $1
+			ORDER BY config_version DESC
+			OFFSET $2 LIMIT 1
+		  )
+	`
+	if _, err := tx.ExecContext(ctx, prune, c.ID, MaxRevisionsPerCalculator); err != nil {
+		return fmt.Errorf("pruning revisions: %w", err)
+	}
+	return nil
+}
+
+// ListRevisions returns the retained revisions of the calculator identified by
+// calculatorID, newest first. Configs are not loaded.
+func (r *PostgresCalculatorRepository) ListRevisions(ctx context.Context, calculatorID string) ([]*Revision, error) {
+	const query = `
+		SELECT id, calculator_id, config_version, author_id, restored_from_version, created_at
+		FROM calculator_revisions
+		WHERE calculator_id = $1
+		ORDER BY config_version DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, calculatorID)
+	if err != nil {
+		return nil, fmt.Errorf("querying revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revs := make([]*Revision, 0)
+	for rows.Next() {
+		var (
+			rev      Revision
+			authorID sql.NullString
+			restored sql.NullInt64
+		)
+		if err := rows.Scan(&rev.ID, &rev.CalculatorID, &rev.ConfigVersion, &authorID, &restored, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning revision: %w", err)
+		}
+		rev.AuthorID = authorID.String
+		rev.RestoredFromVersion = nullIntPtr(restored)
+		revs = append(revs, &rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating revisions: %w", err)
+	}
+	return revs, nil
+}
+
+// GetRevision fetches the revision of calculatorID with the given config_version.
+// Returns ErrRevisionNotFound if no such revision is retained.
+func (r *PostgresCalculatorRepository) GetRevision(ctx context.Context, calculatorID string, version int) (*Revision, error) {
+	const query = `
+		SELECT id, calculator_id, config_version, config, author_id, restored_from_version, created_at
+		FROM calculator_revisions
+		WHERE calculator_id = $1 AND config_version = $2
+	`
+	var (
+		rev      Revision
+		authorID sql.NullString
+		restored sql.NullInt64
+	)
+	err := r.db.QueryRowContext(ctx, query, calculatorID, version).Scan(
+		&rev.ID, &rev.CalculatorID, &rev.ConfigVersion, &rev.Config, &authorID, &restored, &rev.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("querying revision: %w", err)
+	}
+	rev.AuthorID = authorID.String
+	rev.RestoredFromVersion = nullIntPtr(restored)
+	return &rev, nil
+}
+
+// RestoreRevision writes config, the upgraded config of revision version, to
+// the calculator as a new head and records the restore as a revision authored
+// by authorID, all in one transaction. When expectedVersion is non-zero the
+// UPDATE is conditional on config_version still matching it.
+// Returns ErrNotFound if no matching, non-deleted calculator exists.
+// Returns a *VersionConflictError if expectedVersion is not the current version.
+func (r *PostgresCalculatorRepository) RestoreRevision(ctx context.Context, calculatorID string, version, expectedVersion int, config []byte, authorID string) (*Calculator, error) {
+	var c *Calculator
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		c, err = updateConfigTx(ctx, tx, calculatorID, expectedVersion, config)
+		if err != nil {
+			return err
+		}
+		return recordRevisionTx(ctx, tx, c, authorID, &version, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// nullString maps "" to SQL NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullBytes converts a nil byte slice to an untyped nil, which the driver
+// sends as NULL; a typed nil []byte is sent as an empty value.
+func nullBytes(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return b
+}
+
+// nullIntPtr converts a nullable integer column to *int.
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+// ListDeletedCalculators returns the soft-deleted calculators owned by userID,
+// most recently deleted first.
+func (r *PostgresCalculatorRepository) ListDeletedCalculators(ctx context.Context, userID string) ([]*Calculator, error) {
+	const query = `
+		SELECT ` + calculatorColumns + `, deleted_at
+		FROM calculators
+		WHERE user_id = $1 AND is_deleted = TRUE
+		ORDER BY deleted_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying deleted calculators: %w", err)
+	}
+	defer rows.Close()
+
+	calcs := make([]*Calculator, 0)
+	for rows.Next() {
+		var (
+			c         Calculator
+			deletedAt sql.NullTime
+		)
+		if err := rows.Scan(append(calculatorDest(&c), &deletedAt)...); err != nil {
+			return nil, fmt.Errorf("scanning deleted calculator: %w", err)
+		}
+		if deletedAt.Valid {
+			c.DeletedAt = &deletedAt.Time
+		}
+		calcs = append(calcs, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating deleted calculators: %w", err)
+	}
+	return calcs, nil
+}
+
+// RestoreCalculator clears the soft-delete flag on the calculator identified
+// by id. As with GetCalculator, ownership is checked in Go after the row is
+// fetched so "not found" and "forbidden" can be told apart.
+// Returns ErrNotFound if no matching, soft-deleted row exists.
+// Returns ErrForbidden if the row exists but belongs to a different user.
+func (r *PostgresCalculatorRepository) RestoreCalculator(ctx context.Context, id, userID string) (*Calculator, error) {
+	const lock = `SELECT user_id FROM calculators WHERE id = $1 AND is_deleted = TRUE FOR UPDATE`
+	const restore = `
+		UPDATE calculators
+		SET is_deleted = FALSE, deleted_at = NULL
+		WHERE id = $1
+		RETURNING ` + calculatorColumns + `
+	`
+	var c Calculator
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var owner string
+		if err := tx.QueryRowContext(ctx, lock, id).Scan(&owner); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("querying deleted calculator: %w", err)
+		}
+		if owner != userID {
+			return ErrForbidden
+		}
+		if err := tx.QueryRowContext(ctx, restore, id).Scan(calculatorDest(&c)...); err != nil {
+			return fmt.Errorf("restoring calculator: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// PurgeDeletedCalculators hard-deletes up to limit calculators soft-deleted
+// before cutoff; their revisions and asset references go with them via ON
+// DELETE CASCADE. Rows locked by a concurrent restore are skipped and picked
+// up by a later run.
+//
+// It returns the number of calculators deleted and the asset keys they
+// referenced that no remaining calculator or revision references. Because
+// asset keys are content-addressed, the same upload can be shared by several
+// calculators, so only these keys are candidates for deletion from storage;
+// delete them through DeleteUnreferencedAsset, which checks again.
+func (r *PostgresCalculatorRepository) PurgeDeletedCalculators(ctx context.Context, cutoff time.Time, limit int) (int, []string, error) {
+	const selectExpired = `
+		SELECT id FROM calculators
+		WHERE is_deleted = TRUE AND deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	const selectKeys = `SELECT DISTINCT asset_key FROM asset_references WHERE calculator_id = ANY($1)`
+	const deleteExpired = `DELETE FROM calculators WHERE id = ANY($1)`
+	const selectOrphaned = `
+		SELECT key FROM unnest($1::text[]) AS key
+		WHERE NOT EXISTS (SELECT 1 FROM asset_references WHERE asset_key = key)
+	`
+
+	var (
+		purged   int
+		orphaned []string
+	)
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var ids []string
+		if err := queryRows(ctx, tx, selectExpired, func(rows *sql.Rows) error {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+			return nil
+		}, cutoff, limit); err != nil {
+			return fmt.Errorf("querying expired calculators: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		var keys []string
+		if err := queryRows(ctx, tx, selectKeys, func(rows *sql.Rows) error {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			return nil
+		}, pq.Array(ids)); err != nil {
+			return fmt.Errorf("querying expired calculator assets: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteExpired, pq.Array(ids)); err != nil {
+			return fmt.Errorf("deleting expired calculators: %w", err)
+		}
+		purged = len(ids)
+
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := queryRows(ctx, tx, selectOrphaned, func(rows *sql.Rows) error {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				return err
+			}
+			orphaned = append(orphaned, key)
+			return nil
+		}, pq.Array(keys)); err != nil {
+			return fmt.Errorf("querying orphaned assets: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return purged, orphaned, nil
+}
+
+// DeleteUnreferencedAsset calls del for key if nothing references the asset,
+// and reports whether it did. The check and del run under an exclusive lock on
+// key that every write recording a reference to key takes in shared mode, so a
+// save that is referencing the asset either commits first and is seen, or
+// waits until del has returned. If del fails the lock is released and the
+// asset is left for a later purge to retry.
+func (r *PostgresCalculatorRepository) DeleteUnreferencedAsset(ctx context.Context, key string, del func(ctx context.Context, key string) error) (bool, error) {
+	const lock = `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`
+	const selectReferenced = `SELECT EXISTS (SELECT 1 FROM asset_references WHERE asset_key = $1)`
+
+	var deleted bool
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, lock, key); err != nil {
+			return fmt.Errorf("locking asset: %w", err)
+		}
+		var referenced bool
+		if err := tx.QueryRowContext(ctx, selectReferenced, key).Scan(&referenced); err != nil {
+			return fmt.Errorf("querying asset references: %w", err)
+		}
+		if referenced {
+			return nil
+		}
+		if err := del(ctx, key); err != nil {
+			return fmt.Errorf("deleting asset: %w", err)
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// queryRows runs query within tx and calls scan once per result row.
+func queryRows(ctx context.Context, tx *sql.Tx, query string, scan func(*sql.Rows) error, args ...any) error {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// UpdateCalculatorMetadata replaces the name, description, tags, and folder of
+// the calculator identified by id and increments metadata_version, leaving
+// config and config_version untouched. A non-empty m.FolderID must name a
+// folder owned by ownerID. When expectedVersion is non-zero the UPDATE is
+// conditional on metadata_version still matching it.
+// Returns ErrFolderNotFound if m.FolderID is not one of ownerID's folders.
+// Returns ErrNotFound if no matching, non-deleted row exists.
+// Returns a *MetadataVersionConflictError if expectedVersion is not the current version.
+func (r *PostgresCalculatorRepository) UpdateCalculatorMetadata(ctx context.Context, id, ownerID string, expectedVersion int, m Metadata) (*Calculator, error) {
+	const folderExists = `SELECT EXISTS (SELECT 1 FROM calculator_folders WHERE id = $1 AND user_id = $2)`
+	const update = `
+		UPDATE calculators
+		SET name = $2, description = $3, tags = $4, folder_id = $5, metadata_version = metadata_version + 1
+		WHERE id = $1 AND is_deleted = FALSE AND ($6 = 0 OR metadata_version = $6)
+		RETURNING ` + calculatorColumns + `
+	`
+	const current = `SELECT metadata_version FROM calculators WHERE id = $1 AND is_deleted = FALSE`
+
+	var c Calculator
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if m.FolderID != "" {
+			var exists bool
+			if err := tx.QueryRowContext(ctx, folderExists, m.FolderID, ownerID).Scan(&exists); err != nil {
+				return fmt.Errorf("querying folder: %w", err)
+			}
+			if !exists {
+				return ErrFolderNotFound
+			}
+		}
+		err := tx.QueryRowContext(ctx, update, id, m.Name, m.Description, pq.Array(m.Tags), nullString(m.FolderID), expectedVersion).
+			Scan(calculatorDest(&c)...)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("updating calculator metadata: %w", err)
+		}
+		if expectedVersion == 0 {
+			return ErrNotFound
+		}
+		var version int
+		if err := tx.QueryRowContext(ctx, current, id).Scan(&version); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("querying current metadata version: %w", err)
+		}
+		return &MetadataVersionConflictError{CurrentVersion: version}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListFolders returns the folders owned by userID, ordered by name.
+func (r *PostgresCalculatorRepository) ListFolders(ctx context.Context, userID string) ([]*Folder, error) {
+	const query = `
+		SELECT id, user_id, name, created_at, updated_at
+		FROM calculator_folders
+		WHERE user_id = $1
+		ORDER BY name, id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying folders: %w", err)
+	}
+	defer rows.Close()
+
+	folders := make([]*Folder, 0)
+	for rows.Next() {
+		var f Folder
+		if err := rows.Scan(&f.ID, &f.UserID, &f.Name, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning folder: %w", err)
+		}
+		folders = append(folders, &f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating folders: %w", err)
+	}
+	return folders, nil
+}
+
+// CreateFolder inserts a folder named name for userID.
+// Returns ErrFolderExists if the name violates the per-user unique constraint (pq code 23505).
+func (r *PostgresCalculatorRepository) CreateFolder(ctx context.Context, userID, name string) (*Folder, error) {
+	const query = `
+		INSERT INTO calculator_folders (user_id, name)
+		VALUES ($1, $2)
+		RETURNING id, user_id, name, created_at, updated_at
+	`
+	var f Folder
+	err := r.db.QueryRowContext(ctx, query, userID, name).Scan(&f.ID, &f.UserID, &f.Name, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrFolderExists
+		}
+		return nil, fmt.Errorf("inserting folder: %w", err)
+	}
+	return &f, nil
+}
+
+// RenameFolder renames the folder identified by id if it is owned by userID.
+// Returns ErrFolderNotFound if no such folder exists.
+// Returns ErrFolderExists if the name violates the per-user unique constraint (pq code 23505).
+func (r *PostgresCalculatorRepository) RenameFolder(ctx context.Context, id, userID, name string) (*Folder, error) {
+	const query = `
+		UPDATE calculator_folders
+		SET name = $3
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, user_id, name, created_at, updated_at
+	`
+	var f Folder
+	err := r.db.QueryRowContext(ctx, query, id, userID, name).Scan(&f.ID, &f.UserID, &f.Name, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFolderNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrFolderExists
+		}
+		return nil, fmt.Errorf("renaming folder: %w", err)
+	}
+	return &f, nil
+}
+
+// DeleteFolder deletes the folder identified by id if it is owned by userID.
+// Its calculators are left unfiled by the folder_id foreign key's ON DELETE SET NULL.
+// Returns ErrFolderNotFound if no such folder exists.
+func (r *PostgresCalculatorRepository) DeleteFolder(ctx context.Context, id, userID string) error {
+	const query = `DELETE FROM calculator_folders WHERE id = $1 AND user_id = $2`
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("deleting folder: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrFolderNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation (23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pq.Error
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// ListTemplates returns the stored templates ordered by category then name,
+// filtered to category when it is non-empty. Configs are not selected.
+func (r *PostgresCalculatorRepository) ListTemplates(ctx context.Context, category string) ([]*Template, error) {
+	const query = `
+		SELECT slug, name, category, description, created_at, updated_at
+		FROM calculator_templates
+		WHERE ($1 = '' OR category = $1)
+		ORDER BY category, name
+	`
+	rows, err := r.db.QueryContext(ctx, query, category)
+	if err != nil {
+		return nil, fmt.Errorf("querying templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]*Template, 0)
+	for rows.Next() {
+		var t Template
+		if err := rows.Scan(&t.Slug, &t.Name, &t.Category, &t.Description, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning template: %w", err)
+		}
+		templates = append(templates, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplate returns the template with the given slug, including its config.
+// Returns ErrTemplateNotFound if no such row exists.
+func (r *PostgresCalculatorRepository) GetTemplate(ctx context.Context, slug string) (*Template, error) {
+	const query = `
+		SELECT slug, name, category, description, config, created_at, updated_at
+		FROM calculator_templates
+		WHERE slug = $1
+	`
+	var t Template
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(&t.Slug, &t.Name, &t.Category, &t.Description, &t.Config, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("querying template: %w", err)
+	}
+	return &t, nil
+}
+
+// InstantiateTemplate inserts a calculator for userID whose name, description,
+// and config are copied from the template with the given slug, with
+// config_version reset to 1.
+// Returns ErrTemplateNotFound if no such template exists.
+func (r *PostgresCalculatorRepository) InstantiateTemplate(ctx context.Context, slug, userID string) (*Calculator, error) {
+	const query = `
+		INSERT INTO calculators (user_id, name, description, config, config_version)
+		SELECT $2, name, description, config, 1
+		FROM calculator_templates
+		WHERE slug = $1
+		RETURNING ` + calculatorColumns + `
+	`
+	var c Calculator
+	err := r.db.QueryRowContext(ctx, query, slug, userID).Scan(calculatorDest(&c)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("instantiating template: %w", err)
+	}
+	return &c, nil
+}
+
+// ReplaceTemplates upserts every template by slug and deletes stored templates
+// not among them, in a single transaction. Unchanged rows are not rewritten,
+// so updated_at records when a template last changed.
+func (r *PostgresCalculatorRepository) ReplaceTemplates(ctx context.Context, templates []*Template) error {
+	const upsert = `
+		INSERT INTO calculator_templates (slug, name, category, description, config)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (slug) DO UPDATE
+		SET name = EXCLUDED.name,
+			category = EXCLUDED.category,
+			description = EXCLUDED.description,
+			config = EXCLUDED.config
+		WHERE (calculator_templates.name, calculator_templates.category, calculator_templates.description, calculator_templates.config)
+			IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.category, EXCLUDED.description, EXCLUDED.config)
+	`
+	const prune = `DELETE FROM calculator_templates WHERE NOT (slug = ANY($1))`
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		slugs := make([]string, len(templates))
+		for i, t := range templates {
+			slugs[i] = t.Slug
+			if _, err := tx.ExecContext(ctx, upsert, t.Slug, t.Name, t.Category, t.Description, t.Config); err != nil {
+				return fmt.Errorf("upserting template %q: %w", t.Slug, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, prune, pq.Array(slugs)); err != nil {
+			return fmt.Errorf("pruning templates: %w", err)
+		}
+		return nil
+	})
+}
+
+// ImportCalculator inserts a calculator for userID with the given metadata and
+// config. config_version, metadata_version, and published_version take their
+// column defaults, so the calculator starts as an unpublished draft.
+func (r *PostgresCalculatorRepository) ImportCalculator(ctx context.Context, userID string, m Metadata, config []byte) (*Calculator, error) {
+	const query = `
+		INSERT INTO calculators (user_id, name, description, tags, config)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + calculatorColumns + `
+	`
+	var c Calculator
+	err := r.db.QueryRowContext(ctx, query, userID, m.Name, m.Description, pq.Array(m.Tags), config).Scan(calculatorDest(&c)...)
+	if err != nil {
+		return nil, fmt.Errorf("inserting imported calculator: %w", err)
+	}
+	return &c, nil
+}
+
+// schemaVersionOf returns a SQL expression for the schema version recorded in
+// the given JSONB config column, defaulting to 1 like configschema.Migrate.
+func schemaVersionOf(column string) string {
+	return fmt.Sprintf(
+		"CASE WHEN jsonb_typeof(%[1]s->'%[2]s') = 'number' THEN (%[1]s->>'%[2]s')::numeric ELSE 1 END",
+		column, configschema.VersionKey)
+}
+
+// ListStaleConfigs returns up to limit calculators, soft-deleted ones included,
+// whose draft or published config records a schema version below version,
+// ordered by id and starting strictly after afterID.
+func (r *PostgresCalculatorRepository) ListStaleConfigs(ctx context.Context, version int, afterID string, limit int) ([]*StaleConfig, error) {
+	args := []any{version}
+	query := `
+		SELECT id, config, config_version, published_config, published_version
+		FROM calculators
+		WHERE (` + schemaVersionOf("config") + ` < $1
+			OR (published_config IS NOT NULL AND ` + schemaVersionOf("published_config") + ` < $1))`
+	if afterID != "" {
+		args = append(args, afterID)
+		query += fmt.Sprintf("\n\t\t\tAND id > $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf("\n\t\tORDER BY id\n\t\tLIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying stale configs: %w", err)
+	}
+	defer rows.Close()
+
+	stale := make([]*StaleConfig, 0)
+	for rows.Next() {
+		var c StaleConfig
+		if err := rows.Scan(&c.ID, &c.Config, &c.ConfigVersion, &c.PublishedConfig, &c.PublishedVersion); err != nil {
+			return nil, fmt.Errorf("scanning stale config: %w", err)
+		}
+		stale = append(stale, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating stale configs: %w", err)
+	}
+	return stale, nil
+}
+
+// WriteMigratedConfig replaces the draft and, when c.PublishedConfig is set,
+// the published config of calculator c.ID, provided neither has been changed
+// since c was read. config_version and published_version are left as they
+// are. It reports whether the row was updated.
+func (r *PostgresCalculatorRepository) WriteMigratedConfig(ctx context.Context, c *StaleConfig) (bool, error) {
+	const query = `
+		UPDATE calculators
+		SET config = $2, published_config = COALESCE($3, published_config)
+		WHERE id = $1 AND config_version = $4 AND published_version = $5
+	`
+	res, err := r.db.ExecContext(ctx, query, c.ID, c.Config, nullBytes(c.PublishedConfig), c.ConfigVersion, c.PublishedVersion)
+	if err != nil {
+		return false, fmt.Errorf("updating migrated config: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("checking migrated config update: %w", err)
+	}
+	return n == 1, nil
+}
+
+// bulkJobColumns is the column list every bulk job query selects or returns,
+// in the order scanBulkJob expects.
+const bulkJobColumns = "id, user_id, operation, patch, publish, status, created_at, started_at, finished_at"
+
+// scanBulkJob scans one bulkJobColumns row.
+func scanBulkJob(row interface{ Scan(...any) error }) (*BulkJob, error) {
+	var j BulkJob
+	err := row.Scan(&j.ID, &j.UserID, &j.Operation, &j.Patch, &j.Publish, &j.Status, &j.CreatedAt, &j.StartedAt, &j.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// CreateBulkJob inserts job and a pending item for each of its calculators,
+// numbered in order, in one transaction.
+func (r *PostgresCalculatorRepository) CreateBulkJob(ctx context.Context, job *BulkJob) (*BulkJob, error) {
+	const insertJob = `
+		INSERT INTO calculator_bulk_jobs (user_id, operation, patch, publish)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + bulkJobColumns + `
+	`
+	const insertItems = `
+		INSERT INTO calculator_bulk_job_items (job_id, calculator_id, position)
+		SELECT $1, t.id, t.ord - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS t(id, ord)
+	`
+	var created *BulkJob
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		created, err = scanBulkJob(tx.QueryRowContext(ctx, insertJob, job.UserID, job.Operation, nullBytes(job.Patch), job.Publish))
+		if err != nil {
+			return fmt.Errorf("inserting bulk job: %w", err)
+		}
+		ids := make([]string, len(job.Items))
+		created.Items = make([]*BulkItem, len(job.Items))
+		for i, item := range job.Items {
+			ids[i] = item.CalculatorID
+			created.Items[i] = &BulkItem{CalculatorID: item.CalculatorID, Status: BulkItemPending}
+		}
+		if _, err := tx.ExecContext(ctx, insertItems, created.ID, pq.Array(ids)); err != nil {
+			return fmt.Errorf("inserting bulk job items: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetBulkJob fetches the bulk job identified by id and owned by userID, with
+// its items in submission order.
+// Returns ErrBulkJobNotFound if no matching row exists.
+func (r *PostgresCalculatorRepository) GetBulkJob(ctx context.Context, id, userID string) (*BulkJob, error) {
+	const query = `SELECT ` + bulkJobColumns + ` FROM calculator_bulk_jobs WHERE id = $1 AND user_id = $2`
+	job, err := scanBulkJob(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBulkJobNotFound
+		}
+		return nil, fmt.Errorf("querying bulk job: %w", err)
+	}
+	if job.Items, err = r.bulkJobItems(ctx, job.ID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ClaimBulkJob marks the oldest pending job, or the oldest running job whose
+// claim was last refreshed before staleBefore, as running and returns it with
+// its items. SKIP LOCKED lets several instances claim concurrently without
+// blocking on each other. Returns nil when no job is claimable.
+func (r *PostgresCalculatorRepository) ClaimBulkJob(ctx context.Context, staleBefore time.Time) (*BulkJob, error) {
+	const query = `
+		UPDATE calculator_bulk_jobs
+		SET status = 'running', claimed_at = NOW(), started_at = COALESCE(started_at, NOW())
+		WHERE id = (
+			SELECT id FROM calculator_bulk_jobs
+			WHERE status = 'pending' OR (status = 'running' AND claimed_at < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + bulkJobColumns + `
+	`
+	job, err := scanBulkJob(r.db.QueryRowContext(ctx, query, staleBefore))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("claiming bulk job: %w", err)
+	}
+	if job.Items, err = r.bulkJobItems(ctx, job.ID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// bulkJobItems returns the items of job jobID in submission order.
+func (r *PostgresCalculatorRepository) bulkJobItems(ctx context.Context, jobID string) ([]*BulkItem, error) {
+	const query = `
+		SELECT calculator_id, status, error, previous_version, config_version
+		FROM calculator_bulk_job_items
+		WHERE job_id = $1
+		ORDER BY position
+	`
+	rows, err := r.db.QueryContext(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("querying bulk job items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*BulkItem, 0)
+	for rows.Next() {
+		var item BulkItem
+		var previous, current sql.NullInt64
+		if err := rows.Scan(&item.CalculatorID, &item.Status, emptyIfNull{&item.Error}, &previous, &current); err != nil {
+			return nil, fmt.Errorf("scanning bulk job item: %w", err)
+		}
+		item.PreviousVersion, item.ConfigVersion = int(previous.Int64), int(current.Int64)
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating bulk job items: %w", err)
+	}
+	return items, nil
+}
+
+// CompleteBulkItem records the outcome of item if it is still pending and
+// refreshes the job's claim, so an active job is not mistaken for an
+// abandoned one.
+func (r *PostgresCalculatorRepository) CompleteBulkItem(ctx context.Context, jobID string, item *BulkItem) error {
+	const query = `
+		WITH item AS (
+			UPDATE calculator_bulk_job_items
+			SET status = $3, error = $4, previous_version = $5, config_version = $6
+			WHERE job_id = $1 AND calculator_id = $2 AND status = 'pending'
+			RETURNING job_id
+		)
+		UPDATE calculator_bulk_jobs SET claimed_at = NOW()
+		WHERE id IN (SELECT job_id FROM item)
+	`
+	_, err := r.db.ExecContext(ctx, query, jobID, item.CalculatorID, item.Status, nullString(item.Error), item.PreviousVersion, item.ConfigVersion)
+	if err != nil {
+		return fmt.Errorf("updating bulk job item: %w", err)
+	}
+	return nil
+}
+
+// FinishBulkJob marks the bulk job identified by id completed.
+func (r *PostgresCalculatorRepository) FinishBulkJob(ctx context.Context, id string) error {
+	const query = `UPDATE calculator_bulk_jobs SET status = 'completed', finished_at = NOW() WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("finishing bulk job: %w", err)
+	}
+	return nil
+}
+
+// transferColumns lists the calculator_transfers columns scanned into a Transfer.
+const transferColumns = `id, calculator_id, from_user_id, to_email, expires_at, created_at`
+
+// CreateTransfer stores a pending transfer of calculatorID to toEmail,
+// replacing any earlier transfer of the same calculator.
+// Returns ErrTransferToSelf if toEmail is fromUserID's own address.
+func (r *PostgresCalculatorRepository) CreateTransfer(ctx context.Context, calculatorID, fromUserID, toEmail, tokenHash string, expiresAt time.Time) (*Transfer, error) {
+	const query = `
+		INSERT INTO calculator_transfers (calculator_id, from_user_id, to_email, token_hash, expires_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE id = $2 AND LOWER(email) = LOWER($3))
+		ON CONFLICT (calculator_id) DO UPDATE
+		SET from_user_id = EXCLUDED.from_user_id, to_email = EXCLUDED.to_email,
+			token_hash = EXCLUDED.token_hash, expires_at = EXCLUDED.expires_at, created_at = NOW()
+		RETURNING ` + transferColumns + `
+	`
+	var t Transfer
+	err := r.db.QueryRowContext(ctx, query, calculatorID, fromUserID, toEmail, tokenHash, expiresAt).
+		Scan(&t.ID, &t.CalculatorID, &t.FromUserID, &t.ToEmail, &t.ExpiresAt, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransferToSelf
+		}
+		return nil, fmt.Errorf("inserting transfer: %w", err)
+	}
+	return &t, nil
+}
+
+// DeleteTransfer removes the pending transfer of calculatorID.
+// Returns ErrTransferNotFound if there is none.
+func (r *PostgresCalculatorRepository) DeleteTransfer(ctx context.Context, calculatorID string) error {
+	const query = `DELETE FROM calculator_transfers WHERE calculator_id = $1`
+	result, err := r.db.ExecContext(ctx, query, calculatorID)
+	if err != nil {
+		return fmt.Errorf("deleting transfer: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrTransferNotFound
+	}
+	return nil
+}
+
+// AcceptTransfer locks the transfer with tokenHash, checks that recipientID
+// is the account it was sent to, then deletes it and moves the calculator to
+// recipientID, all in one transaction. The calculator is taken out of its
+// folder, which belongs to the previous owner.
+// Returns ErrInvalidTransferToken if no transfer matches or it expired at now.
+// Returns ErrTransferRecipientMismatch if recipientID's email is not the
+// transfer's address, and ErrTransferToSelf if recipientID already owns it.
+// Returns ErrNotFound if the calculator is soft-deleted or no longer owned by
+// the user who started the transfer.
+func (r *PostgresCalculatorRepository) AcceptTransfer(ctx context.Context, tokenHash, recipientID string, now time.Time) (*Calculator, error) {
+	const lock = `SELECT ` + transferColumns + ` FROM calculator_transfers WHERE token_hash = $1 FOR UPDATE`
+	const recipient = `SELECT email FROM users WHERE id = $1`
+	const consume = `DELETE FROM calculator_transfers WHERE id = $1`
+	const move = `
+		UPDATE calculators
+		SET user_id = $2, folder_id = NULL
+		WHERE id = $1 AND user_id = $3 AND is_deleted = FALSE
+		RETURNING ` + calculatorColumns + `
+	`
+	var c Calculator
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var t Transfer
+		err := tx.QueryRowContext(ctx, lock, tokenHash).
+			Scan(&t.ID, &t.CalculatorID, &t.FromUserID, &t.ToEmail, &t.ExpiresAt, &t.CreatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidTransferToken
+			}
+			return fmt.Errorf("querying transfer: %w", err)
+		}
+		if !t.ExpiresAt.After(now) {
+			return ErrInvalidTransferToken
+		}
+		var email string
+		if err := tx.QueryRowContext(ctx, recipient, recipientID).Scan(&email); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTransferRecipientMismatch
+			}
+			return fmt.Errorf("querying recipient: %w", err)
+		}
+		if !strings.EqualFold(email, t.ToEmail) {
+			return ErrTransferRecipientMismatch
+		}
+		if recipientID == t.FromUserID {
+			return ErrTransferToSelf
+		}
+		if _, err := tx.ExecContext(ctx, consume, t.ID); err != nil {
+			return fmt.Errorf("deleting transfer: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx, move, t.CalculatorID, recipientID, t.FromUserID).Scan(calculatorDest(&c)...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("moving calculator: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetEmbedDomains returns the embed allow-list of the calculator identified by id.
+// Returns ErrNotFound if no matching, non-deleted row exists.
+func (r *PostgresCalculatorRepository) GetEmbedDomains(ctx context.Context, id string) ([]string, error) {
+	const query = `SELECT embed_domains FROM calculators WHERE id = $1 AND is_deleted = FALSE`
+	var domains []string
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(pq.Array(&domains)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("querying embed domains: %w", err)
+	}
+	return domains, nil
+}
+
+// SetEmbedDomains replaces the embed allow-list of the calculator identified
+// by id. Neither config_version nor metadata_version changes.
+// Returns ErrNotFound if no matching, non-deleted row exists.
+func (r *PostgresCalculatorRepository) SetEmbedDomains(ctx context.Context, id string, domains []string) error {
+	const query = `UPDATE calculators SET embed_domains = $2 WHERE id = $1 AND is_deleted = FALSE`
+	result, err := r.db.ExecContext(ctx, query, id, pq.Array(domains))
+	if err != nil {
+		return fmt.Errorf("updating embed domains: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -9,7 +9,10 @@ import (
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 )
 
-var listColumns = []string{"id", "user_id", "name", "config", "config_version", "is_deleted", "created_at", "updated_at"}
+var listColumns = []string{
+	"id", "user_id", "name", "config", "config_version", "is_deleted", "created_at", "updated_at",
+	"description", "tags", "folder_id", "metadata_version", "published_version", "published_at",
+}
 
 func TestListCalculators_Empty(t *testing.T) {
 	db, mock, err := sqlmock.New()
@@ -19,12 +22,12 @@ func TestListCalculators_Empty(t *testing.T) {
 
 	rows := sqlmock.NewRows(listColumns)
 	mock.ExpectQuery(`SELECT id, user_id`).
-		WithArgs("user-id").
+		WithArgs("user-id", 51).
 		WillReturnRows(rows)
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	calcs, err := repo.ListCalculators(context.Background(), "user-id")
+	calcs, err := repo.ListCalculators(context.Background(), ListQuery{UserID: "user-id", Sort: SortByUpdatedAt, Descending: true, Limit: 51})
 	if err != nil {
 		t.Fatalf("ListCalculators() returned unexpected error: %v", err)
 	}
@@ -50,15 +53,15 @@ func TestListCalculators_Multiple(t *testing.T) {
 
 	now := time.Now().UTC().Truncate(time.Second)
 	rows := sqlmock.NewRows(listColumns).
-		AddRow("calc-1", "user-id", "", []byte(`{}`), 1, false, now, now).
-		AddRow("calc-2", "user-id", "", []byte(`{}`), 2, false, now, now)
+		AddRow("calc-1", "user-id", "", []byte(`{}`), 1, false, now, now, "", "{}", nil, 1, 0, nil).
+		AddRow("calc-2", "user-id", "", []byte(`{}`), 2, false, now, now, "", "{}", nil, 1, 0, nil)
 	mock.ExpectQuery(`SELECT id, user_id`).
-		WithArgs("user-id").
+		WithArgs("user-id", 51).
 		WillReturnRows(rows)
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	calcs, err := repo.ListCalculators(context.Background(), "user-id")
+	calcs, err := repo.ListCalculators(context.Background(), ListQuery{UserID: "user-id", Sort: SortByUpdatedAt, Descending: true, Limit: 51})
 	if err != nil {
 		t.Fatalf("ListCalculators() returned unexpected error: %v", err)
 	}
@@ -93,12 +96,12 @@ func TestListCalculators_QueryError(t *testing.T) {
 
 	wantErr := errors.New("connection reset")
 	mock.ExpectQuery(`SELECT id, user_id`).
-		WithArgs("user-id").
+		WithArgs("user-id", 51).
 		WillReturnError(wantErr)
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	_, err = repo.ListCalculators(context.Background(), "user-id")
+	_, err = repo.ListCalculators(context.Background(), ListQuery{UserID: "user-id", Sort: SortByUpdatedAt, Descending: true, Limit: 51})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -122,12 +125,12 @@ func TestListCalculators_ScanError(t *testing.T) {
 	// Return only one column — scan expects seven, so this triggers a scan error.
 	rows := sqlmock.NewRows([]string{"id"}).AddRow("calc-id")
 	mock.ExpectQuery(`SELECT id, user_id`).
-		WithArgs("user-id").
+		WithArgs("user-id", 51).
 		WillReturnRows(rows)
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	_, err = repo.ListCalculators(context.Background(), "user-id")
+	_, err = repo.ListCalculators(context.Background(), ListQuery{UserID: "user-id", Sort: SortByUpdatedAt, Descending: true, Limit: 51})
 	if err == nil {
 		t.Fatal("expected scan error, got nil")
 	}
@@ -148,15 +151,15 @@ func TestListCalculators_RowsError(t *testing.T) {
 	wantErr := errors.New("rows iteration failure")
 	now := time.Now().UTC().Truncate(time.Second)
 	rows := sqlmock.NewRows(listColumns).
-		AddRow("calc-1", "user-id", "", []byte(`{}`), 1, false, now, now).
+		AddRow("calc-1", "user-id", "", []byte(`{}`), 1, false, now, now, "", "{}", nil, 1, 0, nil).
 		RowError(0, wantErr)
 	mock.ExpectQuery(`SELECT id, user_id`).
-		WithArgs("user-id").
+		WithArgs("user-id", 51).
 		WillReturnRows(rows)
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	_, err = repo.ListCalculators(context.Background(), "user-id")
+	_, err = repo.ListCalculators(context.Background(), ListQuery{UserID: "user-id", Sort: SortByUpdatedAt, Descending: true, Limit: 51})
 	if err == nil {
 		t.Fatal("expected rows error, got nil")
 	}
@@ -178,9 +181,7 @@ func TestCreateCalculator_Success(t *testing.T) {
 	}
 
 	now := time.Now().UTC().Truncate(time.Second)
-	rows := sqlmock.NewRows([]string{
-		"id", "user_id", "name", "config", "config_version", "is_deleted", "created_at", "updated_at",
-	}).AddRow("calc-id", "user-id", "", []byte("{}"), 1, false, now, now)
+	rows := sqlmock.NewRows(listColumns).AddRow("calc-id", "user-id", "", []byte("{}"), 1, false, now, now, "", "{}", nil, 1, 0, nil)
 	mock.ExpectQuery(`INSERT INTO calculators`).
 		WithArgs("user-id").
 		WillReturnRows(rows)
@@ -244,7 +245,7 @@ func TestGetCalculator_Success(t *testing.T) {
 
 	now := time.Now().UTC().Truncate(time.Second)
 	rows := sqlmock.NewRows(listColumns).
-		AddRow("calc-id", "user-id", "", []byte(`{"field":"value"}`), 1, false, now, now)
+		AddRow("calc-id", "user-id", "", []byte(`{"field":"value"}`), 1, false, now, now, "", "{}", nil, 1, 0, nil)
 	mock.ExpectQuery(`SELECT id, user_id`).
 		WithArgs("calc-id").
 		WillReturnRows(rows)
@@ -306,7 +307,7 @@ func TestGetCalculator_Forbidden(t *testing.T) {
 	now := time.Now().UTC().Truncate(time.Second)
 	// Row belongs to "other-user", not "user-id"
 	rows := sqlmock.NewRows(listColumns).
-		AddRow("calc-id", "other-user", "", []byte(`{}`), 1, false, now, now)
+		AddRow("calc-id", "other-user", "", []byte(`{}`), 1, false, now, now, "", "{}", nil, 1, 0, nil)
 	mock.ExpectQuery(`SELECT id, user_id`).
 		WithArgs("calc-id").
 		WillReturnRows(rows)
@@ -365,14 +366,25 @@ func TestUpdateCalculator_Success(t *testing.T) {
 	now := time.Now().UTC().Truncate(time.Second)
 	newConfig := []byte(`{"key":"value"}`)
 	rows := sqlmock.NewRows(listColumns).
-		AddRow("calc-id", "user-id", "", newConfig, 2, false, now, now)
+		AddRow("calc-id", "user-id", "", newConfig, 2, false, now, now, "", "{}", nil, 1, 0, nil)
+	mock.ExpectBegin()
 	mock.ExpectQuery(`UPDATE calculators`).
-		WithArgs("calc-id", newConfig).
+		WithArgs("calc-id", newConfig, 0).
 		WillReturnRows(rows)
+	mock.ExpectExec(`UPDATE calculator_revisions`).
+		WithArgs("calc-id", 2, newConfig, "user-id", RevisionCoalesceWindow.Seconds()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO calculator_revisions`).
+		WithArgs("calc-id", 2, newConfig, "user-id", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM calculator_revisions`).
+		WithArgs("calc-id", MaxRevisionsPerCalculator).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	calc, err := repo.UpdateCalculator(context.Background(), "calc-id", newConfig)
+	calc, err := repo.UpdateCalculator(context.Background(), "calc-id", "user-id", 0, true, newConfig)
 	if err != nil {
 		t.Fatalf("UpdateCalculator() returned unexpected error: %v", err)
 	}
@@ -400,13 +412,15 @@ func TestUpdateCalculator_NotFound(t *testing.T) {
 	}
 
 	rows := sqlmock.NewRows(listColumns)
+	mock.ExpectBegin()
 	mock.ExpectQuery(`UPDATE calculators`).
-		WithArgs("calc-missing", []byte(`{}`)).
+		WithArgs("calc-missing", []byte(`{}`), 0).
 		WillReturnRows(rows)
+	mock.ExpectRollback()
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	_, err = repo.UpdateCalculator(context.Background(), "calc-missing", []byte(`{}`))
+	_, err = repo.UpdateCalculator(context.Background(), "calc-missing", "user-id", 0, false, []byte(`{}`))
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -428,13 +442,15 @@ func TestUpdateCalculator_QueryError(t *testing.T) {
 	}
 
 	wantErr := errors.New("connection reset")
+	mock.ExpectBegin()
 	mock.ExpectQuery(`UPDATE calculators`).
-		WithArgs("calc-id", []byte(`{}`)).
+		WithArgs("calc-id", []byte(`{}`), 0).
 		WillReturnError(wantErr)
+	mock.ExpectRollback()
 	mock.ExpectClose()
 
 	repo := NewPostgresCalculatorRepository(db)
-	_, err = repo.UpdateCalculator(context.Background(), "calc-id", []byte(`{}`))
+	_, err = repo.UpdateCalculator(context.Background(), "calc-id", "user-id", 0, false, []byte(`{}`))
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -534,9 +550,10 @@ func TestGetPublicCalculatorConfig_Success(t *testing.T) {
 	}
 
 	now := time.Now().UTC().Truncate(time.Second)
-	rows := sqlmock.NewRows(listColumns).
-		AddRow("calc-id", "user-id", "", []byte(`{"field":"value"}`), 1, false, now, now)
-	mock.ExpectQuery(`SELECT id, user_id`).
+	rows := sqlmock.NewRows(append(listColumns, "embed_domains")).
+		AddRow("calc-id", "user-id", "", []byte(`{"field":"value"}`), 1, false, now, now, "", "{}", nil, 1, 1, now, "{*.example.com}")
+	// The public endpoint serves the published snapshot, never the draft.
+	mock.ExpectQuery(`SELECT id, user_id, name, published_config, published_version,.*AND published_config IS NOT NULL`).
 		WithArgs("calc-id").
 		WillReturnRows(rows)
 	mock.ExpectClose()
@@ -555,6 +572,9 @@ func TestGetPublicCalculatorConfig_Success(t *testing.T) {
 	if string(calc.Config) != `{"field":"value"}` {
 		t.Errorf("expected Config %q, got %q", `{"field":"value"}`, string(calc.Config))
 	}
+	if len(calc.EmbedDomains) != 1 || calc.EmbedDomains[0] != "*.example.com" {
+		t.Errorf("expected embed domains [*.example.com], got %v", calc.EmbedDomains)
+	}
 	if err := db.Close(); err != nil {
 		t.Fatalf("Close() failed: %v", err)
 	}
@@ -626,9 +646,7 @@ func TestDuplicateCalculator_Success(t *testing.T) {
 	}
 
 	now := time.Now().UTC().Truncate(time.Second)
-	rows := sqlmock.NewRows([]string{
-		"id", "user_id", "name", "config", "config_version", "is_deleted", "created_at", "updated_at",
-	}).AddRow("calc-new", "user-id", "", []byte("{}"), 1, false, now, now)
+	rows := sqlmock.NewRows(listColumns).AddRow("calc-new", "user-id", "", []byte("{}"), 1, false, now, now, "", "{}", nil, 1, 0, nil)
 	mock.ExpectQuery(`INSERT INTO calculators`).
 		WithArgs("calc-id").
 		WillReturnRows(rows)
@@ -662,9 +680,7 @@ func TestDuplicateCalculator_NotFound(t *testing.T) {
 		t.Fatalf("sqlmock.New() failed: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{
-		"id", "user_id", "name", "config", "config_version", "is_deleted", "created_at", "updated_at",
-	})
+	rows := sqlmock.NewRows(listColumns)
 	mock.ExpectQuery(`INSERT INTO calculators`).
 		WithArgs("calc-missing").
 		WillReturnRows(rows)
@@ -741,3 +757,99 @@ func TestDeleteCalculator_RowsAffectedError(t *testing.T) {
 		t.Errorf("unmet expectations: %v", err)
 	}
 }
+
+func TestUpdateCalculator_ExpectedVersionMatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", []byte(`{}`), 4).
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow("calc-id", "user-id", "", []byte(`{}`), 5, false, now, now, "", "{}", nil, 1, 0, nil))
+	mock.ExpectExec(`INSERT INTO calculator_revisions`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM calculator_revisions`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.UpdateCalculator(context.Background(), "calc-id", "user-id", 4, false, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("UpdateCalculator() returned unexpected error: %v", err)
+	}
+	if calc.ConfigVersion != 5 {
+		t.Errorf("expected ConfigVersion 5, got %d", calc.ConfigVersion)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateCalculator_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", []byte(`{}`), 3).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectQuery(`SELECT config_version FROM calculators`).
+		WithArgs("calc-id").
+		WillReturnRows(sqlmock.NewRows([]string{"config_version"}).AddRow(7))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.UpdateCalculator(context.Background(), "calc-id", "user-id", 3, false, []byte(`{}`))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got: %v", err)
+	}
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.CurrentVersion != 7 {
+		t.Errorf("expected current version 7, got %+v", conflict)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateCalculator_ConditionalNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-missing", []byte(`{}`), 3).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectQuery(`SELECT config_version FROM calculators`).
+		WithArgs("calc-missing").
+		WillReturnRows(sqlmock.NewRows([]string{"config_version"}))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.UpdateCalculator(context.Background(), "calc-missing", "user-id", 3, false, []byte(`{}`))
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
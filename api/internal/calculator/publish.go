@@ -0,0 +1,98 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/jsonpatch"
+)
+
+// Publisher copies a calculator's draft config to its published snapshot.
+// When expectedVersion is non-zero the publish only applies if it matches the
+// current config_version, so a client publishes exactly the draft it saw.
+type Publisher interface {
+	PublishCalculator(ctx context.Context, id string, expectedVersion int) (*Calculator, error)
+}
+
+// ConfigDiff describes how a calculator's draft differs from what is published.
+type ConfigDiff struct {
+	DraftVersion     int
+	PublishedVersion int // 0 if never published
+	// Operations is the RFC 6902 JSON Patch that turns the published config
+	// into the draft. It is empty when there are no unpublished changes.
+	Operations []jsonpatch.Operation
+}
+
+// WithPublisher configures publishing support on the Service and returns the
+// same Service pointer for chained calls.
+func (s *Service) WithPublisher(publisher Publisher) *Service {
+	s.publisher = publisher
+	return s
+}
+
+// Publish verifies ownership of the calculator, checks that its current draft
+// is complete enough for end users, then makes it the version served to
+// widgets. Drafts may hold the blanks of an edit in progress, such as a
+// dropdown with no options yet; publishing one is refused.
+// When expectedVersion is non-zero the publish is conditional on it matching
+// the calculator's current config_version. When it is zero the publish is
+// conditional on the draft that was checked, so a concurrent edit makes it
+// fail rather than publish an unchecked draft.
+// Returns a *configschema.ValidationError if the draft is incomplete.
+// Returns a *VersionConflictError (matching ErrVersionConflict) if expectedVersion is stale.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) Publish(ctx context.Context, id, userID string, expectedVersion int) (*Calculator, error) {
+	draft, err := s.getter.GetCalculator(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	if err := upgradeConfig(draft); err != nil {
+		return nil, err
+	}
+	// A stale expectedVersion names a draft other than this one; the
+	// publisher reports the conflict.
+	if expectedVersion == 0 || expectedVersion == draft.ConfigVersion {
+		if err := configschema.ValidatePublishable(draft.Config); err != nil {
+			return nil, fmt.Errorf("validating draft config: %w", err)
+		}
+		expectedVersion = draft.ConfigVersion
+	}
+	calc, err := s.publisher.PublishCalculator(ctx, id, expectedVersion)
+	if err != nil {
+		return nil, fmt.Errorf("publishing calculator: %w", err)
+	}
+	s.purgeCache(ctx, id)
+	return calc, nil
+}
+
+// Diff returns the changes in the calculator's draft config that have not yet
+// been published. A calculator that has never been published is diffed
+// against an empty config.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) Diff(ctx context.Context, id, userID string) (*ConfigDiff, error) {
+	draft, err := s.getter.GetCalculator(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting calculator: %w", err)
+	}
+	published := []byte(`{}`)
+	pub, err := s.publicConfigGetter.GetPublicCalculatorConfig(ctx, id)
+	switch {
+	case err == nil:
+		published = pub.Config
+	case !errors.Is(err, ErrNotFound):
+		return nil, fmt.Errorf("getting published config: %w", err)
+	}
+	ops, err := jsonpatch.Diff(published, draft.Config)
+	if err != nil {
+		return nil, fmt.Errorf("diffing configs: %w", err)
+	}
+	return &ConfigDiff{
+		DraftVersion:     draft.ConfigVersion,
+		PublishedVersion: draft.PublishedVersion,
+		Operations:       ops,
+	}, nil
+}
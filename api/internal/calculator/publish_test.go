@@ -0,0 +1,226 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+type stubPublisher struct {
+	calc        *Calculator
+	err         error
+	gotExpected int
+	called      bool
+}
+
+func (s *stubPublisher) PublishCalculator(_ context.Context, _ string, expectedVersion int) (*Calculator, error) {
+	s.called = true
+	s.gotExpected = expectedVersion
+	return s.calc, s.err
+}
+
+func newPublishService(getter *stubGetter, published *stubPublicConfigGetter, publisher *stubPublisher) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, published, &stubDuplicator{}).
+		WithPublisher(publisher)
+}
+
+func TestPublish_Success(t *testing.T) {
+	publisher := &stubPublisher{calc: &Calculator{ID: "calc-abc", ConfigVersion: 4, PublishedVersion: 4}}
+	svc := newPublishService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 4}}, &stubPublicConfigGetter{}, publisher)
+
+	calc, err := svc.Publish(context.Background(), "calc-abc", "user-xyz", 4)
+	if err != nil {
+		t.Fatalf("Publish() returned unexpected error: %v", err)
+	}
+	if calc.PublishedVersion != 4 || publisher.gotExpected != 4 {
+		t.Errorf("expected version 4 published, got %d (expected %d)", calc.PublishedVersion, publisher.gotExpected)
+	}
+}
+
+func TestPublish_IncompleteDraftRefused(t *testing.T) {
+	publisher := &stubPublisher{}
+	draft := `{"fields": [{"id": "f1", "type": "dropdown", "label": "Finish", "required": false, "variableName": "finish", "options": []}]}`
+	svc := newPublishService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(draft), ConfigVersion: 3}}, &stubPublicConfigGetter{}, publisher)
+
+	_, err := svc.Publish(context.Background(), "calc-abc", "user-xyz", 0)
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) || verr.Errors[0].Path != "fields[0].options" {
+		t.Fatalf("expected a validation error on fields[0].options, got: %v", err)
+	}
+	if publisher.called {
+		t.Error("expected an incomplete draft not to be published")
+	}
+}
+
+func TestPublish_UnconditionalPinsCheckedDraft(t *testing.T) {
+	publisher := &stubPublisher{calc: &Calculator{ID: "calc-abc"}}
+	svc := newPublishService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 7}}, &stubPublicConfigGetter{}, publisher)
+
+	if _, err := svc.Publish(context.Background(), "calc-abc", "user-xyz", 0); err != nil {
+		t.Fatalf("Publish() returned unexpected error: %v", err)
+	}
+	if publisher.gotExpected != 7 {
+		t.Errorf("expected the publish to be conditional on the checked draft, got expected version %d", publisher.gotExpected)
+	}
+}
+
+func TestPublish_StaleVersionLeftToPublisher(t *testing.T) {
+	conflict := &VersionConflictError{CurrentVersion: 5}
+	publisher := &stubPublisher{err: conflict}
+	// The draft is incomplete, but the client asked for an older version.
+	draft := `{"outputs": [{"id": "r1", "label": "", "expression": ""}]}`
+	svc := newPublishService(&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(draft), ConfigVersion: 5}}, &stubPublicConfigGetter{}, publisher)
+
+	if _, err := svc.Publish(context.Background(), "calc-abc", "user-xyz", 4); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got: %v", err)
+	}
+}
+
+func TestPublish_OwnershipChecked(t *testing.T) {
+	publisher := &stubPublisher{}
+	svc := newPublishService(&stubGetter{err: ErrForbidden}, &stubPublicConfigGetter{}, publisher)
+
+	if _, err := svc.Publish(context.Background(), "calc-abc", "user-xyz", 0); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got: %v", err)
+	}
+	if publisher.called {
+		t.Error("expected publisher not to be called")
+	}
+}
+
+func TestDiff_AgainstPublished(t *testing.T) {
+	svc := newPublishService(
+		&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{"title":"New"}`), ConfigVersion: 6, PublishedVersion: 5}},
+		&stubPublicConfigGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{"title":"Old"}`), ConfigVersion: 5}},
+		&stubPublisher{},
+	)
+
+	diff, err := svc.Diff(context.Background(), "calc-abc", "user-xyz")
+	if err != nil {
+		t.Fatalf("Diff() returned unexpected error: %v", err)
+	}
+	if diff.DraftVersion != 6 || diff.PublishedVersion != 5 {
+		t.Errorf("expected draft 6 and published 5, got %d and %d", diff.DraftVersion, diff.PublishedVersion)
+	}
+	if len(diff.Operations) != 1 || diff.Operations[0].Op != "replace" || *diff.Operations[0].Path != "/title" {
+		t.Errorf("expected one replace of /title, got %+v", diff.Operations)
+	}
+}
+
+func TestDiff_NeverPublished(t *testing.T) {
+	svc := newPublishService(
+		&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{"title":"New"}`), ConfigVersion: 1}},
+		&stubPublicConfigGetter{err: ErrNotFound},
+		&stubPublisher{},
+	)
+
+	diff, err := svc.Diff(context.Background(), "calc-abc", "user-xyz")
+	if err != nil {
+		t.Fatalf("Diff() returned unexpected error: %v", err)
+	}
+	if diff.PublishedVersion != 0 || len(diff.Operations) != 1 || diff.Operations[0].Op != "add" {
+		t.Errorf("expected a single add against an empty config, got %+v", diff)
+	}
+}
+
+func TestDiff_PublishedLookupError(t *testing.T) {
+	svc := newPublishService(
+		&stubGetter{calc: &Calculator{ID: "calc-abc", Config: []byte(`{}`)}},
+		&stubPublicConfigGetter{err: errors.New("db failure")},
+		&stubPublisher{},
+	)
+	if _, err := svc.Diff(context.Background(), "calc-abc", "user-xyz"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestPostgresPublishCalculator_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators\s+SET published_config = config, published_version = config_version, published_at = NOW\(\)`).
+		WithArgs("calc-id", 3).
+		WillReturnRows(sqlmock.NewRows(listColumns).
+			AddRow("calc-id", "user-id", "", []byte(`{}`), 3, false, now, now, "", "{}", nil, 1, 3, now))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.PublishCalculator(context.Background(), "calc-id", 3)
+	if err != nil {
+		t.Fatalf("PublishCalculator() returned unexpected error: %v", err)
+	}
+	if calc.PublishedVersion != 3 || calc.PublishedAt == nil || !calc.PublishedAt.Equal(now) {
+		t.Errorf("expected published version 3 at %v, got %d at %v", now, calc.PublishedVersion, calc.PublishedAt)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresPublishCalculator_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", 3).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectQuery(`SELECT config_version FROM calculators`).
+		WithArgs("calc-id").
+		WillReturnRows(sqlmock.NewRows([]string{"config_version"}).AddRow(4))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.PublishCalculator(context.Background(), "calc-id", 3)
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.CurrentVersion != 4 {
+		t.Errorf("expected *VersionConflictError with version 4, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresPublishCalculator_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", 0).
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.PublishCalculator(context.Background(), "calc-id", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
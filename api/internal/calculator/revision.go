@@ -0,0 +1,134 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxRevisionsPerCalculator is the retention limit for config history. Each
+// config write prunes the calculator's oldest revisions beyond this count.
+const MaxRevisionsPerCalculator = 100
+
+// RevisionCoalesceWindow bounds how often one author's edits become separate
+// revisions. The dashboard autosaves every change, so an autosave by the author
+// of the latest revision within this long of it replaces that revision rather
+// than adding one; otherwise an editing session would push a calculator's
+// whole history past MaxRevisionsPerCalculator in minutes. The window is
+// measured from the revision's creation, so a long session still leaves one
+// revision per window. Other writes, such as patches and bulk edits, always
+// add a revision so the version they replaced can be restored.
+const RevisionCoalesceWindow = 10 * time.Minute
+
+// ErrRevisionNotFound is returned when the requested revision does not exist,
+// either because it was never written or because it has been pruned.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is a snapshot of a calculator's config as of a given config_version.
+type Revision struct {
+	ID            string
+	CalculatorID  string
+	ConfigVersion int
+	Config        []byte // nil in list results
+	// AuthorID is the user who wrote this revision. Empty when the author's
+	// account has since been deleted.
+	AuthorID string
+	// RestoredFromVersion is set when this revision was created by restoring
+	// an earlier one.
+	RestoredFromVersion *int
+	CreatedAt           time.Time
+}
+
+// RevisionLister lists the retained revisions of a calculator.
+type RevisionLister interface {
+	ListRevisions(ctx context.Context, calculatorID string) ([]*Revision, error)
+}
+
+// RevisionGetter fetches a single revision by config_version.
+type RevisionGetter interface {
+	GetRevision(ctx context.Context, calculatorID string, version int) (*Revision, error)
+}
+
+// RevisionRestorer writes config, taken from revision version, as the
+// calculator's head. A non-zero expectedVersion makes the write conditional
+// on the current config_version.
+type RevisionRestorer interface {
+	RestoreRevision(ctx context.Context, calculatorID string, version, expectedVersion int, config []byte, authorID string) (*Calculator, error)
+}
+
+// WithRevisions configures config history support on the Service.
+// It sets the RevisionLister, RevisionGetter, and RevisionRestorer dependencies
+// and returns the same Service pointer for chained calls.
+func (s *Service) WithRevisions(lister RevisionLister, getter RevisionGetter, restorer RevisionRestorer) *Service {
+	s.revisionLister = lister
+	s.revisionGetter = getter
+	s.revisionRestorer = restorer
+	return s
+}
+
+// ListRevisions verifies ownership of the calculator then returns its retained
+// revisions, newest first. Configs are omitted from the results.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) ListRevisions(ctx context.Context, id, userID string) ([]*Revision, error) {
+	if _, err := s.getter.GetCalculator(ctx, id, userID); err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	revs, err := s.revisionLister.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing revisions: %w", err)
+	}
+	return revs, nil
+}
+
+// GetRevision verifies ownership of the calculator then returns the revision
+// with the given config_version, including its config.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+// Returns ErrRevisionNotFound if no such revision is retained.
+func (s *Service) GetRevision(ctx context.Context, id, userID string, version int) (*Revision, error) {
+	if _, err := s.getter.GetCalculator(ctx, id, userID); err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	rev, err := s.revisionGetter.GetRevision(ctx, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("getting revision: %w", err)
+	}
+	return rev, nil
+}
+
+// RestoreRevision verifies ownership of the calculator then writes the config
+// of the given revision as a new head. History is never rewritten: the restore
+// itself becomes a new revision with the next config_version. The revision is
+// upgraded and validated like any other write, since it may predate the
+// current schema; one stored without a schema version is version 1. The write
+// is conditional on expectedVersion, or when that is zero on the head the
+// ownership check saw, so a concurrent save is never silently overwritten.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+// Returns ErrRevisionNotFound if no such revision is retained.
+// Returns a *configschema.ValidationError if the revision is no longer a valid config.
+// Returns a *VersionConflictError if the head changed.
+func (s *Service) RestoreRevision(ctx context.Context, id, userID string, version, expectedVersion int) (*Calculator, error) {
+	head, err := s.getter.GetCalculator(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	rev, err := s.revisionGetter.GetRevision(ctx, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("getting revision: %w", err)
+	}
+	config, err := upgradeStoredForWrite(rev.Config)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion == 0 {
+		expectedVersion = head.ConfigVersion
+	}
+	calc, err := s.revisionRestorer.RestoreRevision(ctx, id, version, expectedVersion, config, userID)
+	if err != nil {
+		return nil, fmt.Errorf("restoring revision: %w", err)
+	}
+	return calc, nil
+}
@@ -0,0 +1,362 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+type stubRevisionStore struct {
+	revs     []*Revision
+	rev      *Revision
+	calc     *Calculator
+	err      error
+	authorID string
+	version  int
+	// gotExpected and gotConfig record the arguments to RestoreRevision.
+	gotExpected int
+	gotConfig   []byte
+	restored    bool
+}
+
+func (s *stubRevisionStore) ListRevisions(_ context.Context, _ string) ([]*Revision, error) {
+	return s.revs, s.err
+}
+
+func (s *stubRevisionStore) GetRevision(_ context.Context, _ string, version int) (*Revision, error) {
+	s.version = version
+	return s.rev, s.err
+}
+
+func (s *stubRevisionStore) RestoreRevision(_ context.Context, _ string, version, expectedVersion int, config []byte, authorID string) (*Calculator, error) {
+	s.restored = true
+	s.version = version
+	s.gotExpected = expectedVersion
+	s.gotConfig = config
+	s.authorID = authorID
+	return s.calc, s.err
+}
+
+func newRevisionService(getter *stubGetter, store *stubRevisionStore) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithRevisions(store, store, store)
+}
+
+func TestListRevisions_Success(t *testing.T) {
+	store := &stubRevisionStore{revs: []*Revision{{ConfigVersion: 3}, {ConfigVersion: 2}}}
+	svc := newRevisionService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, store)
+
+	revs, err := svc.ListRevisions(context.Background(), "calc-abc", "user-xyz")
+	if err != nil {
+		t.Fatalf("ListRevisions() returned unexpected error: %v", err)
+	}
+	if len(revs) != 2 || revs[0].ConfigVersion != 3 {
+		t.Errorf("unexpected revisions: %+v", revs)
+	}
+}
+
+func TestListRevisions_Forbidden(t *testing.T) {
+	svc := newRevisionService(&stubGetter{err: ErrForbidden}, &stubRevisionStore{})
+
+	_, err := svc.ListRevisions(context.Background(), "calc-abc", "user-other")
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected wrapped ErrForbidden, got: %v", err)
+	}
+}
+
+func TestGetRevision_Success(t *testing.T) {
+	store := &stubRevisionStore{rev: &Revision{ConfigVersion: 2, Config: []byte(`{}`)}}
+	svc := newRevisionService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, store)
+
+	rev, err := svc.GetRevision(context.Background(), "calc-abc", "user-xyz", 2)
+	if err != nil {
+		t.Fatalf("GetRevision() returned unexpected error: %v", err)
+	}
+	if rev.ConfigVersion != 2 || store.version != 2 {
+		t.Errorf("expected revision 2, got %+v (requested %d)", rev, store.version)
+	}
+}
+
+func TestGetRevision_NotFound(t *testing.T) {
+	svc := newRevisionService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, &stubRevisionStore{err: ErrRevisionNotFound})
+
+	_, err := svc.GetRevision(context.Background(), "calc-abc", "user-xyz", 99)
+	if !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected wrapped ErrRevisionNotFound, got: %v", err)
+	}
+}
+
+func TestRestoreRevision_Success(t *testing.T) {
+	store := &stubRevisionStore{
+		rev:  &Revision{ConfigVersion: 2, Config: []byte(`{"fields":[]}`)},
+		calc: &Calculator{ID: "calc-abc", ConfigVersion: 5},
+	}
+	svc := newRevisionService(&stubGetter{calc: &Calculator{ID: "calc-abc", ConfigVersion: 4}}, store)
+
+	calc, err := svc.RestoreRevision(context.Background(), "calc-abc", "user-xyz", 2, 0)
+	if err != nil {
+		t.Fatalf("RestoreRevision() returned unexpected error: %v", err)
+	}
+	if calc.ConfigVersion != 5 {
+		t.Errorf("expected ConfigVersion 5, got %d", calc.ConfigVersion)
+	}
+	if store.version != 2 || store.authorID != "user-xyz" {
+		t.Errorf("expected restore of version 2 by user-xyz, got version %d by %q", store.version, store.authorID)
+	}
+	if string(store.gotConfig) != `{"fields":[],"schemaVersion":1}` {
+		t.Errorf("expected the revision's config to be written, got %s", store.gotConfig)
+	}
+	// Without If-Match the write is conditional on the head that was checked.
+	if store.gotExpected != 4 {
+		t.Errorf("expected the restore to be conditional on version 4, got %d", store.gotExpected)
+	}
+}
+
+func TestRestoreRevision_ExpectedVersionPassedThrough(t *testing.T) {
+	store := &stubRevisionStore{rev: &Revision{ConfigVersion: 2, Config: []byte(`{}`)}, calc: &Calculator{ID: "calc-abc"}}
+	svc := newRevisionService(&stubGetter{calc: &Calculator{ID: "calc-abc", ConfigVersion: 4}}, store)
+
+	if _, err := svc.RestoreRevision(context.Background(), "calc-abc", "user-xyz", 2, 3); err != nil {
+		t.Fatalf("RestoreRevision() returned unexpected error: %v", err)
+	}
+	if store.gotExpected != 3 {
+		t.Errorf("expected If-Match version 3 passed through, got %d", store.gotExpected)
+	}
+}
+
+func TestRestoreRevision_InvalidRevisionRefused(t *testing.T) {
+	store := &stubRevisionStore{rev: &Revision{ConfigVersion: 2, Config: []byte(`{"fields":[{"id":"f1","type":"slider"}]}`)}}
+	svc := newRevisionService(&stubGetter{calc: &Calculator{ID: "calc-abc", ConfigVersion: 4}}, store)
+
+	_, err := svc.RestoreRevision(context.Background(), "calc-abc", "user-xyz", 2, 0)
+	var verr *configschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *configschema.ValidationError, got: %v", err)
+	}
+	if store.restored {
+		t.Error("expected an invalid revision not to be written")
+	}
+}
+
+func TestRestoreRevision_RevisionNotFound(t *testing.T) {
+	store := &stubRevisionStore{err: ErrRevisionNotFound}
+	svc := newRevisionService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, store)
+
+	_, err := svc.RestoreRevision(context.Background(), "calc-abc", "user-xyz", 9, 0)
+	if !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected wrapped ErrRevisionNotFound, got: %v", err)
+	}
+	if store.restored {
+		t.Error("expected restore not to be attempted")
+	}
+}
+
+func TestRestoreRevision_OwnershipError(t *testing.T) {
+	store := &stubRevisionStore{}
+	svc := newRevisionService(&stubGetter{err: ErrNotFound}, store)
+
+	_, err := svc.RestoreRevision(context.Background(), "calc-missing", "user-xyz", 2, 0)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected wrapped ErrNotFound, got: %v", err)
+	}
+	if store.restored {
+		t.Error("expected restore not to be attempted")
+	}
+}
+
+var revisionColumns = []string{"id", "calculator_id", "config_version", "author_id", "restored_from_version", "created_at"}
+
+func TestPostgresListRevisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rows := sqlmock.NewRows(revisionColumns).
+		AddRow("rev-3", "calc-id", 3, "user-id", 1, now).
+		AddRow("rev-2", "calc-id", 2, nil, nil, now)
+	mock.ExpectQuery(`SELECT id, calculator_id, config_version`).
+		WithArgs("calc-id").
+		WillReturnRows(rows)
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	revs, err := repo.ListRevisions(context.Background(), "calc-id")
+	if err != nil {
+		t.Fatalf("ListRevisions() returned unexpected error: %v", err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(revs))
+	}
+	if revs[0].AuthorID != "user-id" || revs[0].RestoredFromVersion == nil || *revs[0].RestoredFromVersion != 1 {
+		t.Errorf("unexpected first revision: %+v", revs[0])
+	}
+	if revs[1].AuthorID != "" || revs[1].RestoredFromVersion != nil {
+		t.Errorf("expected NULL columns to map to zero values, got %+v", revs[1])
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresGetRevision_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT id, calculator_id, config_version, config`).
+		WithArgs("calc-id", 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.GetRevision(context.Background(), "calc-id", 7)
+	if !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresGetRevision_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rows := sqlmock.NewRows([]string{"id", "calculator_id", "config_version", "config", "author_id", "restored_from_version", "created_at"}).
+		AddRow("rev-2", "calc-id", 2, []byte(`{"fields":[]}`), "user-id", nil, now)
+	mock.ExpectQuery(`SELECT id, calculator_id, config_version, config`).
+		WithArgs("calc-id", 2).
+		WillReturnRows(rows)
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	rev, err := repo.GetRevision(context.Background(), "calc-id", 2)
+	if err != nil {
+		t.Fatalf("GetRevision() returned unexpected error: %v", err)
+	}
+	if string(rev.Config) != `{"fields":[]}` {
+		t.Errorf("unexpected config %q", rev.Config)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresRestoreRevision_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	oldConfig := []byte(`{"fields":[]}`)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", oldConfig, 4).
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow("calc-id", "user-id", "", oldConfig, 5, false, now, now, "", "{}", nil, 1, 0, nil))
+	mock.ExpectExec(`INSERT INTO calculator_revisions`).
+		WithArgs("calc-id", 5, oldConfig, "user-id", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM calculator_revisions`).
+		WithArgs("calc-id", MaxRevisionsPerCalculator).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.RestoreRevision(context.Background(), "calc-id", 2, 4, oldConfig, "user-id")
+	if err != nil {
+		t.Fatalf("RestoreRevision() returned unexpected error: %v", err)
+	}
+	if calc.ConfigVersion != 5 {
+		t.Errorf("expected ConfigVersion 5, got %d", calc.ConfigVersion)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresUpdateCalculator_CoalescesAutosave(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	config := []byte(`{"title":"Quote"}`)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", config, 0).
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow("calc-id", "user-id", "", config, 6, false, now, now, "", "{}", nil, 1, 0, nil))
+	// The latest revision is the same author's, unpublished, and recent, so
+	// it is replaced and nothing is inserted or pruned.
+	mock.ExpectExec(`UPDATE calculator_revisions .+author_id = \$4 .+restored_from_version IS NULL .+<> c.published_version .+make_interval`).
+		WithArgs("calc-id", 6, config, "user-id", RevisionCoalesceWindow.Seconds()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.UpdateCalculator(context.Background(), "calc-id", "user-id", 0, true, config); err != nil {
+		t.Fatalf("UpdateCalculator() returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresUpdateCalculator_RevisionInsertFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	wantErr := errors.New("disk full")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE calculators`).
+		WithArgs("calc-id", []byte(`{}`), 0).
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow("calc-id", "user-id", "", []byte(`{}`), 2, false, now, now, "", "{}", nil, 1, 0, nil))
+	mock.ExpectExec(`INSERT INTO calculator_revisions`).
+		WillReturnError(wantErr)
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, err = repo.UpdateCalculator(context.Background(), "calc-id", "user-id", 0, false, []byte(`{}`))
+	if !errors.Is(err, wantErr) {
+		t.Errorf("expected wrapped wantErr, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package calculator
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// ErrTemplateNotFound is returned when no template has the requested slug.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// Template is a built-in calculator that users can copy into their account as
+// a starting point. Templates are identified by a URL-friendly slug.
+type Template struct {
+	Slug        string
+	Name        string
+	Category    string
+	Description string
+	Config      []byte // nil in list results
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TemplateStore reads the template library.
+type TemplateStore interface {
+	// ListTemplates returns every template, or only those in category when it
+	// is non-empty, ordered by category then name. Configs are not loaded.
+	ListTemplates(ctx context.Context, category string) ([]*Template, error)
+	GetTemplate(ctx context.Context, slug string) (*Template, error)
+}
+
+// TemplateInstantiator creates a calculator owned by userID from a template's
+// name, description, and config.
+type TemplateInstantiator interface {
+	InstantiateTemplate(ctx context.Context, slug, userID string) (*Calculator, error)
+}
+
+// TemplateReplacer replaces the stored template library with templates,
+// removing any stored template whose slug is not among them.
+type TemplateReplacer interface {
+	ReplaceTemplates(ctx context.Context, templates []*Template) error
+}
+
+// WithTemplates configures template support on the Service and returns the
+// same Service pointer for chained calls.
+func (s *Service) WithTemplates(store TemplateStore, instantiator TemplateInstantiator) *Service {
+	s.templates = store
+	s.templateInstantiator = instantiator
+	return s
+}
+
+// ListTemplates returns the template library, optionally filtered to one
+// category. Configs are not loaded; use GetTemplate to preview one.
+func (s *Service) ListTemplates(ctx context.Context, category string) ([]*Template, error) {
+	templates, err := s.templates.ListTemplates(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("listing templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplate returns the template with the given slug, including its config.
+// Returns ErrTemplateNotFound if there is no such template.
+func (s *Service) GetTemplate(ctx context.Context, slug string) (*Template, error) {
+	t, err := s.templates.GetTemplate(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("getting template: %w", err)
+	}
+	return t, nil
+}
+
+// InstantiateTemplate copies the template with the given slug into userID's
+// account as a new, unpublished calculator with config_version 1. The template
+// itself is unchanged.
+// Returns ErrTemplateNotFound if there is no such template.
+func (s *Service) InstantiateTemplate(ctx context.Context, slug, userID string) (*Calculator, error) {
+	calc, err := s.templateInstantiator.InstantiateTemplate(ctx, slug, userID)
+	if err != nil {
+		return nil, fmt.Errorf("instantiating template: %w", err)
+	}
+	return calc, nil
+}
+
+// builtinTemplates holds the template library shipped with the API, one JSON
+// file per template named after its slug.
+//
+//go:embed templates/*.json
+var builtinTemplates embed.FS
+
+// templateFixture is the on-disk shape of a built-in template.
+type templateFixture struct {
+	Slug        string          `json:"slug"`
+	Name        string          `json:"name"`
+	Category    string          `json:"category"`
+	Description string          `json:"description"`
+	Config      json.RawMessage `json:"config"`
+}
+
+// BuiltinTemplates decodes the embedded template library. Every template's
+// config must record its schema version and pass configschema.Validate, and
+// its slug must match its file name.
+func BuiltinTemplates() ([]*Template, error) {
+	entries, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded templates: %w", err)
+	}
+	templates := make([]*Template, 0, len(entries))
+	for _, entry := range entries {
+		name := path.Join("templates", entry.Name())
+		raw, err := builtinTemplates.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		t, err := parseTemplateFixture(name, raw)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// parseTemplateFixture decodes and checks the template fixture raw, read from
+// the file at name.
+func parseTemplateFixture(name string, raw []byte) (*Template, error) {
+	var f templateFixture
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	if f.Slug != strings.TrimSuffix(path.Base(name), ".json") {
+		return nil, fmt.Errorf("%s: slug %q does not match file name", name, f.Slug)
+	}
+	if f.Name == "" || f.Category == "" {
+		return nil, fmt.Errorf("%s: name and category are required", name)
+	}
+	// Without the key a template would be read as version 1 forever, and
+	// silently upgraded as if it were that old once the schema moves on.
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(f.Config, &config); err != nil {
+		return nil, fmt.Errorf("decoding %s config: %w", name, err)
+	}
+	if _, ok := config[configschema.VersionKey]; !ok {
+		return nil, fmt.Errorf("%s: config must record its %s", name, configschema.VersionKey)
+	}
+	if err := configschema.Validate(f.Config); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &Template{
+		Slug:        f.Slug,
+		Name:        f.Name,
+		Category:    f.Category,
+		Description: f.Description,
+		Config:      f.Config,
+	}, nil
+}
+
+// SyncTemplates replaces the stored template library with the built-in
+// templates. It runs at startup, so the table always matches the deployed
+// binary. It returns the number of templates stored.
+func SyncTemplates(ctx context.Context, store TemplateReplacer) (int, error) {
+	templates, err := BuiltinTemplates()
+	if err != nil {
+		return 0, err
+	}
+	if err := store.ReplaceTemplates(ctx, templates); err != nil {
+		return 0, fmt.Errorf("storing templates: %w", err)
+	}
+	return len(templates), nil
+}
@@ -0,0 +1,234 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/formula"
+)
+
+type stubTemplateStore struct {
+	template    *Template
+	calc        *Calculator
+	err         error
+	gotCategory string
+	gotUserID   string
+	replaced    []*Template
+}
+
+func (s *stubTemplateStore) ListTemplates(_ context.Context, category string) ([]*Template, error) {
+	s.gotCategory = category
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []*Template{s.template}, nil
+}
+
+func (s *stubTemplateStore) GetTemplate(_ context.Context, _ string) (*Template, error) {
+	return s.template, s.err
+}
+
+func (s *stubTemplateStore) InstantiateTemplate(_ context.Context, _, userID string) (*Calculator, error) {
+	s.gotUserID = userID
+	return s.calc, s.err
+}
+
+func (s *stubTemplateStore) ReplaceTemplates(_ context.Context, templates []*Template) error {
+	s.replaced = templates
+	return s.err
+}
+
+func newTemplateService(store *stubTemplateStore) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, &stubGetter{}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithTemplates(store, store)
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	templates, err := BuiltinTemplates()
+	if err != nil {
+		t.Fatalf("BuiltinTemplates() returned unexpected error: %v", err)
+	}
+	// Requirement 1.10.1: at least 10 templates ship with the product.
+	if len(templates) < 10 {
+		t.Errorf("expected at least 10 built-in templates, got %d", len(templates))
+	}
+	for _, tmpl := range templates {
+		cfg, err := configschema.Parse(tmpl.Config)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.Slug, err)
+		}
+		if len(cfg.Fields) == 0 || len(cfg.Outputs) == 0 {
+			t.Errorf("%s: expected fields and outputs", tmpl.Slug)
+		}
+		// Every output must evaluate with the default inputs, so a freshly
+		// instantiated calculator shows a result straight away.
+		vars := FieldDefaults(cfg.Fields)
+		for _, out := range cfg.Outputs {
+			if _, err := formula.Evaluate(out.Expression, vars); err != nil {
+				t.Errorf("%s: output %s: %v", tmpl.Slug, out.ID, err)
+			}
+		}
+	}
+}
+
+func TestParseTemplateFixture_RequiresSchemaVersion(t *testing.T) {
+	raw := []byte(`{"slug":"quote","name":"Quote","category":"general","config":{"fields":[]}}`)
+
+	_, err := parseTemplateFixture("templates/quote.json", raw)
+	if err == nil || !strings.Contains(err.Error(), configschema.VersionKey) {
+		t.Fatalf("expected an error naming %s, got: %v", configschema.VersionKey, err)
+	}
+
+	raw = []byte(`{"slug":"quote","name":"Quote","category":"general","config":{"schemaVersion":1,"fields":[]}}`)
+	if _, err := parseTemplateFixture("templates/quote.json", raw); err != nil {
+		t.Errorf("parseTemplateFixture() returned unexpected error: %v", err)
+	}
+}
+
+func TestSyncTemplates(t *testing.T) {
+	store := &stubTemplateStore{}
+	n, err := SyncTemplates(context.Background(), store)
+	if err != nil {
+		t.Fatalf("SyncTemplates() returned unexpected error: %v", err)
+	}
+	if n != len(store.replaced) || n == 0 {
+		t.Errorf("expected %d templates stored, got %d", n, len(store.replaced))
+	}
+}
+
+func TestListTemplates_PassesCategory(t *testing.T) {
+	store := &stubTemplateStore{template: &Template{Slug: "moving-cost-calculator"}}
+	svc := newTemplateService(store)
+
+	templates, err := svc.ListTemplates(context.Background(), "home-services")
+	if err != nil {
+		t.Fatalf("ListTemplates() returned unexpected error: %v", err)
+	}
+	if len(templates) != 1 || store.gotCategory != "home-services" {
+		t.Errorf("expected one template for category home-services, got %d for %q", len(templates), store.gotCategory)
+	}
+}
+
+func TestInstantiateTemplate_NotFound(t *testing.T) {
+	svc := newTemplateService(&stubTemplateStore{err: ErrTemplateNotFound})
+
+	if _, err := svc.InstantiateTemplate(context.Background(), "missing", "user-xyz"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got: %v", err)
+	}
+}
+
+func TestPostgresGetTemplate_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectQuery(`FROM calculator_templates`).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "name", "category", "description", "config", "created_at", "updated_at"}))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.GetTemplate(context.Background(), "missing"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresInstantiateTemplate_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rows := sqlmock.NewRows(listColumns).AddRow("calc-new", "user-id", "Moving Cost Calculator", []byte(`{"fields":[]}`), 1, false, now, now, "Estimate a move", "{}", nil, 1, 0, nil)
+	mock.ExpectQuery(`INSERT INTO calculators \(user_id, name, description, config, config_version\)\s+SELECT \$2, name, description, config, 1\s+FROM calculator_templates`).
+		WithArgs("moving-cost-calculator", "user-id").
+		WillReturnRows(rows)
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.InstantiateTemplate(context.Background(), "moving-cost-calculator", "user-id")
+	if err != nil {
+		t.Fatalf("InstantiateTemplate() returned unexpected error: %v", err)
+	}
+	if calc.UserID != "user-id" || calc.Name != "Moving Cost Calculator" || calc.PublishedVersion != 0 {
+		t.Errorf("unexpected calculator: %+v", calc)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresInstantiateTemplate_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectQuery(`INSERT INTO calculators`).
+		WithArgs("missing", "user-id").
+		WillReturnRows(sqlmock.NewRows(listColumns))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.InstantiateTemplate(context.Background(), "missing", "user-id"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresReplaceTemplates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	templates := []*Template{
+		{Slug: "a", Name: "A", Category: "x", Config: []byte(`{}`)},
+		{Slug: "b", Name: "B", Category: "y", Config: []byte(`{}`)},
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO calculator_templates .* ON CONFLICT \(slug\) DO UPDATE`).
+		WithArgs("a", "A", "x", "", []byte(`{}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO calculator_templates`).
+		WithArgs("b", "B", "y", "", []byte(`{}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM calculator_templates WHERE NOT \(slug = ANY\(\$1\)\)`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if err := repo.ReplaceTemplates(context.Background(), templates); err != nil {
+		t.Fatalf("ReplaceTemplates() returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
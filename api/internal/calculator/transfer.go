@@ -0,0 +1,170 @@
+package calculator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// TransferTTL is how long the recipient of an ownership transfer has to
+// accept it.
+const TransferTTL = 7 * 24 * time.Hour
+
+// ErrInvalidTransferToken is returned when a transfer token is unknown, has
+// expired, or has already been used.
+var ErrInvalidTransferToken = errors.New("invalid or expired transfer token")
+
+// ErrTransferRecipientMismatch is returned when a transfer token is presented
+// by an account whose email is not the one the transfer was sent to. The token
+// is not consumed.
+var ErrTransferRecipientMismatch = errors.New("transfer was sent to a different email address")
+
+// ErrTransferToSelf is returned when the recipient of a transfer is the
+// calculator's current owner.
+var ErrTransferToSelf = errors.New("calculator is already owned by the recipient")
+
+// ErrTransferNotFound is returned when a calculator has no pending transfer.
+var ErrTransferNotFound = errors.New("transfer not found")
+
+// ErrInvalidTransferEmail is returned when the recipient address of a
+// transfer is not a valid email address.
+var ErrInvalidTransferEmail = errors.New("invalid recipient email address")
+
+// Transfer is a pending handover of a calculator to the account registered
+// under ToEmail.
+type Transfer struct {
+	ID           string
+	CalculatorID string
+	FromUserID   string
+	ToEmail      string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// TransferStore persists pending ownership transfers and applies them.
+type TransferStore interface {
+	// CreateTransfer stores a pending transfer of calculatorID, replacing any
+	// earlier one so only the most recently sent token works.
+	// Returns ErrTransferToSelf if toEmail belongs to fromUserID.
+	CreateTransfer(ctx context.Context, calculatorID, fromUserID, toEmail, tokenHash string, expiresAt time.Time) (*Transfer, error)
+	// DeleteTransfer removes the pending transfer of calculatorID.
+	// Returns ErrTransferNotFound if there is none.
+	DeleteTransfer(ctx context.Context, calculatorID string) error
+	// AcceptTransfer hands the calculator named by the transfer with
+	// tokenHash to recipientID and deletes the transfer, in one transaction.
+	// Returns ErrInvalidTransferToken if no unexpired transfer matches.
+	// Returns ErrTransferRecipientMismatch if recipientID's email is not the
+	// transfer's ToEmail.
+	// Returns ErrNotFound if the calculator was deleted or changed hands
+	// since the transfer started.
+	AcceptTransfer(ctx context.Context, tokenHash, recipientID string, now time.Time) (*Calculator, error)
+}
+
+// TransferEmailSender delivers the acceptance token of an ownership transfer
+// to its recipient.
+type TransferEmailSender interface {
+	SendTransferEmail(ctx context.Context, toEmail, calculatorName, rawToken string) error
+}
+
+// WithTransfers configures ownership transfer support on the Service and
+// returns the same Service pointer for chained calls.
+func (s *Service) WithTransfers(store TransferStore, sender TransferEmailSender) *Service {
+	s.transfers = store
+	s.transferEmails = sender
+	s.genTransferToken = generateTransferToken
+	return s
+}
+
+// StartTransfer verifies ownership of the calculator and sends toEmail a
+// single-use token that moves the calculator into their account when accepted
+// within TransferTTL. Starting a new transfer invalidates any earlier token.
+// Returns ErrInvalidTransferEmail if toEmail is not a valid address.
+// Returns ErrTransferToSelf if toEmail is the owner's own address.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) StartTransfer(ctx context.Context, id, userID, toEmail string) (*Transfer, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(toEmail))
+	if err != nil {
+		return nil, ErrInvalidTransferEmail
+	}
+	calc, err := s.getter.GetCalculator(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+
+	rawToken, tokenHash, err := s.genTransferToken()
+	if err != nil {
+		return nil, fmt.Errorf("generating transfer token: %w", err)
+	}
+	transfer, err := s.transfers.CreateTransfer(ctx, id, userID, addr.Address, tokenHash, time.Now().UTC().Add(TransferTTL))
+	if err != nil {
+		return nil, fmt.Errorf("storing transfer: %w", err)
+	}
+	if err := s.transferEmails.SendTransferEmail(ctx, transfer.ToEmail, calc.Name, rawToken); err != nil {
+		return nil, fmt.Errorf("sending transfer email: %w", err)
+	}
+	return transfer, nil
+}
+
+// CancelTransfer verifies ownership of the calculator and withdraws its
+// pending transfer, invalidating the token that was sent.
+// Returns ErrTransferNotFound if no transfer is pending.
+// Returns ErrNotFound if the calculator does not exist or is soft-deleted.
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) CancelTransfer(ctx context.Context, id, userID string) error {
+	if _, err := s.getter.GetCalculator(ctx, id, userID); err != nil {
+		return fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	if err := s.transfers.DeleteTransfer(ctx, id); err != nil {
+		return fmt.Errorf("cancelling transfer: %w", err)
+	}
+	return nil
+}
+
+// AcceptTransfer consumes rawToken and makes userID the owner of the
+// calculator it was issued for. The calculator keeps its ID, so existing
+// embeds continue to work, and its revisions, published snapshot, and
+// submissions go with it. Uploaded assets are content-addressed and
+// referenced from the config, so they need no copying. The calculator leaves
+// the previous owner's folder, since folders are per account.
+// Returns ErrInvalidTransferToken if the token is unknown, expired, or used.
+// Returns ErrTransferRecipientMismatch if userID's email is not the address
+// the transfer was sent to.
+// Returns ErrNotFound if the calculator was deleted after the transfer started.
+func (s *Service) AcceptTransfer(ctx context.Context, rawToken, userID string) (*Calculator, error) {
+	h := sha256.Sum256([]byte(rawToken))
+	calc, err := s.transfers.AcceptTransfer(ctx, hex.EncodeToString(h[:]), userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("accepting transfer: %w", err)
+	}
+	if err := upgradeConfig(calc); err != nil {
+		return nil, err
+	}
+	return calc, nil
+}
+
+// generateTransferToken generates a random 32-byte opaque token (base64url,
+// no padding) and its SHA-256 hash (lowercase hex), matching the format of
+// password reset tokens.
+func generateTransferToken() (token, tokenHash string, err error) {
+	return generateTransferTokenFrom(rand.Reader)
+}
+
+// generateTransferTokenFrom generates a token using the provided reader.
+func generateTransferTokenFrom(r io.Reader) (token, tokenHash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", "", fmt.Errorf("reading random bytes: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	h := sha256.Sum256([]byte(token))
+	return token, hex.EncodeToString(h[:]), nil
+}
@@ -0,0 +1,257 @@
+package calculator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type stubTransferStore struct {
+	created   *Transfer
+	createErr error
+	deleteErr error
+	accepted  *Calculator
+	acceptErr error
+
+	gotEmail     string
+	gotHash      string
+	gotRecipient string
+	deleted      string
+}
+
+func (s *stubTransferStore) CreateTransfer(_ context.Context, calculatorID, fromUserID, toEmail, tokenHash string, expiresAt time.Time) (*Transfer, error) {
+	s.gotEmail, s.gotHash = toEmail, tokenHash
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	return &Transfer{ID: "transfer-1", CalculatorID: calculatorID, FromUserID: fromUserID, ToEmail: toEmail, ExpiresAt: expiresAt}, nil
+}
+
+func (s *stubTransferStore) DeleteTransfer(_ context.Context, calculatorID string) error {
+	s.deleted = calculatorID
+	return s.deleteErr
+}
+
+func (s *stubTransferStore) AcceptTransfer(_ context.Context, tokenHash, recipientID string, _ time.Time) (*Calculator, error) {
+	s.gotHash, s.gotRecipient = tokenHash, recipientID
+	return s.accepted, s.acceptErr
+}
+
+type stubTransferEmailSender struct {
+	to, name, token string
+	err             error
+}
+
+func (s *stubTransferEmailSender) SendTransferEmail(_ context.Context, toEmail, calculatorName, rawToken string) error {
+	s.to, s.name, s.token = toEmail, calculatorName, rawToken
+	return s.err
+}
+
+func newTransferService(getter *stubGetter, store *stubTransferStore, sender *stubTransferEmailSender) *Service {
+	svc := NewService(&stubCreator{}, &stubLister{}, getter, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithTransfers(store, sender)
+	svc.genTransferToken = func() (string, string, error) { return "raw-token", "token-hash", nil }
+	return svc
+}
+
+func TestStartTransfer_SendsToken(t *testing.T) {
+	store := &stubTransferStore{}
+	sender := &stubTransferEmailSender{}
+	svc := newTransferService(&stubGetter{calc: &Calculator{ID: "calc-abc", Name: "Quote"}}, store, sender)
+
+	transfer, err := svc.StartTransfer(context.Background(), "calc-abc", "user-xyz", "  client@example.com ")
+	if err != nil {
+		t.Fatalf("StartTransfer() returned unexpected error: %v", err)
+	}
+	if store.gotHash != "token-hash" || store.gotEmail != "client@example.com" {
+		t.Errorf("expected hashed token stored for trimmed email, got hash %q email %q", store.gotHash, store.gotEmail)
+	}
+	if sender.to != "client@example.com" || sender.name != "Quote" || sender.token != "raw-token" {
+		t.Errorf("unexpected email: %+v", sender)
+	}
+	if until := time.Until(transfer.ExpiresAt); until < TransferTTL-time.Minute || until > TransferTTL {
+		t.Errorf("expected expiry about %v away, got %v", TransferTTL, until)
+	}
+}
+
+func TestStartTransfer_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		getter  *stubGetter
+		store   *stubTransferStore
+		wantErr error
+	}{
+		{"invalid email", "not-an-email", &stubGetter{calc: &Calculator{}}, &stubTransferStore{}, ErrInvalidTransferEmail},
+		{"not owner", "client@example.com", &stubGetter{err: ErrForbidden}, &stubTransferStore{}, ErrForbidden},
+		{"to self", "owner@example.com", &stubGetter{calc: &Calculator{}}, &stubTransferStore{createErr: ErrTransferToSelf}, ErrTransferToSelf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &stubTransferEmailSender{}
+			svc := newTransferService(tt.getter, tt.store, sender)
+
+			if _, err := svc.StartTransfer(context.Background(), "calc-abc", "user-xyz", tt.email); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if sender.token != "" {
+				t.Error("expected no email to be sent")
+			}
+		})
+	}
+}
+
+func TestCancelTransfer(t *testing.T) {
+	store := &stubTransferStore{deleteErr: ErrTransferNotFound}
+	svc := newTransferService(&stubGetter{calc: &Calculator{ID: "calc-abc"}}, store, &stubTransferEmailSender{})
+
+	if err := svc.CancelTransfer(context.Background(), "calc-abc", "user-xyz"); !errors.Is(err, ErrTransferNotFound) {
+		t.Errorf("expected ErrTransferNotFound, got: %v", err)
+	}
+	if store.deleted != "calc-abc" {
+		t.Errorf("expected transfer of calc-abc deleted, got %q", store.deleted)
+	}
+}
+
+func TestAcceptTransfer_HashesToken(t *testing.T) {
+	store := &stubTransferStore{accepted: &Calculator{ID: "calc-abc", UserID: "user-new", Config: []byte(`{}`)}}
+	svc := newTransferService(&stubGetter{}, store, &stubTransferEmailSender{})
+
+	calc, err := svc.AcceptTransfer(context.Background(), "raw-token", "user-new")
+	if err != nil {
+		t.Fatalf("AcceptTransfer() returned unexpected error: %v", err)
+	}
+	h := sha256.Sum256([]byte("raw-token"))
+	if store.gotHash != hex.EncodeToString(h[:]) || store.gotRecipient != "user-new" {
+		t.Errorf("expected SHA-256 of the token for user-new, got %q for %q", store.gotHash, store.gotRecipient)
+	}
+	if calc.ID != "calc-abc" || calc.UserID != "user-new" {
+		t.Errorf("unexpected calculator: %+v", calc)
+	}
+}
+
+func TestGenerateTransferTokenFrom(t *testing.T) {
+	token, hash, err := generateTransferTokenFrom(bytes.NewReader(make([]byte, 32)))
+	if err != nil {
+		t.Fatalf("generateTransferTokenFrom() returned unexpected error: %v", err)
+	}
+	h := sha256.Sum256([]byte(token))
+	if hash != hex.EncodeToString(h[:]) {
+		t.Errorf("hash %q does not match token %q", hash, token)
+	}
+	if _, _, err := generateTransferTokenFrom(iotest.ErrReader(errors.New("no entropy"))); err == nil {
+		t.Error("expected error from failing reader")
+	}
+}
+
+func TestPostgresAcceptTransfer_MovesCalculator(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC()
+	transferRows := []string{"id", "calculator_id", "from_user_id", "to_email", "expires_at", "created_at"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM calculator_transfers WHERE token_hash = \$1 FOR UPDATE`).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(transferRows).AddRow("transfer-1", "calc-id", "user-old", "Client@example.com", now.Add(time.Hour), now))
+	mock.ExpectQuery(`SELECT email FROM users WHERE id = \$1`).
+		WithArgs("user-new").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("client@example.com"))
+	mock.ExpectExec(`DELETE FROM calculator_transfers WHERE id = \$1`).
+		WithArgs("transfer-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE calculators\s+SET user_id = \$2, folder_id = NULL\s+WHERE id = \$1 AND user_id = \$3 AND is_deleted = FALSE`).
+		WithArgs("calc-id", "user-new", "user-old").
+		WillReturnRows(sqlmock.NewRows(listColumns).AddRow("calc-id", "user-new", "", []byte("{}"), 1, false, now, now, "", "{}", nil, 1, 0, nil))
+	mock.ExpectCommit()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calc, err := repo.AcceptTransfer(context.Background(), "hash", "user-new", now)
+	if err != nil {
+		t.Fatalf("AcceptTransfer() returned unexpected error: %v", err)
+	}
+	if calc.ID != "calc-id" || calc.UserID != "user-new" {
+		t.Errorf("unexpected calculator: %+v", calc)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresAcceptTransfer_Rejects(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name           string
+		expiresAt      time.Time
+		recipientEmail string
+		wantErr        error
+	}{
+		{"expired", now.Add(-time.Minute), "client@example.com", ErrInvalidTransferToken},
+		{"wrong recipient", now.Add(time.Hour), "someone@example.com", ErrTransferRecipientMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New() failed: %v", err)
+			}
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FROM calculator_transfers WHERE token_hash = \$1 FOR UPDATE`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "calculator_id", "from_user_id", "to_email", "expires_at", "created_at"}).
+					AddRow("transfer-1", "calc-id", "user-old", "client@example.com", tt.expiresAt, now))
+			if tt.wantErr == ErrTransferRecipientMismatch {
+				mock.ExpectQuery(`SELECT email FROM users`).
+					WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow(tt.recipientEmail))
+			}
+			mock.ExpectRollback()
+
+			repo := NewPostgresCalculatorRepository(db)
+			if _, err := repo.AcceptTransfer(context.Background(), "hash", "user-new", now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresAcceptTransfer_UnknownToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM calculator_transfers WHERE token_hash = \$1 FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.AcceptTransfer(context.Background(), "hash", "user-new", time.Now()); !errors.Is(err, ErrInvalidTransferToken) {
+		t.Errorf("expected ErrInvalidTransferToken, got: %v", err)
+	}
+}
+
+func TestPostgresCreateTransfer_ToSelf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	mock.ExpectQuery(`INSERT INTO calculator_transfers .*WHERE NOT EXISTS .*ON CONFLICT \(calculator_id\) DO UPDATE`).
+		WithArgs("calc-id", "user-old", "owner@example.com", "hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	repo := NewPostgresCalculatorRepository(db)
+	if _, err := repo.CreateTransfer(context.Background(), "calc-id", "user-old", "owner@example.com", "hash", time.Now()); !errors.Is(err, ErrTransferToSelf) {
+		t.Errorf("expected ErrTransferToSelf, got: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+// purgeBatchSize bounds how many calculators one purge transaction deletes, so
+// a large backlog does not hold row locks for long.
+const purgeBatchSize = 100
+
+// TrashLister lists a user's soft-deleted calculators.
+type TrashLister interface {
+	ListDeletedCalculators(ctx context.Context, userID string) ([]*Calculator, error)
+}
+
+// TrashRestorer takes a soft-deleted calculator out of the trash.
+type TrashRestorer interface {
+	RestoreCalculator(ctx context.Context, id, userID string) (*Calculator, error)
+}
+
+// TrashPurger permanently deletes calculators that were soft-deleted before
+// cutoff, at most limit per call. It returns how many were purged and the
+// asset keys they referenced that nothing else still references.
+// DeleteUnreferencedAsset calls del for key only if, checked again under a
+// lock that concurrent saves respect, nothing references it.
+type TrashPurger interface {
+	PurgeDeletedCalculators(ctx context.Context, cutoff time.Time, limit int) (int, []string, error)
+	DeleteUnreferencedAsset(ctx context.Context, key string, del func(ctx context.Context, key string) error) (bool, error)
+}
+
+// AssetDeleter removes an uploaded asset from object storage.
+type AssetDeleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// WithTrash configures trash support on the Service.
+// It sets the TrashLister and TrashRestorer dependencies and returns the same
+// Service pointer for chained calls.
+func (s *Service) WithTrash(lister TrashLister, restorer TrashRestorer) *Service {
+	s.trashLister = lister
+	s.trashRestorer = restorer
+	return s
+}
+
+// ListTrash returns the soft-deleted calculators owned by userID that have not
+// yet been purged, most recently deleted first.
+func (s *Service) ListTrash(ctx context.Context, userID string) ([]*Calculator, error) {
+	calcs, err := s.trashLister.ListDeletedCalculators(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing deleted calculators: %w", err)
+	}
+	return calcs, nil
+}
+
+// Restore takes the calculator identified by id out of the trash. The public
+// config endpoint serves it again as soon as this returns.
+// Returns ErrNotFound if the calculator is not in the trash (never deleted,
+// already restored, or purged).
+// Returns ErrForbidden if the calculator exists but is owned by a different user.
+func (s *Service) Restore(ctx context.Context, id, userID string) (*Calculator, error) {
+	calc, err := s.trashRestorer.RestoreCalculator(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("restoring calculator: %w", err)
+	}
+	s.purgeCache(ctx, id)
+	return calc, nil
+}
+
+// assetKeyPattern matches the content-addressed keys assigned by the asset
+// upload handler: hex(sha256(data)) followed by an image extension. Keys are
+// embedded in configs as part of the public URL the upload returned. The
+// asset_keys SQL function that maintains asset_references uses the same
+// pattern.
+var assetKeyPattern = regexp.MustCompile(`[0-9a-f]{64}\.(?:jpg|png|webp|gif)`)
+
+// AssetKeys returns the distinct uploaded-asset keys referenced anywhere in
+// config, in order of first appearance.
+func AssetKeys(config []byte) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, m := range assetKeyPattern.FindAll(config, -1) {
+		key := string(m)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Purger permanently deletes calculators whose time in the trash has exceeded
+// the retention period, then deletes the assets only they referenced.
+type Purger struct {
+	store     TrashPurger
+	assets    AssetDeleter
+	retention time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewPurger creates a Purger that purges calculators deleted more than
+// retention ago.
+func NewPurger(store TrashPurger, assets AssetDeleter, retention time.Duration, logger *slog.Logger) *Purger {
+	return &Purger{store: store, assets: assets, retention: retention, logger: logger, now: time.Now}
+}
+
+// PurgeExpired purges every calculator past its retention, in batches, and
+// returns how many were purged. Asset deletion failures are logged rather than
+// returned: the rows are already gone, so a leftover object only wastes space.
+// An asset that a concurrent save has referenced again is kept.
+func (p *Purger) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := p.now().Add(-p.retention)
+	total := 0
+	for {
+		n, keys, err := p.store.PurgeDeletedCalculators(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("purging deleted calculators: %w", err)
+		}
+		total += n
+		for _, key := range keys {
+			if _, err := p.store.DeleteUnreferencedAsset(ctx, key, p.assets.Delete); err != nil {
+				p.logger.Warn("deleting purged calculator asset", "key", key, "error", err)
+			}
+		}
+		if n < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// Run calls PurgeExpired immediately and then every interval until ctx is
+// cancelled. Errors are logged and retried on the next tick.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := p.PurgeExpired(ctx)
+		if err != nil {
+			p.logger.Error("purging trash", "error", err)
+		} else if n > 0 {
+			p.logger.Info("purged trash", "calculators", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package calculator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type stubTrashStore struct {
+	calcs  []*Calculator
+	calc   *Calculator
+	err    error
+	userID string
+}
+
+func (s *stubTrashStore) ListDeletedCalculators(_ context.Context, userID string) ([]*Calculator, error) {
+	s.userID = userID
+	return s.calcs, s.err
+}
+
+func (s *stubTrashStore) RestoreCalculator(_ context.Context, _, userID string) (*Calculator, error) {
+	s.userID = userID
+	return s.calc, s.err
+}
+
+func newTrashService(store *stubTrashStore) *Service {
+	return NewService(&stubCreator{}, &stubLister{}, &stubGetter{}, &stubUpdater{}, &stubDeleter{}, &stubPublicConfigGetter{}, &stubDuplicator{}).
+		WithTrash(store, store)
+}
+
+func TestListTrash_Success(t *testing.T) {
+	store := &stubTrashStore{calcs: []*Calculator{{ID: "calc-1", IsDeleted: true}}}
+	svc := newTrashService(store)
+
+	calcs, err := svc.ListTrash(context.Background(), "user-xyz")
+	if err != nil {
+		t.Fatalf("ListTrash() returned unexpected error: %v", err)
+	}
+	if len(calcs) != 1 || store.userID != "user-xyz" {
+		t.Errorf("unexpected result %+v for user %q", calcs, store.userID)
+	}
+}
+
+func TestRestore_Success(t *testing.T) {
+	store := &stubTrashStore{calc: &Calculator{ID: "calc-1"}}
+	svc := newTrashService(store)
+
+	calc, err := svc.Restore(context.Background(), "calc-1", "user-xyz")
+	if err != nil {
+		t.Fatalf("Restore() returned unexpected error: %v", err)
+	}
+	if calc.ID != "calc-1" || store.userID != "user-xyz" {
+		t.Errorf("unexpected result %+v for user %q", calc, store.userID)
+	}
+}
+
+func TestRestore_Forbidden(t *testing.T) {
+	svc := newTrashService(&stubTrashStore{err: ErrForbidden})
+
+	_, err := svc.Restore(context.Background(), "calc-1", "user-other")
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected wrapped ErrForbidden, got: %v", err)
+	}
+}
+
+const (
+	assetA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.png"
+	assetB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb.webp"
+)
+
+func TestAssetKeys(t *testing.T) {
+	config := []byte(`{"fields":[{"options":[` +
+		`{"imageUrl":"https://cdn.example.com/` + assetA + `"},` +
+		`{"imageUrl":"https://cdn.example.com/` + assetB + `"},` +
+		`{"imageUrl":"https://cdn.example.com/` + assetA + `"},` +
+		`{"imageUrl":"https://elsewhere.example.com/logo.png"}]}]}`)
+
+	got := AssetKeys(config)
+	want := []string{assetA, assetB}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AssetKeys() = %v, want %v", got, want)
+	}
+}
+
+// stubPurgeStore returns one canned batch per call.
+type stubPurgeStore struct {
+	batches    []int
+	keys       [][]string
+	err        error
+	cutoffs    []time.Time
+	referenced map[string]bool
+}
+
+func (s *stubPurgeStore) PurgeDeletedCalculators(_ context.Context, cutoff time.Time, _ int) (int, []string, error) {
+	s.cutoffs = append(s.cutoffs, cutoff)
+	if s.err != nil {
+		return 0, nil, s.err
+	}
+	i := len(s.cutoffs) - 1
+	return s.batches[i], s.keys[i], nil
+}
+
+func (s *stubPurgeStore) DeleteUnreferencedAsset(ctx context.Context, key string, del func(context.Context, string) error) (bool, error) {
+	if s.referenced[key] {
+		return false, nil
+	}
+	if err := del(ctx, key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type stubAssetDeleter struct {
+	deleted []string
+	err     error
+}
+
+func (s *stubAssetDeleter) Delete(_ context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	return s.err
+}
+
+func newTestPurger(store TrashPurger, assets AssetDeleter, now time.Time) *Purger {
+	p := NewPurger(store, assets, 30*24*time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	p.now = func() time.Time { return now }
+	return p
+}
+
+func TestPurgeExpired_DrainsBatches(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	store := &stubPurgeStore{
+		batches: []int{purgeBatchSize, 3},
+		keys:    [][]string{{assetA}, {assetB}},
+	}
+	assets := &stubAssetDeleter{}
+	p := newTestPurger(store, assets, now)
+
+	n, err := p.PurgeExpired(context.Background())
+	if err != nil {
+		t.Fatalf("PurgeExpired() returned unexpected error: %v", err)
+	}
+	if n != purgeBatchSize+3 {
+		t.Errorf("expected %d purged, got %d", purgeBatchSize+3, n)
+	}
+	if len(store.cutoffs) != 2 || !store.cutoffs[0].Equal(now.Add(-30*24*time.Hour)) {
+		t.Errorf("unexpected cutoffs: %v", store.cutoffs)
+	}
+	if !reflect.DeepEqual(assets.deleted, []string{assetA, assetB}) {
+		t.Errorf("unexpected deleted assets: %v", assets.deleted)
+	}
+}
+
+func TestPurgeExpired_AssetDeleteErrorIsNotFatal(t *testing.T) {
+	store := &stubPurgeStore{batches: []int{1}, keys: [][]string{{assetA, assetB}}}
+	assets := &stubAssetDeleter{err: errors.New("storage unavailable")}
+	p := newTestPurger(store, assets, time.Now())
+
+	n, err := p.PurgeExpired(context.Background())
+	if err != nil {
+		t.Fatalf("PurgeExpired() returned unexpected error: %v", err)
+	}
+	if n != 1 || len(assets.deleted) != 2 {
+		t.Errorf("expected 1 purged and 2 delete attempts, got %d and %v", n, assets.deleted)
+	}
+}
+
+func TestPurgeExpired_KeepsReferencedAsset(t *testing.T) {
+	store := &stubPurgeStore{
+		batches:    []int{1},
+		keys:       [][]string{{assetA, assetB}},
+		referenced: map[string]bool{assetA: true},
+	}
+	assets := &stubAssetDeleter{}
+	p := newTestPurger(store, assets, time.Now())
+
+	if _, err := p.PurgeExpired(context.Background()); err != nil {
+		t.Fatalf("PurgeExpired() returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(assets.deleted, []string{assetB}) {
+		t.Errorf("expected only the unreferenced asset deleted, got %v", assets.deleted)
+	}
+}
+
+func TestPurgeExpired_StoreError(t *testing.T) {
+	wantErr := errors.New("db failure")
+	p := newTestPurger(&stubPurgeStore{err: wantErr}, &stubAssetDeleter{}, time.Now())
+
+	_, err := p.PurgeExpired(context.Background())
+	if !errors.Is(err, wantErr) {
+		t.Errorf("expected wrapped store error, got: %v", err)
+	}
+}
+
+func TestPostgresDeleteCalculator_SetsDeletedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectExec(`UPDATE calculators SET is_deleted = TRUE, deleted_at = NOW\(\)`).
+		WithArgs("calc-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	if err := repo.DeleteCalculator(context.Background(), "calc-id"); err != nil {
+		t.Fatalf("DeleteCalculator() returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresListDeletedCalculators(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mock.ExpectQuery(`SELECT .* FROM calculators\s+WHERE user_id = \$1 AND is_deleted = TRUE`).
+		WithArgs("user-id").
+		WillReturnRows(sqlmock.NewRows(append(listColumns, "deleted_at")).
+			AddRow("calc-1", "user-id", "", []byte(`{}`), 2, true, now, now, "", "{}", nil, 1, 0, nil, now))
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	calcs, err := repo.ListDeletedCalculators(context.Background(), "user-id")
+	if err != nil {
+		t.Fatalf("ListDeletedCalculators() returned unexpected error: %v", err)
+	}
+	if len(calcs) != 1 || calcs[0].DeletedAt == nil || !calcs[0].DeletedAt.Equal(now) {
+		t.Errorf("unexpected calculators: %+v", calcs)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresRestoreCalculator(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name    string
+		owner   string // empty means no deleted row
+		wantErr error
+	}{
+		{"success", "user-id", nil},
+		{"not in trash", "", ErrNotFound},
+		{"forbidden", "user-other", ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New() failed: %v", err)
+			}
+
+			rows := sqlmock.NewRows([]string{"user_id"})
+			if tt.owner != "" {
+				rows.AddRow(tt.owner)
+			}
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT user_id FROM calculators WHERE id = \$1 AND is_deleted = TRUE FOR UPDATE`).
+				WithArgs("calc-id").
+				WillReturnRows(rows)
+			if tt.wantErr == nil {
+				mock.ExpectQuery(`UPDATE calculators\s+SET is_deleted = FALSE, deleted_at = NULL`).
+					WithArgs("calc-id").
+					WillReturnRows(sqlmock.NewRows(listColumns).AddRow("calc-id", "user-id", "", []byte(`{}`), 2, false, now, now, "", "{}", nil, 1, 0, nil))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+			mock.ExpectClose()
+
+			repo := NewPostgresCalculatorRepository(db)
+			calc, err := repo.RestoreCalculator(context.Background(), "calc-id", "user-id")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (calc.ID != "calc-id" || calc.IsDeleted) {
+				t.Errorf("unexpected calculator: %+v", calc)
+			}
+			if err := db.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresPurgeDeletedCalculators(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	cutoff := time.Now().UTC().Truncate(time.Second)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM calculators .* FOR UPDATE SKIP LOCKED`).
+		WithArgs(cutoff, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("calc-1"))
+	mock.ExpectQuery(`SELECT DISTINCT asset_key FROM asset_references WHERE calculator_id = ANY\(\$1\)`).
+		WithArgs("{\"calc-1\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"asset_key"}).AddRow(assetA).AddRow(assetB))
+	mock.ExpectExec(`DELETE FROM calculators WHERE id = ANY\(\$1\)`).
+		WithArgs("{\"calc-1\"}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT key FROM unnest.+NOT EXISTS \(SELECT 1 FROM asset_references WHERE asset_key = key\)`).
+		WithArgs(`{"` + assetA + `","` + assetB + `"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow(assetB))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	n, orphaned, err := repo.PurgeDeletedCalculators(context.Background(), cutoff, 10)
+	if err != nil {
+		t.Fatalf("PurgeDeletedCalculators() returned unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 purged, got %d", n)
+	}
+	if !reflect.DeepEqual(orphaned, []string{assetB}) {
+		t.Errorf("expected only the unshared asset to be orphaned, got %v", orphaned)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresPurgeDeletedCalculators_NothingExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM calculators`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	n, orphaned, err := repo.PurgeDeletedCalculators(context.Background(), time.Now(), 10)
+	if err != nil {
+		t.Fatalf("PurgeDeletedCalculators() returned unexpected error: %v", err)
+	}
+	if n != 0 || orphaned != nil {
+		t.Errorf("expected nothing purged, got %d and %v", n, orphaned)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresPurgeDeletedCalculators_DeleteError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM calculators`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("calc-1"))
+	mock.ExpectQuery(`SELECT DISTINCT asset_key FROM asset_references`).
+		WillReturnRows(sqlmock.NewRows([]string{"asset_key"}))
+	mock.ExpectExec(`DELETE FROM calculators`).
+		WillReturnError(errors.New("db failure"))
+	mock.ExpectRollback()
+	mock.ExpectClose()
+
+	repo := NewPostgresCalculatorRepository(db)
+	_, _, err = repo.PurgeDeletedCalculators(context.Background(), time.Now(), 10)
+	if err == nil || !strings.Contains(err.Error(), "deleting expired calculators") {
+		t.Fatalf("expected delete error, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresDeleteUnreferencedAsset(t *testing.T) {
+	tests := []struct {
+		name        string
+		referenced  bool
+		delErr      error
+		wantDeleted bool
+		wantCalls   int
+	}{
+		{name: "unreferenced", wantDeleted: true, wantCalls: 1},
+		{name: "referenced again by a save", referenced: true},
+		{name: "storage error rolls back", delErr: errors.New("storage unavailable"), wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New() failed: %v", err)
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtextextended\(\$1, 0\)\)`).
+				WithArgs(assetA).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM asset_references WHERE asset_key = \$1\)`).
+				WithArgs(assetA).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.referenced))
+			if tt.delErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+			mock.ExpectClose()
+
+			calls := 0
+			repo := NewPostgresCalculatorRepository(db)
+			deleted, err := repo.DeleteUnreferencedAsset(context.Background(), assetA, func(_ context.Context, key string) error {
+				calls++
+				if key != assetA {
+					t.Errorf("del received unexpected key %q", key)
+				}
+				return tt.delErr
+			})
+			if tt.delErr != nil {
+				if !errors.Is(err, tt.delErr) {
+					t.Fatalf("expected wrapped storage error, got: %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("DeleteUnreferencedAsset() returned unexpected error: %v", err)
+			}
+			if deleted != tt.wantDeleted || calls != tt.wantCalls {
+				t.Errorf("expected deleted=%v after %d calls, got %v after %d", tt.wantDeleted, tt.wantCalls, deleted, calls)
+			}
+			if err := db.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
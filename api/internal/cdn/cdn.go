@@ -0,0 +1,24 @@
+// Package cdn invalidates cached API responses at the CDN edge.
+//
+// Cacheable responses are tagged with surrogate keys (the Surrogate-Key
+// response header); purging a key evicts every cached response tagged with it,
+// so a change is visible before the response's max-age expires.
+package cdn
+
+import "context"
+
+// Purger evicts cached responses by surrogate key.
+// Implementations: HTTPPurger (a CDN purge API), NoopPurger (no CDN).
+type Purger interface {
+	// Purge evicts every cached response tagged with any of keys.
+	Purge(ctx context.Context, keys ...string) error
+}
+
+// NoopPurger is a Purger for deployments without a CDN in front of the API.
+// Cached responses then simply expire after their max-age.
+type NoopPurger struct{}
+
+// Purge does nothing and returns nil.
+func (NoopPurger) Purge(context.Context, ...string) error {
+	return nil
+}
@@ -0,0 +1,57 @@
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTPPurger purges surrogate keys through an HTTP purge API, such as Fastly's
+// batch surrogate-key purge: it POSTs to a fixed URL with the keys in a
+// space-separated Surrogate-Key request header.
+type HTTPPurger struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// NewHTTPPurger creates an HTTPPurger that POSTs to url, adding headers (for
+// example an API token) to every request.
+// If httpClient is nil, http.DefaultClient is used.
+func NewHTTPPurger(url string, headers map[string]string, httpClient *http.Client) *HTTPPurger {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HTTPPurger{url: url, headers: headers, httpClient: httpClient}
+}
+
+// Purge sends one purge request for all of keys. It returns an error if the
+// request fails or the purge API responds with a non-2xx status.
+func (p *HTTPPurger) Purge(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, nil)
+	if err != nil {
+		return fmt.Errorf("building purge request: %w", err)
+	}
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Surrogate-Key", strings.Join(keys, " "))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending purge request: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("purge request returned status %d", resp.StatusCode)
+	}
+	return nil
+}
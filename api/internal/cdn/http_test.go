@@ -0,0 +1,77 @@
+package cdn
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPPurger_Purge(t *testing.T) {
+	var (
+		gotMethod, gotKeys, gotToken string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotKeys = r.Header.Get("Surrogate-Key")
+		gotToken = r.Header.Get("Fastly-Key")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPPurger(srv.URL, map[string]string{"Fastly-Key": "secret"}, srv.Client())
+	if err := p.Purge(context.Background(), "calculator-a", "calculator-b"); err != nil {
+		t.Fatalf("Purge() returned unexpected error: %v", err)
+	}
+	if gotMethod != http.MethodPost {
+		t.Errorf("expected POST, got %s", gotMethod)
+	}
+	if gotKeys != "calculator-a calculator-b" {
+		t.Errorf("expected space-separated keys, got %q", gotKeys)
+	}
+	if gotToken != "secret" {
+		t.Errorf("expected configured header to be sent, got %q", gotToken)
+	}
+}
+
+func TestHTTPPurger_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPPurger(srv.URL, nil, srv.Client())
+	if err := p.Purge(context.Background(), "calculator-a"); err == nil {
+		t.Fatal("expected error for non-2xx status, got nil")
+	}
+}
+
+func TestHTTPPurger_NoKeys(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	p := NewHTTPPurger(srv.URL, nil, srv.Client())
+	if err := p.Purge(context.Background()); err != nil {
+		t.Fatalf("Purge() returned unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected no request when there are no keys")
+	}
+}
+
+func TestHTTPPurger_RequestError(t *testing.T) {
+	p := NewHTTPPurger("http://127.0.0.1:0", nil, nil)
+	if err := p.Purge(context.Background(), "calculator-a"); err == nil {
+		t.Fatal("expected error for unreachable purge endpoint, got nil")
+	}
+}
+
+func TestNoopPurger(t *testing.T) {
+	var p Purger = NoopPurger{}
+	if err := p.Purge(context.Background(), "calculator-a"); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
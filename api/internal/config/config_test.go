@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoad_Success(t *testing.T) {
@@ -59,6 +60,9 @@ func TestDefault(t *testing.T) {
 	if len(cfg.API.DashboardOrigins) == 0 {
 		t.Error("Default() should have at least one dashboard origin")
 	}
+	if cfg.API.PublicURL == "" {
+		t.Error("Default() public URL should be non-empty")
+	}
 	if cfg.Storage.Provider == "" {
 		t.Error("Default() storage provider should be non-empty")
 	}
@@ -83,6 +87,110 @@ func TestDefault(t *testing.T) {
 	if !cfg.CDN.ServeLocal {
 		t.Error("Default() CDN ServeLocal should be true")
 	}
+	if cfg.CDN.Purge.Provider != "none" {
+		t.Errorf("Default() CDN purge provider should be %q, got %q", "none", cfg.CDN.Purge.Provider)
+	}
+	if cfg.Trash.Retention <= 0 {
+		t.Errorf("Default() trash retention should be positive, got %v", cfg.Trash.Retention)
+	}
+	if cfg.Trash.PurgeInterval <= 0 {
+		t.Errorf("Default() trash purge interval should be positive, got %v", cfg.Trash.PurgeInterval)
+	}
+	if cfg.Bulk.PollInterval <= 0 || cfg.Bulk.Lease <= 0 {
+		t.Errorf("Default() bulk worker settings should be positive, got %+v", cfg.Bulk)
+	}
+	if r := cfg.Submissions.Retention; r.Interval <= 0 || r.BatchSize <= 0 || !r.Plans["free"].Purge || r.Plans["lapsed"].Purge {
+		t.Errorf("Default() should purge free-plan submissions but not lapsed ones, got %+v", r)
+	}
+	if sp := cfg.Submissions.Spam; sp.Enabled || sp.MinFillTime <= 0 || sp.HourlyLimit != 20 {
+		t.Errorf("Default() should leave the spam filter off, set for 20 submissions per hour, got %+v", sp)
+	}
+}
+
+func TestLoad_TrashFields(t *testing.T) {
+	content := []byte(`
+trash:
+  retention: 168h
+  purge_interval: 15m
+`)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write temp config file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if cfg.Trash.Retention != 168*time.Hour {
+		t.Errorf("expected trash retention 168h, got %v", cfg.Trash.Retention)
+	}
+	if cfg.Trash.PurgeInterval != 15*time.Minute {
+		t.Errorf("expected trash purge interval 15m, got %v", cfg.Trash.PurgeInterval)
+	}
+}
+
+func TestLoad_BulkFields(t *testing.T) {
+	content := []byte(`
+bulk:
+  poll_interval: 10s
+  lease: 2m
+`)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write temp config file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if cfg.Bulk.PollInterval != 10*time.Second {
+		t.Errorf("expected bulk poll interval 10s, got %v", cfg.Bulk.PollInterval)
+	}
+	if cfg.Bulk.Lease != 2*time.Minute {
+		t.Errorf("expected bulk lease 2m, got %v", cfg.Bulk.Lease)
+	}
+}
+
+func TestLoad_SubmissionRetentionFields(t *testing.T) {
+	content := []byte(`
+submissions:
+  retention:
+    interval: 30m
+    batch_size: 500
+    purge_delay: 72h
+    purge_start_hour: 22
+    purge_end_hour: 4
+    plans:
+      free:
+        window: 720h
+        purge: true
+      pro:
+        window: 0s
+`)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write temp config file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	r := cfg.Submissions.Retention
+	if r.Interval != 30*time.Minute || r.BatchSize != 500 || r.PurgeDelay != 72*time.Hour || r.PurgeStartHour != 22 || r.PurgeEndHour != 4 {
+		t.Errorf("unexpected retention schedule %+v", r)
+	}
+	if free := r.Plans["free"]; free.Window != 720*time.Hour || !free.Purge {
+		t.Errorf("unexpected free plan %+v", free)
+	}
+	if pro, ok := r.Plans["pro"]; !ok || pro.Window != 0 || pro.Purge {
+		t.Errorf("unexpected pro plan %+v", pro)
+	}
 }
 
 func TestLoad_StorageAndCDNFields(t *testing.T) {
@@ -105,6 +213,11 @@ cdn:
   base_url: "http://localhost:8080/static"
   widget_dir: "/tmp/widget/dist"
   serve_local: true
+  purge:
+    provider: http
+    url: "https://api.fastly.com/service/abc/purge"
+    headers:
+      Fastly-Key: "secret"
 `)
 	dir := t.TempDir()
 	path := filepath.Join(dir, "config.yaml")
@@ -147,4 +260,39 @@ cdn:
 	if !cfg.CDN.ServeLocal {
 		t.Error("expected CDN serve_local true")
 	}
+	if cfg.CDN.Purge.Provider != "http" {
+		t.Errorf("expected CDN purge provider %q, got %q", "http", cfg.CDN.Purge.Provider)
+	}
+	if cfg.CDN.Purge.URL != "https://api.fastly.com/service/abc/purge" {
+		t.Errorf("expected CDN purge URL %q, got %q", "https://api.fastly.com/service/abc/purge", cfg.CDN.Purge.URL)
+	}
+	if cfg.CDN.Purge.Headers["Fastly-Key"] != "secret" {
+		t.Errorf("expected CDN purge header Fastly-Key %q, got %q", "secret", cfg.CDN.Purge.Headers["Fastly-Key"])
+	}
+}
+
+func TestLoad_SubmissionSpamFields(t *testing.T) {
+	content := []byte(`
+submissions:
+  spam:
+    enabled: true
+    secret: s3cret
+    min_fill_time: 5s
+    proof_of_work_bits: 16
+    hourly_limit: 10
+`)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write temp config file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	sp := cfg.Submissions.Spam
+	if !sp.Enabled || sp.Secret != "s3cret" || sp.MinFillTime != 5*time.Second || sp.ProofOfWorkBits != 16 || sp.HourlyLimit != 10 {
+		t.Errorf("unexpected spam config %+v", sp)
+	}
 }
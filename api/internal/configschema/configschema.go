@@ -0,0 +1,134 @@
+// Package configschema defines the calculator config document stored in
+// calculators.config and validates it before storage.
+//
+// The shape mirrors CalculatorEditorConfig in the dashboard
+// (dashboard/src/shared/config/calculatorConfig.ts), which is the contract
+// between the dashboard (write), the widget (read), and the API (validate).
+// JSON keys are camelCase to match the TypeScript types.
+package configschema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Version is the version of the config document shape described by this
+// package. Documents record it under VersionKey, and Migrate upgrades older
+// documents to it. Increment it together with the upgrades registry.
+const Version = 1
+
+// Field types supported by the calculator builder.
+const (
+	FieldTypeDropdown    = "dropdown"
+	FieldTypeRadio       = "radio"
+	FieldTypeCheckbox    = "checkbox"
+	FieldTypeNumber      = "number"
+	FieldTypeSlider      = "slider"
+	FieldTypeText        = "text"
+	FieldTypeImageSelect = "image_select"
+)
+
+// Rounding modes an output may select. Outputs without one use RoundingHalfUp.
+const (
+	RoundingHalfEven = "half_even"
+	RoundingHalfUp   = "half_up"
+	RoundingFloor    = "floor"
+	RoundingCeil     = "ceil"
+)
+
+// Layout modes supported by the calculator builder.
+const (
+	LayoutSinglePage = "single-page"
+	LayoutMultiStep  = "multi-step"
+)
+
+// Config is the typed view of a calculator config document. Sections that are
+// absent from the stored JSON decode to their zero values.
+type Config struct {
+	Fields          []Field          `json:"fields"`
+	Outputs         []Output         `json:"outputs"`
+	LayoutMode      string           `json:"layoutMode"`
+	Steps           []Step           `json:"steps"`
+	Theme           *Theme           `json:"theme,omitempty"`
+	VisibilityRules []VisibilityRule `json:"visibilityRules"`
+	Settings        json.RawMessage  `json:"settings,omitempty"`
+}
+
+// Field is a single input field. Type-specific properties (options, numeric
+// bounds, placeholder) are optional and only meaningful for the matching types.
+type Field struct {
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	Label        string   `json:"label"`
+	HelpText     string   `json:"helpText,omitempty"`
+	Required     bool     `json:"required"`
+	VariableName string   `json:"variableName"`
+	Options      []Option `json:"options,omitempty"`
+	Min          *float64 `json:"min,omitempty"`
+	Max          *float64 `json:"max,omitempty"`
+	Step         *float64 `json:"step,omitempty"`
+	DefaultValue *float64 `json:"defaultValue,omitempty"`
+	Placeholder  string   `json:"placeholder,omitempty"`
+}
+
+// Option is a selectable choice for dropdown, radio, checkbox, and image_select
+// fields. ImageURL is only used by image_select.
+type Option struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Value    string `json:"value"`
+	ImageURL string `json:"imageUrl,omitempty"`
+}
+
+// Output is a named formula result shown to the end user. Rounding and
+// Currency only affect server-side evaluation: Rounding is one of the
+// Rounding* modes, and Currency is an ISO 4217 code whose minor unit sets the
+// precision of the final result.
+type Output struct {
+	ID         string `json:"id"`
+	Label      string `json:"label"`
+	Expression string `json:"expression"`
+	Rounding   string `json:"rounding,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+}
+
+// Step groups fields into a page of a multi-step calculator.
+type Step struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	FieldIDs []string `json:"fieldIds"`
+}
+
+// Theme holds the calculator's color settings.
+type Theme struct {
+	PrimaryColor    string `json:"primaryColor"`
+	SecondaryColor  string `json:"secondaryColor"`
+	BackgroundColor string `json:"backgroundColor"`
+	TextColor       string `json:"textColor"`
+}
+
+// VisibilityRule shows or hides TargetFieldID based on its conditions.
+type VisibilityRule struct {
+	ID            string                `json:"id"`
+	TargetFieldID string                `json:"targetFieldId"`
+	Conditions    []VisibilityCondition `json:"conditions"`
+	Combinator    string                `json:"combinator"`
+}
+
+// VisibilityCondition compares the value of SourceFieldID against Value.
+type VisibilityCondition struct {
+	ID            string `json:"id"`
+	SourceFieldID string `json:"sourceFieldId"`
+	Operator      string `json:"operator"`
+	Value         string `json:"value"`
+}
+
+// Parse decodes raw into a Config without validating it. Use Validate first
+// when the document comes from an untrusted source.
+func Parse(raw []byte) (*Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("decoding calculator config: %w", err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,56 @@
+package configschema
+
+import "testing"
+
+func TestParse_ValidConfig(t *testing.T) {
+	cfg, err := Parse([]byte(validConfig))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	if len(cfg.Fields) != 4 {
+		t.Fatalf("expected 4 fields, got %d", len(cfg.Fields))
+	}
+	if cfg.Fields[0].VariableName != "sqft" {
+		t.Errorf("expected variableName %q, got %q", "sqft", cfg.Fields[0].VariableName)
+	}
+	if cfg.Fields[0].DefaultValue == nil || *cfg.Fields[0].DefaultValue != 500 {
+		t.Errorf("expected defaultValue 500, got %v", cfg.Fields[0].DefaultValue)
+	}
+	if len(cfg.Fields[1].Options) != 2 {
+		t.Errorf("expected 2 options, got %d", len(cfg.Fields[1].Options))
+	}
+	if cfg.Fields[2].Options[0].ImageURL != "https://cdn.example.com/a.png" {
+		t.Errorf("unexpected imageUrl %q", cfg.Fields[2].Options[0].ImageURL)
+	}
+	if len(cfg.Outputs) != 2 || cfg.Outputs[0].Expression != "{sqft} * {finish}" {
+		t.Errorf("unexpected outputs: %+v", cfg.Outputs)
+	}
+	if cfg.LayoutMode != LayoutMultiStep {
+		t.Errorf("expected layoutMode %q, got %q", LayoutMultiStep, cfg.LayoutMode)
+	}
+	if len(cfg.Steps) != 1 || len(cfg.Steps[0].FieldIDs) != 2 {
+		t.Errorf("unexpected steps: %+v", cfg.Steps)
+	}
+	if cfg.Theme == nil || cfg.Theme.PrimaryColor != "#3B82F6" {
+		t.Errorf("unexpected theme: %+v", cfg.Theme)
+	}
+	if len(cfg.VisibilityRules) != 1 || cfg.VisibilityRules[0].Conditions[0].Operator != ">" {
+		t.Errorf("unexpected visibility rules: %+v", cfg.VisibilityRules)
+	}
+}
+
+func TestParse_EmptyObject(t *testing.T) {
+	cfg, err := Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	if len(cfg.Fields) != 0 || len(cfg.Outputs) != 0 || cfg.Theme != nil {
+		t.Errorf("expected zero-valued config, got %+v", cfg)
+	}
+}
+
+func TestParse_InvalidJSON(t *testing.T) {
+	if _, err := Parse([]byte(`{"fields": 42}`)); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,144 @@
+package configschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// VersionKey is the top-level config key that records which schema version a
+// document was written against. A stored document without it is version 1,
+// which covers every config stored before versioning existed. Documents
+// arriving from clients are read with MigrateFrom instead.
+//
+// config_version on the calculators row is unrelated: it counts edits for
+// optimistic concurrency. The schema version lives in the document so it
+// travels with every copy — revisions, published snapshots, export bundles.
+const VersionKey = "schemaVersion"
+
+// ErrNewerVersion is returned by Migrate when a document was written against a
+// schema version this build does not know. It is never downgraded.
+var ErrNewerVersion = errors.New("config schema version is newer than supported")
+
+// Upgrade rewrites a decoded document from one schema version to the next, in
+// place. Numbers are json.Number so values survive the round trip unchanged.
+type Upgrade func(doc map[string]any) error
+
+// upgrades is the ordered registry of schema upgrades: upgrades[i] takes a
+// document from version i+1 to version i+2. To change the config shape, append
+// a step here and increment Version; a test keeps the two in step. Never edit
+// or reorder a step that has shipped.
+var upgrades = []Upgrade{}
+
+// defaultMigrator applies the registered upgrades.
+var defaultMigrator = NewMigrator(upgrades...)
+
+// Migrator applies an ordered list of upgrades to bring documents to its
+// current version.
+type Migrator struct {
+	steps []Upgrade
+}
+
+// NewMigrator creates a Migrator whose steps[i] upgrades version i+1 to i+2.
+func NewMigrator(steps ...Upgrade) *Migrator {
+	return &Migrator{steps: steps}
+}
+
+// Version returns the schema version documents are upgraded to.
+func (m *Migrator) Version() int {
+	return len(m.steps) + 1
+}
+
+// Migrate upgrades raw to the current schema version, reporting whether any
+// upgrade was applied. A document already at the current version is returned
+// unchanged, byte for byte. An upgraded document has VersionKey set to the
+// current version.
+//
+// Returns an error wrapping ErrNewerVersion if raw was written against a later
+// version, or the failing step's error annotated with its source version.
+func (m *Migrator) Migrate(raw []byte) ([]byte, bool, error) {
+	return m.migrate(raw, 1, false)
+}
+
+// MigrateFrom upgrades raw like Migrate, except that a document without
+// VersionKey is taken to be at version unversioned rather than 1, and is
+// returned with VersionKey set even when no upgrade was needed, so the stored
+// copy is never later read as version 1. It is for documents from outside
+// storage whose version is known some other way. unversioned must be at
+// least 1.
+func (m *Migrator) MigrateFrom(raw []byte, unversioned int) ([]byte, bool, error) {
+	if unversioned < 1 {
+		return nil, false, fmt.Errorf("%s must be a positive integer", VersionKey)
+	}
+	return m.migrate(raw, unversioned, true)
+}
+
+// migrate upgrades raw, taking a document without VersionKey to be at version
+// unversioned. When stamp is set such a document always gets VersionKey.
+func (m *Migrator) migrate(raw []byte, unversioned int, stamp bool) ([]byte, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false, fmt.Errorf("decoding calculator config: %w", err)
+	}
+	if doc == nil {
+		return nil, false, errors.New("decoding calculator config: not a JSON object")
+	}
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	_, versioned := doc[VersionKey]
+	if !versioned {
+		version = unversioned
+	}
+	current := m.Version()
+	if version > current {
+		return nil, false, fmt.Errorf("%w: document is version %d, this build supports %d", ErrNewerVersion, version, current)
+	}
+	if version == current && (versioned || !stamp) {
+		return raw, false, nil
+	}
+	for v := version; v < current; v++ {
+		if err := m.steps[v-1](doc); err != nil {
+			return nil, false, fmt.Errorf("upgrading config from version %d: %w", v, err)
+		}
+	}
+	doc[VersionKey] = current
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, fmt.Errorf("encoding upgraded config: %w", err)
+	}
+	return out, true, nil
+}
+
+// Migrate upgrades raw to Version using the registered upgrades. See
+// Migrator.Migrate.
+func Migrate(raw []byte) ([]byte, bool, error) {
+	return defaultMigrator.Migrate(raw)
+}
+
+// MigrateFrom upgrades raw to Version using the registered upgrades. See
+// Migrator.MigrateFrom.
+func MigrateFrom(raw []byte, unversioned int) ([]byte, bool, error) {
+	return defaultMigrator.MigrateFrom(raw, unversioned)
+}
+
+// documentVersion reads VersionKey from doc, defaulting to 1 when absent.
+func documentVersion(doc map[string]any) (int, error) {
+	raw, present := doc[VersionKey]
+	if !present {
+		return 1, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s must be a positive integer", VersionKey)
+	}
+	v, err := n.Int64()
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", VersionKey)
+	}
+	return int(v), nil
+}
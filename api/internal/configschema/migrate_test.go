@@ -0,0 +1,151 @@
+package configschema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUpgrades_MatchVersion(t *testing.T) {
+	if got := defaultMigrator.Version(); got != Version {
+		t.Errorf("registered upgrades reach version %d, but Version is %d", got, Version)
+	}
+}
+
+// renameLayout is a sample upgrade that renames a top-level key.
+func renameLayout(doc map[string]any) error {
+	if mode, ok := doc["layout"]; ok {
+		doc["layoutMode"] = mode
+		delete(doc, "layout")
+	}
+	return nil
+}
+
+// addSteps is a sample upgrade that fills in a section.
+func addSteps(doc map[string]any) error {
+	if _, ok := doc["steps"]; !ok {
+		doc["steps"] = []any{}
+	}
+	return nil
+}
+
+func TestMigrate_AppliesStepsInOrder(t *testing.T) {
+	m := NewMigrator(renameLayout, addSteps)
+
+	out, changed, err := m.Migrate([]byte(`{"layout":"multi-step","fields":[{"id":"f1","min":0.1}]}`))
+	if err != nil {
+		t.Fatalf("Migrate() returned unexpected error: %v", err)
+	}
+	if !changed {
+		t.Error("expected changed to be true")
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("decoding result: %v", err)
+	}
+	if doc["layoutMode"] != "multi-step" || doc["layout"] != nil || doc["steps"] == nil {
+		t.Errorf("expected both upgrades applied, got %s", out)
+	}
+	if doc[VersionKey] != float64(3) {
+		t.Errorf("expected %s 3, got %v", VersionKey, doc[VersionKey])
+	}
+	if !strings.Contains(string(out), `"min":0.1`) {
+		t.Errorf("expected numbers preserved, got %s", out)
+	}
+}
+
+func TestMigrate_StartsFromDocumentVersion(t *testing.T) {
+	m := NewMigrator(func(map[string]any) error { return errors.New("step 1 must not run") }, addSteps)
+
+	out, changed, err := m.Migrate([]byte(`{"schemaVersion":2}`))
+	if err != nil {
+		t.Fatalf("Migrate() returned unexpected error: %v", err)
+	}
+	if !changed || !strings.Contains(string(out), `"steps":[]`) {
+		t.Errorf("expected only the second step applied, got %s", out)
+	}
+}
+
+func TestMigrate_CurrentVersionUnchanged(t *testing.T) {
+	m := NewMigrator(addSteps)
+	raw := []byte(`{"schemaVersion": 2, "fields": []}`)
+
+	out, changed, err := m.Migrate(raw)
+	if err != nil {
+		t.Fatalf("Migrate() returned unexpected error: %v", err)
+	}
+	if changed || string(out) != string(raw) {
+		t.Errorf("expected document returned unchanged, got %s", out)
+	}
+}
+
+func TestMigrate_Errors(t *testing.T) {
+	failing := NewMigrator(func(map[string]any) error { return errors.New("boom") })
+	cases := []struct {
+		name    string
+		m       *Migrator
+		raw     string
+		wantErr string
+	}{
+		{"newer version", NewMigrator(), `{"schemaVersion":2}`, "newer than supported"},
+		{"non-integer version", NewMigrator(), `{"schemaVersion":1.5}`, "must be a positive integer"},
+		{"zero version", NewMigrator(), `{"schemaVersion":0}`, "must be a positive integer"},
+		{"not an object", NewMigrator(), `[]`, "decoding calculator config"},
+		{"null", NewMigrator(), `null`, "not a JSON object"},
+		{"failing step", failing, `{}`, "upgrading config from version 1: boom"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := tc.m.Migrate([]byte(tc.raw))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMigrateFrom_UnversionedDocument(t *testing.T) {
+	m := NewMigrator(renameLayout, addSteps)
+
+	// A document without a version is taken at the given one, so a current
+	// document is not upgraded again; it still gains the key.
+	out, changed, err := m.MigrateFrom([]byte(`{"layout":"multi-step"}`), 3)
+	if err != nil {
+		t.Fatalf("MigrateFrom() returned unexpected error: %v", err)
+	}
+	if !changed || string(out) != `{"layout":"multi-step","schemaVersion":3}` {
+		t.Errorf("expected the document stamped and otherwise unchanged, got %s", out)
+	}
+
+	out, _, err = m.MigrateFrom([]byte(`{}`), 2)
+	if err != nil {
+		t.Fatalf("MigrateFrom() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(string(out), `"steps":[]`) || strings.Contains(string(out), `"layoutMode"`) {
+		t.Errorf("expected only the second step applied, got %s", out)
+	}
+}
+
+func TestMigrateFrom_VersionedDocument(t *testing.T) {
+	m := NewMigrator(renameLayout, addSteps)
+	raw := []byte(`{"schemaVersion": 3}`)
+
+	out, changed, err := m.MigrateFrom(raw, 1)
+	if err != nil {
+		t.Fatalf("MigrateFrom() returned unexpected error: %v", err)
+	}
+	if changed || string(out) != string(raw) {
+		t.Errorf("expected an explicit version to win, got %s", out)
+	}
+	if _, _, err := m.MigrateFrom([]byte(`{}`), 0); err == nil {
+		t.Error("expected an error for version 0")
+	}
+}
+
+func TestMigrate_NewerVersionSentinel(t *testing.T) {
+	_, _, err := NewMigrator().Migrate([]byte(`{"schemaVersion":5}`))
+	if !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("expected ErrNewerVersion, got: %v", err)
+	}
+}
@@ -0,0 +1,591 @@
+package configschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Limits applied by Validate. They bound the size of a config document so a
+// single calculator cannot degrade widget rendering or formula evaluation.
+const (
+	maxFields           = 200
+	maxOptionsPerField  = 200
+	maxOutputs          = 50
+	maxSteps            = 50
+	maxVisibilityRules  = 200
+	maxConditions       = 50
+	maxLabelLength      = 500
+	maxExpressionLength = 2000
+)
+
+// variableNamePattern matches the identifiers accepted inside a {variable}
+// reference by the formula tokenizer.
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// hexColorPattern matches #RGB and #RRGGBB color values.
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+var validFieldTypes = map[string]bool{
+	FieldTypeDropdown:    true,
+	FieldTypeRadio:       true,
+	FieldTypeCheckbox:    true,
+	FieldTypeNumber:      true,
+	FieldTypeSlider:      true,
+	FieldTypeText:        true,
+	FieldTypeImageSelect: true,
+}
+
+var validRoundingModes = map[string]bool{
+	RoundingHalfEven: true,
+	RoundingHalfUp:   true,
+	RoundingFloor:    true,
+	RoundingCeil:     true,
+}
+
+// currencyCodePattern matches an ISO 4217 alphabetic currency code.
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var validOperators = map[string]bool{"=": true, "!=": true, ">": true, "<": true, ">=": true, "<=": true}
+
+// FieldError describes a single validation failure. Path is a JSONPath-like
+// location relative to the config root, e.g. "fields[3].options".
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+// String formats the error as "path: message".
+func (e FieldError) String() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError is returned by Validate when the config document violates
+// the schema. It carries every failure found, not just the first.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error returns all field errors joined by "; ".
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.String()
+	}
+	return "invalid calculator config: " + strings.Join(parts, "; ")
+}
+
+// Validate checks raw against the calculator config schema. It returns nil when
+// the document is valid and a *ValidationError listing every failure otherwise.
+//
+// Top-level sections may be omitted — a freshly created calculator stores "{}"
+// and the builder fills sections in as the user works — but any section that is
+// present must be well-formed. Unknown top-level keys are ignored so older API
+// instances accept documents written by newer dashboards.
+//
+// Validate checks drafts, which the builder autosaves after every edit, so it
+// accepts the blanks an edit in progress leaves: empty labels, an option list
+// with no options yet, and an option with no image. ValidatePublishable
+// rejects those too.
+func Validate(raw []byte) error {
+	return validate(raw, false)
+}
+
+// ValidatePublishable checks raw like Validate and also requires the document
+// to be complete enough to show to end users: every label is non-empty, every
+// choice field has at least one option, and every image option has an image.
+func ValidatePublishable(raw []byte) error {
+	return validate(raw, true)
+}
+
+func validate(raw []byte, complete bool) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return &ValidationError{Errors: []FieldError{{Path: "config", Message: "must be valid JSON"}}}
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return &ValidationError{Errors: []FieldError{{Path: "config", Message: "must be a JSON object"}}}
+	}
+
+	v := &validator{fieldIDs: make(map[string]bool), complete: complete}
+	v.validateVersion(root)
+	v.validateFields(root)
+	v.validateOutputs(root)
+	v.validateLayout(root)
+	v.validateTheme(root)
+	v.validateVisibilityRules(root)
+	v.validateSettings(root)
+
+	if len(v.errs) > 0 {
+		return &ValidationError{Errors: v.errs}
+	}
+	return nil
+}
+
+// validator accumulates errors while walking a decoded config document.
+// complete is set when validating for publication.
+type validator struct {
+	errs     []FieldError
+	fieldIDs map[string]bool
+	complete bool
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validateVersion rejects documents that claim a schema version this build
+// does not know, so they are never stored and later misread.
+func (v *validator) validateVersion(root map[string]any) {
+	if _, present := root[VersionKey]; !present {
+		return
+	}
+	version, err := documentVersion(root)
+	if err != nil {
+		v.fail(VersionKey, "must be a positive integer")
+		return
+	}
+	if version > Version {
+		v.fail(VersionKey, "must be at most %d", Version)
+	}
+}
+
+func (v *validator) validateFields(root map[string]any) {
+	raw, present := root["fields"]
+	if !present {
+		return
+	}
+	fields, ok := v.array(raw, "fields", maxFields)
+	if !ok {
+		return
+	}
+	variables := make(map[string]bool)
+	for i, item := range fields {
+		path := fmt.Sprintf("fields[%d]", i)
+		field, ok := v.object(item, path)
+		if !ok {
+			continue
+		}
+		if id, ok := v.requiredString(field, path, "id"); ok {
+			if v.fieldIDs[id] {
+				v.fail(path+".id", "duplicate field id %q", id)
+			}
+			v.fieldIDs[id] = true
+		}
+		v.label(field, path, "label")
+		v.optionalString(field, path, "helpText")
+		if _, present := field["required"]; !present {
+			v.fail(path+".required", "is required")
+		} else if _, ok := field["required"].(bool); !ok {
+			v.fail(path+".required", "must be a boolean")
+		}
+		// The editor derives variable names from labels, so a draft may
+		// have a blank one or two fields sharing one until they are renamed.
+		if name, ok := v.draftString(field, path, "variableName"); ok && name != "" {
+			switch {
+			case !variableNamePattern.MatchString(name):
+				v.fail(path+".variableName", "must contain only letters, digits, and underscores")
+			case variables[name] && v.complete:
+				v.fail(path+".variableName", "duplicate variable name %q", name)
+			}
+			variables[name] = true
+		}
+
+		fieldType, ok := v.requiredString(field, path, "type")
+		if !ok {
+			continue
+		}
+		if !validFieldTypes[fieldType] {
+			v.fail(path+".type", "unsupported field type %q", fieldType)
+			continue
+		}
+		switch fieldType {
+		case FieldTypeDropdown, FieldTypeRadio, FieldTypeCheckbox, FieldTypeImageSelect:
+			v.validateOptions(field, path, fieldType == FieldTypeImageSelect)
+		case FieldTypeNumber, FieldTypeSlider:
+			v.validateNumericBounds(field, path)
+			v.optionalString(field, path, "placeholder")
+		case FieldTypeText:
+			v.optionalString(field, path, "placeholder")
+		}
+	}
+}
+
+func (v *validator) validateOptions(field map[string]any, path string, withImage bool) {
+	optsPath := path + ".options"
+	raw, present := field["options"]
+	if !present {
+		v.fail(optsPath, "is required")
+		return
+	}
+	opts, ok := v.array(raw, optsPath, maxOptionsPerField)
+	if !ok {
+		return
+	}
+	if len(opts) == 0 {
+		if v.complete {
+			v.fail(optsPath, "must not be empty")
+		}
+		return
+	}
+	ids := make(map[string]bool)
+	for i, item := range opts {
+		optPath := fmt.Sprintf("%s[%d]", optsPath, i)
+		opt, ok := v.object(item, optPath)
+		if !ok {
+			continue
+		}
+		if id, ok := v.requiredString(opt, optPath, "id"); ok {
+			if ids[id] {
+				v.fail(optPath+".id", "duplicate option id %q", id)
+			}
+			ids[id] = true
+		}
+		v.label(opt, optPath, "label")
+		if _, present := opt["value"]; !present {
+			v.fail(optPath+".value", "is required")
+		} else if _, ok := opt["value"].(string); !ok {
+			v.fail(optPath+".value", "must be a string")
+		}
+		if withImage {
+			if u, ok := v.draftString(opt, optPath, "imageUrl"); ok && u != "" {
+				v.httpURL(u, optPath+".imageUrl", false)
+			}
+		}
+	}
+}
+
+func (v *validator) validateNumericBounds(field map[string]any, path string) {
+	bounds := make(map[string]float64)
+	for _, key := range []string{"min", "max", "step", "defaultValue"} {
+		raw, present := field[key]
+		if !present || raw == nil {
+			continue
+		}
+		n, ok := raw.(json.Number)
+		if !ok {
+			v.fail(path+"."+key, "must be a number")
+			continue
+		}
+		f, err := n.Float64()
+		if err != nil {
+			v.fail(path+"."+key, "must be a finite number")
+			continue
+		}
+		bounds[key] = f
+	}
+	minV, hasMin := bounds["min"]
+	maxV, hasMax := bounds["max"]
+	if hasMin && hasMax && minV > maxV {
+		v.fail(path+".min", "must not be greater than max")
+	}
+	if step, ok := bounds["step"]; ok && step <= 0 {
+		v.fail(path+".step", "must be greater than 0")
+	}
+	if def, ok := bounds["defaultValue"]; ok {
+		if hasMin && def < minV {
+			v.fail(path+".defaultValue", "must not be less than min")
+		}
+		if hasMax && def > maxV {
+			v.fail(path+".defaultValue", "must not be greater than max")
+		}
+	}
+}
+
+func (v *validator) validateOutputs(root map[string]any) {
+	raw, present := root["outputs"]
+	if !present {
+		return
+	}
+	outputs, ok := v.array(raw, "outputs", maxOutputs)
+	if !ok {
+		return
+	}
+	ids := make(map[string]bool)
+	for i, item := range outputs {
+		path := fmt.Sprintf("outputs[%d]", i)
+		out, ok := v.object(item, path)
+		if !ok {
+			continue
+		}
+		if id, ok := v.requiredString(out, path, "id"); ok {
+			if ids[id] {
+				v.fail(path+".id", "duplicate output id %q", id)
+			}
+			ids[id] = true
+		}
+		v.label(out, path, "label")
+		// An empty expression is valid: new outputs start blank and evaluate to 0.
+		if _, present := out["expression"]; !present {
+			v.fail(path+".expression", "is required")
+		} else if expr, ok := out["expression"].(string); !ok {
+			v.fail(path+".expression", "must be a string")
+		} else if len(expr) > maxExpressionLength {
+			v.fail(path+".expression", "must be at most %d characters", maxExpressionLength)
+		}
+		if raw, present := out["rounding"]; present {
+			if mode, ok := raw.(string); !ok || !validRoundingModes[mode] {
+				v.fail(path+".rounding", "must be one of %q, %q, %q, %q", RoundingHalfEven, RoundingHalfUp, RoundingFloor, RoundingCeil)
+			}
+		}
+		if raw, present := out["currency"]; present {
+			if code, ok := raw.(string); !ok || !currencyCodePattern.MatchString(code) {
+				v.fail(path+".currency", "must be an ISO 4217 currency code such as USD")
+			}
+		}
+	}
+}
+
+func (v *validator) validateLayout(root map[string]any) {
+	if raw, present := root["layoutMode"]; present {
+		mode, ok := raw.(string)
+		if !ok || (mode != LayoutSinglePage && mode != LayoutMultiStep) {
+			v.fail("layoutMode", "must be %q or %q", LayoutSinglePage, LayoutMultiStep)
+		}
+	}
+
+	raw, present := root["steps"]
+	if !present {
+		return
+	}
+	steps, ok := v.array(raw, "steps", maxSteps)
+	if !ok {
+		return
+	}
+	ids := make(map[string]bool)
+	for i, item := range steps {
+		path := fmt.Sprintf("steps[%d]", i)
+		step, ok := v.object(item, path)
+		if !ok {
+			continue
+		}
+		if id, ok := v.requiredString(step, path, "id"); ok {
+			if ids[id] {
+				v.fail(path+".id", "duplicate step id %q", id)
+			}
+			ids[id] = true
+		}
+		if _, present := step["title"]; !present {
+			v.fail(path+".title", "is required")
+		} else if _, ok := step["title"].(string); !ok {
+			v.fail(path+".title", "must be a string")
+		}
+		rawIDs, present := step["fieldIds"]
+		if !present {
+			v.fail(path+".fieldIds", "is required")
+			continue
+		}
+		fieldIDs, ok := v.array(rawIDs, path+".fieldIds", maxFields)
+		if !ok {
+			continue
+		}
+		for j, rawID := range fieldIDs {
+			idPath := fmt.Sprintf("%s.fieldIds[%d]", path, j)
+			id, ok := rawID.(string)
+			if !ok {
+				v.fail(idPath, "must be a string")
+				continue
+			}
+			v.fieldRef(id, idPath)
+		}
+	}
+}
+
+func (v *validator) validateTheme(root map[string]any) {
+	raw, present := root["theme"]
+	if !present {
+		return
+	}
+	theme, ok := v.object(raw, "theme")
+	if !ok {
+		return
+	}
+	for _, key := range []string{"primaryColor", "secondaryColor", "backgroundColor", "textColor"} {
+		color, ok := v.requiredString(theme, "theme", key)
+		if ok && !hexColorPattern.MatchString(color) {
+			v.fail("theme."+key, "must be a hex color such as #3B82F6")
+		}
+	}
+}
+
+func (v *validator) validateVisibilityRules(root map[string]any) {
+	raw, present := root["visibilityRules"]
+	if !present {
+		return
+	}
+	rules, ok := v.array(raw, "visibilityRules", maxVisibilityRules)
+	if !ok {
+		return
+	}
+	for i, item := range rules {
+		path := fmt.Sprintf("visibilityRules[%d]", i)
+		rule, ok := v.object(item, path)
+		if !ok {
+			continue
+		}
+		v.requiredString(rule, path, "id")
+		if target, ok := v.draftString(rule, path, "targetFieldId"); ok && target != "" {
+			v.fieldRef(target, path+".targetFieldId")
+		}
+		if comb, ok := v.draftString(rule, path, "combinator"); ok && comb != "" && comb != "AND" && comb != "OR" {
+			v.fail(path+".combinator", `must be "AND" or "OR"`)
+		}
+		rawConds, present := rule["conditions"]
+		if !present {
+			v.fail(path+".conditions", "is required")
+			continue
+		}
+		conds, ok := v.array(rawConds, path+".conditions", maxConditions)
+		if !ok {
+			continue
+		}
+		for j, c := range conds {
+			condPath := fmt.Sprintf("%s.conditions[%d]", path, j)
+			cond, ok := v.object(c, condPath)
+			if !ok {
+				continue
+			}
+			v.requiredString(cond, condPath, "id")
+			if src, ok := v.draftString(cond, condPath, "sourceFieldId"); ok && src != "" {
+				v.fieldRef(src, condPath+".sourceFieldId")
+			}
+			if op, ok := v.requiredString(cond, condPath, "operator"); ok && !validOperators[op] {
+				v.fail(condPath+".operator", "unsupported operator %q", op)
+			}
+			if _, present := cond["value"]; !present {
+				v.fail(condPath+".value", "is required")
+			} else if _, ok := cond["value"].(string); !ok {
+				v.fail(condPath+".value", "must be a string")
+			}
+		}
+	}
+}
+
+func (v *validator) validateSettings(root map[string]any) {
+	raw, present := root["settings"]
+	if !present {
+		return
+	}
+	settings, ok := v.object(raw, "settings")
+	if !ok {
+		return
+	}
+	if rawURL, present := settings["redirectUrl"]; present {
+		u, ok := rawURL.(string)
+		if !ok {
+			v.fail("settings.redirectUrl", "must be a string")
+		} else if u != "" {
+			v.httpURL(u, "settings.redirectUrl", true)
+		}
+	}
+}
+
+// array asserts that raw is a JSON array of at most limit elements.
+func (v *validator) array(raw any, path string, limit int) ([]any, bool) {
+	arr, ok := raw.([]any)
+	if !ok {
+		v.fail(path, "must be an array")
+		return nil, false
+	}
+	if len(arr) > limit {
+		v.fail(path, "must contain at most %d items", limit)
+		return nil, false
+	}
+	return arr, true
+}
+
+// object asserts that raw is a JSON object.
+func (v *validator) object(raw any, path string) (map[string]any, bool) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		v.fail(path, "must be an object")
+		return nil, false
+	}
+	return obj, true
+}
+
+// requiredString asserts that obj[key] is a non-empty string.
+func (v *validator) requiredString(obj map[string]any, path, key string) (string, bool) {
+	raw, present := obj[key]
+	if !present {
+		v.fail(path+"."+key, "is required")
+		return "", false
+	}
+	s, ok := raw.(string)
+	if !ok {
+		v.fail(path+"."+key, "must be a string")
+		return "", false
+	}
+	if strings.TrimSpace(s) == "" {
+		v.fail(path+"."+key, "must not be empty")
+		return "", false
+	}
+	return s, true
+}
+
+// optionalString asserts that obj[key], when present, is a string.
+func (v *validator) optionalString(obj map[string]any, path, key string) {
+	if raw, present := obj[key]; present {
+		if _, ok := raw.(string); !ok {
+			v.fail(path+"."+key, "must be a string")
+		}
+	}
+}
+
+// draftString asserts that obj[key] is a string, and also that it is non-empty
+// when validating for publication.
+func (v *validator) draftString(obj map[string]any, path, key string) (string, bool) {
+	if v.complete {
+		return v.requiredString(obj, path, key)
+	}
+	raw, present := obj[key]
+	if !present {
+		v.fail(path+"."+key, "is required")
+		return "", false
+	}
+	s, ok := raw.(string)
+	if !ok {
+		v.fail(path+"."+key, "must be a string")
+		return "", false
+	}
+	return s, true
+}
+
+// label asserts that obj[key] is a string within maxLabelLength, non-empty
+// when validating for publication.
+func (v *validator) label(obj map[string]any, path, key string) {
+	if s, ok := v.draftString(obj, path, key); ok && len(s) > maxLabelLength {
+		v.fail(path+"."+key, "must be at most %d characters", maxLabelLength)
+	}
+}
+
+// fieldRef asserts that id names a field declared in the fields section.
+func (v *validator) fieldRef(id, path string) {
+	if !v.fieldIDs[id] {
+		v.fail(path, "references unknown field %q", id)
+	}
+}
+
+// httpURL asserts that s is an absolute http(s) URL. When httpsOnly is set,
+// only the https scheme is accepted.
+func (v *validator) httpURL(s, path string, httpsOnly bool) {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		v.fail(path, "must be an absolute URL")
+		return
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && !httpsOnly:
+	case httpsOnly:
+		v.fail(path, "must use https")
+	default:
+		v.fail(path, "must use http or https")
+	}
+}
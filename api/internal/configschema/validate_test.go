@@ -0,0 +1,416 @@
+package configschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// validConfig is a complete config document exercising every section.
+const validConfig = `{
+	"fields": [
+		{"id": "f1", "type": "number", "label": "Square feet", "required": true, "variableName": "sqft", "min": 0, "max": 10000, "step": 1, "defaultValue": 500},
+		{"id": "f2", "type": "dropdown", "label": "Finish", "required": false, "variableName": "finish",
+		 "options": [{"id": "o1", "label": "Basic", "value": "1"}, {"id": "o2", "label": "Premium", "value": "1.5"}]},
+		{"id": "f3", "type": "image_select", "label": "Style", "required": false, "variableName": "style",
+		 "options": [{"id": "o3", "label": "Modern", "value": "2", "imageUrl": "https://cdn.example.com/a.png"}]},
+		{"id": "f4", "type": "text", "label": "Notes", "required": false, "variableName": "notes", "placeholder": "Anything else?"}
+	],
+	"outputs": [
+		{"id": "r1", "label": "Total", "expression": "{sqft} * {finish}", "rounding": "half_even", "currency": "USD"},
+		{"id": "r2", "label": "Draft", "expression": ""}
+	],
+	"layoutMode": "multi-step",
+	"steps": [{"id": "s1", "title": "Basics", "fieldIds": ["f1", "f2"]}],
+	"theme": {"primaryColor": "#3B82F6", "secondaryColor": "#6B7280", "backgroundColor": "#FFF", "textColor": "#111827"},
+	"visibilityRules": [
+		{"id": "v1", "targetFieldId": "f3", "combinator": "AND",
+		 "conditions": [{"id": "c1", "sourceFieldId": "f1", "operator": ">", "value": "100"}]}
+	],
+	"settings": {"redirectUrl": "https://example.com/thanks"}
+}`
+
+func TestValidate_ValidConfig(t *testing.T) {
+	if err := Validate([]byte(validConfig)); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+}
+
+func TestValidate_EmptyObject(t *testing.T) {
+	if err := Validate([]byte(`{}`)); err != nil {
+		t.Fatalf("Validate() returned unexpected error for empty config: %v", err)
+	}
+}
+
+func TestValidate_IgnoresUnknownTopLevelKeys(t *testing.T) {
+	if err := Validate([]byte(`{"futureSection": {"anything": true}}`)); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+}
+
+func TestValidate_NotAnObject(t *testing.T) {
+	for _, raw := range []string{`[]`, `null`, `"text"`, `42`, `{not json`} {
+		t.Run(raw, func(t *testing.T) {
+			err := Validate([]byte(raw))
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected *ValidationError, got: %v", err)
+			}
+			if verr.Errors[0].Path != "config" {
+				t.Errorf("expected path %q, got %q", "config", verr.Errors[0].Path)
+			}
+		})
+	}
+}
+
+func TestValidate_FieldErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   string
+		wantPath string
+		wantMsg  string
+	}{
+		{
+			name:     "fields not an array",
+			config:   `{"fields": {}}`,
+			wantPath: "fields",
+			wantMsg:  "must be an array",
+		},
+		{
+			name:     "field not an object",
+			config:   `{"fields": [42]}`,
+			wantPath: "fields[0]",
+			wantMsg:  "must be an object",
+		},
+		{
+			name:     "missing id",
+			config:   `{"fields": [{"type": "text", "label": "A", "required": false, "variableName": "a"}]}`,
+			wantPath: "fields[0].id",
+			wantMsg:  "is required",
+		},
+		{
+			name: "duplicate id",
+			config: `{"fields": [
+				{"id": "f1", "type": "text", "label": "A", "required": false, "variableName": "a"},
+				{"id": "f1", "type": "text", "label": "B", "required": false, "variableName": "b"}]}`,
+			wantPath: "fields[1].id",
+			wantMsg:  `duplicate field id "f1"`,
+		},
+		{
+			name:     "unsupported type",
+			config:   `{"fields": [{"id": "f1", "type": "date", "label": "A", "required": false, "variableName": "a"}]}`,
+			wantPath: "fields[0].type",
+			wantMsg:  `unsupported field type "date"`,
+		},
+		{
+			name:     "label not a string",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": 5, "required": false, "variableName": "a"}]}`,
+			wantPath: "fields[0].label",
+			wantMsg:  "must be a string",
+		},
+		{
+			name:     "required not a boolean",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": "A", "required": "yes", "variableName": "a"}]}`,
+			wantPath: "fields[0].required",
+			wantMsg:  "must be a boolean",
+		},
+		{
+			name:     "invalid variable name",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": "A", "required": false, "variableName": "num bathrooms"}]}`,
+			wantPath: "fields[0].variableName",
+			wantMsg:  "must contain only letters, digits, and underscores",
+		},
+		{
+			name:     "missing options",
+			config:   `{"fields": [{"id": "f1", "type": "checkbox", "label": "A", "required": false, "variableName": "a"}]}`,
+			wantPath: "fields[0].options",
+			wantMsg:  "is required",
+		},
+		{
+			name:     "option value not a string",
+			config:   `{"fields": [{"id": "f1", "type": "dropdown", "label": "A", "required": false, "variableName": "a", "options": [{"id": "o1", "label": "X", "value": 5}]}]}`,
+			wantPath: "fields[0].options[0].value",
+			wantMsg:  "must be a string",
+		},
+		{
+			name:     "image option with non-http url",
+			config:   `{"fields": [{"id": "f1", "type": "image_select", "label": "A", "required": false, "variableName": "a", "options": [{"id": "o1", "label": "X", "value": "1", "imageUrl": "javascript:alert(1)"}]}]}`,
+			wantPath: "fields[0].options[0].imageUrl",
+			wantMsg:  "must be an absolute URL",
+		},
+		{
+			name:     "min greater than max",
+			config:   `{"fields": [{"id": "f1", "type": "slider", "label": "A", "required": false, "variableName": "a", "min": 10, "max": 5}]}`,
+			wantPath: "fields[0].min",
+			wantMsg:  "must not be greater than max",
+		},
+		{
+			name:     "non-positive step",
+			config:   `{"fields": [{"id": "f1", "type": "number", "label": "A", "required": false, "variableName": "a", "step": 0}]}`,
+			wantPath: "fields[0].step",
+			wantMsg:  "must be greater than 0",
+		},
+		{
+			name:     "numeric bound not a number",
+			config:   `{"fields": [{"id": "f1", "type": "number", "label": "A", "required": false, "variableName": "a", "max": "ten"}]}`,
+			wantPath: "fields[0].max",
+			wantMsg:  "must be a number",
+		},
+		{
+			name:     "default below min",
+			config:   `{"fields": [{"id": "f1", "type": "number", "label": "A", "required": false, "variableName": "a", "min": 1, "defaultValue": 0}]}`,
+			wantPath: "fields[0].defaultValue",
+			wantMsg:  "must not be less than min",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertSingleError(t, tc.config, tc.wantPath, tc.wantMsg)
+		})
+	}
+}
+
+// TestValidate_AcceptsDraftBlanks covers the states the builder autosaves
+// while a field is being filled in.
+func TestValidate_AcceptsDraftBlanks(t *testing.T) {
+	config := `{
+		"fields": [
+			{"id": "f1", "type": "dropdown", "label": "", "required": false, "variableName": "a", "options": []},
+			{"id": "f2", "type": "image_select", "label": "B", "required": false, "variableName": "b",
+			 "options": [{"id": "o1", "label": "", "value": "", "imageUrl": ""}]}
+		],
+		"outputs": [{"id": "r1", "label": "", "expression": ""}]
+	}`
+	if err := Validate([]byte(config)); err != nil {
+		t.Errorf("Validate() rejected a draft in progress: %v", err)
+	}
+}
+
+func TestValidatePublishable_Errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   string
+		wantPath string
+		wantMsg  string
+	}{
+		{
+			name:     "empty label",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": " ", "required": false, "variableName": "a"}]}`,
+			wantPath: "fields[0].label",
+			wantMsg:  "must not be empty",
+		},
+		{
+			name: "empty options",
+			config: `{"fields": [
+				{"id": "f0", "type": "text", "label": "A", "required": false, "variableName": "a"},
+				{"id": "f1", "type": "text", "label": "B", "required": false, "variableName": "b"},
+				{"id": "f2", "type": "text", "label": "C", "required": false, "variableName": "c"},
+				{"id": "f3", "type": "radio", "label": "D", "required": false, "variableName": "d", "options": []}]}`,
+			wantPath: "fields[3].options",
+			wantMsg:  "must not be empty",
+		},
+		{
+			name:     "empty option label",
+			config:   `{"fields": [{"id": "f1", "type": "dropdown", "label": "A", "required": false, "variableName": "a", "options": [{"id": "o1", "label": "", "value": "x"}]}]}`,
+			wantPath: "fields[0].options[0].label",
+			wantMsg:  "must not be empty",
+		},
+		{
+			name:     "empty image url",
+			config:   `{"fields": [{"id": "f1", "type": "image_select", "label": "A", "required": false, "variableName": "a", "options": [{"id": "o1", "label": "X", "value": "1", "imageUrl": ""}]}]}`,
+			wantPath: "fields[0].options[0].imageUrl",
+			wantMsg:  "must not be empty",
+		},
+		{
+			name: "duplicate variable name",
+			config: `{"fields": [
+				{"id": "f1", "type": "text", "label": "A", "required": false, "variableName": "a"},
+				{"id": "f2", "type": "text", "label": "B", "required": false, "variableName": "a"}]}`,
+			wantPath: "fields[1].variableName",
+			wantMsg:  `duplicate variable name "a"`,
+		},
+		{
+			name:     "empty variable name",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": "A", "required": false, "variableName": ""}]}`,
+			wantPath: "fields[0].variableName",
+			wantMsg:  "must not be empty",
+		},
+		{
+			name:     "empty condition source",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": "A", "required": false, "variableName": "a"}], "visibilityRules": [{"id": "v1", "targetFieldId": "f1", "combinator": "AND", "conditions": [{"id": "c1", "sourceFieldId": "", "operator": "=", "value": ""}]}]}`,
+			wantPath: "visibilityRules[0].conditions[0].sourceFieldId",
+			wantMsg:  "must not be empty",
+		},
+		{
+			name:     "empty output label",
+			config:   `{"outputs": [{"id": "r1", "label": "", "expression": ""}]}`,
+			wantPath: "outputs[0].label",
+			wantMsg:  "must not be empty",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Validate([]byte(tc.config)); err != nil {
+				t.Fatalf("Validate() should accept the draft, got: %v", err)
+			}
+			assertSingleFieldError(t, ValidatePublishable([]byte(tc.config)), tc.wantPath, tc.wantMsg)
+		})
+	}
+}
+
+func TestValidate_SectionErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   string
+		wantPath string
+		wantMsg  string
+	}{
+		{
+			name:     "output missing expression",
+			config:   `{"outputs": [{"id": "r1", "label": "Total"}]}`,
+			wantPath: "outputs[0].expression",
+			wantMsg:  "is required",
+		},
+		{
+			name:     "output expression too long",
+			config:   `{"outputs": [{"id": "r1", "label": "Total", "expression": "` + strings.Repeat("1", maxExpressionLength+1) + `"}]}`,
+			wantPath: "outputs[0].expression",
+			wantMsg:  "must be at most 2000 characters",
+		},
+		{
+			name:     "duplicate output id",
+			config:   `{"outputs": [{"id": "r1", "label": "A", "expression": ""}, {"id": "r1", "label": "B", "expression": ""}]}`,
+			wantPath: "outputs[1].id",
+			wantMsg:  `duplicate output id "r1"`,
+		},
+		{
+			name:     "unknown output rounding mode",
+			config:   `{"outputs": [{"id": "r1", "label": "Total", "expression": "", "rounding": "half_down"}]}`,
+			wantPath: "outputs[0].rounding",
+			wantMsg:  `must be one of "half_even", "half_up", "floor", "ceil"`,
+		},
+		{
+			name:     "lowercase output currency",
+			config:   `{"outputs": [{"id": "r1", "label": "Total", "expression": "", "currency": "usd"}]}`,
+			wantPath: "outputs[0].currency",
+			wantMsg:  "must be an ISO 4217 currency code such as USD",
+		},
+		{
+			name:     "invalid layout mode",
+			config:   `{"layoutMode": "sideways"}`,
+			wantPath: "layoutMode",
+			wantMsg:  `must be "single-page" or "multi-step"`,
+		},
+		{
+			name:     "step references unknown field",
+			config:   `{"steps": [{"id": "s1", "title": "One", "fieldIds": ["missing"]}]}`,
+			wantPath: "steps[0].fieldIds[0]",
+			wantMsg:  `references unknown field "missing"`,
+		},
+		{
+			name:     "invalid theme color",
+			config:   `{"theme": {"primaryColor": "blue", "secondaryColor": "#000", "backgroundColor": "#FFF", "textColor": "#111"}}`,
+			wantPath: "theme.primaryColor",
+			wantMsg:  "must be a hex color such as #3B82F6",
+		},
+		{
+			name:     "visibility rule invalid combinator",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": "A", "required": false, "variableName": "a"}], "visibilityRules": [{"id": "v1", "targetFieldId": "f1", "combinator": "XOR", "conditions": []}]}`,
+			wantPath: "visibilityRules[0].combinator",
+			wantMsg:  `must be "AND" or "OR"`,
+		},
+		{
+			name:     "visibility condition unsupported operator",
+			config:   `{"fields": [{"id": "f1", "type": "text", "label": "A", "required": false, "variableName": "a"}], "visibilityRules": [{"id": "v1", "targetFieldId": "f1", "combinator": "AND", "conditions": [{"id": "c1", "sourceFieldId": "f1", "operator": "~", "value": "x"}]}]}`,
+			wantPath: "visibilityRules[0].conditions[0].operator",
+			wantMsg:  `unsupported operator "~"`,
+		},
+		{
+			name:     "settings not an object",
+			config:   `{"settings": []}`,
+			wantPath: "settings",
+			wantMsg:  "must be an object",
+		},
+		{
+			name:     "redirect url not https",
+			config:   `{"settings": {"redirectUrl": "http://example.com"}}`,
+			wantPath: "settings.redirectUrl",
+			wantMsg:  "must use https",
+		},
+		{
+			name:     "schema version not an integer",
+			config:   `{"schemaVersion": "1"}`,
+			wantPath: "schemaVersion",
+			wantMsg:  "must be a positive integer",
+		},
+		{
+			name:     "schema version newer than supported",
+			config:   `{"schemaVersion": 99}`,
+			wantPath: "schemaVersion",
+			wantMsg:  "must be at most 1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertSingleError(t, tc.config, tc.wantPath, tc.wantMsg)
+		})
+	}
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	err := Validate([]byte(`{"layoutMode": "x", "theme": [], "fields": [{"id": "f1"}]}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got: %v", err)
+	}
+	if len(verr.Errors) < 3 {
+		t.Errorf("expected at least 3 errors, got %d: %v", len(verr.Errors), verr.Errors)
+	}
+}
+
+func TestValidate_TooManyFields(t *testing.T) {
+	items := make([]string, maxFields+1)
+	for i := range items {
+		items[i] = `{}`
+	}
+	assertSingleError(t, `{"fields": [`+strings.Join(items, ",")+`]}`, "fields", "must contain at most 200 items")
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{Errors: []FieldError{
+		{Path: "fields[3].options", Message: "must not be empty"},
+		{Path: "layoutMode", Message: "is invalid"},
+	}}
+	want := "invalid calculator config: fields[3].options: must not be empty; layoutMode: is invalid"
+	if got := err.Error(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// assertSingleError validates config and asserts that exactly one error is
+// reported with the given path and message.
+func assertSingleError(t *testing.T, config, wantPath, wantMsg string) {
+	t.Helper()
+	assertSingleFieldError(t, Validate([]byte(config)), wantPath, wantMsg)
+}
+
+// assertSingleFieldError checks that err is a *ValidationError with exactly one error.
+func assertSingleFieldError(t *testing.T, err error, wantPath, wantMsg string) {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got: %v", err)
+	}
+	if len(verr.Errors) != 1 {
+		t.Fatalf("expected exactly 1 error, got %d: %v", len(verr.Errors), verr.Errors)
+	}
+	if verr.Errors[0].Path != wantPath {
+		t.Errorf("expected path %q, got %q", wantPath, verr.Errors[0].Path)
+	}
+	if verr.Errors[0].Message != wantMsg {
+		t.Errorf("expected message %q, got %q", wantMsg, verr.Errors[0].Message)
+	}
+}
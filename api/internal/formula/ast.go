@@ -0,0 +1,54 @@
+package formula
+
+// Node is implemented by every AST node produced by Parse.
+type Node interface {
+	// Position returns the byte offset in the source expression where the node begins.
+	Position() int
+}
+
+// NumberLiteral is a numeric literal such as 42 or 3.14.
+type NumberLiteral struct {
+	Value float64
+	// Raw is the literal exactly as written, preserved so exact-arithmetic
+	// evaluators can avoid binary floating-point conversion.
+	Raw string
+	Pos int
+}
+
+// Variable is a field reference such as {qty}. Name is stored without braces.
+type Variable struct {
+	Name string
+	Pos  int
+}
+
+// BinaryOp is a binary operation: Left Op Right. The parser produces
+// left-associative trees for operators at the same precedence level.
+//
+// Op is one of + - * / % = != > < >= <=.
+type BinaryOp struct {
+	Op    string
+	Left  Node
+	Right Node
+	Pos   int // position of the operator token
+}
+
+// UnaryOp is a unary operation. Only unary minus is supported.
+type UnaryOp struct {
+	Op      string
+	Operand Node
+	Pos     int
+}
+
+// FunctionCall is a call to one of the allow-listed functions
+// (IF, MIN, MAX, ABS, ROUND). Argument counts are checked by the evaluator.
+type FunctionCall struct {
+	Name string
+	Args []Node
+	Pos  int
+}
+
+func (n *NumberLiteral) Position() int { return n.Pos }
+func (n *Variable) Position() int      { return n.Pos }
+func (n *BinaryOp) Position() int      { return n.Pos }
+func (n *UnaryOp) Position() int       { return n.Pos }
+func (n *FunctionCall) Position() int  { return n.Pos }
@@ -0,0 +1,93 @@
+package formula
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// conformanceCase mirrors an entry in the shared fixture. Non-finite values are
+// encoded as the strings "Infinity", "-Infinity", and "NaN".
+type conformanceCase struct {
+	Name       string             `json:"name"`
+	Expression string             `json:"expression"`
+	Context    map[string]float64 `json:"context"`
+	Value      json.RawMessage    `json:"value"`
+	Error      *string            `json:"error"`
+}
+
+func loadConformanceCases(t *testing.T) []conformanceCase {
+	t.Helper()
+	path := filepath.Join("..", "..", "..", "packages", "formula-engine", "conformance", "cases.json")
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading conformance fixture: %v", err)
+	}
+	var fixture struct {
+		Cases []conformanceCase `json:"cases"`
+	}
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		t.Fatalf("decoding conformance fixture: %v", err)
+	}
+	if len(fixture.Cases) == 0 {
+		t.Fatal("conformance fixture has no cases")
+	}
+	return fixture.Cases
+}
+
+func decodeConformanceValue(t *testing.T, raw json.RawMessage) float64 {
+	t.Helper()
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		switch s {
+		case "Infinity":
+			return math.Inf(1)
+		case "-Infinity":
+			return math.Inf(-1)
+		case "NaN":
+			return math.NaN()
+		}
+		t.Fatalf("unexpected string value %q", s)
+	}
+	var f float64
+	if err := json.Unmarshal(raw, &f); err != nil {
+		t.Fatalf("decoding value %s: %v", raw, err)
+	}
+	return f
+}
+
+// TestConformance checks the Go engine against the cases shared with the
+// TypeScript engine (see packages/formula-engine/src/conformance.test.ts).
+func TestConformance(t *testing.T) {
+	for _, tc := range loadConformanceCases(t) {
+		t.Run(tc.Name, func(t *testing.T) {
+			got, err := Evaluate(tc.Expression, tc.Context)
+
+			if tc.Error != nil {
+				if err == nil {
+					t.Fatalf("Evaluate(%q) = %v, want error %q", tc.Expression, got, *tc.Error)
+				}
+				if err.Error() != *tc.Error {
+					t.Errorf("Evaluate(%q) error = %q, want %q", tc.Expression, err.Error(), *tc.Error)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Evaluate(%q) returned unexpected error: %v", tc.Expression, err)
+			}
+			want := decodeConformanceValue(t, tc.Value)
+			if math.IsNaN(want) {
+				if !math.IsNaN(got) {
+					t.Errorf("Evaluate(%q) = %v, want NaN", tc.Expression, got)
+				}
+				return
+			}
+			if got != want {
+				t.Errorf("Evaluate(%q) = %v, want %v", tc.Expression, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Timeout bounds the wall-clock time spent evaluating a single expression.
+const Timeout = 100 * time.Millisecond
+
+// ErrTimeout is returned when evaluation exceeds Timeout. The message matches
+// the TypeScript engine's TimeoutError.
+var ErrTimeout = errors.New("Formula evaluation timed out (exceeded 100ms)")
+
+// EvaluateError is returned when a syntactically valid AST cannot be
+// evaluated — for example, a reference to an unknown variable or a function
+// called with the wrong number of arguments.
+type EvaluateError struct {
+	Message string
+	Pos     int // byte offset of the node that failed
+}
+
+func (e *EvaluateError) Error() string {
+	return e.Message
+}
+
+// Evaluate tokenizes, parses, and evaluates expression against vars.
+//
+// An empty or whitespace-only expression evaluates to 0. The returned error is
+// a *TokenizeError, *ParseError, *EvaluateError, or ErrTimeout.
+//
+// Comparisons return 1 for true and 0 for false. Division by zero follows
+// IEEE 754 (and JavaScript) semantics — n/0 is ±Inf and 0/0 is NaN — and is
+// not treated as an error.
+func Evaluate(expression string, vars map[string]float64) (float64, error) {
+	if strings.TrimSpace(expression) == "" {
+		return 0, nil
+	}
+	deadline := time.Now().Add(Timeout)
+
+	root, err := ParseExpression(expression)
+	if err != nil {
+		return 0, err
+	}
+	e := &evaluator{vars: vars, deadline: deadline}
+	return e.eval(root)
+}
+
+// EvaluateNode evaluates an already-parsed AST against vars, bounded by
+// Timeout.
+func EvaluateNode(node Node, vars map[string]float64) (float64, error) {
+	e := &evaluator{vars: vars, deadline: time.Now().Add(Timeout)}
+	return e.eval(node)
+}
+
+type evaluator struct {
+	vars     map[string]float64
+	deadline time.Time
+}
+
+func (e *evaluator) eval(node Node) (float64, error) {
+	if time.Now().After(e.deadline) {
+		return 0, ErrTimeout
+	}
+
+	switch n := node.(type) {
+	case *NumberLiteral:
+		return n.Value, nil
+
+	case *Variable:
+		value, ok := e.vars[n.Name]
+		if !ok {
+			candidates := make([]string, 0, len(e.vars))
+			for name := range e.vars {
+				candidates = append(candidates, name)
+			}
+			return 0, &EvaluateError{Message: UnknownVariableMessage(n.Name, candidates), Pos: n.Pos}
+		}
+		return value, nil
+
+	case *UnaryOp:
+		operand, err := e.eval(n.Operand)
+		if err != nil {
+			return 0, err
+		}
+		return -operand, nil
+
+	case *BinaryOp:
+		left, err := e.eval(n.Left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := e.eval(n.Right)
+		if err != nil {
+			return 0, err
+		}
+		return applyBinary(n.Op, left, right, n.Pos)
+
+	case *FunctionCall:
+		return e.evalCall(n)
+	}
+
+	return 0, &EvaluateError{Message: fmt.Sprintf("Unhandled node type %T", node), Pos: node.Position()}
+}
+
+func applyBinary(op string, left, right float64, pos int) (float64, error) {
+	switch op {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		return left / right, nil
+	case "%":
+		// math.Mod matches JavaScript's %: the result takes the sign of the
+		// dividend, and x % 0 is NaN.
+		return math.Mod(left, right), nil
+	case "=":
+		return boolToFloat(left == right), nil
+	case "!=":
+		return boolToFloat(left != right), nil
+	case ">":
+		return boolToFloat(left > right), nil
+	case "<":
+		return boolToFloat(left < right), nil
+	case ">=":
+		return boolToFloat(left >= right), nil
+	case "<=":
+		return boolToFloat(left <= right), nil
+	}
+	return 0, &EvaluateError{Message: fmt.Sprintf("Unhandled operator: %s", op), Pos: pos}
+}
+
+func (e *evaluator) evalCall(n *FunctionCall) (float64, error) {
+	if msg := CheckArity(n.Name, len(n.Args)); msg != "" {
+		return 0, &EvaluateError{Message: msg, Pos: n.Pos}
+	}
+
+	switch n.Name {
+	case "IF":
+		condition, err := e.eval(n.Args[0])
+		if err != nil {
+			return 0, err
+		}
+		if condition != 0 {
+			return e.eval(n.Args[1])
+		}
+		return e.eval(n.Args[2])
+
+	case "MIN", "MAX":
+		pick := math.Min
+		if n.Name == "MAX" {
+			pick = math.Max
+		}
+		var result float64
+		for i, arg := range n.Args {
+			value, err := e.eval(arg)
+			if err != nil {
+				return 0, err
+			}
+			if i == 0 {
+				result = value
+			} else {
+				result = pick(result, value)
+			}
+		}
+		return result, nil
+
+	case "ABS":
+		value, err := e.eval(n.Args[0])
+		if err != nil {
+			return 0, err
+		}
+		return math.Abs(value), nil
+
+	case "ROUND":
+		value, err := e.eval(n.Args[0])
+		if err != nil {
+			return 0, err
+		}
+		if len(n.Args) == 1 {
+			return jsRound(value), nil
+		}
+		decimals, err := e.eval(n.Args[1])
+		if err != nil {
+			return 0, err
+		}
+		factor := math.Pow(10, jsRound(decimals))
+		return jsRound(value*factor) / factor, nil
+	}
+
+	return 0, &EvaluateError{Message: fmt.Sprintf("Function '%s' is not supported", n.Name), Pos: n.Pos}
+}
+
+// CheckArity returns the TypeScript engine's arity error message for a call
+// to name with argc arguments, or "" when the count is valid.
+func CheckArity(name string, argc int) string {
+	switch name {
+	case "IF":
+		if argc != 3 {
+			return fmt.Sprintf("IF requires exactly 3 arguments (condition, then, else), got %d", argc)
+		}
+	case "MIN", "MAX":
+		if argc < 1 {
+			return fmt.Sprintf("%s requires at least 1 argument, got %d", name, argc)
+		}
+	case "ABS":
+		if argc != 1 {
+			return fmt.Sprintf("ABS requires exactly 1 argument, got %d", argc)
+		}
+	case "ROUND":
+		if argc < 1 || argc > 2 {
+			return fmt.Sprintf("ROUND requires 1 or 2 arguments, got %d", argc)
+		}
+	}
+	return ""
+}
+
+// UnknownVariableMessage formats the error for a reference to an unknown
+// variable, including a "Did you mean" hint when a candidate is close enough.
+func UnknownVariableMessage(name string, candidates []string) string {
+	if suggestion, ok := ClosestName(name, candidates); ok {
+		return fmt.Sprintf("Unknown variable: {%s}. Did you mean {%s}?", name, suggestion)
+	}
+	return fmt.Sprintf("Unknown variable: {%s}", name)
+}
+
+// ClosestName returns the candidate with the smallest Levenshtein distance to
+// name, provided the distance is within max(1, len(name)/3). Ties are broken
+// lexicographically so the result does not depend on candidate order.
+func ClosestName(name string, candidates []string) (string, bool) {
+	threshold := max(1, len(name)/3)
+	best := ""
+	bestDistance := math.MaxInt
+	for _, candidate := range candidates {
+		d := levenshtein(name, candidate)
+		if d <= threshold && (d < bestDistance || (d == bestDistance && candidate < best)) {
+			bestDistance = d
+			best = candidate
+		}
+	}
+	return best, bestDistance != math.MaxInt
+}
+
+// levenshtein computes the edit distance between a and b using the standard
+// two-row dynamic-programming approach.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(curr[j-1]+1, prev[j]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// jsRound implements JavaScript's Math.round: halves round towards +Inf, and
+// the sign of zero is preserved for inputs in [-0.5, -0].
+func jsRound(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return x
+	}
+	r := math.Floor(x)
+	if x-r >= 0.5 {
+		r++
+	}
+	if r == 0 && math.Signbit(x) {
+		return math.Copysign(0, -1)
+	}
+	return r
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
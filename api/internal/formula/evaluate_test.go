@@ -0,0 +1,120 @@
+package formula
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEvaluate_Variables(t *testing.T) {
+	got, err := Evaluate("{qty} * {price}", map[string]float64{"qty": 3, "price": 2.5})
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if got != 7.5 {
+		t.Errorf("expected 7.5, got %v", got)
+	}
+}
+
+func TestEvaluate_UnknownVariableError(t *testing.T) {
+	_, err := Evaluate("1 + {qtty}", map[string]float64{"qty": 1})
+	var eerr *EvaluateError
+	if !errors.As(err, &eerr) {
+		t.Fatalf("expected *EvaluateError, got %v", err)
+	}
+	if eerr.Pos != 4 {
+		t.Errorf("expected Pos 4, got %d", eerr.Pos)
+	}
+	if eerr.Message != "Unknown variable: {qtty}. Did you mean {qty}?" {
+		t.Errorf("unexpected message %q", eerr.Message)
+	}
+}
+
+func TestEvaluate_ArityErrorPosition(t *testing.T) {
+	_, err := Evaluate("1 + ABS()", nil)
+	var eerr *EvaluateError
+	if !errors.As(err, &eerr) {
+		t.Fatalf("expected *EvaluateError, got %v", err)
+	}
+	if eerr.Pos != 4 {
+		t.Errorf("expected Pos 4, got %d", eerr.Pos)
+	}
+}
+
+func TestEvaluateNode_Timeout(t *testing.T) {
+	node, err := ParseExpression("1 + 1")
+	if err != nil {
+		t.Fatalf("ParseExpression() returned unexpected error: %v", err)
+	}
+	e := &evaluator{deadline: time.Now().Add(-time.Millisecond)}
+	if _, err := e.eval(node); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestEvaluateNode_Succeeds(t *testing.T) {
+	node, err := ParseExpression("IF({a} > 1, 10, 20)")
+	if err != nil {
+		t.Fatalf("ParseExpression() returned unexpected error: %v", err)
+	}
+	got, err := EvaluateNode(node, map[string]float64{"a": 0})
+	if err != nil {
+		t.Fatalf("EvaluateNode() returned unexpected error: %v", err)
+	}
+	if got != 20 {
+		t.Errorf("expected 20, got %v", got)
+	}
+}
+
+func TestJSRound(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{2.5, 3},
+		{-2.5, -2},
+		{-2.6, -3},
+		{0.49999999999999994, 0},
+		{math.Inf(1), math.Inf(1)},
+	}
+	for _, tt := range tests {
+		if got := jsRound(tt.in); got != tt.want {
+			t.Errorf("jsRound(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if got := jsRound(-0.4); got != 0 || !math.Signbit(got) {
+		t.Errorf("jsRound(-0.4) = %v, want -0", got)
+	}
+	if got := jsRound(math.NaN()); !math.IsNaN(got) {
+		t.Errorf("jsRound(NaN) = %v, want NaN", got)
+	}
+}
+
+func TestClosestName(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+		wantOK     bool
+	}{
+		{"numbathroom", []string{"num_bathrooms", "sqft"}, "num_bathrooms", true},
+		{"ab", []string{"ac", "aa"}, "aa", true},
+		{"zzz", []string{"qty"}, "", false},
+		{"qty", nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ClosestName(tt.name, tt.candidates)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ClosestName(%q, %v) = (%q, %v), want (%q, %v)", tt.name, tt.candidates, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCheckArity(t *testing.T) {
+	if msg := CheckArity("ROUND", 2); msg != "" {
+		t.Errorf("expected no error for ROUND/2, got %q", msg)
+	}
+	if msg := CheckArity("MAX", 0); msg != "MAX requires at least 1 argument, got 0" {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
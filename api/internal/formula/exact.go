@@ -0,0 +1,288 @@
+package formula
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRoundDecimals bounds the decimals argument of ROUND in exact
+// evaluation, where a scale of 10^n is materialised rather than overflowing.
+const maxRoundDecimals = 308
+
+// RoundingMode selects how a value is rounded to a fixed number of decimals.
+type RoundingMode string
+
+// Rounding modes accepted by Round.
+const (
+	// RoundHalfEven rounds to the nearest value, and halves to the even neighbour.
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundHalfUp rounds to the nearest value, and halves away from zero.
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor RoundingMode = "floor"
+	// RoundCeil rounds towards positive infinity.
+	RoundCeil RoundingMode = "ceil"
+)
+
+// IsRoundingMode reports whether mode is one of the supported rounding modes.
+func IsRoundingMode(mode RoundingMode) bool {
+	switch mode {
+	case RoundHalfEven, RoundHalfUp, RoundFloor, RoundCeil:
+		return true
+	}
+	return false
+}
+
+// EvaluateExact evaluates expression like Evaluate, but in exact rational
+// arithmetic, so 0.1 + 0.2 is exactly 0.3 and 1 / 3 * 3 is exactly 1. Number
+// literals are read from their source text and each value in vars is taken as
+// the shortest decimal that round-trips to it, so a float64 input of 0.1 is
+// one tenth rather than its binary approximation.
+//
+// Results differ from Evaluate only where floating point is inexact, with two
+// exceptions: division or remainder by zero, which Evaluate lets become ±Inf
+// or NaN, is an *EvaluateError; and ROUND rounds the exact value, so
+// ROUND(1.005, 2) is 1.01 rather than JavaScript's 1.
+//
+// The returned error is a *TokenizeError, *ParseError, *EvaluateError, or
+// ErrTimeout.
+func EvaluateExact(expression string, vars map[string]float64) (*big.Rat, error) {
+	if strings.TrimSpace(expression) == "" {
+		return new(big.Rat), nil
+	}
+	deadline := time.Now().Add(Timeout)
+
+	root, err := ParseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	e := &exactEvaluator{vars: vars, deadline: deadline}
+	return e.eval(root)
+}
+
+type exactEvaluator struct {
+	vars     map[string]float64
+	deadline time.Time
+}
+
+func (e *exactEvaluator) eval(node Node) (*big.Rat, error) {
+	if time.Now().After(e.deadline) {
+		return nil, ErrTimeout
+	}
+
+	switch n := node.(type) {
+	case *NumberLiteral:
+		r, ok := new(big.Rat).SetString(n.Raw)
+		if !ok {
+			return nil, &EvaluateError{Message: fmt.Sprintf("Invalid number literal '%s'", n.Raw), Pos: n.Pos}
+		}
+		return r, nil
+
+	case *Variable:
+		value, ok := e.vars[n.Name]
+		if !ok {
+			candidates := make([]string, 0, len(e.vars))
+			for name := range e.vars {
+				candidates = append(candidates, name)
+			}
+			return nil, &EvaluateError{Message: UnknownVariableMessage(n.Name, candidates), Pos: n.Pos}
+		}
+		r, ok := RatFromFloat(value)
+		if !ok {
+			return nil, &EvaluateError{Message: fmt.Sprintf("Variable {%s} is not a finite number", n.Name), Pos: n.Pos}
+		}
+		return r, nil
+
+	case *UnaryOp:
+		operand, err := e.eval(n.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return operand.Neg(operand), nil
+
+	case *BinaryOp:
+		left, err := e.eval(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.eval(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return applyBinaryExact(n.Op, left, right, n.Pos)
+
+	case *FunctionCall:
+		return e.evalCall(n)
+	}
+
+	return nil, &EvaluateError{Message: fmt.Sprintf("Unhandled node type %T", node), Pos: node.Position()}
+}
+
+func applyBinaryExact(op string, left, right *big.Rat, pos int) (*big.Rat, error) {
+	switch op {
+	case "+":
+		return new(big.Rat).Add(left, right), nil
+	case "-":
+		return new(big.Rat).Sub(left, right), nil
+	case "*":
+		return new(big.Rat).Mul(left, right), nil
+	case "/":
+		if right.Sign() == 0 {
+			return nil, &EvaluateError{Message: "Division by zero", Pos: pos}
+		}
+		return new(big.Rat).Quo(left, right), nil
+	case "%":
+		if right.Sign() == 0 {
+			return nil, &EvaluateError{Message: "Division by zero", Pos: pos}
+		}
+		// As in JavaScript, the result takes the sign of the dividend:
+		// left - right * trunc(left / right).
+		q := new(big.Rat).Quo(left, right)
+		t := new(big.Int).Quo(q.Num(), q.Denom())
+		return new(big.Rat).Sub(left, new(big.Rat).Mul(right, new(big.Rat).SetInt(t))), nil
+	case "=":
+		return ratBool(left.Cmp(right) == 0), nil
+	case "!=":
+		return ratBool(left.Cmp(right) != 0), nil
+	case ">":
+		return ratBool(left.Cmp(right) > 0), nil
+	case "<":
+		return ratBool(left.Cmp(right) < 0), nil
+	case ">=":
+		return ratBool(left.Cmp(right) >= 0), nil
+	case "<=":
+		return ratBool(left.Cmp(right) <= 0), nil
+	}
+	return nil, &EvaluateError{Message: fmt.Sprintf("Unhandled operator: %s", op), Pos: pos}
+}
+
+func (e *exactEvaluator) evalCall(n *FunctionCall) (*big.Rat, error) {
+	if msg := CheckArity(n.Name, len(n.Args)); msg != "" {
+		return nil, &EvaluateError{Message: msg, Pos: n.Pos}
+	}
+
+	switch n.Name {
+	case "IF":
+		condition, err := e.eval(n.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		if condition.Sign() != 0 {
+			return e.eval(n.Args[1])
+		}
+		return e.eval(n.Args[2])
+
+	case "MIN", "MAX":
+		var result *big.Rat
+		for _, arg := range n.Args {
+			value, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			if result == nil || (n.Name == "MIN" && value.Cmp(result) < 0) || (n.Name == "MAX" && value.Cmp(result) > 0) {
+				result = value
+			}
+		}
+		return result, nil
+
+	case "ABS":
+		value, err := e.eval(n.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		return value.Abs(value), nil
+
+	case "ROUND":
+		value, err := e.eval(n.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		decimals := 0
+		if len(n.Args) == 2 {
+			d, err := e.eval(n.Args[1])
+			if err != nil {
+				return nil, err
+			}
+			// Math.round semantics for the decimals argument itself.
+			d = roundRat(d, 0, jsHalfUp)
+			if !d.Num().IsInt64() || d.Num().Int64() > maxRoundDecimals || d.Num().Int64() < -maxRoundDecimals {
+				return nil, &EvaluateError{Message: fmt.Sprintf("ROUND decimals must be between %d and %d", -maxRoundDecimals, maxRoundDecimals), Pos: n.Pos}
+			}
+			decimals = int(d.Num().Int64())
+		}
+		return roundRat(value, decimals, jsHalfUp), nil
+	}
+
+	return nil, &EvaluateError{Message: fmt.Sprintf("Function '%s' is not supported", n.Name), Pos: n.Pos}
+}
+
+// jsHalfUp is JavaScript's Math.round rule, used by the ROUND function: halves
+// round towards positive infinity. It is internal; Round offers RoundHalfUp,
+// which rounds halves away from zero as financial rounding expects.
+const jsHalfUp RoundingMode = "js_half_up"
+
+// Round returns x rounded to decimals places after the decimal point using
+// mode. decimals may be negative to round to tens, hundreds, and so on.
+// x is not modified.
+func Round(x *big.Rat, decimals int, mode RoundingMode) *big.Rat {
+	return roundRat(x, decimals, mode)
+}
+
+func roundRat(x *big.Rat, decimals int, mode RoundingMode) *big.Rat {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(decimals))), nil))
+	if decimals < 0 {
+		scale.Inv(scale)
+	}
+	scaled := new(big.Rat).Mul(x, scale)
+
+	// With a positive denominator, Euclidean division gives floor(num/den) and
+	// a remainder in [0, den).
+	q, rem := new(big.Int).DivMod(scaled.Num(), scaled.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		half := new(big.Int).Lsh(rem, 1).Cmp(scaled.Denom()) // sign of 2*rem - den
+		up := false
+		switch mode {
+		case RoundFloor:
+		case RoundCeil:
+			up = true
+		case RoundHalfEven:
+			up = half > 0 || (half == 0 && q.Bit(0) == 1)
+		case jsHalfUp:
+			up = half >= 0
+		default: // RoundHalfUp
+			up = half > 0 || (half == 0 && x.Sign() > 0)
+		}
+		if up {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return new(big.Rat).Quo(new(big.Rat).SetInt(q), scale)
+}
+
+// RatFromFloat converts v to the rational value of the shortest decimal that
+// round-trips to v. It reports false for NaN and ±Inf.
+func RatFromFloat(v float64) (*big.Rat, bool) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+	return r, ok
+}
+
+func ratBool(b bool) *big.Rat {
+	if b {
+		return big.NewRat(1, 1)
+	}
+	return new(big.Rat)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package formula
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestEvaluateExact(t *testing.T) {
+	tests := []struct {
+		expr string
+		vars map[string]float64
+		want string // exact value as a fraction
+	}{
+		{"0.1 + 0.2", nil, "3/10"},
+		{"1 / 3 * 3", nil, "1"},
+		{"{price} * 3", map[string]float64{"price": 0.1}, "3/10"},
+		{"1.1 * 1.1", nil, "121/100"},
+		{"19.99 * 3 - 59.97", nil, "0"},
+		{"-7 % 3", nil, "-1"},
+		{"5.5 % 2", nil, "3/2"},
+		{"ROUND(1.005, 2)", nil, "101/100"},
+		{"ROUND(-2.5)", nil, "-2"},
+		{"ROUND(1250, -2)", nil, "1300"},
+		{"MAX(0.3, 0.1 + 0.2) = 0.3", nil, "1"},
+		{"MIN({a}, -{a})", map[string]float64{"a": 2}, "-2"},
+		{"IF(0.1 + 0.2 = 0.3, 1, 2)", nil, "1"},
+		{"ABS(-0.5)", nil, "1/2"},
+		{"", nil, "0"},
+	}
+	for _, tt := range tests {
+		got, err := EvaluateExact(tt.expr, tt.vars)
+		if err != nil {
+			t.Errorf("EvaluateExact(%q) returned unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if got.RatString() != tt.want {
+			t.Errorf("EvaluateExact(%q) = %s, want %s", tt.expr, got.RatString(), tt.want)
+		}
+	}
+}
+
+func TestEvaluateExact_DivisionByZero(t *testing.T) {
+	for _, expr := range []string{"1 + 1 / 0", "1 + 5 % (2 - 2)"} {
+		_, err := EvaluateExact(expr, nil)
+		var eerr *EvaluateError
+		if !errors.As(err, &eerr) || eerr.Message != "Division by zero" {
+			t.Errorf("EvaluateExact(%q): expected division by zero error, got %v", expr, err)
+		}
+	}
+}
+
+func TestEvaluateExact_Errors(t *testing.T) {
+	_, err := EvaluateExact("{qtty}", map[string]float64{"qty": 1})
+	var eerr *EvaluateError
+	if !errors.As(err, &eerr) || eerr.Message != "Unknown variable: {qtty}. Did you mean {qty}?" {
+		t.Errorf("expected unknown variable error, got %v", err)
+	}
+	if _, err := EvaluateExact("ROUND(1, 1000)", nil); !errors.As(err, &eerr) {
+		t.Errorf("expected out-of-range ROUND decimals to fail, got %v", err)
+	}
+	var perr *ParseError
+	if _, err := EvaluateExact("1 +", nil); !errors.As(err, &perr) {
+		t.Errorf("expected *ParseError, got %v", err)
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		value    string
+		decimals int
+		mode     RoundingMode
+		want     string
+	}{
+		{"2.675", 2, RoundHalfEven, "2.68"},
+		{"2.665", 2, RoundHalfEven, "2.66"},
+		{"2.5", 0, RoundHalfEven, "2.00"},
+		{"-2.5", 0, RoundHalfEven, "-2.00"},
+		{"3.5", 0, RoundHalfEven, "4.00"},
+		{"2.5", 0, RoundHalfUp, "3.00"},
+		{"-2.5", 0, RoundHalfUp, "-3.00"},
+		{"2.449", 1, RoundHalfUp, "2.40"},
+		{"1.001", 2, RoundCeil, "1.01"},
+		{"-1.001", 2, RoundCeil, "-1.00"},
+		{"1.009", 2, RoundFloor, "1.00"},
+		{"-1.001", 2, RoundFloor, "-1.01"},
+		{"1.10", 2, RoundFloor, "1.10"},
+		{"12345", -2, RoundHalfEven, "12300.00"},
+		{"1/3", 2, RoundHalfUp, "0.33"},
+		{"2/3", 2, RoundFloor, "0.66"},
+	}
+	for _, tt := range tests {
+		x, _ := new(big.Rat).SetString(tt.value)
+		if got := Round(x, tt.decimals, tt.mode).FloatString(2); got != tt.want {
+			t.Errorf("Round(%s, %d, %s) = %s, want %s", tt.value, tt.decimals, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestRatFromFloat(t *testing.T) {
+	if r, ok := RatFromFloat(0.1); !ok || r.RatString() != "1/10" {
+		t.Errorf("expected 0.1 to convert to exactly 1/10, got %v", r)
+	}
+	if r, ok := RatFromFloat(1e21); !ok || r.RatString() != "1000000000000000000000" {
+		t.Errorf("expected 1e21 to convert exactly, got %v", r)
+	}
+	if _, ok := RatFromFloat(math.Inf(1)); ok {
+		t.Error("expected +Inf to be rejected")
+	}
+}
@@ -0,0 +1,171 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity ranks a Diagnostic. Errors stop an expression from evaluating;
+// warnings flag expressions that evaluate but are almost certainly wrong.
+type Severity string
+
+// Diagnostic severities.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic codes reported by Lint.
+const (
+	CodeParseError      = "parse_error"
+	CodeUnknownVariable = "unknown_variable"
+	CodeUnknownFunction = "unknown_function"
+	CodeWrongArity      = "wrong_arity"
+	CodeDivisionByZero  = "division_by_zero"
+)
+
+// Diagnostic is a single problem found in an expression by Lint.
+type Diagnostic struct {
+	Code     string
+	Severity Severity
+	Message  string
+	Pos      int // byte offset in the expression
+	// Suggestion is the name the author probably meant, for unknown variables
+	// and functions, or "" when nothing is close enough.
+	Suggestion string
+}
+
+// Lint statically analyses expression against the variable names a
+// calculator defines and returns every problem it finds, ordered by position.
+// An empty expression has no diagnostics.
+//
+// Unlike Evaluate, which stops at the first error, Lint reports all unknown
+// variables, arity errors, and divisions by a literal zero in an expression
+// that parses. Syntax errors stop analysis, as nothing after them can be
+// trusted; unknown function names are all reported before parsing, so a typo
+// in one call does not hide another.
+func Lint(expression string, variables []string) []Diagnostic {
+	if strings.TrimSpace(expression) == "" {
+		return nil
+	}
+	tokens, err := Tokenize(expression)
+	if err != nil {
+		return []Diagnostic{syntaxDiagnostic(err)}
+	}
+	if diags := lintFunctionNames(tokens); len(diags) > 0 {
+		return diags
+	}
+	root, err := Parse(tokens)
+	if err != nil {
+		return []Diagnostic{syntaxDiagnostic(err)}
+	}
+
+	l := &linter{variables: variables, known: make(map[string]bool, len(variables))}
+	for _, name := range variables {
+		l.known[name] = true
+	}
+	l.walk(root)
+	sort.SliceStable(l.diags, func(i, j int) bool { return l.diags[i].Pos < l.diags[j].Pos })
+	return l.diags
+}
+
+// syntaxDiagnostic converts a *TokenizeError or *ParseError into a diagnostic.
+func syntaxDiagnostic(err error) Diagnostic {
+	d := Diagnostic{Code: CodeParseError, Severity: SeverityError, Message: err.Error()}
+	var terr *TokenizeError
+	var perr *ParseError
+	if errors.As(err, &terr) {
+		d.Pos = terr.Pos
+	} else if errors.As(err, &perr) {
+		d.Pos = perr.Pos
+	}
+	return d
+}
+
+// lintFunctionNames reports every call to a function that is not on the
+// allow-list, suggesting the closest allowed name.
+func lintFunctionNames(tokens []Token) []Diagnostic {
+	names := make([]string, 0, len(functionNames))
+	for name := range functionNames {
+		names = append(names, name)
+	}
+	var diags []Diagnostic
+	for i, tok := range tokens[:len(tokens)-1] {
+		if tok.Kind != TokenIdent || tokens[i+1].Kind != TokenLParen || IsFunctionName(tok.Value) {
+			continue
+		}
+		d := Diagnostic{
+			Code:     CodeUnknownFunction,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Unknown function: %s", tok.Value),
+			Pos:      tok.Pos,
+		}
+		// Function names are upper case, so compare case-insensitively to
+		// catch round() as well as ROUDN().
+		if suggestion, ok := ClosestName(strings.ToUpper(tok.Value), names); ok {
+			d.Message += fmt.Sprintf(". Did you mean %s?", suggestion)
+			d.Suggestion = suggestion
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+type linter struct {
+	variables []string
+	known     map[string]bool
+	diags     []Diagnostic
+}
+
+func (l *linter) walk(node Node) {
+	switch n := node.(type) {
+	case *Variable:
+		if l.known[n.Name] {
+			return
+		}
+		suggestion, _ := ClosestName(n.Name, l.variables)
+		l.diags = append(l.diags, Diagnostic{
+			Code:       CodeUnknownVariable,
+			Severity:   SeverityError,
+			Message:    UnknownVariableMessage(n.Name, l.variables),
+			Pos:        n.Pos,
+			Suggestion: suggestion,
+		})
+
+	case *UnaryOp:
+		l.walk(n.Operand)
+
+	case *BinaryOp:
+		l.walk(n.Left)
+		l.walk(n.Right)
+		if (n.Op == "/" || n.Op == "%") && isLiteralZero(n.Right) {
+			l.diags = append(l.diags, Diagnostic{
+				Code:     CodeDivisionByZero,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Division by zero: '%s 0' never produces a finite result", n.Op),
+				Pos:      n.Pos,
+			})
+		}
+
+	case *FunctionCall:
+		if msg := CheckArity(n.Name, len(n.Args)); msg != "" {
+			l.diags = append(l.diags, Diagnostic{Code: CodeWrongArity, Severity: SeverityError, Message: msg, Pos: n.Pos})
+		}
+		for _, arg := range n.Args {
+			l.walk(arg)
+		}
+	}
+}
+
+// isLiteralZero reports whether node is the literal 0, possibly negated.
+func isLiteralZero(node Node) bool {
+	switch n := node.(type) {
+	case *NumberLiteral:
+		return n.Value == 0
+	case *UnaryOp:
+		return isLiteralZero(n.Operand)
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package formula
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint_Clean(t *testing.T) {
+	for _, expr := range []string{"", "   ", "{qty} * {price}", "ROUND({qty} / 3, 2)", "{qty} / 0.5"} {
+		if diags := Lint(expr, []string{"qty", "price"}); len(diags) != 0 {
+			t.Errorf("Lint(%q) = %+v, want no diagnostics", expr, diags)
+		}
+	}
+}
+
+func TestLint_UnknownVariables(t *testing.T) {
+	diags := Lint("{numbathrooms} * 50 + {sqft}", []string{"num_bathrooms", "square_feet"})
+	want := []Diagnostic{
+		{
+			Code:       CodeUnknownVariable,
+			Severity:   SeverityError,
+			Message:    "Unknown variable: {numbathrooms}. Did you mean {num_bathrooms}?",
+			Pos:        0,
+			Suggestion: "num_bathrooms",
+		},
+		{
+			Code:     CodeUnknownVariable,
+			Severity: SeverityError,
+			Message:  "Unknown variable: {sqft}",
+			Pos:      22,
+		},
+	}
+	if !reflect.DeepEqual(diags, want) {
+		t.Errorf("Lint() =\n%+v\nwant\n%+v", diags, want)
+	}
+}
+
+func TestLint_UnknownFunctions(t *testing.T) {
+	diags := Lint("round({qty}) + SUM(1, 2)", []string{"qty"})
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", diags)
+	}
+	if diags[0].Code != CodeUnknownFunction || diags[0].Suggestion != "ROUND" || diags[0].Message != "Unknown function: round. Did you mean ROUND?" {
+		t.Errorf("unexpected first diagnostic %+v", diags[0])
+	}
+	if diags[1].Pos != 15 || diags[1].Suggestion != "" {
+		t.Errorf("unexpected second diagnostic %+v", diags[1])
+	}
+}
+
+func TestLint_ArityAndDivision(t *testing.T) {
+	diags := Lint("IF({a}, 1) + {a} / -(0) + ABS()", []string{"a"})
+	var codes []string
+	var positions []int
+	for _, d := range diags {
+		codes = append(codes, d.Code)
+		positions = append(positions, d.Pos)
+	}
+	if want := []string{CodeWrongArity, CodeDivisionByZero, CodeWrongArity}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("expected codes %v, got %v", want, codes)
+	}
+	if want := []int{0, 17, 26}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("expected positions %v, got %v", want, positions)
+	}
+	if diags[1].Severity != SeverityWarning {
+		t.Errorf("expected division by zero to be a warning, got %q", diags[1].Severity)
+	}
+}
+
+func TestLint_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"1 + {qty", 4},
+		{"1 + * 2", 4},
+		{"(1 + 2", 6},
+	}
+	for _, tt := range tests {
+		diags := Lint(tt.expr, []string{"qty"})
+		if len(diags) != 1 || diags[0].Code != CodeParseError || diags[0].Pos != tt.pos {
+			t.Errorf("Lint(%q) = %+v, want one parse_error at %d", tt.expr, diags, tt.pos)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ParseError is returned when the token sequence does not match the grammar,
+// including unknown identifiers, unknown function names, or mismatched
+// parentheses.
+type ParseError struct {
+	Message string
+	Pos     int // byte offset of the offending token
+}
+
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+// functionNames is the allow-list of callable functions.
+var functionNames = map[string]bool{"IF": true, "MIN": true, "MAX": true, "ABS": true, "ROUND": true}
+
+// IsFunctionName reports whether name is an allow-listed function.
+func IsFunctionName(name string) bool {
+	return functionNames[name]
+}
+
+// parser is a single-use recursive descent parser over a token slice.
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() Token {
+	tok := p.tokens[p.pos]
+	// Never step past the trailing EOF token.
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind TokenKind) (Token, error) {
+	tok := p.peek()
+	if tok.Kind != kind {
+		return Token{}, &ParseError{
+			Message: fmt.Sprintf("Expected '%s' but got '%s'", kind, tok.Kind) + quotedValue(tok),
+			Pos:     tok.Pos,
+		}
+	}
+	return p.advance(), nil
+}
+
+// parseExpression: expression → comparison
+func (p *parser) parseExpression() (Node, error) {
+	return p.parseComparison()
+}
+
+// comparisonOps, additionOps, and multiplicationOps map token kinds to operator
+// symbols for each precedence level.
+var (
+	comparisonOps = map[TokenKind]string{
+		TokenEq: "=", TokenNeq: "!=", TokenGt: ">", TokenLt: "<", TokenGte: ">=", TokenLte: "<=",
+	}
+	additionOps       = map[TokenKind]string{TokenPlus: "+", TokenMinus: "-"}
+	multiplicationOps = map[TokenKind]string{TokenStar: "*", TokenSlash: "/", TokenPercent: "%"}
+)
+
+// parseBinaryLevel parses operand (op operand)* for the operators in ops,
+// producing a left-associative tree.
+func (p *parser) parseBinaryLevel(ops map[TokenKind]string, operand func() (Node, error)) (Node, error) {
+	node, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		op, ok := ops[tok.Kind]
+		if !ok {
+			return node, nil
+		}
+		p.advance()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		node = &BinaryOp{Op: op, Left: node, Right: right, Pos: tok.Pos}
+	}
+}
+
+// parseComparison: comparison → addition ((= | != | > | < | >= | <=) addition)*
+func (p *parser) parseComparison() (Node, error) {
+	return p.parseBinaryLevel(comparisonOps, p.parseAddition)
+}
+
+// parseAddition: addition → multiplication ((+ | -) multiplication)*
+func (p *parser) parseAddition() (Node, error) {
+	return p.parseBinaryLevel(additionOps, p.parseMultiplication)
+}
+
+// parseMultiplication: multiplication → unary ((* | / | %) unary)*
+func (p *parser) parseMultiplication() (Node, error) {
+	return p.parseBinaryLevel(multiplicationOps, p.parseUnary)
+}
+
+// parseUnary: unary → - unary | primary
+func (p *parser) parseUnary() (Node, error) {
+	if tok := p.peek(); tok.Kind == TokenMinus {
+		p.advance()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryOp{Op: "-", Operand: operand, Pos: tok.Pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary:
+//
+//	primary → NUMBER
+//	        | VARIABLE
+//	        | IDENT LPAREN args RPAREN
+//	        | LPAREN expression RPAREN
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.peek()
+
+	switch tok.Kind {
+	case TokenNumber:
+		p.advance()
+		value, err := strconv.ParseFloat(tok.Value, 64)
+		// Overlong literals overflow to ±Inf, matching JavaScript's parseFloat.
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return nil, &ParseError{Message: fmt.Sprintf("Invalid number literal '%s'", tok.Value), Pos: tok.Pos}
+		}
+		return &NumberLiteral{Value: value, Raw: tok.Value, Pos: tok.Pos}, nil
+
+	case TokenVariable:
+		p.advance()
+		return &Variable{Name: tok.Value, Pos: tok.Pos}, nil
+
+	case TokenIdent:
+		p.advance()
+		if p.peek().Kind == TokenLParen {
+			if !IsFunctionName(tok.Value) {
+				return nil, &ParseError{
+					Message: fmt.Sprintf("Unknown function name '%s': expected one of IF, MIN, MAX, ABS, ROUND", tok.Value),
+					Pos:     tok.Pos,
+				}
+			}
+			p.advance() // consume '('
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(TokenRParen); err != nil {
+				return nil, err
+			}
+			return &FunctionCall{Name: tok.Value, Args: args, Pos: tok.Pos}, nil
+		}
+		return nil, &ParseError{
+			Message: fmt.Sprintf("Unknown identifier '%s': bare identifiers are not allowed; "+
+				"use variable references (e.g. {%s}) or one of the allowed "+
+				"function names: IF, MIN, MAX, ABS, ROUND", tok.Value, tok.Value),
+			Pos: tok.Pos,
+		}
+
+	case TokenLParen:
+		p.advance()
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(TokenRParen); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	return nil, &ParseError{
+		Message: fmt.Sprintf("Unexpected token '%s'", tok.Kind) + quotedValue(tok),
+		Pos:     tok.Pos,
+	}
+}
+
+// parseArgs: args → ε | expression (, expression)*
+//
+// Parses function arguments after '(' has been consumed, without consuming the
+// closing ')'. Zero-argument calls such as MIN() parse successfully so the
+// evaluator's arity checks produce domain-appropriate messages.
+func (p *parser) parseArgs() ([]Node, error) {
+	if p.peek().Kind == TokenRParen {
+		return []Node{}, nil
+	}
+	first, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	args := []Node{first}
+	for p.peek().Kind == TokenComma {
+		p.advance()
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// quotedValue formats a token's value as " ('value')" for error messages, or
+// returns "" for tokens without a value (EOF).
+func quotedValue(tok Token) string {
+	if tok.Value == "" {
+		return ""
+	}
+	return fmt.Sprintf(" ('%s')", tok.Value)
+}
+
+// Parse converts a token slice (as produced by Tokenize) into an AST.
+//
+// Supported grammar:
+//   - Numeric literals: 42, 3.14
+//   - Variable references: {name}
+//   - Arithmetic: + - * / % (with standard precedence)
+//   - Comparison: = != > < >= <= (lower precedence than arithmetic)
+//   - Unary minus: -expr
+//   - Function calls: IF(…) MIN(…) MAX(…) ABS(…) ROUND(…)
+//   - Parenthesised grouping: (expr)
+//
+// Any other identifier is a *ParseError.
+func Parse(tokens []Token) (Node, error) {
+	if len(tokens) == 0 || tokens[len(tokens)-1].Kind != TokenEOF {
+		return nil, &ParseError{Message: "Token stream must end with EOF"}
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.Kind != TokenEOF {
+		return nil, &ParseError{
+			Message: fmt.Sprintf("Unexpected token after expression: '%s'", next.Kind) + quotedValue(next),
+			Pos:     next.Pos,
+		}
+	}
+	return root, nil
+}
+
+// ParseExpression tokenizes and parses expression in one step. The returned
+// error is a *TokenizeError or *ParseError.
+func ParseExpression(expression string) (Node, error) {
+	tokens, err := Tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(tokens)
+}
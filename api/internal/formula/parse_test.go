@@ -0,0 +1,98 @@
+package formula
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseExpression_Precedence(t *testing.T) {
+	node, err := ParseExpression("1 + 2 * 3 > 4")
+	if err != nil {
+		t.Fatalf("ParseExpression() returned unexpected error: %v", err)
+	}
+	cmp, ok := node.(*BinaryOp)
+	if !ok || cmp.Op != ">" {
+		t.Fatalf("expected root '>' BinaryOp, got %#v", node)
+	}
+	add, ok := cmp.Left.(*BinaryOp)
+	if !ok || add.Op != "+" {
+		t.Fatalf("expected '+' on the left of '>', got %#v", cmp.Left)
+	}
+	mul, ok := add.Right.(*BinaryOp)
+	if !ok || mul.Op != "*" {
+		t.Fatalf("expected '*' on the right of '+', got %#v", add.Right)
+	}
+	if mul.Pos != 6 {
+		t.Errorf("expected '*' at position 6, got %d", mul.Pos)
+	}
+}
+
+func TestParseExpression_FunctionCall(t *testing.T) {
+	node, err := ParseExpression("ROUND({total}, 2)")
+	if err != nil {
+		t.Fatalf("ParseExpression() returned unexpected error: %v", err)
+	}
+	call, ok := node.(*FunctionCall)
+	if !ok || call.Name != "ROUND" || len(call.Args) != 2 {
+		t.Fatalf("expected ROUND call with 2 args, got %#v", node)
+	}
+	if v, ok := call.Args[0].(*Variable); !ok || v.Name != "total" || v.Pos != 6 {
+		t.Errorf("unexpected first argument %#v", call.Args[0])
+	}
+	if n, ok := call.Args[1].(*NumberLiteral); !ok || n.Value != 2 || n.Raw != "2" {
+		t.Errorf("unexpected second argument %#v", call.Args[1])
+	}
+}
+
+func TestParseExpression_ZeroArgumentCall(t *testing.T) {
+	node, err := ParseExpression("MIN()")
+	if err != nil {
+		t.Fatalf("ParseExpression() returned unexpected error: %v", err)
+	}
+	if call, ok := node.(*FunctionCall); !ok || len(call.Args) != 0 {
+		t.Errorf("expected zero-argument call, got %#v", node)
+	}
+}
+
+func TestParseExpression_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantPos int
+	}{
+		{"unknown function", "1 + SQRT(4)", 4},
+		{"bare identifier", "2 * qty", 4},
+		{"trailing operator", "1 +", 3},
+		{"unclosed parenthesis", "(1 + 2", 6},
+		{"trailing token", "1 2", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseExpression(tt.input)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if perr.Pos != tt.wantPos {
+				t.Errorf("expected Pos %d, got %d", tt.wantPos, perr.Pos)
+			}
+		})
+	}
+}
+
+func TestParse_RequiresEOF(t *testing.T) {
+	if _, err := Parse([]Token{{Kind: TokenNumber, Value: "1"}}); err == nil {
+		t.Fatal("expected error for token stream without EOF, got nil")
+	}
+	if _, err := Parse(nil); err == nil {
+		t.Fatal("expected error for empty token stream, got nil")
+	}
+}
+
+func TestParseExpression_TokenizeErrorPassesThrough(t *testing.T) {
+	_, err := ParseExpression("{qty")
+	var terr *TokenizeError
+	if !errors.As(err, &terr) {
+		t.Fatalf("expected *TokenizeError, got %v", err)
+	}
+}
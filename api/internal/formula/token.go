@@ -0,0 +1,51 @@
+// Package formula is the server-side port of the TypeScript formula engine in
+// packages/formula-engine. It tokenizes, parses, and evaluates calculator
+// output expressions with the same grammar, semantics, and error messages, so
+// the API can compute results for headless clients without a browser.
+//
+// The conformance cases in packages/formula-engine/conformance/cases.json are
+// exercised by both engines to keep them in lockstep.
+package formula
+
+// TokenKind is the kind of a lexical token produced by Tokenize.
+//
+// IDENT is used for both keywords (IF, MIN, MAX, ABS, ROUND) and user-defined
+// identifiers. Distinguishing keywords from identifiers is the parser's
+// responsibility.
+type TokenKind string
+
+// Token kinds. The string values match the TypeScript TokenKind union so error
+// messages are identical across engines.
+const (
+	TokenNumber   TokenKind = "NUMBER"   // numeric literal: e.g. 42, 3.14
+	TokenIdent    TokenKind = "IDENT"    // identifier or keyword: e.g. IF, foo
+	TokenVariable TokenKind = "VARIABLE" // field reference: {field_name} — value stored without braces
+	TokenPlus     TokenKind = "PLUS"     // +
+	TokenMinus    TokenKind = "MINUS"    // -
+	TokenStar     TokenKind = "STAR"     // *
+	TokenSlash    TokenKind = "SLASH"    // /
+	TokenPercent  TokenKind = "PERCENT"  // %
+	TokenEq       TokenKind = "EQ"       // =
+	TokenNeq      TokenKind = "NEQ"      // !=
+	TokenGt       TokenKind = "GT"       // >
+	TokenLt       TokenKind = "LT"       // <
+	TokenGte      TokenKind = "GTE"      // >=
+	TokenLte      TokenKind = "LTE"      // <=
+	TokenLParen   TokenKind = "LPAREN"   // (
+	TokenRParen   TokenKind = "RPAREN"   // )
+	TokenComma    TokenKind = "COMMA"    // ,
+	TokenEOF      TokenKind = "EOF"      // end of input
+)
+
+// Token is a single lexical token.
+type Token struct {
+	// Kind is the kind of token.
+	Kind TokenKind
+
+	// Value is the raw text of the token. For VARIABLE tokens this is the field
+	// name without the surrounding braces. For EOF it is empty.
+	Value string
+
+	// Pos is the byte offset of the token's first character in the input.
+	Pos int
+}
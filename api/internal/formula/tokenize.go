@@ -0,0 +1,186 @@
+package formula
+
+import "fmt"
+
+// TokenizeError is returned when the tokenizer encounters input it cannot
+// produce a valid token from — for example, an unclosed variable reference or
+// an unrecognized character.
+type TokenizeError struct {
+	Message string
+	Pos     int // byte offset of the offending character
+}
+
+func (e *TokenizeError) Error() string {
+	return e.Message
+}
+
+// Tokenize converts a formula expression into a flat slice of tokens.
+//
+// Rules:
+//   - Whitespace (space, tab, newline, carriage return) is skipped.
+//   - Numbers start with a digit and may include a single decimal point
+//     followed by more digits. Leading-decimal numbers like .5 are rejected.
+//   - Identifiers start with an ASCII letter and continue with letters,
+//     digits, or underscores.
+//   - Variables are written as {name}; the token value is the name without
+//     braces. An unclosed or empty reference is an error.
+//   - Two-character operators (!=, >=, <=) are matched before their
+//     single-character counterparts. A lone ! is an error.
+//   - The returned slice always ends with an EOF token.
+func Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	pos := 0
+
+	for pos < len(input) {
+		ch := input[pos]
+
+		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
+			pos++
+			continue
+		}
+
+		// Number: must start with a digit.
+		if isDigit(ch) {
+			start := pos
+			for pos < len(input) && isDigit(input[pos]) {
+				pos++
+			}
+			// Optional decimal portion — must have at least one digit after '.'.
+			if pos < len(input) && input[pos] == '.' {
+				dotPos := pos
+				pos++
+				if pos >= len(input) || !isDigit(input[pos]) {
+					return nil, &TokenizeError{
+						Message: fmt.Sprintf("Invalid number literal: trailing '.' at position %d", dotPos),
+						Pos:     dotPos,
+					}
+				}
+				for pos < len(input) && isDigit(input[pos]) {
+					pos++
+				}
+			}
+			tokens = append(tokens, Token{Kind: TokenNumber, Value: input[start:pos], Pos: start})
+			continue
+		}
+
+		// Identifier: starts with a letter.
+		if isLetter(ch) {
+			start := pos
+			for pos < len(input) && isIdentChar(input[pos]) {
+				pos++
+			}
+			tokens = append(tokens, Token{Kind: TokenIdent, Value: input[start:pos], Pos: start})
+			continue
+		}
+
+		// Variable: {name}
+		if ch == '{' {
+			open := pos
+			pos++
+			start := pos
+			for pos < len(input) && isIdentChar(input[pos]) {
+				pos++
+			}
+			if pos == start {
+				return nil, &TokenizeError{
+					Message: fmt.Sprintf("Empty variable name at position %d: variable references must be non-empty", open),
+					Pos:     open,
+				}
+			}
+			if pos >= len(input) || input[pos] != '}' {
+				return nil, &TokenizeError{
+					Message: fmt.Sprintf("Unclosed variable reference starting at position %d", open),
+					Pos:     open,
+				}
+			}
+			name := input[start:pos]
+			pos++ // consume '}'
+			tokens = append(tokens, Token{Kind: TokenVariable, Value: name, Pos: open})
+			continue
+		}
+
+		if pos+1 < len(input) {
+			var kind TokenKind
+			switch input[pos : pos+2] {
+			case "!=":
+				kind = TokenNeq
+			case ">=":
+				kind = TokenGte
+			case "<=":
+				kind = TokenLte
+			}
+			if kind != "" {
+				tokens = append(tokens, Token{Kind: kind, Value: input[pos : pos+2], Pos: pos})
+				pos += 2
+				continue
+			}
+		}
+
+		if kind, ok := singleCharToken(ch); ok {
+			tokens = append(tokens, Token{Kind: kind, Value: string(ch), Pos: pos})
+			pos++
+			continue
+		}
+
+		if ch == '!' {
+			return nil, &TokenizeError{
+				Message: fmt.Sprintf("Unexpected '!' at position %d: expected '!=' but found '!' alone", pos),
+				Pos:     pos,
+			}
+		}
+
+		// Report the whole (possibly multi-byte) character, as the TypeScript
+		// engine reports the full code unit rather than a byte fragment.
+		r := []rune(input[pos:])[0]
+		return nil, &TokenizeError{
+			Message: fmt.Sprintf("Unrecognized character '%c' at position %d", r, pos),
+			Pos:     pos,
+		}
+	}
+
+	tokens = append(tokens, Token{Kind: TokenEOF, Value: "", Pos: len(input)})
+	return tokens, nil
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isLetter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isIdentChar(ch byte) bool {
+	return isLetter(ch) || isDigit(ch) || ch == '_'
+}
+
+// singleCharToken returns the TokenKind for a recognized single-character
+// operator. It does not handle '!' (that requires lookahead).
+func singleCharToken(ch byte) (TokenKind, bool) {
+	switch ch {
+	case '+':
+		return TokenPlus, true
+	case '-':
+		return TokenMinus, true
+	case '*':
+		return TokenStar, true
+	case '/':
+		return TokenSlash, true
+	case '%':
+		return TokenPercent, true
+	case '=':
+		return TokenEq, true
+	case '>':
+		return TokenGt, true
+	case '<':
+		return TokenLt, true
+	case '(':
+		return TokenLParen, true
+	case ')':
+		return TokenRParen, true
+	case ',':
+		return TokenComma, true
+	default:
+		return "", false
+	}
+}
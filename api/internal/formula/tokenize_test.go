@@ -0,0 +1,66 @@
+package formula
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize_Tokens(t *testing.T) {
+	tokens, err := Tokenize("{qty} * 2.5 >= MAX(1, 3)")
+	if err != nil {
+		t.Fatalf("Tokenize() returned unexpected error: %v", err)
+	}
+	want := []Token{
+		{Kind: TokenVariable, Value: "qty", Pos: 0},
+		{Kind: TokenStar, Value: "*", Pos: 6},
+		{Kind: TokenNumber, Value: "2.5", Pos: 8},
+		{Kind: TokenGte, Value: ">=", Pos: 12},
+		{Kind: TokenIdent, Value: "MAX", Pos: 15},
+		{Kind: TokenLParen, Value: "(", Pos: 18},
+		{Kind: TokenNumber, Value: "1", Pos: 19},
+		{Kind: TokenComma, Value: ",", Pos: 20},
+		{Kind: TokenNumber, Value: "3", Pos: 22},
+		{Kind: TokenRParen, Value: ")", Pos: 23},
+		{Kind: TokenEOF, Value: "", Pos: 24},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("Tokenize() =\n%+v\nwant\n%+v", tokens, want)
+	}
+}
+
+func TestTokenize_EmptyInput(t *testing.T) {
+	tokens, err := Tokenize("")
+	if err != nil {
+		t.Fatalf("Tokenize() returned unexpected error: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Kind != TokenEOF {
+		t.Errorf("expected a single EOF token, got %+v", tokens)
+	}
+}
+
+func TestTokenize_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantPos int
+	}{
+		{"trailing decimal point", "1 + 5.", 5},
+		{"unclosed variable", "2 * {qty", 4},
+		{"empty variable", "{}", 0},
+		{"lone bang", "1 ! 2", 2},
+		{"unrecognized character", "1 # 2", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Tokenize(tt.input)
+			var terr *TokenizeError
+			if !errors.As(err, &terr) {
+				t.Fatalf("expected *TokenizeError, got %v", err)
+			}
+			if terr.Pos != tt.wantPos {
+				t.Errorf("expected Pos %d, got %d", tt.wantPos, terr.Pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Diff returns the RFC 6902 operations that transform from into to, or an
+// empty slice if the documents are equal. Object members are visited in
+// sorted key order so the result is deterministic. Arrays are compared
+// index by index, with trailing elements added or removed; moves are never
+// detected.
+func Diff(from, to []byte) ([]Operation, error) {
+	a, err := decode(from)
+	if err != nil {
+		return nil, fmt.Errorf("decoding source document: %w", err)
+	}
+	b, err := decode(to)
+	if err != nil {
+		return nil, fmt.Errorf("decoding target document: %w", err)
+	}
+	ops := make([]Operation, 0)
+	if err := diffValue(&ops, "", a, b); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+func diffValue(ops *[]Operation, path string, a, b any) error {
+	if equal(a, b) {
+		return nil
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			return diffObject(ops, path, av, bv)
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			return diffArray(ops, path, av, bv)
+		}
+	}
+	return appendOp(ops, "replace", path, b)
+}
+
+func diffObject(ops *[]Operation, path string, a, b map[string]any) error {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		p := path + "/" + escapeToken(k)
+		av, inA := a[k]
+		bv, inB := b[k]
+		var err error
+		switch {
+		case !inB:
+			err = appendOp(ops, "remove", p, nil)
+		case !inA:
+			err = appendOp(ops, "add", p, bv)
+		default:
+			err = diffValue(ops, p, av, bv)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffArray(ops *[]Operation, path string, a, b []any) error {
+	shared := min(len(a), len(b))
+	for i := 0; i < shared; i++ {
+		if err := diffValue(ops, path+"/"+strconv.Itoa(i), a[i], b[i]); err != nil {
+			return err
+		}
+	}
+	for i := shared; i < len(b); i++ {
+		if err := appendOp(ops, "add", path+"/"+strconv.Itoa(i), b[i]); err != nil {
+			return err
+		}
+	}
+	// Remove from the end so earlier indices stay valid.
+	for i := len(a) - 1; i >= shared; i-- {
+		if err := appendOp(ops, "remove", path+"/"+strconv.Itoa(i), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendOp appends an operation; value is ignored for "remove".
+func appendOp(ops *[]Operation, op, path string, value any) error {
+	o := Operation{Op: op, Path: &path}
+	if op != "remove" {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encoding value at %q: %w", path, err)
+		}
+		o.Value = raw
+	}
+	*ops = append(*ops, o)
+	return nil
+}
+
+var tokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapeToken escapes a member name for use as a JSON Pointer reference token.
+func escapeToken(s string) string {
+	return tokenEscaper.Replace(s)
+}
@@ -0,0 +1,77 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiff_Operations(t *testing.T) {
+	from := `{"a":1,"b":{"c":"x","d":[1,2,3]},"gone":true,"a/b":0}`
+	to := `{"a":1,"b":{"c":"y","d":[1,5]},"new":null,"a/b":1}`
+
+	ops, err := Diff([]byte(from), []byte(to))
+	if err != nil {
+		t.Fatalf("Diff() returned unexpected error: %v", err)
+	}
+	got, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatalf("marshalling ops: %v", err)
+	}
+	want := `[{"op":"replace","path":"/a~1b","value":1},` +
+		`{"op":"replace","path":"/b/c","value":"y"},` +
+		`{"op":"replace","path":"/b/d/1","value":5},` +
+		`{"op":"remove","path":"/b/d/2"},` +
+		`{"op":"remove","path":"/gone"},` +
+		`{"op":"add","path":"/new","value":null}]`
+	if string(got) != want {
+		t.Errorf("unexpected diff:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestDiff_Equal(t *testing.T) {
+	ops, err := Diff([]byte(`{"a":[1,{"b":1.0}]}`), []byte(`{"a":[1,{"b":1}]}`))
+	if err != nil {
+		t.Fatalf("Diff() returned unexpected error: %v", err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("expected no operations for equal documents, got %+v", ops)
+	}
+}
+
+func TestDiff_RoundTrip(t *testing.T) {
+	tests := []struct{ from, to string }{
+		{`{}`, `{"fields":[{"id":"f1"}]}`},
+		{`{"fields":[{"id":"f1"},{"id":"f2"},{"id":"f3"}]}`, `{"fields":[{"id":"f3"}]}`},
+		{`[1,2]`, `{"a":1}`},
+		{`{"x~y":{"p/q":[true]}}`, `{"x~y":{"p/q":[false,null]}}`},
+		{`{"n":12345678901234567890}`, `{"n":12345678901234567891}`},
+	}
+	for _, tt := range tests {
+		ops, err := Diff([]byte(tt.from), []byte(tt.to))
+		if err != nil {
+			t.Fatalf("Diff(%s, %s) returned unexpected error: %v", tt.from, tt.to, err)
+		}
+		patch, err := json.Marshal(ops)
+		if err != nil {
+			t.Fatalf("marshalling ops: %v", err)
+		}
+		got, err := Apply([]byte(tt.from), patch)
+		if err != nil {
+			t.Fatalf("Apply(%s, %s) returned unexpected error: %v", tt.from, patch, err)
+		}
+		want, _ := decode([]byte(tt.to))
+		gotV, _ := decode(got)
+		if !equal(gotV, want) {
+			t.Errorf("round trip of %s -> %s produced %s", tt.from, tt.to, got)
+		}
+	}
+}
+
+func TestDiff_InvalidDocument(t *testing.T) {
+	if _, err := Diff([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("expected error for invalid source document, got nil")
+	}
+	if _, err := Diff([]byte(`{}`), []byte(`nope`)); err == nil {
+		t.Error("expected error for invalid target document, got nil")
+	}
+}
@@ -0,0 +1,434 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7386) and JSON Patch
+// (RFC 6902) documents to raw JSON, and computes JSON Patch diffs.
+//
+// Documents are decoded with json.Number so numeric values round-trip without
+// float64 precision loss. Objects are re-encoded with sorted keys, which is
+// harmless for JSONB storage where key order is not preserved anyway.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned (wrapped in an *Error) when a JSON Patch "test"
+// operation does not match the document.
+var ErrTestFailed = errors.New("test operation failed")
+
+// Error describes why a patch could not be applied. Index is the zero-based
+// position of the failing operation, or -1 when the patch document itself is
+// malformed.
+type Error struct {
+	Index   int
+	Op      string
+	Path    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Index < 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("operation %d (%s %q): %s", e.Index, e.Op, e.Path, e.Message)
+}
+
+// Unwrap returns the underlying sentinel, such as ErrTestFailed.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to doc and returns the
+// resulting document. Members set to null in patch are removed; objects are
+// merged recursively; any other value replaces the target outright.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding document: %w", err)
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, &Error{Index: -1, Message: "merge patch must be valid JSON"}
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergeValue(t[k], v)
+	}
+	return t
+}
+
+// Operation is a single RFC 6902 operation. Value is left raw so an explicit
+// null can be told apart from an absent member.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path,omitempty"`
+	From  *string         `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies an RFC 6902 JSON Patch to doc and returns the resulting
+// document. Operations are applied in order and the patch is atomic: if any
+// operation fails, including a "test", an *Error is returned and no result is
+// produced.
+func Apply(doc, patch []byte) ([]byte, error) {
+	root, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding document: %w", err)
+	}
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, &Error{Index: -1, Message: "JSON patch must be an array of operations"}
+	}
+	for i, op := range ops {
+		root, err = applyOp(root, op)
+		if err != nil {
+			var perr *Error
+			if errors.As(err, &perr) {
+				perr.Index, perr.Op = i, op.Op
+				if op.Path != nil {
+					perr.Path = *op.Path
+				}
+				return nil, perr
+			}
+			return nil, err
+		}
+	}
+	return json.Marshal(root)
+}
+
+func applyOp(root any, op Operation) (any, error) {
+	if op.Path == nil {
+		return nil, &Error{Message: `missing "path"`}
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, &Error{Message: `missing "value"`}
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, &Error{Message: `invalid "value"`}
+		}
+		switch op.Op {
+		case "add":
+			return add(root, path, value)
+		case "replace":
+			return replace(root, path, value)
+		default:
+			current, err := get(root, path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, &Error{Message: "value does not match", Err: ErrTestFailed}
+			}
+			return root, nil
+		}
+
+	case "remove":
+		root, _, err := remove(root, path)
+		return root, err
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, &Error{Message: `missing "from"`}
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value, err := get(root, from)
+			if err != nil {
+				return nil, err
+			}
+			return add(root, path, deepCopy(value))
+		}
+		if *op.Path == *op.From {
+			return root, nil
+		}
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, &Error{Message: "cannot move a value into one of its own children"}
+		}
+		root, value, err := remove(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, path, value)
+	}
+
+	return nil, &Error{Message: fmt.Sprintf("unknown op %q", op.Op)}
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, &Error{Message: fmt.Sprintf("invalid JSON pointer %q", p)}
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array reference token. Leading zeros are not allowed.
+// When allowEnd is set, "-" refers to the position after the last element.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, &Error{Message: fmt.Sprintf("invalid array index %q", token)}
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, &Error{Message: fmt.Sprintf("invalid array index %q", token)}
+	}
+	limit := length - 1
+	if allowEnd {
+		limit = length
+	}
+	if idx > limit {
+		return 0, &Error{Message: fmt.Sprintf("array index %d out of bounds", idx)}
+	}
+	return idx, nil
+}
+
+// get returns the value referenced by path.
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[token]
+			if !ok {
+				return nil, &Error{Message: fmt.Sprintf("member %q not found", token)}
+			}
+			node = v
+		case []any:
+			idx, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[idx]
+		default:
+			return nil, &Error{Message: fmt.Sprintf("cannot traverse into a scalar at %q", token)}
+		}
+	}
+	return node, nil
+}
+
+// mutate walks to the parent of the last token in path and calls fn with the
+// parent container and the final token. fn returns the replacement container,
+// which is written back into its own parent so slice growth is preserved.
+func mutate(node any, path []string, fn func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, &Error{Message: fmt.Sprintf("member %q not found", path[0])}
+		}
+		updated, err := mutate(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = updated
+		return n, nil
+	case []any:
+		idx, err := arrayIndex(path[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := mutate(n[idx], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = updated
+		return n, nil
+	}
+	return nil, &Error{Message: fmt.Sprintf("cannot traverse into a scalar at %q", path[0])}
+}
+
+func add(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return mutate(root, path, func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[token] = value
+			return c, nil
+		case []any:
+			idx, err := arrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[idx+1:], c[idx:])
+			c[idx] = value
+			return c, nil
+		}
+		return nil, &Error{Message: "parent is not an object or array"}
+	})
+}
+
+func replace(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return mutate(root, path, func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			if _, ok := c[token]; !ok {
+				return nil, &Error{Message: fmt.Sprintf("member %q not found", token)}
+			}
+			c[token] = value
+			return c, nil
+		case []any:
+			idx, err := arrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			c[idx] = value
+			return c, nil
+		}
+		return nil, &Error{Message: "parent is not an object or array"}
+	})
+}
+
+// remove deletes the value at path and returns the updated root along with
+// the removed value.
+func remove(root any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, &Error{Message: "cannot remove the document root"}
+	}
+	var removed any
+	updated, err := mutate(root, path, func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			v, ok := c[token]
+			if !ok {
+				return nil, &Error{Message: fmt.Sprintf("member %q not found", token)}
+			}
+			removed = v
+			delete(c, token)
+			return c, nil
+		case []any:
+			idx, err := arrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[idx]
+			return append(c[:idx], c[idx+1:]...), nil
+		}
+		return nil, &Error{Message: "parent is not an object or array"}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, removed, nil
+}
+
+// equal compares two decoded JSON values. Numbers compare by value, so 1 and
+// 1.0 are equal as RFC 6902 requires.
+func equal(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			w, ok := bv[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okx := new(big.Float).SetString(av.String())
+		y, oky := new(big.Float).SetString(bv.String())
+		return okx && oky && x.Cmp(y) == 0
+	default:
+		return a == b
+	}
+}
+
+func deepCopy(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[k] = deepCopy(val)
+		}
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, val := range t {
+			s[i] = deepCopy(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// decode parses a single JSON value, keeping numbers as json.Number.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
@@ -0,0 +1,181 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// assertJSONEqual compares two JSON documents structurally.
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("result is not valid JSON: %s", got)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("want is not valid JSON: %s", want)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// Cases from RFC 7386 Appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s) returned unexpected error: %v", tt.doc, tt.patch, err)
+			continue
+		}
+		assertJSONEqual(t, got, tt.want)
+	}
+}
+
+func TestMergePatch_InvalidPatch(t *testing.T) {
+	_, err := MergePatch([]byte(`{}`), []byte(`{`))
+	var perr *Error
+	if !errors.As(err, &perr) || perr.Index != -1 {
+		t.Errorf("expected *Error with Index -1, got: %v", err)
+	}
+}
+
+func TestMergePatch_PreservesNumberPrecision(t *testing.T) {
+	got, err := MergePatch([]byte(`{"a":12345678901234567890}`), []byte(`{"b":1}`))
+	if err != nil {
+		t.Fatalf("MergePatch() returned unexpected error: %v", err)
+	}
+	if string(got) != `{"a":12345678901234567890,"b":1}` {
+		t.Errorf("unexpected result: %s", got)
+	}
+}
+
+// Cases adapted from RFC 6902 Appendix A.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		{"add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"add to array end", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"copy value", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{"test passes", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"escaped pointer", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, `{"~1":10}`},
+		{"numbers compare by value", `{"foo":1}`, `[{"op":"test","path":"/foo","value":1.0}]`, `{"foo":1}`},
+		{"add explicit null", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply() returned unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	tests := []struct {
+		name, doc, patch string
+		index            int
+	}{
+		{"patch not an array", `{}`, `{"op":"add"}`, -1},
+		{"unknown op", `{}`, `[{"op":"frob","path":"/a"}]`, 0},
+		{"missing path", `{}`, `[{"op":"remove"}]`, 0},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, 0},
+		{"missing from", `{}`, `[{"op":"move","path":"/a"}]`, 0},
+		{"remove missing member", `{"a":1}`, `[{"op":"remove","path":"/b"}]`, 0},
+		{"replace missing member", `{"a":1}`, `[{"op":"replace","path":"/b","value":2}]`, 0},
+		{"add to missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, 0},
+		{"array index out of bounds", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/3","value":"x"}]`, 0},
+		{"leading zero index", `{"foo":["bar","baz"]}`, `[{"op":"replace","path":"/foo/01","value":"x"}]`, 0},
+		{"move into own child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, 0},
+		{"invalid pointer", `{}`, `[{"op":"add","path":"a","value":1}]`, 0},
+		{"second op fails", `{"a":1}`, `[{"op":"remove","path":"/a"},{"op":"remove","path":"/a"}]`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *Error, got: %v", err)
+			}
+			if perr.Index != tt.index {
+				t.Errorf("expected Index %d, got %d (%v)", tt.index, perr.Index, perr)
+			}
+			if errors.Is(err, ErrTestFailed) {
+				t.Errorf("did not expect ErrTestFailed: %v", err)
+			}
+		})
+	}
+}
+
+func TestApply_TestFails(t *testing.T) {
+	tests := []struct {
+		name, doc, patch string
+	}{
+		{"different string", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{"string vs number", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`},
+		{"different array length", `{"a":[1,2]}`, `[{"op":"test","path":"/a","value":[1]}]`},
+		{"object member mismatch", `{"a":{"b":1}}`, `[{"op":"test","path":"/a","value":{"c":1}}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if !errors.Is(err, ErrTestFailed) {
+				t.Errorf("expected ErrTestFailed, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestApply_TestFailureIsAtomic(t *testing.T) {
+	doc := []byte(`{"a":1}`)
+	got, err := Apply(doc, []byte(`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("expected ErrTestFailed, got: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected no result, got %s", got)
+	}
+	if string(doc) != `{"a":1}` {
+		t.Errorf("input document was modified: %s", doc)
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	err := &Error{Index: 2, Op: "remove", Path: "/a", Message: `member "a" not found`}
+	want := `operation 2 (remove "/a"): member "a" not found`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
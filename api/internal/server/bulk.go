@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// BulkJobSubmitter queues an operation against many calculators.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type BulkJobSubmitter interface {
+	SubmitBulkJob(ctx context.Context, userID string, req calculator.BulkRequest) (*calculator.BulkJob, error)
+}
+
+// BulkJobGetter retrieves a bulk job and its per-calculator outcomes.
+type BulkJobGetter interface {
+	GetBulkJob(ctx context.Context, id, userID string) (*calculator.BulkJob, error)
+}
+
+// CalculatorBulkService is the full set of bulk job capabilities consumed by the server.
+type CalculatorBulkService interface {
+	BulkJobSubmitter
+	BulkJobGetter
+}
+
+// maxBulkBodyBytes caps the size of a bulk job request body.
+const maxBulkBodyBytes = 1 << 20
+
+// bulkFilterRequest selects calculators with the same parameters as
+// GET /v1/calculators. Folder is a folder ID or "none" for unfiled.
+type bulkFilterRequest struct {
+	Q      string   `json:"q"`
+	Tags   []string `json:"tags"`
+	Folder string   `json:"folder"`
+}
+
+// bulkJobRequest is the request body for POST /v1/calculators/bulk.
+type bulkJobRequest struct {
+	IDs       []string           `json:"ids"`
+	Filter    *bulkFilterRequest `json:"filter"`
+	Operation string             `json:"operation"`
+	Patch     json.RawMessage    `json:"patch"`
+	Publish   bool               `json:"publish"`
+}
+
+// bulkItemResponse is the outcome for one calculator in a bulk job response.
+// previous_version is the revision to restore to undo a patch.
+type bulkItemResponse struct {
+	CalculatorID    string `json:"calculator_id"`
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+	PreviousVersion int    `json:"previous_version,omitempty"`
+	ConfigVersion   int    `json:"config_version,omitempty"`
+}
+
+// bulkJobResponse is the data payload returned by the bulk job endpoints.
+type bulkJobResponse struct {
+	ID         string             `json:"id"`
+	Status     string             `json:"status"`
+	Operation  string             `json:"operation"`
+	Publish    bool               `json:"publish"`
+	Total      int                `json:"total"`
+	Succeeded  int                `json:"succeeded"`
+	Failed     int                `json:"failed"`
+	Pending    int                `json:"pending"`
+	Results    []bulkItemResponse `json:"results"`
+	CreatedAt  time.Time          `json:"created_at"`
+	StartedAt  *time.Time         `json:"started_at"`
+	FinishedAt *time.Time         `json:"finished_at"`
+}
+
+// newBulkJobResponse builds the response shape for job, tallying its items.
+func newBulkJobResponse(job *calculator.BulkJob) bulkJobResponse {
+	resp := bulkJobResponse{
+		ID:         job.ID,
+		Status:     string(job.Status),
+		Operation:  string(job.Operation),
+		Publish:    job.Publish,
+		Total:      len(job.Items),
+		Results:    make([]bulkItemResponse, len(job.Items)),
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	for i, item := range job.Items {
+		switch item.Status {
+		case calculator.BulkItemSucceeded:
+			resp.Succeeded++
+		case calculator.BulkItemFailed:
+			resp.Failed++
+		default:
+			resp.Pending++
+		}
+		resp.Results[i] = bulkItemResponse{
+			CalculatorID:    item.CalculatorID,
+			Status:          string(item.Status),
+			Error:           item.Error,
+			PreviousVersion: item.PreviousVersion,
+			ConfigVersion:   item.ConfigVersion,
+		}
+	}
+	return resp
+}
+
+// submitBulkJobHandler returns an http.HandlerFunc for POST /v1/calculators/bulk.
+// The job runs in the background: the response is 202 with the queued job and
+// a Location header naming its status endpoint.
+func submitBulkJobHandler(svc BulkJobSubmitter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+
+		var req bulkJobRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes)).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		bulk := calculator.BulkRequest{
+			IDs:       req.IDs,
+			Operation: calculator.BulkOperation(req.Operation),
+			Patch:     req.Patch,
+			Publish:   req.Publish,
+		}
+		if req.Filter != nil {
+			bulk.Filter = &calculator.BulkFilter{Search: req.Filter.Q, Tags: req.Filter.Tags}
+			if req.Filter.Folder == "none" {
+				bulk.Filter.Unfiled = true
+			} else {
+				bulk.Filter.FolderID = req.Filter.Folder
+			}
+		}
+
+		job, err := svc.SubmitBulkJob(r.Context(), userID, bulk)
+		if err != nil {
+			var berr *calculator.BulkRequestError
+			if errors.As(err, &berr) {
+				WriteErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, "bulk request failed validation",
+					[]ErrorDetail{{Field: berr.Field, Message: berr.Message}})
+				return
+			}
+			LoggerFrom(r.Context()).Error("submitting bulk job", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("Location", "/v1/calculators/bulk/"+job.ID)
+		WriteJSON(w, http.StatusAccepted, newBulkJobResponse(job))
+	}
+}
+
+// getBulkJobHandler returns an http.HandlerFunc for GET /v1/calculators/bulk/{jobID}.
+// Poll it until status is "completed".
+func getBulkJobHandler(svc BulkJobGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		job, err := svc.GetBulkJob(r.Context(), chi.URLParam(r, "jobID"), userID)
+		if err != nil {
+			if errors.Is(err, calculator.ErrBulkJobNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "bulk job not found")
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting bulk job", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, newBulkJobResponse(job))
+	}
+}
+
+// MountCalculatorBulk registers the bulk job routes on the server's private authenticated group.
+func (s *Server) MountCalculatorBulk(validator TokenValidator, svc CalculatorBulkService) {
+	protected := s.Authenticated(validator)
+	protected.Post("/calculators/bulk", submitBulkJobHandler(svc))
+	protected.Get("/calculators/bulk/{jobID}", getBulkJobHandler(svc))
+}
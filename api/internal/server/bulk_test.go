@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+func TestSubmitBulkJobHandler_Accepted(t *testing.T) {
+	svc := &stubBulkService{job: &calculator.BulkJob{
+		ID:        "job-1",
+		Operation: calculator.BulkMergePatch,
+		Status:    calculator.BulkJobPending,
+		Items:     []*calculator.BulkItem{{CalculatorID: "calc-a", Status: calculator.BulkItemPending}},
+		CreatedAt: time.Now().UTC(),
+	}}
+	h := submitBulkJobHandler(svc)
+
+	body := `{"filter":{"tags":["agency"],"folder":"none"},"operation":"merge_patch","patch":{"theme":{"primaryColor":"#000000"}},"publish":true}`
+	req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/bulk", body, "", "")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if loc := rec.Header().Get("Location"); loc != "/v1/calculators/bulk/job-1" {
+		t.Errorf("unexpected Location %q", loc)
+	}
+	got := svc.gotReq
+	if got.Operation != calculator.BulkMergePatch || !got.Publish || got.Filter == nil || !got.Filter.Unfiled || got.Filter.Tags[0] != "agency" {
+		t.Errorf("unexpected request passed to service: %+v", got)
+	}
+	var env Envelope[bulkJobResponse]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Data.ID != "job-1" || env.Data.Status != "pending" || env.Data.Total != 1 || env.Data.Pending != 1 {
+		t.Errorf("unexpected response: %+v", env.Data)
+	}
+}
+
+func TestSubmitBulkJobHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{"invalid body", `{`, nil, http.StatusBadRequest},
+		{"validation", `{"operation":"x"}`, &calculator.BulkRequestError{Field: "operation", Message: "bad"}, http.StatusUnprocessableEntity},
+		{"internal", `{"operation":"publish","ids":[]}`, errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := submitBulkJobHandler(&stubBulkService{err: tt.err})
+
+			req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/bulk", tt.body, "", "")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetBulkJobHandler_Success(t *testing.T) {
+	now := time.Now().UTC()
+	svc := &stubBulkService{job: &calculator.BulkJob{
+		ID:         "job-1",
+		Operation:  calculator.BulkJSONPatch,
+		Status:     calculator.BulkJobCompleted,
+		CreatedAt:  now,
+		FinishedAt: &now,
+		Items: []*calculator.BulkItem{
+			{CalculatorID: "calc-a", Status: calculator.BulkItemSucceeded, PreviousVersion: 3, ConfigVersion: 4},
+			{CalculatorID: "calc-b", Status: calculator.BulkItemFailed, Error: "calculator not found"},
+		},
+	}}
+	h := getBulkJobHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/bulk/job-1", "", "jobID", "job-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[bulkJobResponse]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	d := env.Data
+	if d.Status != "completed" || d.Succeeded != 1 || d.Failed != 1 || d.Pending != 0 {
+		t.Errorf("unexpected tallies: %+v", d)
+	}
+	if d.Results[0].PreviousVersion != 3 || d.Results[1].Error != "calculator not found" {
+		t.Errorf("unexpected results: %+v", d.Results)
+	}
+}
+
+func TestGetBulkJobHandler_NotFound(t *testing.T) {
+	h := getBulkJobHandler(&stubBulkService{err: fmt.Errorf("getting bulk job: %w", calculator.ErrBulkJobNotFound)})
+
+	req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/bulk/job-1", "", "jobID", "job-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
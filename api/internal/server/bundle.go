@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// CalculatorExporter writes a calculator and its assets as a portable bundle.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorExporter interface {
+	Export(ctx context.Context, id, userID string, w io.Writer) error
+}
+
+// CalculatorImporter creates a calculator from a bundle produced by CalculatorExporter.
+type CalculatorImporter interface {
+	Import(ctx context.Context, userID string, r io.ReaderAt, size int64) (*calculator.Calculator, error)
+}
+
+// CalculatorBundleService is the full set of export and import capabilities consumed by the server.
+type CalculatorBundleService interface {
+	CalculatorExporter
+	CalculatorImporter
+}
+
+// maxImportBundleBytes caps the size of an uploaded bundle. It leaves room for
+// a config and a few dozen images at the asset upload limit.
+const maxImportBundleBytes = 64 << 20
+
+// exportCalculatorHandler returns an http.HandlerFunc for GET /v1/calculators/{id}/export.
+// The bundle is built in memory so a storage failure can still be reported as
+// an error response rather than a truncated download.
+func exportCalculatorHandler(svc CalculatorExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		var buf bytes.Buffer
+		if err := svc.Export(r.Context(), id, userID, &buf); err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("exporting calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="calculator-%s.zip"`, id))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// importCalculatorHandler returns an http.HandlerFunc for POST /v1/calculators/import.
+// It accepts a multipart form upload with the bundle in the "file" field, as
+// produced by GET /v1/calculators/{id}/export on this or another server.
+func importCalculatorHandler(svc CalculatorImporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportBundleBytes+4096)
+		// Parts beyond the in-memory threshold spill to temporary files.
+		if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid multipart form")
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "missing file field")
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxImportBundleBytes+1))
+		if err != nil {
+			LoggerFrom(r.Context()).Error("reading uploaded bundle", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		if len(data) > maxImportBundleBytes {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "bundle exceeds 64 MB limit")
+			return
+		}
+
+		calc, err := svc.Import(r.Context(), userID, bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			var berr *calculator.BundleError
+			if errors.As(err, &berr) {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, berr.Error())
+				return
+			}
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeValidationError(w, "config failed validation", verr)
+				return
+			}
+			var merr *calculator.MetadataError
+			if errors.As(err, &merr) {
+				writeMetadataError(w, merr)
+				return
+			}
+			LoggerFrom(r.Context()).Error("importing calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusCreated, createCalculatorResponse{
+			ID:        calc.ID,
+			Name:      calc.Name,
+			CreatedAt: calc.CreatedAt,
+			UpdatedAt: calc.UpdatedAt,
+		})
+	}
+}
+
+// MountCalculatorBundles registers the export and import routes on the server's private authenticated group.
+func (s *Server) MountCalculatorBundles(validator TokenValidator, svc CalculatorBundleService) {
+	protected := s.Authenticated(validator)
+	protected.Get("/calculators/{id}/export", exportCalculatorHandler(svc))
+	protected.Post("/calculators/import", importCalculatorHandler(svc))
+}
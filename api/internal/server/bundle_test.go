@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+func TestExportCalculatorHandler_Success(t *testing.T) {
+	svc := &stubBundleService{bundle: []byte("PK-bundle")}
+	h := exportCalculatorHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/export", "", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("expected Content-Type application/zip, got %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="calculator-calc-abc.zip"` {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+	if rec.Body.String() != "PK-bundle" {
+		t.Errorf("expected bundle body, got %q", rec.Body.String())
+	}
+}
+
+func TestExportCalculatorHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"not found", calculator.ErrNotFound, http.StatusNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden},
+		{"internal", errors.New("storage down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := exportCalculatorHandler(&stubBundleService{err: fmt.Errorf("getting calculator: %w", tt.err)})
+
+			req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/export", "", "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestImportCalculatorHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := &stubBundleService{calc: &calculator.Calculator{ID: "calc-new", Name: "Quote", CreatedAt: now, UpdatedAt: now}}
+	h := importCalculatorHandler(svc)
+
+	req := withUserID(buildMultipartRequest(t, "file", "calculator.zip", []byte("PK-bundle")), "user-xyz")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if string(svc.gotBundle) != "PK-bundle" {
+		t.Errorf("expected uploaded bundle passed through, got %q", svc.gotBundle)
+	}
+}
+
+func TestImportCalculatorHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		err      error
+		wantCode int
+	}{
+		{"missing file", "upload", nil, http.StatusBadRequest},
+		{"bad bundle", "file", &calculator.BundleError{Message: "not a zip archive"}, http.StatusBadRequest},
+		{"invalid config", "file", &configschema.ValidationError{Errors: []configschema.FieldError{{Path: "fields", Message: "must be an array"}}}, http.StatusUnprocessableEntity},
+		{"invalid metadata", "file", &calculator.MetadataError{Field: "tags", Message: "too many"}, http.StatusUnprocessableEntity},
+		{"internal", "file", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := importCalculatorHandler(&stubBundleService{err: tt.err})
+
+			req := withUserID(buildMultipartRequest(t, tt.field, "calculator.zip", []byte("PK")), "user-xyz")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d (body: %s)", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportCalculatorHandler_Unauthenticated(t *testing.T) {
+	h := importCalculatorHandler(&stubBundleService{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, buildMultipartRequest(t, "file", "calculator.zip", []byte("PK")))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
@@ -4,12 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/jsonpatch"
 )
 
 // CalculatorCreator creates new calculators.
@@ -18,10 +24,10 @@ type CalculatorCreator interface {
 	Create(ctx context.Context, userID string) (*calculator.Calculator, error)
 }
 
-// CalculatorLister lists calculators for the authenticated user.
+// CalculatorLister lists calculators for the authenticated user, one page at a time.
 // Defined here at the consumer (server package) per the interfaces-where-consumed convention.
 type CalculatorLister interface {
-	List(ctx context.Context, userID string) ([]*calculator.Calculator, error)
+	List(ctx context.Context, userID string, opts calculator.ListOptions) (*calculator.Page, error)
 }
 
 // CalculatorGetter gets a single calculator by ID.
@@ -29,9 +35,18 @@ type CalculatorGetter interface {
 	Get(ctx context.Context, id, userID string) (*calculator.Calculator, error)
 }
 
-// CalculatorUpdater updates the config of an existing calculator.
+// CalculatorUpdater updates the config of an existing calculator. A non-zero
+// expectedVersion makes the update conditional on the current config_version.
+// An autosave may be folded into the user's previous revision.
 type CalculatorUpdater interface {
-	Update(ctx context.Context, id, userID string, config []byte) (*calculator.Calculator, error)
+	Update(ctx context.Context, id, userID string, expectedVersion int, autosave bool, config []byte) (*calculator.Calculator, error)
+}
+
+// CalculatorPatcher applies a JSON Merge Patch or JSON Patch to the config of an
+// existing calculator. A non-zero expectedVersion makes the patch conditional on
+// the current config_version.
+type CalculatorPatcher interface {
+	Patch(ctx context.Context, id, userID string, expectedVersion int, format calculator.PatchFormat, patch []byte) (*calculator.Calculator, error)
 }
 
 // CalculatorDeleter soft-deletes an existing calculator.
@@ -50,6 +65,7 @@ type CalculatorService interface {
 	CalculatorLister
 	CalculatorGetter
 	CalculatorUpdater
+	CalculatorPatcher
 	CalculatorDeleter
 	CalculatorDuplicator
 }
@@ -64,10 +80,35 @@ type createCalculatorResponse struct {
 
 // calculatorSummary is the per-item shape in a list response.
 type calculatorSummary struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	Tags             []string   `json:"tags"`
+	FolderID         *string    `json:"folder_id"`
+	MetadataVersion  int        `json:"metadata_version"`
+	ConfigVersion    int        `json:"config_version"`
+	PublishedVersion int        `json:"published_version"`
+	PublishedAt      *time.Time `json:"published_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// responseTags returns tags for a response, using an empty array rather than
+// null when there are none.
+func responseTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// responseFolderID returns folderID for a response, using null rather than ""
+// for an unfiled calculator.
+func responseFolderID(folderID string) *string {
+	if folderID == "" {
+		return nil
+	}
+	return &folderID
 }
 
 // createCalculatorHandler returns an http.HandlerFunc that handles POST /v1/calculators.
@@ -93,7 +134,59 @@ func createCalculatorHandler(svc CalculatorCreator) http.HandlerFunc {
 	}
 }
 
-// listCalculatorsHandler returns an http.HandlerFunc that handles GET /v1/calculators.
+// parseListOptions reads the list query parameters:
+//
+//	sort    updated_at (default), created_at, or name
+//	order   asc or desc; defaults to asc for name and desc otherwise
+//	q       case-insensitive substring of the calculator name or description
+//	tag     a tag the calculator must carry; repeat to require several
+//	folder  a folder ID, or "none" for calculators not in any folder
+//	limit   page size, 1 to calculator.MaxPageSize
+//	cursor  the next_cursor from the previous page's meta
+func parseListOptions(r *http.Request) (calculator.ListOptions, error) {
+	params := r.URL.Query()
+	opts := calculator.ListOptions{
+		Sort:   calculator.SortByUpdatedAt,
+		Search: params.Get("q"),
+		Tags:   params["tag"],
+		Cursor: params.Get("cursor"),
+	}
+	switch folder := params.Get("folder"); {
+	case folder == "":
+	case folder == "none":
+		opts.Unfiled = true
+	case calculator.ValidFolderID(folder):
+		opts.FolderID = folder
+	default:
+		return opts, errors.New(`folder must be a folder ID or "none"`)
+	}
+	if v := params.Get("sort"); v != "" {
+		opts.Sort = calculator.SortField(v)
+		if !opts.Sort.Valid() {
+			return opts, errors.New("sort must be one of updated_at, created_at, name")
+		}
+	}
+	switch params.Get("order") {
+	case "":
+		opts.Descending = opts.Sort != calculator.SortByName
+	case "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, errors.New("order must be asc or desc")
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > calculator.MaxPageSize {
+			return opts, fmt.Errorf("limit must be an integer from 1 to %d", calculator.MaxPageSize)
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+// listCalculatorsHandler returns an http.HandlerFunc for GET /v1/calculators.
+// Results are paginated; meta.next_cursor is present when another page follows.
 func listCalculatorsHandler(svc CalculatorLister) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := UserIDFromContext(r.Context())
@@ -101,32 +194,79 @@ func listCalculatorsHandler(svc CalculatorLister) http.HandlerFunc {
 			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
 			return
 		}
-		calcs, err := svc.List(r.Context(), userID)
+		opts, err := parseListOptions(r)
 		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+		page, err := svc.List(r.Context(), userID, opts)
+		if err != nil {
+			if errors.Is(err, calculator.ErrInvalidCursor) {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid cursor")
+				return
+			}
+			var merr *calculator.MetadataError
+			if errors.As(err, &merr) {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "tag "+merr.Message)
+				return
+			}
 			LoggerFrom(r.Context()).Error("listing calculators", "error", err)
 			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
 			return
 		}
-		summaries := make([]calculatorSummary, len(calcs))
-		for i, c := range calcs {
+		summaries := make([]calculatorSummary, len(page.Calculators))
+		for i, c := range page.Calculators {
 			summaries[i] = calculatorSummary{
-				ID:        c.ID,
-				Name:      c.Name,
-				CreatedAt: c.CreatedAt,
-				UpdatedAt: c.UpdatedAt,
+				ID:               c.ID,
+				Name:             c.Name,
+				Description:      c.Description,
+				Tags:             responseTags(c.Tags),
+				FolderID:         responseFolderID(c.FolderID),
+				MetadataVersion:  c.MetadataVersion,
+				ConfigVersion:    c.ConfigVersion,
+				PublishedVersion: c.PublishedVersion,
+				PublishedAt:      c.PublishedAt,
+				CreatedAt:        c.CreatedAt,
+				UpdatedAt:        c.UpdatedAt,
 			}
 		}
-		WriteJSON(w, http.StatusOK, summaries)
+		WriteJSONWithMeta(w, http.StatusOK, summaries, Meta{NextCursor: page.NextCursor})
 	}
 }
 
 // calculatorResponse is the full calculator shape returned by GET /v1/calculators/:id.
+// Config is the draft; PublishedVersion is the config_version widgets are served.
 type calculatorResponse struct {
-	ID            string          `json:"id"`
-	Config        json.RawMessage `json:"config"`
-	ConfigVersion int             `json:"config_version"`
-	CreatedAt     time.Time       `json:"created_at"`
-	UpdatedAt     time.Time       `json:"updated_at"`
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	Tags             []string        `json:"tags"`
+	FolderID         *string         `json:"folder_id"`
+	MetadataVersion  int             `json:"metadata_version"`
+	Config           json.RawMessage `json:"config"`
+	ConfigVersion    int             `json:"config_version"`
+	PublishedVersion int             `json:"published_version"`
+	PublishedAt      *time.Time      `json:"published_at"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// newCalculatorResponse builds the full response shape for calc.
+func newCalculatorResponse(calc *calculator.Calculator) calculatorResponse {
+	return calculatorResponse{
+		ID:               calc.ID,
+		Name:             calc.Name,
+		Description:      calc.Description,
+		Tags:             responseTags(calc.Tags),
+		FolderID:         responseFolderID(calc.FolderID),
+		MetadataVersion:  calc.MetadataVersion,
+		Config:           json.RawMessage(calc.Config),
+		ConfigVersion:    calc.ConfigVersion,
+		PublishedVersion: calc.PublishedVersion,
+		PublishedAt:      calc.PublishedAt,
+		CreatedAt:        calc.CreatedAt,
+		UpdatedAt:        calc.UpdatedAt,
+	}
 }
 
 // getCalculatorHandler returns an http.HandlerFunc for GET /v1/calculators/{id}.
@@ -152,22 +292,52 @@ func getCalculatorHandler(svc CalculatorGetter) http.HandlerFunc {
 			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
 			return
 		}
-		WriteJSON(w, http.StatusOK, calculatorResponse{
-			ID:            calc.ID,
-			Config:        json.RawMessage(calc.Config),
-			ConfigVersion: calc.ConfigVersion,
-			CreatedAt:     calc.CreatedAt,
-			UpdatedAt:     calc.UpdatedAt,
-		})
+		w.Header().Set("ETag", calculatorETag(calc.ID, calc.ConfigVersion))
+		WriteJSON(w, http.StatusOK, newCalculatorResponse(calc))
 	}
 }
 
 // updateCalculatorRequest is the request body for PUT /v1/calculators/{id}.
+// ConfigVersion is an optional precondition equivalent to an If-Match header.
+// Autosave marks a save the editor made without the user asking for one.
 type updateCalculatorRequest struct {
-	Config json.RawMessage `json:"config"`
+	Config        json.RawMessage `json:"config"`
+	ConfigVersion *int            `json:"config_version"`
+	Autosave      bool            `json:"autosave"`
+}
+
+// versionConflictResponse is the data payload of a 409 response to a stale
+// conditional update, telling the client which version to rebase onto.
+type versionConflictResponse struct {
+	ID            string `json:"id"`
+	ConfigVersion int    `json:"config_version"`
+}
+
+// expectedConfigVersion resolves the update precondition from the If-Match
+// header and the optional config_version body field. It returns 0 when the
+// client supplied neither. When both are present they must agree.
+func expectedConfigVersion(r *http.Request, id string, bodyVersion *int) (int, error) {
+	expected, err := parseIfMatch(r.Header.Get("If-Match"), id)
+	if err != nil {
+		return 0, err
+	}
+	if bodyVersion == nil {
+		return expected, nil
+	}
+	if *bodyVersion < 1 {
+		return 0, errors.New("config_version must be a positive integer")
+	}
+	if expected != 0 && expected != *bodyVersion {
+		return 0, errors.New("If-Match and config_version disagree")
+	}
+	return *bodyVersion, nil
 }
 
 // updateCalculatorHandler returns an http.HandlerFunc for PUT /v1/calculators/{id}.
+//
+// Clients opt in to optimistic concurrency by sending the ETag from GET as
+// If-Match, or the config_version they last saw in the body. A stale version
+// yields 409 CONFLICT carrying the current version and its ETag.
 func updateCalculatorHandler(svc CalculatorUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := UserIDFromContext(r.Context())
@@ -192,7 +362,13 @@ func updateCalculatorHandler(svc CalculatorUpdater) http.HandlerFunc {
 		}
 
 		id := chi.URLParam(r, "id")
-		calc, err := svc.Update(r.Context(), id, userID, req.Config)
+		expected, err := expectedConfigVersion(r, id, req.ConfigVersion)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+
+		calc, err := svc.Update(r.Context(), id, userID, expected, req.Autosave, req.Config)
 		if err != nil {
 			if errors.Is(err, calculator.ErrNotFound) {
 				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
@@ -202,20 +378,161 @@ func updateCalculatorHandler(svc CalculatorUpdater) http.HandlerFunc {
 				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
 				return
 			}
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeConfigValidationError(w, verr)
+				return
+			}
+			var conflict *calculator.VersionConflictError
+			if errors.As(err, &conflict) {
+				writeVersionConflict(w, id, conflict)
+				return
+			}
 			LoggerFrom(r.Context()).Error("updating calculator", "error", err)
 			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
 			return
 		}
-		WriteJSON(w, http.StatusOK, calculatorResponse{
-			ID:            calc.ID,
-			Config:        json.RawMessage(calc.Config),
-			ConfigVersion: calc.ConfigVersion,
-			CreatedAt:     calc.CreatedAt,
-			UpdatedAt:     calc.UpdatedAt,
-		})
+		w.Header().Set("ETag", calculatorETag(calc.ID, calc.ConfigVersion))
+		WriteJSON(w, http.StatusOK, newSavedCalculatorResponse(r, calc))
 	}
 }
 
+// Patch document media types accepted by PATCH /v1/calculators/{id}.
+const (
+	mediaTypeMergePatch = "application/merge-patch+json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+// maxPatchBodyBytes caps the size of a PATCH request body.
+const maxPatchBodyBytes = 1 << 20
+
+// patchFormatFor maps a Content-Type header to the patch format it names.
+// It returns false for any other media type.
+func patchFormatFor(contentType string) (calculator.PatchFormat, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, false
+	}
+	switch mediaType {
+	case mediaTypeMergePatch:
+		return calculator.MergePatch, true
+	case mediaTypeJSONPatch:
+		return calculator.JSONPatch, true
+	}
+	return 0, false
+}
+
+// patchCalculatorHandler returns an http.HandlerFunc for PATCH /v1/calculators/{id}.
+//
+// The body is an RFC 7386 merge patch or an RFC 6902 JSON patch, selected by
+// Content-Type. The patch is applied to the stored config and the result is
+// validated before anything is written. If-Match works as it does for PUT.
+// A failed JSON Patch "test" op yields 409 CONFLICT so clients can use it as a
+// compare-and-set on individual values; a patch that cannot be applied yields
+// 422.
+func patchCalculatorHandler(svc CalculatorPatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+
+		format, ok := patchFormatFor(r.Header.Get("Content-Type"))
+		if !ok {
+			w.Header().Set("Accept-Patch", mediaTypeMergePatch+", "+mediaTypeJSONPatch)
+			WriteError(w, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMediaType,
+				"Content-Type must be "+mediaTypeMergePatch+" or "+mediaTypeJSONPatch)
+			return
+		}
+
+		id := chi.URLParam(r, "id")
+		expected, err := parseIfMatch(r.Header.Get("If-Match"), id)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+
+		patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBodyBytes))
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+
+		calc, err := svc.Patch(r.Context(), id, userID, expected, format, patch)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			var conflict *calculator.VersionConflictError
+			if errors.As(err, &conflict) {
+				writeVersionConflict(w, id, conflict)
+				return
+			}
+			var perr *jsonpatch.Error
+			if errors.As(err, &perr) {
+				writePatchError(w, perr)
+				return
+			}
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeConfigValidationError(w, verr)
+				return
+			}
+			LoggerFrom(r.Context()).Error("patching calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("ETag", calculatorETag(calc.ID, calc.ConfigVersion))
+		WriteJSON(w, http.StatusOK, newSavedCalculatorResponse(r, calc))
+	}
+}
+
+// writePatchError maps a patch application failure to a response: 400 for a
+// malformed patch document, 409 for a failed "test" op, and 422 for an op that
+// cannot be applied to the stored config.
+func writePatchError(w http.ResponseWriter, perr *jsonpatch.Error) {
+	if perr.Index < 0 {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, perr.Message)
+		return
+	}
+	details := []ErrorDetail{{Field: perr.Path, Message: perr.Error()}}
+	if errors.Is(perr, jsonpatch.ErrTestFailed) {
+		WriteErrorDetails(w, http.StatusConflict, ErrCodeConflict, "patch test operation failed", details)
+		return
+	}
+	WriteErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, "patch could not be applied", details)
+}
+
+// writeVersionConflict writes the 409 response for a stale conditional write,
+// carrying the current version in the body and as the ETag.
+func writeVersionConflict(w http.ResponseWriter, id string, conflict *calculator.VersionConflictError) {
+	w.Header().Set("ETag", calculatorETag(id, conflict.CurrentVersion))
+	WriteErrorWithData(w, http.StatusConflict, ErrCodeConflict, "calculator was modified by another request",
+		versionConflictResponse{ID: id, ConfigVersion: conflict.CurrentVersion})
+}
+
+// writeConfigValidationError writes a 422 response listing every schema
+// violation in the submitted config as an error detail.
+func writeConfigValidationError(w http.ResponseWriter, verr *configschema.ValidationError) {
+	writeValidationError(w, "config failed validation", verr)
+}
+
+// writeValidationError writes a 422 response with the given message and one
+// error detail per violation in verr.
+func writeValidationError(w http.ResponseWriter, message string, verr *configschema.ValidationError) {
+	details := make([]ErrorDetail, len(verr.Errors))
+	for i, fe := range verr.Errors {
+		details[i] = ErrorDetail{Field: fe.Path, Message: fe.Message}
+	}
+	WriteErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, message, details)
+}
+
 // deleteCalculatorHandler returns an http.HandlerFunc for DELETE /v1/calculators/{id}.
 func deleteCalculatorHandler(svc CalculatorDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -281,6 +598,7 @@ func (s *Server) MountCalculators(validator TokenValidator, svc CalculatorServic
 	protected.Get("/calculators", listCalculatorsHandler(svc))
 	protected.Get("/calculators/{id}", getCalculatorHandler(svc))
 	protected.Put("/calculators/{id}", updateCalculatorHandler(svc))
+	protected.Patch("/calculators/{id}", patchCalculatorHandler(svc))
 	protected.Delete("/calculators/{id}", deleteCalculatorHandler(svc))
 	protected.Post("/calculators/{id}/duplicate", duplicateCalculatorHandler(svc))
 }
@@ -306,12 +624,19 @@ type publicConfigResponse struct {
 
 // publicConfigHandler returns an http.HandlerFunc for GET /v1/calculators/{id}/config.
 // No authentication is required — this endpoint is public for widget rendering.
+// It serves the published snapshot; unpublished draft changes are never visible.
+// If the calculator has an embed allow-list, a page not on it gets 403.
+// A request whose If-None-Match names the current ETag gets 304 Not Modified.
 func publicConfigHandler(svc CalculatorPublicConfigGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
 		calc, err := svc.GetPublicConfig(r.Context(), id)
 		if err != nil {
 			if errors.Is(err, calculator.ErrNotFound) {
+				// A deleted calculator can be restored and an unpublished one
+				// published at any time; caching the 404 would keep the widget
+				// broken afterwards.
+				w.Header().Set("Cache-Control", "no-store")
 				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
 				return
 			}
@@ -319,7 +644,20 @@ func publicConfigHandler(svc CalculatorPublicConfigGetter) http.HandlerFunc {
 			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
 			return
 		}
-		w.Header().Set("Cache-Control", "public, max-age=300")
+		if !allowEmbed(w, r, calc) {
+			return
+		}
+		// The ETag changes whenever a new version is published, so clients and
+		// caches can revalidate cheaply after max-age; the surrogate key lets
+		// the CDN copy be purged as soon as the calculator changes.
+		etag := publicConfigETag(calc.ID, calc.ConfigVersion)
+		w.Header().Set("Cache-Control", embedCacheControl(r, calc, 300))
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Surrogate-Key", calculator.CacheKey(calc.ID))
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
 		WriteJSON(w, http.StatusOK, publicConfigResponse{
 			ID:            calc.ID,
 			Config:        json.RawMessage(calc.Config),
@@ -329,13 +667,89 @@ func publicConfigHandler(svc CalculatorPublicConfigGetter) http.HandlerFunc {
 	}
 }
 
+// CalculatorEvaluator computes a calculator's outputs from a set of input values.
+type CalculatorEvaluator interface {
+	Evaluate(ctx context.Context, id string, values map[string]float64) ([]calculator.OutputResult, error)
+}
+
+// PublicCalculatorService is the set of calculator capabilities exposed without authentication.
+type PublicCalculatorService interface {
+	CalculatorPublicConfigGetter
+	CalculatorEvaluator
+}
+
+// maxEvaluateBodyBytes caps the size of an evaluate request body.
+const maxEvaluateBodyBytes = 64 << 10
+
+// evaluateRequest is the request body for POST /v1/calculators/{id}/evaluate.
+// Values maps field variable names to numeric inputs; omitted fields take
+// their configured defaults.
+type evaluateRequest struct {
+	Values map[string]float64 `json:"values"`
+}
+
+// outputResult is the per-output shape in an evaluate response. Decimal is the
+// exact rounded result as a string, e.g. "0.30", for clients that must not
+// round-trip through floating point. Value is null and Decimal omitted when
+// Error is set.
+type outputResult struct {
+	ID      string   `json:"id"`
+	Label   string   `json:"label"`
+	Value   *float64 `json:"value"`
+	Decimal string   `json:"decimal,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// evaluateResponse is the data payload returned on successful evaluation.
+type evaluateResponse struct {
+	Outputs []outputResult `json:"outputs"`
+}
+
+// evaluateHandler returns an http.HandlerFunc for POST /v1/calculators/{id}/evaluate.
+// No authentication is required — this endpoint lets headless clients compute
+// results server-side with the widget's formula grammar and exact decimal
+// arithmetic.
+func evaluateHandler(svc CalculatorEvaluator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req evaluateRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEvaluateBodyBytes)).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+
+		id := chi.URLParam(r, "id")
+		results, err := svc.Evaluate(r.Context(), id, req.Values)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeValidationError(w, "values failed validation", verr)
+				return
+			}
+			LoggerFrom(r.Context()).Error("evaluating calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+
+		outputs := make([]outputResult, len(results))
+		for i, res := range results {
+			outputs[i] = outputResult{ID: res.ID, Label: res.Label, Value: res.Value, Decimal: res.Decimal, Error: res.Error}
+		}
+		WriteJSON(w, http.StatusOK, evaluateResponse{Outputs: outputs})
+	}
+}
+
 // MountPublicCalculators registers public (no auth) calculator routes on the server's public group.
-// The config endpoint is rate-limited to 60 requests per minute per IP to prevent abuse while
+// The routes are rate-limited to 60 requests per minute per IP to prevent abuse while
 // allowing normal widget traffic (widgets are cached client-side and by CDN).
-func (s *Server) MountPublicCalculators(svc CalculatorPublicConfigGetter) {
+func (s *Server) MountPublicCalculators(svc PublicCalculatorService) {
 	limiter := newRateLimiter(60) // 60 requests per minute per IP
 	s.publicGroup.Group(func(r chi.Router) {
 		r.Use(IPRateLimit(limiter))
 		r.Get("/calculators/{id}/config", publicConfigHandler(svc))
+		r.Post("/calculators/{id}/evaluate", evaluateHandler(svc))
 	})
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +15,8 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/jsonpatch"
 )
 
 func TestCreateCalculatorHandler_Success(t *testing.T) {
@@ -195,6 +199,78 @@ func TestListCalculatorsHandler_InternalError(t *testing.T) {
 	}
 }
 
+func TestListCalculatorsHandler_QueryParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		want     calculator.ListOptions
+	}{
+		{"defaults", "", http.StatusOK, calculator.ListOptions{Sort: calculator.SortByUpdatedAt, Descending: true}},
+		{"name defaults ascending", "?sort=name", http.StatusOK, calculator.ListOptions{Sort: calculator.SortByName}},
+		{"explicit order", "?sort=created_at&order=asc", http.StatusOK, calculator.ListOptions{Sort: calculator.SortByCreatedAt}},
+		{"search, limit and cursor", "?q=roof&limit=10&cursor=abc", http.StatusOK, calculator.ListOptions{Sort: calculator.SortByUpdatedAt, Descending: true, Search: "roof", Limit: 10, Cursor: "abc"}},
+		{"tags and folder", "?tag=roofing&tag=residential&folder=7d3c9a4e-0b1f-4c2a-9e8d-5f6a7b8c9d0e", http.StatusOK, calculator.ListOptions{Sort: calculator.SortByUpdatedAt, Descending: true, Tags: []string{"roofing", "residential"}, FolderID: "7d3c9a4e-0b1f-4c2a-9e8d-5f6a7b8c9d0e"}},
+		{"unfiled", "?folder=none", http.StatusOK, calculator.ListOptions{Sort: calculator.SortByUpdatedAt, Descending: true, Unfiled: true}},
+		{"malformed folder", "?folder=inbox", http.StatusBadRequest, calculator.ListOptions{}},
+		{"unknown sort", "?sort=config", http.StatusBadRequest, calculator.ListOptions{}},
+		{"unknown order", "?order=sideways", http.StatusBadRequest, calculator.ListOptions{}},
+		{"zero limit", "?limit=0", http.StatusBadRequest, calculator.ListOptions{}},
+		{"limit too large", "?limit=101", http.StatusBadRequest, calculator.ListOptions{}},
+		{"non-numeric limit", "?limit=ten", http.StatusBadRequest, calculator.ListOptions{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubCalculatorService{calcs: []*calculator.Calculator{}}
+			h := listCalculatorsHandler(svc)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/calculators"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d (body: %s)", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if !reflect.DeepEqual(svc.gotListOptions, tt.want) {
+				t.Errorf("expected options %+v, got %+v", tt.want, svc.gotListOptions)
+			}
+		})
+	}
+}
+
+func TestListCalculatorsHandler_NextCursorInMeta(t *testing.T) {
+	svc := &stubCalculatorService{calcs: []*calculator.Calculator{{ID: "calc-1"}}, nextCursor: "next-page"}
+	h := listCalculatorsHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/calculators?limit=1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var env Envelope[[]map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Meta.NextCursor != "next-page" {
+		t.Errorf("expected meta.next_cursor %q, got %q", "next-page", env.Meta.NextCursor)
+	}
+}
+
+func TestListCalculatorsHandler_InvalidCursor(t *testing.T) {
+	svc := &stubCalculatorService{err: calculator.ErrInvalidCursor}
+	h := listCalculatorsHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/calculators?cursor=bogus", nil)
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
 func TestMountCalculators_RegistersRoute(t *testing.T) {
 	s := testServer(t)
 	authSvc := &stubAuthService{userID: "user-xyz"}
@@ -314,6 +390,9 @@ func TestGetCalculatorHandler_Success(t *testing.T) {
 	if env.Data["config"] == nil {
 		t.Error("expected config to be non-null in response")
 	}
+	if etag := rec.Header().Get("ETag"); etag != `"calc-abc.1"` {
+		t.Errorf("expected ETag %q, got %q", `"calc-abc.1"`, etag)
+	}
 }
 
 func TestGetCalculatorHandler_NotFound(t *testing.T) {
@@ -481,6 +560,32 @@ func TestUpdateCalculatorHandler_Success(t *testing.T) {
 	if !ok || int(cv) != 2 {
 		t.Errorf("expected config_version 2, got %v", env.Data["config_version"])
 	}
+	if svc.gotAutosave {
+		t.Error("expected a plain save not to be marked as an autosave")
+	}
+}
+
+func TestUpdateCalculatorHandler_Autosave(t *testing.T) {
+	svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 3}}
+	h := updateCalculatorHandler(svc)
+
+	body := `{"config":{},"autosave":true}`
+	req := httptest.NewRequest(http.MethodPut, "/v1/calculators/calc-abc", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "calc-abc")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, authUserKey{}, "user-xyz")
+	req = req.WithContext(ctx)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if !svc.gotAutosave {
+		t.Error("expected the autosave flag to reach the service")
+	}
 }
 
 func TestUpdateCalculatorHandler_MissingAuth(t *testing.T) {
@@ -669,6 +774,44 @@ func TestUpdateCalculatorHandler_InternalError(t *testing.T) {
 	}
 }
 
+func TestUpdateCalculatorHandler_ValidationError(t *testing.T) {
+	verr := &configschema.ValidationError{Errors: []configschema.FieldError{
+		{Path: "fields[3].options", Message: "must not be empty"},
+		{Path: "layoutMode", Message: "must be \"single-page\" or \"multi-step\""},
+	}}
+	svc := &stubCalculatorService{err: fmt.Errorf("validating calculator config: %w", verr)}
+	h := updateCalculatorHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/calculators/calc-abc", strings.NewReader(`{"config":{}}`))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "calc-abc")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, authUserKey{}, "user-xyz")
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+	var env Envelope[any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Error == nil {
+		t.Fatal("expected error in response, got nil")
+	}
+	if env.Error.Code != ErrCodeValidation {
+		t.Errorf("expected error code %q, got %q", ErrCodeValidation, env.Error.Code)
+	}
+	if len(env.Error.Details) != 2 {
+		t.Fatalf("expected 2 details, got %d", len(env.Error.Details))
+	}
+	if env.Error.Details[0].Field != "fields[3].options" || env.Error.Details[0].Message != "must not be empty" {
+		t.Errorf("unexpected first detail: %+v", env.Error.Details[0])
+	}
+}
+
 func TestDeleteCalculatorHandler_Success(t *testing.T) {
 	svc := &stubCalculatorService{}
 	h := deleteCalculatorHandler(svc)
@@ -1026,6 +1169,58 @@ func TestPublicConfigHandler_Success(t *testing.T) {
 	}
 }
 
+func TestPublicConfigHandler_ValidatorHeaders(t *testing.T) {
+	svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 4}}
+	h := publicConfigHandler(svc)
+
+	req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/config", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if want := fmt.Sprintf(`"calc-abc.4.s%d"`, configschema.Version); rec.Header().Get("ETag") != want {
+		t.Errorf("expected ETag %q, got %q", want, rec.Header().Get("ETag"))
+	}
+	if got := rec.Header().Get("Surrogate-Key"); got != "calculator-calc-abc" {
+		t.Errorf("expected Surrogate-Key %q, got %q", "calculator-calc-abc", got)
+	}
+}
+
+func TestPublicConfigHandler_NotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{"current version", fmt.Sprintf(`"calc-abc.4.s%d"`, configschema.Version), http.StatusNotModified},
+		{"stale version", fmt.Sprintf(`"calc-abc.3.s%d"`, configschema.Version), http.StatusOK},
+		{"older schema version", fmt.Sprintf(`"calc-abc.4.s%d"`, configschema.Version-1), http.StatusOK},
+		{"draft tag", `"calc-abc.4"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 4}}
+			h := publicConfigHandler(svc)
+
+			req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/config", "id", "calc-abc")
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusNotModified {
+				if rec.Body.Len() != 0 {
+					t.Errorf("expected empty body on 304, got %q", rec.Body.String())
+				}
+				if rec.Header().Get("ETag") == "" || rec.Header().Get("Cache-Control") == "" {
+					t.Error("expected 304 to carry ETag and Cache-Control")
+				}
+			}
+		})
+	}
+}
+
 func TestPublicConfigHandler_NotFound(t *testing.T) {
 	svc := &stubCalculatorService{err: calculator.ErrNotFound}
 	h := publicConfigHandler(svc)
@@ -1037,6 +1232,9 @@ func TestPublicConfigHandler_NotFound(t *testing.T) {
 	if rec.Code != http.StatusNotFound {
 		t.Errorf("expected 404, got %d", rec.Code)
 	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("expected 404 to be uncacheable so a restore takes effect immediately, got Cache-Control %q", cc)
+	}
 	var env Envelope[any]
 	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
@@ -1131,3 +1329,366 @@ func TestMountPublicCalculators_RateLimitsConfigEndpoint(t *testing.T) {
 		t.Errorf("expected the 61st request to return 429, got %d", lastCode)
 	}
 }
+
+func newEvaluateRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculators/calc-abc/evaluate", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "calc-abc")
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestEvaluateHandler_Success(t *testing.T) {
+	total := 600.0
+	svc := &stubCalculatorService{results: []calculator.OutputResult{
+		{ID: "out1", Label: "Total", Value: &total, Decimal: "600.00"},
+		{ID: "out2", Label: "Broken", Error: "Unknown variable: {x}"},
+	}}
+	h := evaluateHandler(svc)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newEvaluateRequest(`{"values":{"sqft":200}}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[evaluateResponse]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(env.Data.Outputs) != 2 {
+		t.Fatalf("expected 2 outputs, got %d", len(env.Data.Outputs))
+	}
+	if got := env.Data.Outputs[0]; got.ID != "out1" || got.Value == nil || *got.Value != 600 || got.Decimal != "600.00" {
+		t.Errorf("unexpected first output: %+v", got)
+	}
+	if got := env.Data.Outputs[1]; got.Value != nil || got.Error != "Unknown variable: {x}" {
+		t.Errorf("unexpected second output: %+v", got)
+	}
+}
+
+func TestEvaluateHandler_InvalidBody(t *testing.T) {
+	h := evaluateHandler(&stubCalculatorService{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newEvaluateRequest(`{"values":{"sqft":"two hundred"}}`))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestEvaluateHandler_BodyTooLarge(t *testing.T) {
+	h := evaluateHandler(&stubCalculatorService{})
+
+	body := `{"values":{"a":` + strings.Repeat(" ", maxEvaluateBodyBytes) + `1}}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newEvaluateRequest(body))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestEvaluateHandler_NotFound(t *testing.T) {
+	h := evaluateHandler(&stubCalculatorService{err: calculator.ErrNotFound})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newEvaluateRequest(`{"values":{}}`))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestEvaluateHandler_ValidationError(t *testing.T) {
+	verr := &configschema.ValidationError{Errors: []configschema.FieldError{
+		{Path: "values.bogus", Message: "does not match any field variable"},
+	}}
+	h := evaluateHandler(&stubCalculatorService{err: fmt.Errorf("evaluating: %w", verr)})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newEvaluateRequest(`{"values":{"bogus":1}}`))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	var env Envelope[any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Error == nil || env.Error.Code != ErrCodeValidation {
+		t.Fatalf("expected %q error, got %+v", ErrCodeValidation, env.Error)
+	}
+	if len(env.Error.Details) != 1 || env.Error.Details[0].Field != "values.bogus" {
+		t.Errorf("unexpected details: %+v", env.Error.Details)
+	}
+}
+
+func TestEvaluateHandler_InternalError(t *testing.T) {
+	h := evaluateHandler(&stubCalculatorService{err: errors.New("db failure")})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newEvaluateRequest(`{}`))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestMountPublicCalculators_RegistersEvaluateRoute(t *testing.T) {
+	s := testServer(t)
+	s.MountPublicCalculators(&stubCalculatorService{results: []calculator.OutputResult{}})
+
+	// No Authorization header — this is a public route.
+	req := httptest.NewRequest(http.MethodPost, "/v1/calculators/calc-abc/evaluate", strings.NewReader(`{"values":{}}`))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 from mounted evaluate route, got %d", rec.Code)
+	}
+}
+
+// newUpdateRequest builds an authenticated PUT /v1/calculators/calc-abc request.
+func newUpdateRequest(body, ifMatch string) *http.Request {
+	req := httptest.NewRequest(http.MethodPut, "/v1/calculators/calc-abc", strings.NewReader(body))
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "calc-abc")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, authUserKey{}, "user-xyz")
+	return req.WithContext(ctx)
+}
+
+func TestUpdateCalculatorHandler_Preconditions(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		ifMatch      string
+		wantCode     int
+		wantExpected int
+	}{
+		{"none", `{"config":{}}`, "", http.StatusOK, 0},
+		{"if-match", `{"config":{}}`, `"calc-abc.4"`, http.StatusOK, 4},
+		{"if-match wildcard", `{"config":{}}`, `*`, http.StatusOK, 0},
+		{"body field", `{"config":{},"config_version":4}`, "", http.StatusOK, 4},
+		{"both agree", `{"config":{},"config_version":4}`, `"calc-abc.4"`, http.StatusOK, 4},
+		{"both disagree", `{"config":{},"config_version":4}`, `"calc-abc.5"`, http.StatusBadRequest, 0},
+		{"weak if-match", `{"config":{}}`, `W/"calc-abc.4"`, http.StatusBadRequest, 0},
+		{"if-match for another calculator", `{"config":{}}`, `"calc-xyz.4"`, http.StatusBadRequest, 0},
+		{"non-positive body field", `{"config":{},"config_version":0}`, "", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 5}}
+			h := updateCalculatorHandler(svc)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, newUpdateRequest(tt.body, tt.ifMatch))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+			if svc.gotExpectedVersion != tt.wantExpected {
+				t.Errorf("expected Update to receive version %d, got %d", tt.wantExpected, svc.gotExpectedVersion)
+			}
+			if rec.Code == http.StatusOK {
+				if etag := rec.Header().Get("ETag"); etag != `"calc-abc.5"` {
+					t.Errorf("expected ETag of the new version, got %q", etag)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateCalculatorHandler_VersionConflict(t *testing.T) {
+	svc := &stubCalculatorService{err: fmt.Errorf("updating calculator: %w", &calculator.VersionConflictError{CurrentVersion: 7})}
+	h := updateCalculatorHandler(svc)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newUpdateRequest(`{"config":{}}`, `"calc-abc.3"`))
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"calc-abc.7"` {
+		t.Errorf("expected ETag of the current version, got %q", etag)
+	}
+	var env Envelope[versionConflictResponse]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Error == nil || env.Error.Code != ErrCodeConflict {
+		t.Fatalf("expected %q error, got %+v", ErrCodeConflict, env.Error)
+	}
+	if env.Data.ID != "calc-abc" || env.Data.ConfigVersion != 7 {
+		t.Errorf("expected current version 7 in data, got %+v", env.Data)
+	}
+}
+
+// newPatchRequest builds an authenticated PATCH /v1/calculators/calc-abc request.
+func newPatchRequest(contentType, body, ifMatch string) *http.Request {
+	req := httptest.NewRequest(http.MethodPatch, "/v1/calculators/calc-abc", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "calc-abc")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, authUserKey{}, "user-xyz")
+	return req.WithContext(ctx)
+}
+
+func TestPatchCalculatorHandler_Success(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantFormat  calculator.PatchFormat
+	}{
+		{"merge patch", "application/merge-patch+json", `{"layoutMode":"multi-step"}`, calculator.MergePatch},
+		{"json patch", "application/json-patch+json", `[{"op":"remove","path":"/theme"}]`, calculator.JSONPatch},
+		{"media type parameters", "application/merge-patch+json; charset=utf-8", `{}`, calculator.MergePatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 5}}
+			h := patchCalculatorHandler(svc)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, newPatchRequest(tt.contentType, tt.body, `"calc-abc.4"`))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+			}
+			if svc.gotPatchFormat != tt.wantFormat {
+				t.Errorf("expected format %d, got %d", tt.wantFormat, svc.gotPatchFormat)
+			}
+			if string(svc.gotPatch) != tt.body {
+				t.Errorf("expected patch %s, got %s", tt.body, svc.gotPatch)
+			}
+			if svc.gotExpectedVersion != 4 {
+				t.Errorf("expected Patch to receive version 4, got %d", svc.gotExpectedVersion)
+			}
+			if etag := rec.Header().Get("ETag"); etag != `"calc-abc.5"` {
+				t.Errorf("expected ETag of the new version, got %q", etag)
+			}
+		})
+	}
+}
+
+func TestPatchCalculatorHandler_UnsupportedMediaType(t *testing.T) {
+	for _, contentType := range []string{"", "application/json", "text/plain"} {
+		svc := &stubCalculatorService{}
+		h := patchCalculatorHandler(svc)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newPatchRequest(contentType, `{}`, ""))
+
+		if rec.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Content-Type %q: expected 415, got %d", contentType, rec.Code)
+		}
+		if got := rec.Header().Get("Accept-Patch"); got != "application/merge-patch+json, application/json-patch+json" {
+			t.Errorf("Content-Type %q: unexpected Accept-Patch %q", contentType, got)
+		}
+	}
+}
+
+func TestPatchCalculatorHandler_MissingAuth(t *testing.T) {
+	h := patchCalculatorHandler(&stubCalculatorService{})
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/calculators/calc-abc", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chi.NewRouteContext()))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestPatchCalculatorHandler_InvalidIfMatch(t *testing.T) {
+	h := patchCalculatorHandler(&stubCalculatorService{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newPatchRequest("application/merge-patch+json", `{}`, `W/"calc-abc.4"`))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestPatchCalculatorHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{"not found", calculator.ErrNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden, ErrCodeForbidden},
+		{"malformed patch", &jsonpatch.Error{Index: -1, Message: "JSON patch must be an array of operations"}, http.StatusBadRequest, ErrCodeBadRequest},
+		{"inapplicable op", &jsonpatch.Error{Index: 0, Op: "remove", Path: "/x", Message: `member "x" not found`}, http.StatusUnprocessableEntity, ErrCodeValidation},
+		{"test op failed", &jsonpatch.Error{Index: 0, Op: "test", Path: "/layoutMode", Message: "value does not match", Err: jsonpatch.ErrTestFailed}, http.StatusConflict, ErrCodeConflict},
+		{"schema violation", &configschema.ValidationError{Errors: []configschema.FieldError{{Path: "layoutMode", Message: "must be one of"}}}, http.StatusUnprocessableEntity, ErrCodeValidation},
+		{"version conflict", &calculator.VersionConflictError{CurrentVersion: 7}, http.StatusConflict, ErrCodeConflict},
+		{"internal", errors.New("db failure"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubCalculatorService{err: fmt.Errorf("patching calculator: %w", tt.err)}
+			h := patchCalculatorHandler(svc)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, newPatchRequest("application/json-patch+json", `[]`, ""))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d (body: %s)", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			var env Envelope[any]
+			if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if env.Error == nil || env.Error.Code != tt.wantErr {
+				t.Errorf("expected %q error, got %+v", tt.wantErr, env.Error)
+			}
+		})
+	}
+}
+
+func TestPatchCalculatorHandler_TestFailureDetails(t *testing.T) {
+	perr := &jsonpatch.Error{Index: 1, Op: "test", Path: "/layoutMode", Message: "value does not match", Err: jsonpatch.ErrTestFailed}
+	h := patchCalculatorHandler(&stubCalculatorService{err: perr})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newPatchRequest("application/json-patch+json", `[]`, ""))
+
+	var env Envelope[any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Error == nil || len(env.Error.Details) != 1 || env.Error.Details[0].Field != "/layoutMode" {
+		t.Errorf("expected one detail for /layoutMode, got %+v", env.Error)
+	}
+}
+
+func TestMountCalculators_RegistersPatchRoute(t *testing.T) {
+	s := testServer(t)
+	authSvc := &stubAuthService{userID: "user-xyz"}
+	s.MountAuth(authSvc)
+	s.MountCalculators(authSvc, &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 2}})
+
+	req := httptest.NewRequest(http.MethodPatch, "/v1/calculators/calc-abc", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 from mounted PATCH route, got %d", rec.Code)
+	}
+}
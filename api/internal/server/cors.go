@@ -13,7 +13,8 @@ import (
 // accessible from any domain because the widget is embedded on third-party
 // sites. Using a wildcard is safe here because these endpoints do not use
 // cookies or session tokens — they accept only calculator IDs and submission
-// payloads.
+// payloads. A builder who restricts where a calculator may be embedded is
+// served by the handlers, which check Origin and Referer per calculator.
 func publicCORS() func(http.Handler) http.Handler {
 	return cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
@@ -31,11 +32,15 @@ func publicCORS() func(http.Handler) http.Handler {
 // session cookies for authentication, so AllowCredentials must be true and
 // the origin list must be restricted. A wildcard origin cannot be combined
 // with AllowCredentials: true per the CORS specification.
+//
+// If-Match is allowed and ETag exposed so the dashboard can make conditional
+// calculator updates.
 func privateCORS(allowedOrigins []string) func(http.Handler) http.Handler {
 	return cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "If-Match"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}).Handler
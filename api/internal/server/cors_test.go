@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -334,3 +335,45 @@ func TestServer_PrivateGroupCORSWiring_DisallowedOrigin(t *testing.T) {
 		t.Errorf("expected no Access-Control-Allow-Origin for disallowed origin, got %q", origin)
 	}
 }
+
+// TestPrivateCORS_ConditionalRequestHeaders verifies that the dashboard may send
+// If-Match and read the ETag response header for optimistic concurrency.
+func TestPrivateCORS_ConditionalRequestHeaders(t *testing.T) {
+	h := privateCORS([]string{"http://localhost:3000"})(dummyHandler)
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	req.Header.Set("Access-Control-Request-Headers", "If-Match")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); !strings.EqualFold(got, "If-Match") {
+		t.Errorf("expected If-Match to be allowed, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "Etag" && got != "ETag" {
+		t.Errorf("expected ETag to be exposed, got %q", got)
+	}
+}
+
+// TestPrivateCORS_AllowsPatch verifies that the dashboard may send partial
+// config updates cross-origin.
+func TestPrivateCORS_AllowsPatch(t *testing.T) {
+	h := privateCORS([]string{"http://localhost:3000"})(dummyHandler)
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != http.MethodPatch {
+		t.Errorf("expected PATCH to be allowed, got %q", got)
+	}
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// CalculatorEmbedDomainGetter retrieves a calculator's embed allow-list.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorEmbedDomainGetter interface {
+	GetEmbedDomains(ctx context.Context, id, userID string) ([]string, error)
+}
+
+// CalculatorEmbedDomainSetter replaces a calculator's embed allow-list.
+type CalculatorEmbedDomainSetter interface {
+	SetEmbedDomains(ctx context.Context, id, userID string, domains []string) ([]string, error)
+}
+
+// CalculatorEmbedDomainService is the full set of embed allow-list capabilities consumed by the server.
+type CalculatorEmbedDomainService interface {
+	CalculatorEmbedDomainGetter
+	CalculatorEmbedDomainSetter
+}
+
+// embedDomainsBody is the request and response body of the embed-domains endpoints.
+type embedDomainsBody struct {
+	Domains []string `json:"domains"`
+}
+
+// newEmbedDomainsBody builds the response body for domains, using an empty
+// array rather than null for an unrestricted calculator.
+func newEmbedDomainsBody(domains []string) embedDomainsBody {
+	if domains == nil {
+		domains = []string{}
+	}
+	return embedDomainsBody{Domains: domains}
+}
+
+// getEmbedDomainsHandler returns an http.HandlerFunc for GET /v1/calculators/{id}/embed-domains.
+func getEmbedDomainsHandler(svc CalculatorEmbedDomainGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		domains, err := svc.GetEmbedDomains(r.Context(), id, userID)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting embed domains", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, newEmbedDomainsBody(domains))
+	}
+}
+
+// setEmbedDomainsHandler returns an http.HandlerFunc for PUT /v1/calculators/{id}/embed-domains.
+// An empty list lets the calculator be embedded on any site again.
+func setEmbedDomainsHandler(svc CalculatorEmbedDomainSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		var req embedDomainsBody
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		domains, err := svc.SetEmbedDomains(r.Context(), id, userID, req.Domains)
+		if err != nil {
+			var derr *calculator.EmbedDomainError
+			if errors.As(err, &derr) {
+				field := "domains"
+				if derr.Index >= 0 {
+					field = fmt.Sprintf("domains[%d]", derr.Index)
+				}
+				WriteErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, "embed domains failed validation",
+					[]ErrorDetail{{Field: field, Message: derr.Message}})
+				return
+			}
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("setting embed domains", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, newEmbedDomainsBody(domains))
+	}
+}
+
+// MountCalculatorEmbedDomains registers the embed allow-list routes on the server's private authenticated group.
+func (s *Server) MountCalculatorEmbedDomains(validator TokenValidator, svc CalculatorEmbedDomainService) {
+	protected := s.Authenticated(validator)
+	protected.Get("/calculators/{id}/embed-domains", getEmbedDomainsHandler(svc))
+	protected.Put("/calculators/{id}/embed-domains", setEmbedDomainsHandler(svc))
+}
+
+// embedHost returns the host of the page making a public request, taken from
+// Origin or, when a browser omits it, Referer. fromOrigin reports which one
+// was used. host is "" if neither names a host (including Origin "null").
+func embedHost(r *http.Request) (host string, fromOrigin bool) {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		return urlHost(origin), true
+	}
+	return urlHost(r.Header.Get("Referer")), false
+}
+
+// urlHost returns the host name of raw, or "" if raw is not an absolute URL.
+func urlHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// allowEmbed enforces calc's embed allow-list on a public request. If the
+// requesting page is not allowed it writes a 403 ORIGIN_NOT_ALLOWED response
+// and returns false. Responses for restricted calculators vary by Origin;
+// unrestricted calculators are left untouched.
+func allowEmbed(w http.ResponseWriter, r *http.Request, calc *calculator.Calculator) bool {
+	if len(calc.EmbedDomains) == 0 {
+		return true
+	}
+	w.Header().Add("Vary", "Origin")
+	host, _ := embedHost(r)
+	if !calculator.EmbedAllowed(calc.EmbedDomains, host) {
+		w.Header().Set("Cache-Control", "no-store")
+		WriteError(w, http.StatusForbidden, ErrCodeOriginNotAllowed, "this calculator may not be embedded on this site")
+		return false
+	}
+	return true
+}
+
+// embedCacheControl returns the Cache-Control value for a public response
+// about calc. A restricted calculator allowed on the strength of Referer is
+// kept out of shared caches, since Referer is too varied to key them on.
+func embedCacheControl(r *http.Request, calc *calculator.Calculator, maxAge int) string {
+	if _, fromOrigin := embedHost(r); len(calc.EmbedDomains) > 0 && !fromOrigin {
+		return fmt.Sprintf("private, max-age=%d", maxAge)
+	}
+	return fmt.Sprintf("public, max-age=%d", maxAge)
+}
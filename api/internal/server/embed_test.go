@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+func TestGetEmbedDomainsHandler_EmptyList(t *testing.T) {
+	h := getEmbedDomainsHandler(&stubEmbedDomainService{})
+
+	req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/embed-domains", "", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[embedDomainsBody]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Data.Domains == nil || len(env.Data.Domains) != 0 {
+		t.Errorf("expected an empty array, got %v", env.Data.Domains)
+	}
+}
+
+func TestSetEmbedDomainsHandler_Success(t *testing.T) {
+	svc := &stubEmbedDomainService{domains: []string{"example.com", "*.example.org"}}
+	h := setEmbedDomainsHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPut, "/v1/calculators/calc-abc/embed-domains", `{"domains":["Example.com","*.example.org"]}`, "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if !reflect.DeepEqual(svc.gotDomains, []string{"Example.com", "*.example.org"}) {
+		t.Errorf("unexpected domains passed to service: %v", svc.gotDomains)
+	}
+	var env Envelope[embedDomainsBody]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(env.Data.Domains, svc.domains) {
+		t.Errorf("expected normalized domains in response, got %v", env.Data.Domains)
+	}
+}
+
+func TestSetEmbedDomainsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		err       error
+		wantCode  int
+		wantField string
+	}{
+		{"invalid body", `{`, nil, http.StatusBadRequest, ""},
+		{"invalid entry", `{"domains":["ok.com","bad/"]}`, &calculator.EmbedDomainError{Index: 1, Message: "bad"}, http.StatusUnprocessableEntity, "domains[1]"},
+		{"too many", `{"domains":[]}`, &calculator.EmbedDomainError{Index: -1, Message: "too many"}, http.StatusUnprocessableEntity, "domains"},
+		{"forbidden", `{"domains":[]}`, fmt.Errorf("verifying calculator ownership: %w", calculator.ErrForbidden), http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := setEmbedDomainsHandler(&stubEmbedDomainService{err: tt.err})
+
+			req := newAuthedChiRequest(http.MethodPut, "/v1/calculators/calc-abc/embed-domains", tt.body, "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var env Envelope[any]
+			if err := decodeEnvelope(rec, &env); err != nil {
+				t.Fatal(err)
+			}
+			if len(env.Error.Details) != 1 || env.Error.Details[0].Field != tt.wantField {
+				t.Errorf("expected detail for %q, got %+v", tt.wantField, env.Error.Details)
+			}
+		})
+	}
+}
+
+func TestPublicConfigHandler_EmbedAllowList(t *testing.T) {
+	calc := &calculator.Calculator{
+		ID:            "calc-abc",
+		Config:        []byte(`{}`),
+		ConfigVersion: 1,
+		EmbedDomains:  []string{"*.example.com"},
+	}
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		wantCode  int
+		wantCache string
+	}{
+		{"allowed origin", map[string]string{"Origin": "https://shop.example.com"}, http.StatusOK, "public, max-age=300"},
+		{"allowed referer", map[string]string{"Referer": "https://shop.example.com/pricing"}, http.StatusOK, "private, max-age=300"},
+		{"other origin", map[string]string{"Origin": "https://evil.test", "Referer": "https://shop.example.com/"}, http.StatusForbidden, "no-store"},
+		{"null origin", map[string]string{"Origin": "null"}, http.StatusForbidden, "no-store"},
+		{"no headers", nil, http.StatusForbidden, "no-store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := publicConfigHandler(&stubCalculatorService{calc: calc})
+
+			req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/config", "id", "calc-abc")
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != tt.wantCache {
+				t.Errorf("expected Cache-Control %q, got %q", tt.wantCache, cc)
+			}
+			if rec.Header().Get("Vary") != "Origin" {
+				t.Errorf("expected Vary: Origin, got %q", rec.Header().Get("Vary"))
+			}
+			if tt.wantCode == http.StatusForbidden {
+				var env Envelope[any]
+				if err := decodeEnvelope(rec, &env); err != nil {
+					t.Fatal(err)
+				}
+				if env.Error == nil || env.Error.Code != ErrCodeOriginNotAllowed {
+					t.Errorf("expected %s error, got %+v", ErrCodeOriginNotAllowed, env.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestPublicConfigHandler_NoAllowListIgnoresOrigin(t *testing.T) {
+	h := publicConfigHandler(&stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`)}})
+
+	req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/config", "id", "calc-abc")
+	req.Header.Set("Origin", "https://anywhere.test")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Vary") != "" {
+		t.Errorf("expected no Vary header for an unrestricted calculator, got %q", rec.Header().Get("Vary"))
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// errInvalidIfMatch is returned by parseIfMatch for headers that do not name a
+// single strong entity tag for the requested calculator.
+var errInvalidIfMatch = errors.New("If-Match must be a single strong entity tag for this calculator")
+
+// calculatorETag returns the strong entity tag for a calculator at a given
+// config_version. Any config change increments config_version, so the tag
+// changes exactly when the config does.
+func calculatorETag(id string, version int) string {
+	return fmt.Sprintf(`"%s.%d"`, id, version)
+}
+
+// publicConfigETag returns the strong entity tag for a calculator's published
+// config at a given version. The config is served upgraded to the current
+// schema version, so a deploy that adds an upgrade step changes the body of an
+// unchanged calculator, and the tag changes with it.
+func publicConfigETag(id string, version int) string {
+	return fmt.Sprintf(`"%s.%d.s%d"`, id, version, configschema.Version)
+}
+
+// parseIfMatch extracts the config_version from an If-Match header produced
+// from calculatorETag. An empty header or "*" imposes no precondition and
+// returns 0.
+func parseIfMatch(header, id string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	// Weak tags cannot be used for If-Match (RFC 9110 §13.1.1), and lists are
+	// rejected because a config has exactly one current version.
+	if !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) || len(header) < 2 {
+		return 0, errInvalidIfMatch
+	}
+	opaque := header[1 : len(header)-1]
+	versionStr, ok := strings.CutPrefix(opaque, id+".")
+	if !ok {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+// etagMatches reports whether an If-None-Match header matches etag. The header
+// may list several tags or be "*"; comparison is weak (RFC 9110 §13.1.2), so a
+// W/ prefix on either side is ignored.
+func etagMatches(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
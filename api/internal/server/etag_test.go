@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCalculatorETag(t *testing.T) {
+	if got := calculatorETag("calc-abc", 3); got != `"calc-abc.3"` {
+		t.Errorf("calculatorETag() = %s, want %s", got, `"calc-abc.3"`)
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{"empty", "", 0, false},
+		{"wildcard", "*", 0, false},
+		{"round trip", calculatorETag("calc-abc", 7), 7, false},
+		{"surrounding whitespace", ` "calc-abc.7" `, 7, false},
+		{"weak tag", `W/"calc-abc.7"`, 0, true},
+		{"unquoted", `calc-abc.7`, 0, true},
+		{"other calculator", `"calc-xyz.7"`, 0, true},
+		{"list", `"calc-abc.7", "calc-abc.8"`, 0, true},
+		{"non-numeric version", `"calc-abc.x"`, 0, true},
+		{"zero version", `"calc-abc.0"`, 0, true},
+		{"lone quote", `"`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header, "calc-abc")
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidIfMatch) {
+					t.Errorf("expected errInvalidIfMatch, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseIfMatch() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	etag := calculatorETag("calc-abc", 3)
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"empty", "", false},
+		{"wildcard", "*", true},
+		{"exact", `"calc-abc.3"`, true},
+		{"weak", `W/"calc-abc.3"`, true},
+		{"list", `"calc-abc.2", "calc-abc.3"`, true},
+		{"stale version", `"calc-abc.2"`, false},
+		{"unquoted", `calc-abc.3`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
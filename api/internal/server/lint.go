@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// CalculatorLinter analyses a calculator's formulas.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorLinter interface {
+	Lint(ctx context.Context, id, userID string, config []byte) ([]calculator.FormulaDiagnostic, error)
+}
+
+// formulaDiagnosticResponse is the JSON shape of a calculator.FormulaDiagnostic.
+type formulaDiagnosticResponse struct {
+	OutputID   string `json:"output_id"`
+	Path       string `json:"path"`
+	Code       string `json:"code"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Position   int    `json:"position"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// newFormulaDiagnosticsResponse converts diags for a response, using an empty
+// array rather than null when there are none.
+func newFormulaDiagnosticsResponse(diags []calculator.FormulaDiagnostic) []formulaDiagnosticResponse {
+	resp := make([]formulaDiagnosticResponse, len(diags))
+	for i, d := range diags {
+		resp[i] = formulaDiagnosticResponse{
+			OutputID:   d.OutputID,
+			Path:       d.Path,
+			Code:       d.Code,
+			Severity:   string(d.Severity),
+			Message:    d.Message,
+			Position:   d.Pos,
+			Suggestion: d.Suggestion,
+		}
+	}
+	return resp
+}
+
+// lintResponse is the response body of POST /v1/calculators/{id}/lint.
+type lintResponse struct {
+	Diagnostics []formulaDiagnosticResponse `json:"diagnostics"`
+}
+
+// savedCalculatorResponse is the response to a config save: the calculator
+// plus the formula diagnostics for the config just saved. Drafts may be saved
+// with broken formulas, so diagnostics never block a save.
+type savedCalculatorResponse struct {
+	calculatorResponse
+	Diagnostics []formulaDiagnosticResponse `json:"diagnostics"`
+}
+
+// newSavedCalculatorResponse builds the response to a config save of calc.
+// A config that cannot be linted is logged and reported with no diagnostics
+// rather than failing a save that has already been written.
+func newSavedCalculatorResponse(r *http.Request, calc *calculator.Calculator) savedCalculatorResponse {
+	diags, err := calculator.LintConfig(calc.Config)
+	if err != nil {
+		LoggerFrom(r.Context()).Error("linting saved config", "calculator_id", calc.ID, "error", err)
+	}
+	return savedCalculatorResponse{
+		calculatorResponse: newCalculatorResponse(calc),
+		Diagnostics:        newFormulaDiagnosticsResponse(diags),
+	}
+}
+
+// lintRequest is the optional request body of POST /v1/calculators/{id}/lint.
+type lintRequest struct {
+	Config json.RawMessage `json:"config"`
+}
+
+// lintCalculatorHandler returns an http.HandlerFunc for POST /v1/calculators/{id}/lint.
+// The body may carry an unsaved config to lint; with no body, or no config in
+// it, the saved draft is linted.
+func lintCalculatorHandler(svc CalculatorLinter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		var req lintRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		var config []byte
+		if len(req.Config) > 0 && string(req.Config) != "null" {
+			config = req.Config
+		}
+
+		id := chi.URLParam(r, "id")
+		diags, err := svc.Lint(r.Context(), id, userID, config)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeConfigValidationError(w, verr)
+				return
+			}
+			LoggerFrom(r.Context()).Error("linting calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, lintResponse{Diagnostics: newFormulaDiagnosticsResponse(diags)})
+	}
+}
+
+// MountCalculatorLint registers the formula lint route on the server's private authenticated group.
+func (s *Server) MountCalculatorLint(validator TokenValidator, svc CalculatorLinter) {
+	protected := s.Authenticated(validator)
+	protected.Post("/calculators/{id}/lint", lintCalculatorHandler(svc))
+}
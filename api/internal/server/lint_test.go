@@ -0,0 +1,132 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/formula"
+)
+
+func TestLintCalculatorHandler_Success(t *testing.T) {
+	svc := &stubLinter{diags: []calculator.FormulaDiagnostic{{
+		OutputID: "out-1",
+		Path:     "outputs[0].expression",
+		Diagnostic: formula.Diagnostic{
+			Code:       formula.CodeUnknownVariable,
+			Severity:   formula.SeverityError,
+			Message:    "Unknown variable: {numbathrooms}. Did you mean {num_bathrooms}?",
+			Pos:        0,
+			Suggestion: "num_bathrooms",
+		},
+	}}}
+	h := lintCalculatorHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/calc-abc/lint", "", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotConfig != nil {
+		t.Errorf("expected saved draft to be linted without a body, got config %s", svc.gotConfig)
+	}
+	var env Envelope[lintResponse]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.Data.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", env.Data.Diagnostics)
+	}
+	d := env.Data.Diagnostics[0]
+	if d.Code != "unknown_variable" || d.Severity != "error" || d.Path != "outputs[0].expression" || d.Suggestion != "num_bathrooms" {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+}
+
+func TestLintCalculatorHandler_UnsavedConfig(t *testing.T) {
+	svc := &stubLinter{}
+	h := lintCalculatorHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/calc-abc/lint", `{"config":{"fields":[]}}`, "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if string(svc.gotConfig) != `{"fields":[]}` {
+		t.Errorf("expected body config to be linted, got %s", svc.gotConfig)
+	}
+	var env Envelope[map[string]any]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if diags, ok := env.Data["diagnostics"].([]any); !ok || len(diags) != 0 {
+		t.Errorf("expected an empty diagnostics array, got %v", env.Data["diagnostics"])
+	}
+}
+
+func TestLintCalculatorHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"bad body", `{`, nil, http.StatusBadRequest, ErrCodeBadRequest},
+		{"not found", "", calculator.ErrNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{"forbidden", "", calculator.ErrForbidden, http.StatusForbidden, ErrCodeForbidden},
+		{"invalid config", `{"config":[]}`, &configschema.ValidationError{Errors: []configschema.FieldError{{Path: "config", Message: "must be a JSON object"}}},
+			http.StatusUnprocessableEntity, ErrCodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := lintCalculatorHandler(&stubLinter{err: tt.err})
+			req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/calc-abc/lint", tt.body, "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var env Envelope[any]
+			if err := decodeEnvelope(rec, &env); err != nil {
+				t.Fatal(err)
+			}
+			if env.Error == nil || env.Error.Code != tt.wantCode {
+				t.Errorf("expected error code %q, got %+v", tt.wantCode, env.Error)
+			}
+		})
+	}
+}
+
+func TestUpdateCalculatorHandler_IncludesDiagnostics(t *testing.T) {
+	svc := &stubCalculatorService{calc: &calculator.Calculator{
+		ID:     "calc-abc",
+		Config: []byte(`{"fields":[{"variableName":"qty"}],"outputs":[{"id":"out-1","expression":"{qtty} / 0"}]}`),
+	}}
+	h := updateCalculatorHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPut, "/v1/calculators/calc-abc", `{"config":{}}`, "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var env Envelope[savedCalculatorResponse]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Data.ID != "calc-abc" {
+		t.Errorf("expected calculator fields alongside diagnostics, got id %q", env.Data.ID)
+	}
+	if len(env.Data.Diagnostics) != 2 || env.Data.Diagnostics[0].Suggestion != "qty" || env.Data.Diagnostics[1].Code != "division_by_zero" {
+		t.Errorf("unexpected diagnostics %+v", env.Data.Diagnostics)
+	}
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// CalculatorMetadataUpdater replaces the metadata of an existing calculator. A
+// non-zero expectedVersion makes the update conditional on the current
+// metadata_version.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorMetadataUpdater interface {
+	UpdateMetadata(ctx context.Context, id, userID string, expectedVersion int, m calculator.Metadata) (*calculator.Calculator, error)
+}
+
+// CalculatorFolderService manages the authenticated user's calculator folders.
+type CalculatorFolderService interface {
+	ListFolders(ctx context.Context, userID string) ([]*calculator.Folder, error)
+	CreateFolder(ctx context.Context, userID, name string) (*calculator.Folder, error)
+	RenameFolder(ctx context.Context, id, userID, name string) (*calculator.Folder, error)
+	DeleteFolder(ctx context.Context, id, userID string) error
+}
+
+// CalculatorMetadataService is the full set of metadata and folder capabilities consumed by the server.
+type CalculatorMetadataService interface {
+	CalculatorMetadataUpdater
+	CalculatorFolderService
+}
+
+// updateMetadataRequest is the request body for PUT /v1/calculators/{id}/metadata.
+// Every field is replaced; omitted fields are cleared. MetadataVersion is an
+// optional precondition.
+type updateMetadataRequest struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Tags            []string `json:"tags"`
+	FolderID        *string  `json:"folder_id"`
+	MetadataVersion *int     `json:"metadata_version"`
+}
+
+// metadataConflictResponse is the data payload of a 409 response to a stale
+// conditional metadata update.
+type metadataConflictResponse struct {
+	ID              string `json:"id"`
+	MetadataVersion int    `json:"metadata_version"`
+}
+
+// folderRequest is the request body for creating or renaming a folder.
+type folderRequest struct {
+	Name string `json:"name"`
+}
+
+// folderResponse is the folder shape returned by the folder endpoints.
+type folderResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newFolderResponse(f *calculator.Folder) folderResponse {
+	return folderResponse{ID: f.ID, Name: f.Name, CreatedAt: f.CreatedAt, UpdatedAt: f.UpdatedAt}
+}
+
+// writeMetadataError writes a 422 response for a metadata field that failed validation.
+func writeMetadataError(w http.ResponseWriter, merr *calculator.MetadataError) {
+	WriteErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, "metadata failed validation",
+		[]ErrorDetail{{Field: merr.Field, Message: merr.Message}})
+}
+
+// updateMetadataHandler returns an http.HandlerFunc for PUT /v1/calculators/{id}/metadata.
+//
+// Metadata has its own metadata_version, so this never changes config_version
+// or the calculator's ETag, and published widgets are unaffected.
+func updateMetadataHandler(svc CalculatorMetadataUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+
+		var req updateMetadataRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		expected := 0
+		if req.MetadataVersion != nil {
+			if *req.MetadataVersion < 1 {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "metadata_version must be a positive integer")
+				return
+			}
+			expected = *req.MetadataVersion
+		}
+		m := calculator.Metadata{Name: req.Name, Description: req.Description, Tags: req.Tags}
+		if req.FolderID != nil {
+			m.FolderID = *req.FolderID
+		}
+
+		id := chi.URLParam(r, "id")
+		calc, err := svc.UpdateMetadata(r.Context(), id, userID, expected, m)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			if errors.Is(err, calculator.ErrFolderNotFound) {
+				writeMetadataError(w, &calculator.MetadataError{Field: "folder_id", Message: "folder not found"})
+				return
+			}
+			var merr *calculator.MetadataError
+			if errors.As(err, &merr) {
+				writeMetadataError(w, merr)
+				return
+			}
+			var conflict *calculator.MetadataVersionConflictError
+			if errors.As(err, &conflict) {
+				WriteErrorWithData(w, http.StatusConflict, ErrCodeConflict, "calculator metadata was modified by another request",
+					metadataConflictResponse{ID: id, MetadataVersion: conflict.CurrentVersion})
+				return
+			}
+			LoggerFrom(r.Context()).Error("updating calculator metadata", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("ETag", calculatorETag(calc.ID, calc.ConfigVersion))
+		WriteJSON(w, http.StatusOK, newCalculatorResponse(calc))
+	}
+}
+
+// listFoldersHandler returns an http.HandlerFunc for GET /v1/folders.
+func listFoldersHandler(svc CalculatorFolderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		folders, err := svc.ListFolders(r.Context(), userID)
+		if err != nil {
+			LoggerFrom(r.Context()).Error("listing folders", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		resp := make([]folderResponse, len(folders))
+		for i, f := range folders {
+			resp[i] = newFolderResponse(f)
+		}
+		WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// createFolderHandler returns an http.HandlerFunc for POST /v1/folders.
+func createFolderHandler(svc CalculatorFolderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		var req folderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		f, err := svc.CreateFolder(r.Context(), userID, req.Name)
+		if err != nil {
+			if writeFolderError(w, err) {
+				return
+			}
+			LoggerFrom(r.Context()).Error("creating folder", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusCreated, newFolderResponse(f))
+	}
+}
+
+// renameFolderHandler returns an http.HandlerFunc for PUT /v1/folders/{id}.
+func renameFolderHandler(svc CalculatorFolderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		var req folderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		if !calculator.ValidFolderID(id) {
+			WriteError(w, http.StatusNotFound, ErrCodeNotFound, "folder not found")
+			return
+		}
+		f, err := svc.RenameFolder(r.Context(), id, userID, req.Name)
+		if err != nil {
+			if writeFolderError(w, err) {
+				return
+			}
+			LoggerFrom(r.Context()).Error("renaming folder", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, newFolderResponse(f))
+	}
+}
+
+// deleteFolderHandler returns an http.HandlerFunc for DELETE /v1/folders/{id}.
+// The folder's calculators become unfiled.
+func deleteFolderHandler(svc CalculatorFolderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		if !calculator.ValidFolderID(id) {
+			WriteError(w, http.StatusNotFound, ErrCodeNotFound, "folder not found")
+			return
+		}
+		if err := svc.DeleteFolder(r.Context(), id, userID); err != nil {
+			if writeFolderError(w, err) {
+				return
+			}
+			LoggerFrom(r.Context()).Error("deleting folder", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// writeFolderError maps errors from folder operations to responses.
+// It returns false if err is not a recognised client error.
+func writeFolderError(w http.ResponseWriter, err error) bool {
+	var merr *calculator.MetadataError
+	switch {
+	case errors.Is(err, calculator.ErrFolderNotFound):
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, "folder not found")
+	case errors.Is(err, calculator.ErrFolderExists):
+		WriteError(w, http.StatusConflict, ErrCodeConflict, "a folder with that name already exists")
+	case errors.As(err, &merr):
+		writeMetadataError(w, merr)
+	default:
+		return false
+	}
+	return true
+}
+
+// MountCalculatorMetadata registers the metadata and folder routes on the server's private authenticated group.
+func (s *Server) MountCalculatorMetadata(validator TokenValidator, svc CalculatorMetadataService) {
+	protected := s.Authenticated(validator)
+	protected.Put("/calculators/{id}/metadata", updateMetadataHandler(svc))
+	protected.Get("/folders", listFoldersHandler(svc))
+	protected.Post("/folders", createFolderHandler(svc))
+	protected.Put("/folders/{id}", renameFolderHandler(svc))
+	protected.Delete("/folders/{id}", deleteFolderHandler(svc))
+}
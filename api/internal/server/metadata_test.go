@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+const testFolderID = "7d3c9a4e-0b1f-4c2a-9e8d-5f6a7b8c9d0e"
+
+// newAuthedChiRequest builds a request with a JSON body, one chi URL
+// parameter, and an authenticated user.
+func newAuthedChiRequest(method, path, body, paramKey, paramVal string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(paramKey, paramVal)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, authUserKey{}, "user-xyz")
+	return req.WithContext(ctx)
+}
+
+func TestUpdateMetadataHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := &stubMetadataService{calc: &calculator.Calculator{
+		ID:              "calc-abc",
+		Name:            "Roof quote",
+		Tags:            []string{"roofing"},
+		FolderID:        testFolderID,
+		MetadataVersion: 4,
+		Config:          []byte(`{}`),
+		ConfigVersion:   7,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}}
+	h := updateMetadataHandler(svc)
+
+	body := `{"name":"Roof quote","description":"","tags":["Roofing"],"folder_id":"` + testFolderID + `","metadata_version":3}`
+	req := newAuthedChiRequest(http.MethodPut, "/v1/calculators/calc-abc/metadata", body, "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotExpected != 3 {
+		t.Errorf("expected metadata_version 3 passed through, got %d", svc.gotExpected)
+	}
+	if got := svc.gotMetadata; got.Name != "Roof quote" || got.FolderID != testFolderID || !slices.Equal(got.Tags, []string{"Roofing"}) {
+		t.Errorf("unexpected metadata passed to service: %+v", got)
+	}
+	// The ETag tracks config_version, which a metadata edit does not change.
+	if etag := rec.Header().Get("ETag"); etag != `"calc-abc.7"` {
+		t.Errorf("expected ETag %q, got %q", `"calc-abc.7"`, etag)
+	}
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Data["metadata_version"] != float64(4) || env.Data["folder_id"] != testFolderID || env.Data["name"] != "Roof quote" {
+		t.Errorf("unexpected response data: %v", env.Data)
+	}
+}
+
+func TestUpdateMetadataHandler_NullFolderUnfiles(t *testing.T) {
+	svc := &stubMetadataService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`)}}
+	h := updateMetadataHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPut, "/v1/calculators/calc-abc/metadata", `{"name":"x","folder_id":null}`, "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if svc.gotMetadata.FolderID != "" {
+		t.Errorf("expected empty folder ID, got %q", svc.gotMetadata.FolderID)
+	}
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if v, ok := env.Data["folder_id"]; !ok || v != nil {
+		t.Errorf("expected folder_id null, got %v", v)
+	}
+	if tags, ok := env.Data["tags"].([]any); !ok || len(tags) != 0 {
+		t.Errorf("expected empty tags array, got %v", env.Data["tags"])
+	}
+}
+
+func TestUpdateMetadataHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{"invalid body", `not-json`, nil, http.StatusBadRequest, ErrCodeBadRequest},
+		{"bad version", `{"metadata_version":0}`, nil, http.StatusBadRequest, ErrCodeBadRequest},
+		{"not found", `{}`, calculator.ErrNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{"forbidden", `{}`, calculator.ErrForbidden, http.StatusForbidden, ErrCodeForbidden},
+		{"unknown folder", `{}`, calculator.ErrFolderNotFound, http.StatusUnprocessableEntity, ErrCodeValidation},
+		{"invalid field", `{}`, &calculator.MetadataError{Field: "tags", Message: "too many"}, http.StatusUnprocessableEntity, ErrCodeValidation},
+		{"conflict", `{}`, &calculator.MetadataVersionConflictError{CurrentVersion: 5}, http.StatusConflict, ErrCodeConflict},
+		{"internal", `{}`, errors.New("db failure"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.err != nil {
+				err = fmt.Errorf("updating calculator metadata: %w", tt.err)
+			}
+			h := updateMetadataHandler(&stubMetadataService{err: err})
+
+			req := newAuthedChiRequest(http.MethodPut, "/v1/calculators/calc-abc/metadata", tt.body, "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d (body: %s)", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			var env Envelope[map[string]any]
+			if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if env.Error == nil || env.Error.Code != tt.wantErr {
+				t.Errorf("expected error code %q, got %+v", tt.wantErr, env.Error)
+			}
+		})
+	}
+}
+
+func TestUpdateMetadataHandler_ConflictCarriesVersion(t *testing.T) {
+	h := updateMetadataHandler(&stubMetadataService{err: &calculator.MetadataVersionConflictError{CurrentVersion: 5}})
+
+	req := newAuthedChiRequest(http.MethodPut, "/v1/calculators/calc-abc/metadata", `{"metadata_version":4}`, "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Data["metadata_version"] != float64(5) {
+		t.Errorf("expected current metadata_version 5 in conflict data, got %v", env.Data)
+	}
+}
+
+func TestCreateFolderHandler(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{"created", `{"name":"Clients"}`, nil, http.StatusCreated},
+		{"invalid body", `[`, nil, http.StatusBadRequest},
+		{"duplicate", `{"name":"Clients"}`, calculator.ErrFolderExists, http.StatusConflict},
+		{"invalid name", `{"name":""}`, &calculator.MetadataError{Field: "name", Message: "is required"}, http.StatusUnprocessableEntity},
+		{"internal", `{"name":"Clients"}`, errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubMetadataService{folder: &calculator.Folder{ID: testFolderID, Name: "Clients", CreatedAt: now, UpdatedAt: now}, err: tt.err}
+			h := createFolderHandler(svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/folders", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d (body: %s)", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRenameFolderHandler_MalformedID(t *testing.T) {
+	svc := &stubMetadataService{}
+	h := renameFolderHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPut, "/v1/folders/inbox", `{"name":"Clients"}`, "id", "inbox")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+	if svc.gotName != "" {
+		t.Error("expected service not to be called for a malformed folder ID")
+	}
+}
+
+func TestDeleteFolderHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"deleted", nil, http.StatusNoContent},
+		{"not found", calculator.ErrFolderNotFound, http.StatusNotFound},
+		{"internal", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := deleteFolderHandler(&stubMetadataService{err: tt.err})
+
+			req := newAuthedChiRequest(http.MethodDelete, "/v1/folders/"+testFolderID, "", "id", testFolderID)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMountCalculatorMetadata_RegistersRoutes(t *testing.T) {
+	s := testServer(t)
+	authSvc := &stubAuthService{userID: "user-xyz"}
+	s.MountCalculators(authSvc, &stubCalculatorService{err: calculator.ErrNotFound})
+	s.MountCalculatorMetadata(authSvc, &stubMetadataService{
+		calc:    &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`)},
+		folders: []*calculator.Folder{},
+		folder:  &calculator.Folder{ID: testFolderID},
+	})
+
+	tests := []struct {
+		method, path, body string
+		wantCode           int
+	}{
+		{http.MethodPut, "/v1/calculators/calc-abc/metadata", `{"name":"x"}`, http.StatusOK},
+		{http.MethodGet, "/v1/folders", "", http.StatusOK},
+		{http.MethodPost, "/v1/folders", `{"name":"Clients"}`, http.StatusCreated},
+		{http.MethodPut, "/v1/folders/" + testFolderID, `{"name":"Clients"}`, http.StatusOK},
+		{http.MethodDelete, "/v1/folders/" + testFolderID, "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.wantCode, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// Default size of an embedded calculator, shrunk to fit a consumer's maxwidth
+// and maxheight. Height is a hint: the widget sizes itself to its content.
+const (
+	defaultOEmbedWidth  = 600
+	defaultOEmbedHeight = 640
+)
+
+// oembedResponse is a "rich" oEmbed 1.0 response.
+type oembedResponse struct {
+	Type         string `json:"type"`
+	Version      string `json:"version"`
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	CacheAge     int    `json:"cache_age"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// oembedURL returns the oEmbed endpoint URL that describes the standalone
+// page at pageURL, for discovery links in that page.
+func oembedURL(publicURL, pageURL string) string {
+	return publicURL + "/oembed?format=json&url=" + url.QueryEscape(pageURL)
+}
+
+// oembedCalculatorID returns the ID of the calculator whose standalone page
+// is raw, or "" if raw is not a standalone page URL on this server.
+func oembedCalculatorID(publicURL, raw string) string {
+	base, err := url.Parse(publicURL)
+	if err != nil {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Host, base.Host) {
+		return ""
+	}
+	id, ok := strings.CutPrefix(strings.TrimSuffix(u.Path, "/"), strings.TrimSuffix(base.Path, "/")+"/c/")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return ""
+	}
+	return id
+}
+
+// oembedDimension parses the maxwidth or maxheight parameter named key and
+// returns def capped to it. ok is false if the parameter is not a positive
+// integer.
+func oembedDimension(r *http.Request, key string, def int) (n int, ok bool) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return def, true
+	}
+	max, err := strconv.Atoi(raw)
+	if err != nil || max <= 0 {
+		return 0, false
+	}
+	return min(def, max), true
+}
+
+// oembedHandler returns an http.HandlerFunc for GET /oembed, the oEmbed
+// provider endpoint for standalone calculator URLs. The embed HTML is an
+// iframe of the standalone page, which consumers that strip scripts still
+// accept.
+//
+// The oEmbed spec fixes the response shape, so unlike the /v1 API the
+// response is not wrapped in an Envelope, and errors are bare status codes.
+func oembedHandler(svc CalculatorPublicConfigGetter, publicURL string) http.HandlerFunc {
+	publicURL = strings.TrimSuffix(publicURL, "/")
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if format := q.Get("format"); format != "" && format != "json" {
+			http.Error(w, "only the json format is supported", http.StatusNotImplemented)
+			return
+		}
+		width, ok := oembedDimension(r, "maxwidth", defaultOEmbedWidth)
+		if !ok {
+			http.Error(w, "maxwidth must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		height, ok := oembedDimension(r, "maxheight", defaultOEmbedHeight)
+		if !ok {
+			http.Error(w, "maxheight must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		if q.Get("url") == "" {
+			http.Error(w, "url is required", http.StatusBadRequest)
+			return
+		}
+		id := oembedCalculatorID(publicURL, q.Get("url"))
+		if id == "" {
+			http.Error(w, "not a calculator URL", http.StatusNotFound)
+			return
+		}
+
+		calc, err := svc.GetPublicConfig(r.Context(), id)
+		// A calculator restricted to an embed allow-list has no standalone page to frame.
+		if err == nil && len(calc.EmbedDomains) > 0 {
+			err = calculator.ErrNotFound
+		}
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				w.Header().Set("Cache-Control", "no-store")
+				http.Error(w, "calculator not found", http.StatusNotFound)
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting oembed calculator", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		title := strings.TrimSpace(calc.Name)
+		if title == "" {
+			title = defaultPageTitle
+		}
+		resp := oembedResponse{
+			Type:         "rich",
+			Version:      "1.0",
+			Title:        title,
+			ProviderName: "QuoteCraft",
+			ProviderURL:  publicURL,
+			CacheAge:     300,
+			HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" style="border:0;max-width:100%%" loading="lazy"></iframe>`,
+				html.EscapeString(standalonePageURL(publicURL, calc.ID)), width, height, html.EscapeString(title)),
+			Width:  width,
+			Height: height,
+		}
+		body, err := json.Marshal(resp)
+		if err != nil {
+			LoggerFrom(r.Context()).Error("encoding oembed response", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Header().Set("Surrogate-Key", calculator.CacheKey(calc.ID))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}
+}
+
+// MountOEmbed registers GET /oembed at the root of the server, where oEmbed
+// consumers discover it from the standalone pages.
+func (s *Server) MountOEmbed(svc CalculatorPublicConfigGetter) {
+	limiter := newRateLimiter(60) // 60 requests per minute per IP
+	s.mux.Group(func(r chi.Router) {
+		r.Use(IPRateLimit(limiter))
+		r.Get("/oembed", oembedHandler(svc, s.cfg.PublicURL))
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+func serveOEmbed(t *testing.T, svc CalculatorPublicConfigGetter, query url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	h := oembedHandler(svc, "https://quotecraft.example/")
+	req := httptest.NewRequest(http.MethodGet, "/oembed?"+query.Encode(), nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOEmbedHandler_Success(t *testing.T) {
+	svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Name: `Roof "quote"`}}
+	rec := serveOEmbed(t, svc, url.Values{
+		"url":       {"https://quotecraft.example/c/calc-abc"},
+		"format":    {"json"},
+		"maxwidth":  {"400"},
+		"maxheight": {"1000"},
+	})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp oembedResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Type != "rich" || resp.Version != "1.0" || resp.ProviderName != "QuoteCraft" || resp.ProviderURL != "https://quotecraft.example" {
+		t.Errorf("unexpected response metadata: %+v", resp)
+	}
+	if resp.Title != `Roof "quote"` {
+		t.Errorf("expected title %q, got %q", `Roof "quote"`, resp.Title)
+	}
+	if resp.Width != 400 || resp.Height != defaultOEmbedHeight {
+		t.Errorf("expected 400x%d, got %dx%d", defaultOEmbedHeight, resp.Width, resp.Height)
+	}
+	for _, want := range []string{`src="https://quotecraft.example/c/calc-abc"`, `width="400"`, `title="Roof &#34;quote&#34;"`} {
+		if !strings.Contains(resp.HTML, want) {
+			t.Errorf("expected html to contain %q, got %q", want, resp.HTML)
+		}
+	}
+	if got := rec.Header().Get("Surrogate-Key"); got != "calculator-calc-abc" {
+		t.Errorf("expected Surrogate-Key %q, got %q", "calculator-calc-abc", got)
+	}
+}
+
+func TestOEmbedHandler_Errors(t *testing.T) {
+	found := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc"}}
+	tests := []struct {
+		name       string
+		svc        *stubCalculatorService
+		query      url.Values
+		wantStatus int
+	}{
+		{"missing url", found, url.Values{}, http.StatusBadRequest},
+		{"xml format", found, url.Values{"url": {"https://quotecraft.example/c/calc-abc"}, "format": {"xml"}}, http.StatusNotImplemented},
+		{"bad maxwidth", found, url.Values{"url": {"https://quotecraft.example/c/calc-abc"}, "maxwidth": {"-5"}}, http.StatusBadRequest},
+		{"other host", found, url.Values{"url": {"https://evil.example/c/calc-abc"}}, http.StatusNotFound},
+		{"not a calculator page", found, url.Values{"url": {"https://quotecraft.example/pricing"}}, http.StatusNotFound},
+		{"deleted", &stubCalculatorService{err: calculator.ErrNotFound}, url.Values{"url": {"https://quotecraft.example/c/calc-abc"}}, http.StatusNotFound},
+		{"embed restricted", &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", EmbedDomains: []string{"example.com"}}},
+			url.Values{"url": {"https://quotecraft.example/c/calc-abc"}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serveOEmbed(t, tt.svc, tt.query); rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestOEmbedCalculatorID(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://quotecraft.example/c/calc-abc", "calc-abc"},
+		{"http://QuoteCraft.example/c/calc-abc/", "calc-abc"},
+		{"https://quotecraft.example/c/calc-abc/extra", ""},
+		{"https://quotecraft.example/c/", ""},
+		{"ftp://quotecraft.example/c/calc-abc", ""},
+	}
+	for _, tt := range tests {
+		if got := oembedCalculatorID("https://quotecraft.example", tt.raw); got != tt.want {
+			t.Errorf("oembedCalculatorID(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/jsonpatch"
+)
+
+// CalculatorPublisher promotes a calculator's draft config to the version
+// served to widgets. A non-zero expectedVersion makes the publish conditional
+// on the current config_version.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorPublisher interface {
+	Publish(ctx context.Context, id, userID string, expectedVersion int) (*calculator.Calculator, error)
+}
+
+// CalculatorDiffer compares a calculator's draft config with its published snapshot.
+type CalculatorDiffer interface {
+	Diff(ctx context.Context, id, userID string) (*calculator.ConfigDiff, error)
+}
+
+// CalculatorPublishService is the full set of publishing capabilities consumed by the server.
+type CalculatorPublishService interface {
+	CalculatorPublisher
+	CalculatorDiffer
+}
+
+// configDiffResponse is the data payload returned by GET /v1/calculators/{id}/diff.
+// Operations is a JSON Patch that turns the published config into the draft.
+type configDiffResponse struct {
+	DraftVersion     int                   `json:"draft_version"`
+	PublishedVersion int                   `json:"published_version"`
+	HasChanges       bool                  `json:"has_changes"`
+	Operations       []jsonpatch.Operation `json:"operations"`
+}
+
+// publishCalculatorHandler returns an http.HandlerFunc for POST /v1/calculators/{id}/publish.
+//
+// Clients can send the ETag from GET as If-Match to publish only the draft
+// they reviewed; if the draft has changed since, the response is 409 CONFLICT
+// carrying the current version.
+func publishCalculatorHandler(svc CalculatorPublisher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		expected, err := parseIfMatch(r.Header.Get("If-Match"), id)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+		calc, err := svc.Publish(r.Context(), id, userID, expected)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			var conflict *calculator.VersionConflictError
+			if errors.As(err, &conflict) {
+				writeVersionConflict(w, id, conflict)
+				return
+			}
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeValidationError(w, "draft config is incomplete", verr)
+				return
+			}
+			LoggerFrom(r.Context()).Error("publishing calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("ETag", calculatorETag(calc.ID, calc.ConfigVersion))
+		WriteJSON(w, http.StatusOK, newCalculatorResponse(calc))
+	}
+}
+
+// diffCalculatorHandler returns an http.HandlerFunc for GET /v1/calculators/{id}/diff.
+func diffCalculatorHandler(svc CalculatorDiffer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		diff, err := svc.Diff(r.Context(), id, userID)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("diffing calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, configDiffResponse{
+			DraftVersion:     diff.DraftVersion,
+			PublishedVersion: diff.PublishedVersion,
+			HasChanges:       len(diff.Operations) > 0,
+			Operations:       diff.Operations,
+		})
+	}
+}
+
+// MountCalculatorPublishing registers the publish and diff routes on the server's private authenticated group.
+func (s *Server) MountCalculatorPublishing(validator TokenValidator, svc CalculatorPublishService) {
+	protected := s.Authenticated(validator)
+	protected.Post("/calculators/{id}/publish", publishCalculatorHandler(svc))
+	protected.Get("/calculators/{id}/diff", diffCalculatorHandler(svc))
+}
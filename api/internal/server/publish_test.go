@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/jsonpatch"
+)
+
+func TestPublishCalculatorHandler_Success(t *testing.T) {
+	svc := &stubPublishService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 4, PublishedVersion: 4}}
+	h := publishCalculatorHandler(svc)
+
+	req := newChiRequest(http.MethodPost, "/v1/calculators/calc-abc/publish", "id", "calc-abc")
+	req.Header.Set("If-Match", `"calc-abc.4"`)
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotExpected != 4 {
+		t.Errorf("expected If-Match version 4 passed through, got %d", svc.gotExpected)
+	}
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Data["published_version"] != float64(4) {
+		t.Errorf("expected published_version 4, got %v", env.Data["published_version"])
+	}
+}
+
+func TestPublishCalculatorHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  string
+		err      error
+		wantCode int
+	}{
+		{"bad If-Match", `W/"calc-abc.4"`, nil, http.StatusBadRequest},
+		{"not found", "", calculator.ErrNotFound, http.StatusNotFound},
+		{"forbidden", "", calculator.ErrForbidden, http.StatusForbidden},
+		{"stale draft", `"calc-abc.4"`, &calculator.VersionConflictError{CurrentVersion: 5}, http.StatusConflict},
+		{"incomplete draft", "", &configschema.ValidationError{Errors: []configschema.FieldError{{Path: "fields[0].options", Message: "must not be empty"}}}, http.StatusUnprocessableEntity},
+		{"internal", "", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.err != nil {
+				err = fmt.Errorf("publishing calculator: %w", tt.err)
+			}
+			h := publishCalculatorHandler(&stubPublishService{err: err})
+
+			req := newChiRequest(http.MethodPost, "/v1/calculators/calc-abc/publish", "id", "calc-abc")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestDiffCalculatorHandler_Success(t *testing.T) {
+	path := "/title"
+	svc := &stubPublishService{diff: &calculator.ConfigDiff{
+		DraftVersion:     6,
+		PublishedVersion: 5,
+		Operations:       []jsonpatch.Operation{{Op: "replace", Path: &path, Value: json.RawMessage(`"New"`)}},
+	}}
+	h := diffCalculatorHandler(svc)
+
+	req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/diff", "id", "calc-abc")
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[configDiffResponse]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	got := env.Data
+	if got.DraftVersion != 6 || got.PublishedVersion != 5 || !got.HasChanges || len(got.Operations) != 1 {
+		t.Errorf("unexpected diff response: %+v", got)
+	}
+}
+
+func TestDiffCalculatorHandler_NoChanges(t *testing.T) {
+	svc := &stubPublishService{diff: &calculator.ConfigDiff{DraftVersion: 5, PublishedVersion: 5, Operations: []jsonpatch.Operation{}}}
+	h := diffCalculatorHandler(svc)
+
+	req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/diff", "id", "calc-abc")
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Data["has_changes"] != false {
+		t.Errorf("expected has_changes false, got %v", env.Data["has_changes"])
+	}
+	if ops, ok := env.Data["operations"].([]any); !ok || len(ops) != 0 {
+		t.Errorf("expected empty operations array, got %v", env.Data["operations"])
+	}
+}
+
+func TestDiffCalculatorHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"not found", calculator.ErrNotFound, http.StatusNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden},
+		{"internal", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := diffCalculatorHandler(&stubPublishService{err: fmt.Errorf("getting calculator: %w", tt.err)})
+
+			req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/diff", "id", "calc-abc")
+			req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMountCalculatorPublishing_RegistersRoutes(t *testing.T) {
+	s := testServer(t)
+	authSvc := &stubAuthService{userID: "user-xyz"}
+	s.MountCalculatorPublishing(authSvc, &stubPublishService{
+		calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`)},
+		diff: &calculator.ConfigDiff{Operations: []jsonpatch.Operation{}},
+	})
+
+	for _, tt := range []struct{ method, path string }{
+		{http.MethodPost, "/v1/calculators/calc-abc/publish"},
+		{http.MethodGet, "/v1/calculators/calc-abc/diff"},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s: expected 200, got %d", tt.method, tt.path, rec.Code)
+		}
+	}
+}
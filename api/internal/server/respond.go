@@ -8,13 +8,18 @@ import (
 // Sentinel error codes used by handlers when calling WriteError. These codes
 // are included in the JSON error envelope and are safe to expose to clients.
 const (
-	ErrCodeInternal        = "INTERNAL_ERROR"
-	ErrCodeNotFound        = "NOT_FOUND"
-	ErrCodeBadRequest      = "BAD_REQUEST"
-	ErrCodeUnauthorized    = "UNAUTHORIZED"
-	ErrCodeForbidden       = "FORBIDDEN"
-	ErrCodeConflict        = "CONFLICT"
-	ErrCodeTooManyRequests = "TOO_MANY_REQUESTS"
+	ErrCodeInternal             = "INTERNAL_ERROR"
+	ErrCodeNotFound             = "NOT_FOUND"
+	ErrCodeBadRequest           = "BAD_REQUEST"
+	ErrCodeUnauthorized         = "UNAUTHORIZED"
+	ErrCodeForbidden            = "FORBIDDEN"
+	ErrCodeConflict             = "CONFLICT"
+	ErrCodeTooManyRequests      = "TOO_MANY_REQUESTS"
+	ErrCodeValidation           = "VALIDATION_ERROR"
+	ErrCodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	ErrCodeOriginNotAllowed     = "ORIGIN_NOT_ALLOWED"
+	ErrCodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	ErrCodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
 )
 
 // fallbackErrorBody is written verbatim when json.Marshal itself fails. Using a
@@ -22,17 +27,29 @@ const (
 // that could loop indefinitely.
 var fallbackErrorBody = []byte(`{"data":null,"error":{"code":"INTERNAL_ERROR","message":"internal error"},"meta":{}}`)
 
-// Meta carries pagination or other per-response metadata. It is always
-// serialized as an empty JSON object {} for now, but is exported so future
-// tasks can add fields without changing the call sites.
-type Meta struct{}
+// Meta carries pagination or other per-response metadata. Unset fields are
+// omitted, so most responses serialize it as an empty JSON object {}.
+type Meta struct {
+	// NextCursor is the opaque cursor for the next page of a paginated list.
+	// It is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
 
 // ErrorBody is the error section of the JSON envelope. Code is a machine-
 // readable sentinel (e.g., "NOT_FOUND") and Message is a human-readable
 // explanation safe to surface to API clients. Stack traces and internal details
 // are never included here; they are logged server-side.
 type ErrorBody struct {
-	Code    string `json:"code"`
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail pinpoints one problem within a request, such as a single invalid
+// property of a calculator config. Field is a path relative to the document
+// root (e.g., "fields[3].options").
+type ErrorDetail struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
@@ -76,10 +93,16 @@ func writeResponse(w http.ResponseWriter, status int, b []byte) {
 // channel), WriteJSON writes a hardcoded 500 fallback body rather than calling
 // itself recursively, which would loop indefinitely.
 func WriteJSON[T any](w http.ResponseWriter, status int, data T) {
+	WriteJSONWithMeta(w, status, data, Meta{})
+}
+
+// WriteJSONWithMeta behaves like WriteJSON but also populates the envelope's
+// meta section, for example with a pagination cursor.
+func WriteJSONWithMeta[T any](w http.ResponseWriter, status int, data T, meta Meta) {
 	env := Envelope[T]{
 		Data:  data,
 		Error: nil,
-		Meta:  Meta{},
+		Meta:  meta,
 	}
 
 	b, err := json.Marshal(env)
@@ -99,9 +122,34 @@ func WriteJSON[T any](w http.ResponseWriter, status int, data T) {
 // marshal-failure path. WriteJSON handles the marshal-failure case for
 // user-supplied data types that may be unmarshalable.
 func WriteError(w http.ResponseWriter, status int, code, message string) {
+	WriteErrorDetails(w, status, code, message, nil)
+}
+
+// WriteErrorWithData behaves like WriteError but also populates the envelope's
+// data section, for errors where the client needs machine-readable state to
+// recover — for example, the current version on an edit conflict.
+func WriteErrorWithData[T any](w http.ResponseWriter, status int, code, message string, data T) {
+	env := Envelope[T]{
+		Data:  data,
+		Error: &ErrorBody{Code: code, Message: message},
+		Meta:  Meta{},
+	}
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		writeResponse(w, 0, nil)
+		return
+	}
+	writeResponse(w, status, b)
+}
+
+// WriteErrorDetails behaves like WriteError but also includes per-field details
+// in the error body. A nil or empty details slice is omitted from the output,
+// making the response identical to WriteError's.
+func WriteErrorDetails(w http.ResponseWriter, status int, code, message string, details []ErrorDetail) {
 	env := Envelope[any]{
 		Data:  nil,
-		Error: &ErrorBody{Code: code, Message: message},
+		Error: &ErrorBody{Code: code, Message: message, Details: details},
 		Meta:  Meta{},
 	}
 
@@ -152,6 +152,48 @@ func TestWriteError_VariousStatusCodes(t *testing.T) {
 	}
 }
 
+// TestWriteErrorDetails_IncludesDetails verifies that WriteErrorDetails
+// serializes per-field details inside the error body.
+func TestWriteErrorDetails_IncludesDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteErrorDetails(rec, http.StatusUnprocessableEntity, ErrCodeValidation, "invalid config", []ErrorDetail{
+		{Field: "fields[3].options", Message: "must not be empty"},
+	})
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", rec.Code)
+	}
+
+	var env Envelope[any]
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("response body is not valid JSON: %v", err)
+	}
+	if env.Error == nil {
+		t.Fatal("expected non-null error field")
+	}
+	if len(env.Error.Details) != 1 {
+		t.Fatalf("expected 1 detail, got %d", len(env.Error.Details))
+	}
+	if env.Error.Details[0].Field != "fields[3].options" {
+		t.Errorf("expected field %q, got %q", "fields[3].options", env.Error.Details[0].Field)
+	}
+	if env.Error.Details[0].Message != "must not be empty" {
+		t.Errorf("expected message %q, got %q", "must not be empty", env.Error.Details[0].Message)
+	}
+}
+
+// TestWriteError_OmitsDetails verifies that errors without details keep the
+// original envelope shape (no "details" key).
+func TestWriteError_OmitsDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, http.StatusNotFound, ErrCodeNotFound, "not found")
+
+	want := `{"data":null,"error":{"code":"NOT_FOUND","message":"not found"},"meta":{}}`
+	if body := rec.Body.String(); body != want {
+		t.Errorf("expected body %s, got %s", want, body)
+	}
+}
+
 // TestErrCodes_NonEmpty verifies that all sentinel error code constants are
 // non-empty strings.
 func TestErrCodes_NonEmpty(t *testing.T) {
@@ -165,6 +207,7 @@ func TestErrCodes_NonEmpty(t *testing.T) {
 		{"ErrCodeUnauthorized", ErrCodeUnauthorized},
 		{"ErrCodeForbidden", ErrCodeForbidden},
 		{"ErrCodeConflict", ErrCodeConflict},
+		{"ErrCodeValidation", ErrCodeValidation},
 	}
 
 	for _, c := range codes {
@@ -196,6 +239,18 @@ func TestWriteJSON_MetaIsEmptyObject(t *testing.T) {
 	}
 }
 
+// TestWriteJSONWithMeta_NextCursor verifies that a pagination cursor is
+// serialized into meta alongside the data.
+func TestWriteJSONWithMeta_NextCursor(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteJSONWithMeta(rec, http.StatusOK, []string{"a"}, Meta{NextCursor: "abc"})
+
+	want := `{"data":["a"],"error":null,"meta":{"next_cursor":"abc"}}`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expected body %s, got %s", want, got)
+	}
+}
+
 // TestWriteError_MetaIsEmptyObject verifies that WriteError also produces
 // a meta field serialized as an empty JSON object {}.
 func TestWriteError_MetaIsEmptyObject(t *testing.T) {
@@ -298,3 +353,31 @@ func TestWriteResponse_WritesBodyAndStatus(t *testing.T) {
 		t.Errorf("expected body %q, got %q", string(body), rec.Body.String())
 	}
 }
+
+func TestWriteErrorWithData_IncludesDataAndError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteErrorWithData(rec, http.StatusConflict, ErrCodeConflict, "stale", map[string]int{"config_version": 7})
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rec.Code)
+	}
+	var env Envelope[map[string]int]
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("response body is not valid JSON: %v", err)
+	}
+	if env.Error == nil || env.Error.Code != ErrCodeConflict {
+		t.Fatalf("expected %q error, got %+v", ErrCodeConflict, env.Error)
+	}
+	if env.Data["config_version"] != 7 {
+		t.Errorf("expected data.config_version 7, got %v", env.Data)
+	}
+}
+
+func TestWriteErrorWithData_MarshalFailureFallsBack(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteErrorWithData(rec, http.StatusConflict, ErrCodeConflict, "stale", make(chan int))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// CalculatorRevisionLister lists the config history of a calculator.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorRevisionLister interface {
+	ListRevisions(ctx context.Context, id, userID string) ([]*calculator.Revision, error)
+}
+
+// CalculatorRevisionGetter fetches a single revision of a calculator.
+type CalculatorRevisionGetter interface {
+	GetRevision(ctx context.Context, id, userID string, version int) (*calculator.Revision, error)
+}
+
+// CalculatorRevisionRestorer restores an earlier revision as the calculator's
+// head. A non-zero expectedVersion makes the restore conditional on the
+// current config_version.
+type CalculatorRevisionRestorer interface {
+	RestoreRevision(ctx context.Context, id, userID string, version, expectedVersion int) (*calculator.Calculator, error)
+}
+
+// CalculatorRevisionService is the full set of revision capabilities consumed by the server.
+type CalculatorRevisionService interface {
+	CalculatorRevisionLister
+	CalculatorRevisionGetter
+	CalculatorRevisionRestorer
+}
+
+// revisionSummary is the per-item shape in a revision list response.
+type revisionSummary struct {
+	ConfigVersion       int       `json:"config_version"`
+	AuthorID            string    `json:"author_id,omitempty"`
+	RestoredFromVersion *int      `json:"restored_from_version,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// revisionResponse is the full revision shape returned by GET /v1/calculators/{id}/revisions/{version}.
+type revisionResponse struct {
+	ConfigVersion       int             `json:"config_version"`
+	Config              json.RawMessage `json:"config"`
+	AuthorID            string          `json:"author_id,omitempty"`
+	RestoredFromVersion *int            `json:"restored_from_version,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+}
+
+// parseRevisionVersion reads the {version} URL parameter. It returns false if
+// the parameter is not a positive integer.
+func parseRevisionVersion(r *http.Request) (int, bool) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// writeRevisionLookupError maps errors from revision lookups to responses.
+// It returns false if err is not a recognised client error.
+func writeRevisionLookupError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, calculator.ErrNotFound):
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+	case errors.Is(err, calculator.ErrForbidden):
+		WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+	case errors.Is(err, calculator.ErrRevisionNotFound):
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, "revision not found")
+	default:
+		return false
+	}
+	return true
+}
+
+// listRevisionsHandler returns an http.HandlerFunc for GET /v1/calculators/{id}/revisions.
+func listRevisionsHandler(svc CalculatorRevisionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		revs, err := svc.ListRevisions(r.Context(), id, userID)
+		if err != nil {
+			if writeRevisionLookupError(w, err) {
+				return
+			}
+			LoggerFrom(r.Context()).Error("listing revisions", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		summaries := make([]revisionSummary, len(revs))
+		for i, rev := range revs {
+			summaries[i] = revisionSummary{
+				ConfigVersion:       rev.ConfigVersion,
+				AuthorID:            rev.AuthorID,
+				RestoredFromVersion: rev.RestoredFromVersion,
+				CreatedAt:           rev.CreatedAt,
+			}
+		}
+		WriteJSON(w, http.StatusOK, summaries)
+	}
+}
+
+// getRevisionHandler returns an http.HandlerFunc for GET /v1/calculators/{id}/revisions/{version}.
+func getRevisionHandler(svc CalculatorRevisionGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		version, ok := parseRevisionVersion(r)
+		if !ok {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "version must be a positive integer")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		rev, err := svc.GetRevision(r.Context(), id, userID, version)
+		if err != nil {
+			if writeRevisionLookupError(w, err) {
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting revision", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, revisionResponse{
+			ConfigVersion:       rev.ConfigVersion,
+			Config:              json.RawMessage(rev.Config),
+			AuthorID:            rev.AuthorID,
+			RestoredFromVersion: rev.RestoredFromVersion,
+			CreatedAt:           rev.CreatedAt,
+		})
+	}
+}
+
+// restoreRevisionHandler returns an http.HandlerFunc for POST /v1/calculators/{id}/revisions/{version}/restore.
+// The response is the calculator's new head, in the same shape as PUT /v1/calculators/{id}.
+// Like PUT, it honours If-Match; a stale version yields 409 CONFLICT.
+func restoreRevisionHandler(svc CalculatorRevisionRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		version, ok := parseRevisionVersion(r)
+		if !ok {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "version must be a positive integer")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		expected, err := parseIfMatch(r.Header.Get("If-Match"), id)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+		calc, err := svc.RestoreRevision(r.Context(), id, userID, version, expected)
+		if err != nil {
+			if writeRevisionLookupError(w, err) {
+				return
+			}
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeConfigValidationError(w, verr)
+				return
+			}
+			var conflict *calculator.VersionConflictError
+			if errors.As(err, &conflict) {
+				writeVersionConflict(w, id, conflict)
+				return
+			}
+			LoggerFrom(r.Context()).Error("restoring revision", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("ETag", calculatorETag(calc.ID, calc.ConfigVersion))
+		WriteJSON(w, http.StatusOK, newCalculatorResponse(calc))
+	}
+}
+
+// MountCalculatorRevisions registers calculator revision routes on the server's private authenticated group.
+func (s *Server) MountCalculatorRevisions(validator TokenValidator, svc CalculatorRevisionService) {
+	protected := s.Authenticated(validator)
+	protected.Get("/calculators/{id}/revisions", listRevisionsHandler(svc))
+	protected.Get("/calculators/{id}/revisions/{version}", getRevisionHandler(svc))
+	protected.Post("/calculators/{id}/revisions/{version}/restore", restoreRevisionHandler(svc))
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// newRevisionRequest builds an authenticated request with {id} and {version} URL params.
+func newRevisionRequest(method, version string) *http.Request {
+	req := httptest.NewRequest(method, "/v1/calculators/calc-abc/revisions/"+version, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "calc-abc")
+	rctx.URLParams.Add("version", version)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, authUserKey{}, "user-xyz")
+	return req.WithContext(ctx)
+}
+
+func TestListRevisionsHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	restored := 1
+	svc := &stubRevisionService{revs: []*calculator.Revision{
+		{ConfigVersion: 3, AuthorID: "user-xyz", RestoredFromVersion: &restored, CreatedAt: now},
+		{ConfigVersion: 2, CreatedAt: now},
+	}}
+	h := listRevisionsHandler(svc)
+
+	req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/revisions", "id", "calc-abc")
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[[]map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(env.Data) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(env.Data))
+	}
+	if env.Data[0]["config_version"] != float64(3) || env.Data[0]["restored_from_version"] != float64(1) {
+		t.Errorf("unexpected first revision: %v", env.Data[0])
+	}
+	if _, ok := env.Data[1]["author_id"]; ok {
+		t.Errorf("expected author_id to be omitted when unknown, got %v", env.Data[1])
+	}
+	if _, ok := env.Data[0]["config"]; ok {
+		t.Error("expected list response to omit config")
+	}
+}
+
+func TestListRevisionsHandler_Unauthorized(t *testing.T) {
+	h := listRevisionsHandler(&stubRevisionService{})
+
+	req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/revisions", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestListRevisionsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"not found", calculator.ErrNotFound, http.StatusNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden},
+		{"internal", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := listRevisionsHandler(&stubRevisionService{err: fmt.Errorf("wrapped: %w", tt.err)})
+			req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/revisions", "id", "calc-abc")
+			req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetRevisionHandler_Success(t *testing.T) {
+	svc := &stubRevisionService{rev: &calculator.Revision{ConfigVersion: 2, Config: []byte(`{"fields":[]}`)}}
+	h := getRevisionHandler(svc)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRevisionRequest(http.MethodGet, "2"))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Data["config"] == nil {
+		t.Error("expected config in response")
+	}
+}
+
+func TestGetRevisionHandler_InvalidVersion(t *testing.T) {
+	for _, version := range []string{"abc", "0", "-1"} {
+		h := getRevisionHandler(&stubRevisionService{})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRevisionRequest(http.MethodGet, version))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("version %q: expected 400, got %d", version, rec.Code)
+		}
+	}
+}
+
+func TestGetRevisionHandler_RevisionNotFound(t *testing.T) {
+	h := getRevisionHandler(&stubRevisionService{err: calculator.ErrRevisionNotFound})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRevisionRequest(http.MethodGet, "9"))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	var env Envelope[any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Error == nil || env.Error.Message != "revision not found" {
+		t.Errorf("unexpected error: %+v", env.Error)
+	}
+}
+
+func TestRestoreRevisionHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := &stubRevisionService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 5, CreatedAt: now, UpdatedAt: now}}
+	h := restoreRevisionHandler(svc)
+
+	req := newRevisionRequest(http.MethodPost, "2")
+	req.Header.Set("If-Match", `"calc-abc.4"`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if svc.gotExpected != 4 {
+		t.Errorf("expected If-Match version 4 passed through, got %d", svc.gotExpected)
+	}
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if env.Data["config_version"] != float64(5) {
+		t.Errorf("expected config_version 5, got %v", env.Data["config_version"])
+	}
+}
+
+func TestRestoreRevisionHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"calculator not found", calculator.ErrNotFound, http.StatusNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden},
+		{"revision not found", calculator.ErrRevisionNotFound, http.StatusNotFound},
+		{"invalid revision", &configschema.ValidationError{Errors: []configschema.FieldError{{Path: "fields[0].type", Message: "unknown field type"}}}, http.StatusUnprocessableEntity},
+		{"stale head", &calculator.VersionConflictError{CurrentVersion: 6}, http.StatusConflict},
+		{"internal", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := restoreRevisionHandler(&stubRevisionService{err: fmt.Errorf("wrapped: %w", tt.err)})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, newRevisionRequest(http.MethodPost, "2"))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMountCalculatorRevisions_RegistersRoutes(t *testing.T) {
+	s := testServer(t)
+	authSvc := &stubAuthService{userID: "user-xyz"}
+	s.MountCalculatorRevisions(authSvc, &stubRevisionService{
+		revs: []*calculator.Revision{},
+		rev:  &calculator.Revision{ConfigVersion: 1, Config: []byte(`{}`)},
+		calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`)},
+	})
+
+	tests := []struct {
+		method, path string
+	}{
+		{http.MethodGet, "/v1/calculators/calc-abc/revisions"},
+		{http.MethodGet, "/v1/calculators/calc-abc/revisions/1"},
+		{http.MethodPost, "/v1/calculators/calc-abc/revisions/1/restore"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s: expected 200, got %d", tt.method, tt.path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+//go:embed templates/standalone.html
+var standalonePageSource string
+
+// standalonePageTemplate renders a calculator's standalone page.
+var standalonePageTemplate = template.Must(template.New("standalone").Parse(standalonePageSource))
+
+// Standalone page defaults for calculators without a name, description, or theme.
+const (
+	defaultPageTitle       = "Calculator"
+	defaultPageDescription = "Get an instant estimate with this calculator."
+	defaultPageBackground  = "#ffffff"
+	defaultPageText        = "#111827"
+)
+
+// standalonePage is the data rendered into standalonePageTemplate.
+type standalonePage struct {
+	ID              string
+	Title           string
+	Description     string
+	ShowDescription bool
+	URL             string
+	OEmbedURL       string
+	WidgetURL       string
+	Background      string
+	Text            string
+	Fields          []pageField
+	Outputs         []string
+}
+
+// pageField summarises one input field for the <noscript> fallback.
+type pageField struct {
+	Label    string
+	Required bool
+	Detail   string
+	HelpText string
+}
+
+// pageSettings holds what every standalone page shares: where pages and the
+// widget are served from, the Content-Security-Policy, and a version folded
+// into ETags so a deploy that changes any of them, or the config schema
+// version pages are rendered at, invalidates cached pages.
+type pageSettings struct {
+	publicURL string
+	widgetURL string
+	csp       string
+	version   string
+}
+
+// newPageSettings derives the page settings from the public base URL of the
+// server and the CDN base URL that serves the widget loader.
+func newPageSettings(publicURL, cdnBaseURL string) pageSettings {
+	ps := pageSettings{
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		widgetURL: strings.TrimSuffix(cdnBaseURL, "/") + "/widget-loader.js",
+	}
+	cdn := urlOrigin(cdnBaseURL)
+	// Scripts may only come from the CDN: the page has no inline script, so
+	// injected markup cannot run code. The widget talks to this server, and
+	// uploaded images may be served from object storage.
+	ps.csp = strings.Join([]string{
+		"default-src 'none'",
+		"script-src " + cdn,
+		"connect-src 'self'",
+		"img-src 'self' " + cdn + " https: data:",
+		"style-src 'self' " + cdn + " 'unsafe-inline'",
+		"font-src " + cdn,
+		"base-uri 'none'",
+		"form-action 'none'",
+	}, "; ")
+	h := sha256.Sum256([]byte(standalonePageSource + "\x00" + ps.publicURL + "\x00" + ps.widgetURL + "\x00" + strconv.Itoa(configschema.Version)))
+	ps.version = hex.EncodeToString(h[:4])
+	return ps
+}
+
+// urlOrigin returns the scheme and host of raw, or "'self'" if raw is not an
+// absolute URL.
+func urlOrigin(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "'self'"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// standalonePageURL returns the canonical URL of the standalone page for
+// calculator id on the server at publicURL.
+func standalonePageURL(publicURL, id string) string {
+	return strings.TrimSuffix(publicURL, "/") + "/c/" + url.PathEscape(id)
+}
+
+// pageETag returns the entity tag of calc's standalone page. The page shows
+// the calculator's name and description as well as its published config, so
+// both versions are part of the tag.
+func (ps pageSettings) pageETag(calc *calculator.Calculator) string {
+	return fmt.Sprintf(`"page-%s.%d.%d.%s"`, calc.ID, calc.ConfigVersion, calc.MetadataVersion, ps.version)
+}
+
+// newStandalonePage builds the page data for calc from its published config.
+func newStandalonePage(ps pageSettings, calc *calculator.Calculator, cfg *configschema.Config) standalonePage {
+	page := standalonePage{
+		ID:              calc.ID,
+		Title:           strings.TrimSpace(calc.Name),
+		Description:     strings.TrimSpace(calc.Description),
+		URL:             standalonePageURL(ps.publicURL, calc.ID),
+		OEmbedURL:       oembedURL(ps.publicURL, standalonePageURL(ps.publicURL, calc.ID)),
+		WidgetURL:       ps.widgetURL,
+		Background:      defaultPageBackground,
+		Text:            defaultPageText,
+		ShowDescription: strings.TrimSpace(calc.Description) != "",
+	}
+	if page.Title == "" {
+		page.Title = defaultPageTitle
+	}
+	if page.Description == "" {
+		page.Description = defaultPageDescription
+	}
+	if cfg.Theme != nil && cfg.Theme.BackgroundColor != "" {
+		page.Background = cfg.Theme.BackgroundColor
+	}
+	if cfg.Theme != nil && cfg.Theme.TextColor != "" {
+		page.Text = cfg.Theme.TextColor
+	}
+	for _, f := range cfg.Fields {
+		page.Fields = append(page.Fields, pageField{
+			Label:    f.Label,
+			Required: f.Required,
+			Detail:   fieldDetail(f),
+			HelpText: f.HelpText,
+		})
+	}
+	for _, o := range cfg.Outputs {
+		page.Outputs = append(page.Outputs, o.Label)
+	}
+	return page
+}
+
+// fieldDetail describes the values field accepts, such as its options or
+// numeric range, or returns "" when there is nothing to add to its label.
+func fieldDetail(f configschema.Field) string {
+	switch f.Type {
+	case configschema.FieldTypeDropdown, configschema.FieldTypeRadio, configschema.FieldTypeImageSelect, configschema.FieldTypeCheckbox:
+		labels := make([]string, len(f.Options))
+		for i, o := range f.Options {
+			labels[i] = o.Label
+		}
+		if len(labels) == 0 {
+			return ""
+		}
+		if f.Type == configschema.FieldTypeCheckbox {
+			return "any of " + strings.Join(labels, ", ")
+		}
+		return "one of " + strings.Join(labels, ", ")
+	case configschema.FieldTypeNumber, configschema.FieldTypeSlider:
+		switch {
+		case f.Min != nil && f.Max != nil:
+			return "a number from " + formatNumber(*f.Min) + " to " + formatNumber(*f.Max)
+		case f.Min != nil:
+			return "a number of at least " + formatNumber(*f.Min)
+		case f.Max != nil:
+			return "a number of at most " + formatNumber(*f.Max)
+		}
+		return "a number"
+	}
+	return ""
+}
+
+// formatNumber formats v without trailing zeros.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// standalonePageHandler returns an http.HandlerFunc for GET /c/{id}, the
+// shareable page that hosts a calculator full-width. The page is rendered
+// from the published config and loads the widget exactly as an embed does;
+// a <noscript> summary of the fields serves crawlers and visitors without
+// JavaScript.
+//
+// Calculators with an embed allow-list have no standalone page: the builder
+// has said where the calculator may appear, and this page is not one of them.
+func standalonePageHandler(svc CalculatorPublicConfigGetter, ps pageSettings) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		calc, err := svc.GetPublicConfig(r.Context(), id)
+		if err == nil && len(calc.EmbedDomains) > 0 {
+			err = calculator.ErrNotFound
+		}
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				w.Header().Set("Cache-Control", "no-store")
+				http.Error(w, "calculator not found", http.StatusNotFound)
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting standalone page config", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		etag := ps.pageETag(calc)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Surrogate-Key", calculator.CacheKey(calc.ID))
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		cfg, err := configschema.Parse(calc.Config)
+		if err != nil {
+			LoggerFrom(r.Context()).Error("parsing published config", "calculator_id", calc.ID, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		var buf bytes.Buffer
+		if err := standalonePageTemplate.Execute(&buf, newStandalonePage(ps, calc, cfg)); err != nil {
+			LoggerFrom(r.Context()).Error("rendering standalone page", "calculator_id", calc.ID, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", ps.csp)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// MountStandalonePages registers GET /c/{id}, the standalone calculator pages,
+// at the root of the server. cdnBaseURL is where the widget loader is served
+// from. The route is rate-limited like the public API; pages are meant to be
+// served from the CDN.
+func (s *Server) MountStandalonePages(svc CalculatorPublicConfigGetter, cdnBaseURL string) {
+	ps := newPageSettings(s.cfg.PublicURL, cdnBaseURL)
+	limiter := newRateLimiter(60) // 60 requests per minute per IP
+	s.mux.Group(func(r chi.Router) {
+		r.Use(IPRateLimit(limiter))
+		r.Get("/c/{id}", standalonePageHandler(svc, ps))
+	})
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+const standaloneTestConfig = `{
+	"fields": [
+		{"id": "f1", "type": "dropdown", "label": "Package", "required": true,
+		 "options": [{"id": "o1", "label": "Basic"}, {"id": "o2", "label": "Pro"}]},
+		{"id": "f2", "type": "number", "label": "Guests", "min": 1, "max": 250, "helpText": "Including you"}
+	],
+	"outputs": [{"id": "out1", "label": "Estimated total", "expression": "1"}],
+	"theme": {"backgroundColor": "#fafafa", "textColor": "#222"}
+}`
+
+func serveStandalonePage(t *testing.T, svc CalculatorPublicConfigGetter, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	h := standalonePageHandler(svc, newPageSettings("https://api.example.com/", "https://cdn.example.com/static"))
+	req := newChiRequest(http.MethodGet, "/c/calc-abc", "id", "calc-abc")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStandalonePageHandler_Renders(t *testing.T) {
+	svc := &stubCalculatorService{calc: &calculator.Calculator{
+		ID:            "calc-abc",
+		Name:          `Wedding <Quote>`,
+		Description:   "Price your big day",
+		Config:        []byte(standaloneTestConfig),
+		ConfigVersion: 3,
+	}}
+	rec := serveStandalonePage(t, svc, nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("expected HTML content type, got %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<title>Wedding &lt;Quote&gt;</title>`,
+		`<meta property="og:title" content="Wedding &lt;Quote&gt;">`,
+		`<meta name="twitter:description" content="Price your big day">`,
+		`<link rel="canonical" href="https://api.example.com/c/calc-abc">`,
+		`<link rel="alternate" type="application/json+oembed" href="https://api.example.com/oembed?format=json&amp;url=https%3A%2F%2Fapi.example.com%2Fc%2Fcalc-abc"`,
+		`<script src="https://cdn.example.com/static/widget-loader.js" data-calculator-id="calc-abc" async></script>`,
+		`<strong>Package</strong> (required): one of Basic, Pro`,
+		`<strong>Guests</strong>: a number from 1 to 250 — Including you`,
+		`<li>Estimated total</li>`,
+		`background: #fafafa`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+	if strings.Contains(body, "<Quote>") {
+		t.Error("expected calculator name to be escaped")
+	}
+}
+
+func TestStandalonePageHandler_Headers(t *testing.T) {
+	svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 4, MetadataVersion: 2}}
+	rec := serveStandalonePage(t, svc, nil)
+
+	csp := rec.Header().Get("Content-Security-Policy")
+	for _, want := range []string{"default-src 'none'", "script-src https://cdn.example.com;", "base-uri 'none'"} {
+		if !strings.Contains(csp, want) {
+			t.Errorf("expected CSP to contain %q, got %q", want, csp)
+		}
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("expected public Cache-Control, got %q", got)
+	}
+	if got := rec.Header().Get("Surrogate-Key"); got != "calculator-calc-abc" {
+		t.Errorf("expected Surrogate-Key %q, got %q", "calculator-calc-abc", got)
+	}
+	if etag := rec.Header().Get("ETag"); !strings.HasPrefix(etag, `"page-calc-abc.4.2.`) {
+		t.Errorf("expected ETag to carry config and metadata versions, got %q", etag)
+	}
+}
+
+func TestStandalonePageHandler_NotModified(t *testing.T) {
+	svc := &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 4}}
+	etag := serveStandalonePage(t, svc, nil).Header().Get("ETag")
+
+	rec := serveStandalonePage(t, svc, http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected empty body on 304, got %q", rec.Body.String())
+	}
+
+	svc.calc.ConfigVersion = 5
+	if rec := serveStandalonePage(t, svc, http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 after republish, got %d", rec.Code)
+	}
+}
+
+func TestStandalonePageHandler_NotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		svc  *stubCalculatorService
+	}{
+		{"unpublished", &stubCalculatorService{err: calculator.ErrNotFound}},
+		{"embed restricted", &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), EmbedDomains: []string{"example.com"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveStandalonePage(t, tt.svc, nil)
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("expected 404, got %d", rec.Code)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+				t.Errorf("expected Cache-Control no-store, got %q", cc)
+			}
+		})
+	}
+}
+
+func TestStandalonePageHandler_InternalError(t *testing.T) {
+	rec := serveStandalonePage(t, &stubCalculatorService{err: errors.New("db failure")}, nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/submission"
 )
 
 // stubPinger is a reusable test implementation of Pinger. It is shared across
@@ -59,24 +62,51 @@ func (s *stubGoogleOAuthCallbacker) GoogleCallback(_ context.Context, _, _, _ st
 
 // stubCalculatorService is a reusable test implementation of CalculatorService.
 type stubCalculatorService struct {
-	calc  *calculator.Calculator
-	calcs []*calculator.Calculator
-	err   error
+	calc    *calculator.Calculator
+	calcs   []*calculator.Calculator
+	results []calculator.OutputResult
+	err     error
+
+	// nextCursor is returned as the Page cursor from List.
+	nextCursor string
+	// gotListOptions records the options passed to List.
+	gotListOptions calculator.ListOptions
+
+	// gotExpectedVersion records the precondition passed to Update or Patch.
+	gotExpectedVersion int
+	// gotAutosave records the autosave flag passed to Update.
+	gotAutosave bool
+	// gotPatchFormat and gotPatch record the arguments passed to Patch.
+	gotPatchFormat calculator.PatchFormat
+	gotPatch       []byte
 }
 
 func (s *stubCalculatorService) Create(_ context.Context, _ string) (*calculator.Calculator, error) {
 	return s.calc, s.err
 }
 
-func (s *stubCalculatorService) List(_ context.Context, _ string) ([]*calculator.Calculator, error) {
-	return s.calcs, s.err
+func (s *stubCalculatorService) List(_ context.Context, _ string, opts calculator.ListOptions) (*calculator.Page, error) {
+	s.gotListOptions = opts
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &calculator.Page{Calculators: s.calcs, NextCursor: s.nextCursor}, nil
 }
 
 func (s *stubCalculatorService) Get(_ context.Context, _, _ string) (*calculator.Calculator, error) {
 	return s.calc, s.err
 }
 
-func (s *stubCalculatorService) Update(_ context.Context, _, _ string, _ []byte) (*calculator.Calculator, error) {
+func (s *stubCalculatorService) Update(_ context.Context, _, _ string, expectedVersion int, autosave bool, _ []byte) (*calculator.Calculator, error) {
+	s.gotExpectedVersion = expectedVersion
+	s.gotAutosave = autosave
+	return s.calc, s.err
+}
+
+func (s *stubCalculatorService) Patch(_ context.Context, _, _ string, expectedVersion int, format calculator.PatchFormat, patch []byte) (*calculator.Calculator, error) {
+	s.gotExpectedVersion = expectedVersion
+	s.gotPatchFormat = format
+	s.gotPatch = patch
 	return s.calc, s.err
 }
 
@@ -91,3 +121,299 @@ func (s *stubCalculatorService) GetPublicConfig(_ context.Context, _ string) (*c
 func (s *stubCalculatorService) Duplicate(_ context.Context, _, _ string) (*calculator.Calculator, error) {
 	return s.calc, s.err
 }
+
+func (s *stubCalculatorService) Evaluate(_ context.Context, _ string, _ map[string]float64) ([]calculator.OutputResult, error) {
+	return s.results, s.err
+}
+
+// stubRevisionService is a reusable test implementation of CalculatorRevisionService.
+type stubRevisionService struct {
+	revs []*calculator.Revision
+	rev  *calculator.Revision
+	calc *calculator.Calculator
+	err  error
+	// gotExpected records the expectedVersion passed to RestoreRevision.
+	gotExpected int
+}
+
+func (s *stubRevisionService) ListRevisions(_ context.Context, _, _ string) ([]*calculator.Revision, error) {
+	return s.revs, s.err
+}
+
+func (s *stubRevisionService) GetRevision(_ context.Context, _, _ string, _ int) (*calculator.Revision, error) {
+	return s.rev, s.err
+}
+
+func (s *stubRevisionService) RestoreRevision(_ context.Context, _, _ string, _, expectedVersion int) (*calculator.Calculator, error) {
+	s.gotExpected = expectedVersion
+	return s.calc, s.err
+}
+
+// stubTrashService is a reusable test implementation of CalculatorTrashService.
+type stubTrashService struct {
+	calcs []*calculator.Calculator
+	calc  *calculator.Calculator
+	err   error
+}
+
+func (s *stubTrashService) ListTrash(_ context.Context, _ string) ([]*calculator.Calculator, error) {
+	return s.calcs, s.err
+}
+
+func (s *stubTrashService) Restore(_ context.Context, _, _ string) (*calculator.Calculator, error) {
+	return s.calc, s.err
+}
+
+// stubMetadataService is a reusable test implementation of CalculatorMetadataService.
+type stubMetadataService struct {
+	calc    *calculator.Calculator
+	folders []*calculator.Folder
+	folder  *calculator.Folder
+	err     error
+
+	gotExpected int
+	gotMetadata calculator.Metadata
+	gotName     string
+}
+
+func (s *stubMetadataService) UpdateMetadata(_ context.Context, _, _ string, expectedVersion int, m calculator.Metadata) (*calculator.Calculator, error) {
+	s.gotExpected = expectedVersion
+	s.gotMetadata = m
+	return s.calc, s.err
+}
+
+func (s *stubMetadataService) ListFolders(_ context.Context, _ string) ([]*calculator.Folder, error) {
+	return s.folders, s.err
+}
+
+func (s *stubMetadataService) CreateFolder(_ context.Context, _, name string) (*calculator.Folder, error) {
+	s.gotName = name
+	return s.folder, s.err
+}
+
+func (s *stubMetadataService) RenameFolder(_ context.Context, _, _, name string) (*calculator.Folder, error) {
+	s.gotName = name
+	return s.folder, s.err
+}
+
+func (s *stubMetadataService) DeleteFolder(_ context.Context, _, _ string) error {
+	return s.err
+}
+
+// stubPublishService is a reusable test implementation of CalculatorPublishService.
+type stubPublishService struct {
+	calc *calculator.Calculator
+	diff *calculator.ConfigDiff
+	err  error
+
+	gotExpected int
+}
+
+func (s *stubPublishService) Publish(_ context.Context, _, _ string, expectedVersion int) (*calculator.Calculator, error) {
+	s.gotExpected = expectedVersion
+	return s.calc, s.err
+}
+
+func (s *stubPublishService) Diff(_ context.Context, _, _ string) (*calculator.ConfigDiff, error) {
+	return s.diff, s.err
+}
+
+// stubTemplateService is a reusable test implementation of TemplateService.
+type stubTemplateService struct {
+	templates []*calculator.Template
+	template  *calculator.Template
+	calc      *calculator.Calculator
+	err       error
+
+	gotCategory string
+	gotUserID   string
+}
+
+func (s *stubTemplateService) ListTemplates(_ context.Context, category string) ([]*calculator.Template, error) {
+	s.gotCategory = category
+	return s.templates, s.err
+}
+
+func (s *stubTemplateService) GetTemplate(_ context.Context, _ string) (*calculator.Template, error) {
+	return s.template, s.err
+}
+
+func (s *stubTemplateService) InstantiateTemplate(_ context.Context, _, userID string) (*calculator.Calculator, error) {
+	s.gotUserID = userID
+	return s.calc, s.err
+}
+
+// stubBundleService is a reusable test implementation of CalculatorBundleService.
+type stubBundleService struct {
+	bundle []byte
+	calc   *calculator.Calculator
+	err    error
+
+	gotBundle []byte
+}
+
+func (s *stubBundleService) Export(_ context.Context, _, _ string, w io.Writer) error {
+	if s.err != nil {
+		return s.err
+	}
+	_, err := w.Write(s.bundle)
+	return err
+}
+
+func (s *stubBundleService) Import(_ context.Context, _ string, r io.ReaderAt, size int64) (*calculator.Calculator, error) {
+	s.gotBundle = make([]byte, size)
+	if _, err := r.ReadAt(s.gotBundle, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return s.calc, s.err
+}
+
+// stubBulkService is a reusable test implementation of CalculatorBulkService.
+type stubBulkService struct {
+	job *calculator.BulkJob
+	err error
+
+	gotReq calculator.BulkRequest
+}
+
+func (s *stubBulkService) SubmitBulkJob(_ context.Context, _ string, req calculator.BulkRequest) (*calculator.BulkJob, error) {
+	s.gotReq = req
+	return s.job, s.err
+}
+
+func (s *stubBulkService) GetBulkJob(_ context.Context, _, _ string) (*calculator.BulkJob, error) {
+	return s.job, s.err
+}
+
+// stubTransferService is a reusable test implementation of CalculatorTransferService.
+type stubTransferService struct {
+	transfer *calculator.Transfer
+	calc     *calculator.Calculator
+	err      error
+
+	gotEmail string
+	gotToken string
+}
+
+func (s *stubTransferService) StartTransfer(_ context.Context, _, _, toEmail string) (*calculator.Transfer, error) {
+	s.gotEmail = toEmail
+	return s.transfer, s.err
+}
+
+func (s *stubTransferService) CancelTransfer(_ context.Context, _, _ string) error {
+	return s.err
+}
+
+func (s *stubTransferService) AcceptTransfer(_ context.Context, rawToken, _ string) (*calculator.Calculator, error) {
+	s.gotToken = rawToken
+	return s.calc, s.err
+}
+
+// stubEmbedDomainService is a reusable test implementation of CalculatorEmbedDomainService.
+type stubEmbedDomainService struct {
+	domains []string
+	err     error
+
+	gotDomains []string
+}
+
+func (s *stubEmbedDomainService) GetEmbedDomains(_ context.Context, _, _ string) ([]string, error) {
+	return s.domains, s.err
+}
+
+func (s *stubEmbedDomainService) SetEmbedDomains(_ context.Context, _, _ string, domains []string) ([]string, error) {
+	s.gotDomains = domains
+	return s.domains, s.err
+}
+
+// stubLinter is a test double for CalculatorLinter.
+type stubLinter struct {
+	diags []calculator.FormulaDiagnostic
+	err   error
+	// gotConfig records the config passed to Lint.
+	gotConfig []byte
+}
+
+func (s *stubLinter) Lint(_ context.Context, _, _ string, config []byte) ([]calculator.FormulaDiagnostic, error) {
+	s.gotConfig = config
+	return s.diags, s.err
+}
+
+// stubSubmitter is a test double for SubmissionCreator.
+type stubSubmitter struct {
+	sub *submission.Submission
+	err error
+	// gotInput records the input passed to Submit.
+	gotInput submission.Input
+}
+
+func (s *stubSubmitter) Submit(_ context.Context, _ *calculator.Calculator, in submission.Input) (*submission.Submission, error) {
+	s.gotInput = in
+	return s.sub, s.err
+}
+
+// stubSubmissionReader is a test double for SubmissionReader.
+type stubSubmissionReader struct {
+	page   *submission.Page
+	sub    *submission.Submission
+	export *submission.Export
+	err    error
+	// gotOpts records the options passed to List or Export.
+	gotOpts submission.ListOptions
+	// gotLoc records the location passed to Export.
+	gotLoc *time.Location
+}
+
+func (s *stubSubmissionReader) List(_ context.Context, _, _ string, opts submission.ListOptions) (*submission.Page, error) {
+	s.gotOpts = opts
+	return s.page, s.err
+}
+
+func (s *stubSubmissionReader) Get(_ context.Context, _, _ string) (*submission.Submission, error) {
+	return s.sub, s.err
+}
+
+func (s *stubSubmissionReader) Export(_ context.Context, _, _ string, opts submission.ListOptions, loc *time.Location) (*submission.Export, error) {
+	s.gotOpts = opts
+	s.gotLoc = loc
+	return s.export, s.err
+}
+
+// stubSubmissionStore serves a fixed set of submissions and calculator to a
+// real submission.Service, for tests that need a *submission.Export.
+type stubSubmissionStore struct {
+	calc *calculator.Calculator
+	subs []*submission.Submission
+}
+
+func (s *stubSubmissionStore) ListSubmissions(_ context.Context, q submission.ListQuery) ([]*submission.Submission, error) {
+	if q.After != nil {
+		return nil, nil
+	}
+	return s.subs, nil
+}
+
+func (s *stubSubmissionStore) GetSubmission(_ context.Context, _ string) (*submission.Submission, error) {
+	return nil, submission.ErrNotFound
+}
+
+func (s *stubSubmissionStore) Get(_ context.Context, _, _ string) (*calculator.Calculator, error) {
+	return s.calc, nil
+}
+
+func (s *stubSubmissionStore) SubmissionKeys(_ context.Context, _ submission.ListQuery) ([]string, []string, error) {
+	return nil, nil, nil
+}
+
+// stubSubmissionChallenger is a test double for SubmissionChallenger.
+type stubSubmissionChallenger struct {
+	challenge submission.Challenge
+	err       error
+	// gotCalcID records the calculator ID passed to Challenge.
+	gotCalcID string
+}
+
+func (s *stubSubmissionChallenger) Challenge(calculatorID string) (submission.Challenge, error) {
+	s.gotCalcID = calculatorID
+	return s.challenge, s.err
+}
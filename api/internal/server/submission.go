@@ -0,0 +1,477 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/submission"
+)
+
+// maxSubmissionBodyBytes caps the size of a submission request body. A
+// submission carries one value per field and output plus lead details, so
+// legitimate payloads are a few kilobytes.
+const maxSubmissionBodyBytes = 32 << 10
+
+// submissionRetryAfter is the Retry-After value, in seconds, sent when the
+// submission queue is full.
+const submissionRetryAfter = "5"
+
+// uuidPattern matches the canonical text form of a UUID. Calculator IDs are
+// checked against it before any database lookup.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SubmissionCreator validates and stores an end user's submission against a
+// published calculator.
+type SubmissionCreator interface {
+	Submit(ctx context.Context, calc *calculator.Calculator, in submission.Input) (*submission.Submission, error)
+}
+
+// leadInfoRequest is the optional lead_info object of a submission.
+type leadInfoRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// createSubmissionRequest is the request body for POST /v1/submissions.
+// InputValues is keyed by field variable name and OutputValues by output ID.
+// ReferrerURL is the page the widget is embedded on; when omitted the
+// Referer header is used instead.
+type createSubmissionRequest struct {
+	CalculatorID string           `json:"calculator_id"`
+	InputValues  map[string]any   `json:"input_values"`
+	OutputValues map[string]any   `json:"output_values"`
+	LeadInfo     *leadInfoRequest `json:"lead_info"`
+	ReferrerURL  string           `json:"referrer_url"`
+	// Honeypot is the widget's hidden field, left empty by people.
+	Honeypot       string `json:"honeypot"`
+	ChallengeToken string `json:"challenge_token"`
+	ProofOfWork    string `json:"proof_of_work"`
+}
+
+// submissionChallenge is the spam challenge the widget answers when it
+// submits: it sends Token back as challenge_token and, when Difficulty is
+// non-zero, a nonce such that SHA-256(token + ":" + nonce) starts with
+// Difficulty zero bits as proof_of_work.
+type submissionChallenge struct {
+	Token      string `json:"token"`
+	Difficulty int    `json:"difficulty"`
+}
+
+// SubmissionChallenger issues the spam challenge the widget answers when it
+// submits to a calculator.
+type SubmissionChallenger interface {
+	Challenge(calculatorID string) (submission.Challenge, error)
+}
+
+// submissionChallengeHandler returns an http.HandlerFunc for
+// GET /v1/calculators/{id}/submission-challenge. No authentication is
+// required. The widget fetches a challenge when it renders a published
+// calculator. Every response carries a fresh token, so it is never cached:
+// a shared copy would hand one token to every visitor. Keeping the challenge
+// out of the config response leaves that response cacheable with its ETag.
+func submissionChallengeHandler(configs CalculatorPublicConfigGetter, challenges SubmissionChallenger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "private, no-store")
+		id := chi.URLParam(r, "id")
+		calc, err := configs.GetPublicConfig(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting public calculator config", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		if !allowEmbed(w, r, calc) {
+			return
+		}
+		c, err := challenges.Challenge(calc.ID)
+		if err != nil {
+			LoggerFrom(r.Context()).Error("issuing submission challenge", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, submissionChallenge{Token: c.Token, Difficulty: c.Difficulty})
+	}
+}
+
+// submissionResponse is the data payload returned when a submission is stored.
+type submissionResponse struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// createSubmissionHandler returns an http.HandlerFunc for POST /v1/submissions.
+// No authentication is required. The submission is validated against the
+// calculator's published config and the calculator's embed allow-list is
+// enforced. It responds 201 once the submission is stored, or 503 with
+// Retry-After when the API is shedding load, so the widget keeps the payload
+// and retries. Submissions flagged as spam are stored quarantined and get the
+// same 201, so a bot learns nothing from the response.
+func createSubmissionHandler(configs CalculatorPublicConfigGetter, svc SubmissionCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req createSubmissionRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubmissionBodyBytes)).Decode(&req); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				WriteError(w, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge, "request body too large")
+				return
+			}
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		if !uuidPattern.MatchString(req.CalculatorID) {
+			writeValidationError(w, "submission failed validation", &configschema.ValidationError{
+				Errors: []configschema.FieldError{{Path: "calculator_id", Message: "must be a calculator ID"}},
+			})
+			return
+		}
+
+		calc, err := configs.GetPublicConfig(r.Context(), req.CalculatorID)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting public calculator config", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		if !allowEmbed(w, r, calc) {
+			return
+		}
+
+		in := submission.Input{
+			InputValues:    req.InputValues,
+			OutputValues:   req.OutputValues,
+			ReferrerURL:    req.ReferrerURL,
+			IPAddress:      clientIP(r),
+			Honeypot:       req.Honeypot,
+			ChallengeToken: req.ChallengeToken,
+			ProofOfWork:    req.ProofOfWork,
+		}
+		if in.ReferrerURL == "" {
+			in.ReferrerURL = r.Referer()
+		}
+		if req.LeadInfo != nil {
+			in.LeadInfo = &submission.LeadInfo{Name: req.LeadInfo.Name, Email: req.LeadInfo.Email, Phone: req.LeadInfo.Phone}
+		}
+
+		sub, err := svc.Submit(r.Context(), calc, in)
+		if err != nil {
+			var verr *configschema.ValidationError
+			if errors.As(err, &verr) {
+				writeValidationError(w, "submission failed validation", verr)
+				return
+			}
+			if errors.Is(err, submission.ErrOverloaded) {
+				LoggerFrom(r.Context()).Warn("submission queue full", "calculator_id", calc.ID)
+				w.Header().Set("Retry-After", submissionRetryAfter)
+				WriteError(w, http.StatusServiceUnavailable, ErrCodeServiceUnavailable, "too many submissions, retry shortly")
+				return
+			}
+			LoggerFrom(r.Context()).Error("creating submission", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		if sub.SpamReason != "" {
+			LoggerFrom(r.Context()).Info("submission quarantined", "calculator_id", calc.ID, "reason", sub.SpamReason)
+		}
+		WriteJSON(w, http.StatusCreated, submissionResponse{ID: sub.ID, CreatedAt: sub.CreatedAt})
+	}
+}
+
+// MountSubmissions registers the public submission endpoints on the server's
+// public group, rate-limited to 60 requests per minute per IP. That limit
+// rejects floods outright; the spam filter's lower per-calculator limit
+// quarantines instead. challenges issues the submission challenge, or is nil
+// when submissions are not spam-checked, in which case the challenge route
+// is not registered and the widget submits without a token.
+func (s *Server) MountSubmissions(configs CalculatorPublicConfigGetter, svc SubmissionCreator, challenges SubmissionChallenger) {
+	limiter := newRateLimiter(60) // 60 requests per minute per IP
+	s.publicGroup.Group(func(r chi.Router) {
+		r.Use(IPRateLimit(limiter))
+		r.Post("/submissions", createSubmissionHandler(configs, svc))
+		if challenges != nil {
+			r.Get("/calculators/{id}/submission-challenge", submissionChallengeHandler(configs, challenges))
+		}
+	})
+}
+
+// SubmissionReader reads a calculator's submissions back for its owner.
+type SubmissionReader interface {
+	List(ctx context.Context, calculatorID, userID string, opts submission.ListOptions) (*submission.Page, error)
+	Get(ctx context.Context, id, userID string) (*submission.Submission, error)
+	Export(ctx context.Context, calculatorID, userID string, opts submission.ListOptions, loc *time.Location) (*submission.Export, error)
+}
+
+// leadInfoResponse is the lead_info object of a submission response.
+type leadInfoResponse struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// submissionSummary is the per-submission shape in GET /v1/calculators/:id/submissions.
+// Total is the value of the calculator's first output; it and LeadInfo are
+// null when absent.
+type submissionSummary struct {
+	ID           string            `json:"id"`
+	CalculatorID string            `json:"calculator_id"`
+	Total        *float64          `json:"total"`
+	LeadInfo     *leadInfoResponse `json:"lead_info"`
+	ReferrerURL  string            `json:"referrer_url"`
+	CreatedAt    time.Time         `json:"created_at"`
+	// SpamReason is why the submission was quarantined, or null if it was not.
+	SpamReason *string `json:"spam_reason"`
+}
+
+// submissionDetail is the full submission shape returned by GET /v1/submissions/:id.
+type submissionDetail struct {
+	submissionSummary
+	InputValues  map[string]any `json:"input_values"`
+	OutputValues map[string]any `json:"output_values"`
+	IPAddress    string         `json:"ip_address"`
+}
+
+func newSubmissionSummary(sub *submission.Submission) submissionSummary {
+	s := submissionSummary{
+		ID:           sub.ID,
+		CalculatorID: sub.CalculatorID,
+		Total:        sub.Total,
+		ReferrerURL:  sub.ReferrerURL,
+		CreatedAt:    sub.CreatedAt,
+	}
+	if sub.SpamReason != "" {
+		s.SpamReason = &sub.SpamReason
+	}
+	if sub.LeadInfo != nil {
+		s.LeadInfo = &leadInfoResponse{Name: sub.LeadInfo.Name, Email: sub.LeadInfo.Email, Phone: sub.LeadInfo.Phone}
+	}
+	return s
+}
+
+// parseSubmissionListOptions reads the submission log's query parameters:
+// from and to (RFC 3339 timestamps), min_total and max_total, has_email
+// (true or false), q (lead search), quarantined (true lists only submissions
+// the spam filter quarantined, which are otherwise left out), cursor, and
+// limit.
+func parseSubmissionListOptions(r *http.Request) (submission.ListOptions, error) {
+	params := r.URL.Query()
+	opts := submission.ListOptions{
+		Search: params.Get("q"),
+		Cursor: params.Get("cursor"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &opts.From}, {"to", &opts.To}} {
+		if v := params.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return opts, fmt.Errorf("%s must be an RFC 3339 timestamp", p.name)
+			}
+			*p.dst = t.UTC()
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **float64
+	}{{"min_total", &opts.MinTotal}, {"max_total", &opts.MaxTotal}} {
+		if v := params.Get(p.name); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+				return opts, fmt.Errorf("%s must be a number", p.name)
+			}
+			*p.dst = &f
+		}
+	}
+	if v := params.Get("has_email"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, errors.New("has_email must be true or false")
+		}
+		opts.HasEmail = &b
+	}
+	if v := params.Get("quarantined"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, errors.New("quarantined must be true or false")
+		}
+		opts.Quarantined = b
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > submission.MaxPageSize {
+			return opts, fmt.Errorf("limit must be an integer from 1 to %d", submission.MaxPageSize)
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+// listSubmissionsHandler returns an http.HandlerFunc for GET /v1/calculators/{id}/submissions.
+// Results are newest first and paginated; meta.next_cursor is present when
+// another page follows. Submissions older than the owner's plan allows are
+// never returned.
+func listSubmissionsHandler(svc SubmissionReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		opts, err := parseSubmissionListOptions(r)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+		id := chi.URLParam(r, "id")
+		page, err := svc.List(r.Context(), id, userID, opts)
+		if err != nil {
+			if errors.Is(err, submission.ErrInvalidCursor) {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid cursor")
+				return
+			}
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("listing submissions", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		summaries := make([]submissionSummary, len(page.Submissions))
+		for i, sub := range page.Submissions {
+			summaries[i] = newSubmissionSummary(sub)
+		}
+		WriteJSONWithMeta(w, http.StatusOK, summaries, Meta{NextCursor: page.NextCursor})
+	}
+}
+
+// getSubmissionHandler returns an http.HandlerFunc for GET /v1/submissions/{id}.
+func getSubmissionHandler(svc SubmissionReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		if !uuidPattern.MatchString(id) {
+			WriteError(w, http.StatusNotFound, ErrCodeNotFound, "submission not found")
+			return
+		}
+		sub, err := svc.Get(r.Context(), id, userID)
+		if err != nil {
+			// A submission whose calculator is in the trash is as invisible
+			// as one that never existed.
+			if errors.Is(err, submission.ErrNotFound) || errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "submission not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting submission", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, submissionDetail{
+			submissionSummary: newSubmissionSummary(sub),
+			InputValues:       sub.InputValues,
+			OutputValues:      sub.OutputValues,
+			IPAddress:         sub.IPAddress,
+		})
+	}
+}
+
+// exportSubmissionsHandler returns an http.HandlerFunc for
+// GET /v1/calculators/{id}/submissions/export. format is csv or xlsx, and tz
+// is the IANA time zone for timestamps, defaulting to UTC. It accepts the same
+// filters as the list endpoint; cursor and limit are ignored. Rows are
+// streamed as they are read, so an error partway through can only be logged
+// and leaves the download truncated.
+func exportSubmissionsHandler(svc SubmissionReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format != "csv" && format != "xlsx" {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "format must be csv or xlsx")
+			return
+		}
+		loc := time.UTC
+		if tz := r.URL.Query().Get("tz"); tz != "" {
+			var err error
+			// "Local" would be the server's zone, which means nothing to the caller.
+			if loc, err = time.LoadLocation(tz); err != nil || tz == "Local" {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "tz must be an IANA time zone name")
+				return
+			}
+		}
+		opts, err := parseSubmissionListOptions(r)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+		id := chi.URLParam(r, "id")
+		export, err := svc.Export(r.Context(), id, userID, opts, loc)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("preparing submission export", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+
+		write := export.WriteCSV
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		if format == "xlsx" {
+			write = export.WriteXLSX
+			w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="submissions-%s.%s"`, id, format))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if err := write(r.Context(), w); err != nil {
+			LoggerFrom(r.Context()).Error("streaming submission export", "error", err)
+		}
+	}
+}
+
+// MountSubmissionLog registers the dashboard's submission routes on the
+// server's private authenticated group.
+func (s *Server) MountSubmissionLog(validator TokenValidator, svc SubmissionReader) {
+	protected := s.Authenticated(validator)
+	protected.Get("/calculators/{id}/submissions", listSubmissionsHandler(svc))
+	protected.Get("/calculators/{id}/submissions/export", exportSubmissionsHandler(svc))
+	protected.Get("/submissions/{id}", getSubmissionHandler(svc))
+}
@@ -0,0 +1,557 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+	"github.com/evanisnor/quotecraft/api/internal/submission"
+)
+
+const testSubmissionCalcID = "5f0c6a1e-2b7d-4c8e-9a31-0d6b2f4e8c17"
+
+func newSubmissionRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/submissions", strings.NewReader(body))
+	req.RemoteAddr = "203.0.113.7:51234"
+	return req
+}
+
+func TestCreateSubmissionHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	configs := &stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID}}
+	svc := &stubSubmitter{sub: &submission.Submission{ID: "sub-1", CreatedAt: now}}
+	h := createSubmissionHandler(configs, svc)
+
+	body := `{"calculator_id":"` + testSubmissionCalcID + `","input_values":{"qty":2},"output_values":{"total":20},"lead_info":{"email":"sam@example.com"}}`
+	req := newSubmissionRequest(body)
+	req.Header.Set("Referer", "https://shop.example.com/pricing")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var env Envelope[submissionResponse]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Data.ID != "sub-1" || !env.Data.CreatedAt.Equal(now) {
+		t.Errorf("unexpected response %+v", env.Data)
+	}
+	in := svc.gotInput
+	if in.InputValues["qty"] != 2.0 || in.OutputValues["total"] != 20.0 {
+		t.Errorf("expected values to be passed through, got %+v", in)
+	}
+	if in.LeadInfo == nil || in.LeadInfo.Email != "sam@example.com" {
+		t.Errorf("expected lead info to be passed through, got %+v", in.LeadInfo)
+	}
+	if in.IPAddress != "203.0.113.7" {
+		t.Errorf("expected client IP without port, got %q", in.IPAddress)
+	}
+	if in.ReferrerURL != "https://shop.example.com/pricing" {
+		t.Errorf("expected Referer fallback, got %q", in.ReferrerURL)
+	}
+}
+
+func TestCreateSubmissionHandler_BodyReferrerWins(t *testing.T) {
+	svc := &stubSubmitter{sub: &submission.Submission{ID: "sub-1"}}
+	h := createSubmissionHandler(&stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID}}, svc)
+
+	req := newSubmissionRequest(`{"calculator_id":"` + testSubmissionCalcID + `","input_values":{},"output_values":{},"referrer_url":"https://a.example/quote"}`)
+	req.Header.Set("Referer", "https://b.example/")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if svc.gotInput.ReferrerURL != "https://a.example/quote" {
+		t.Errorf("expected body referrer_url to be used, got %q", svc.gotInput.ReferrerURL)
+	}
+}
+
+func TestCreateSubmissionHandler_SpamFields(t *testing.T) {
+	svc := &stubSubmitter{sub: &submission.Submission{ID: "sub-1", SpamReason: submission.SpamHoneypot}}
+	h := createSubmissionHandler(&stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID}}, svc)
+
+	req := newSubmissionRequest(`{"calculator_id":"` + testSubmissionCalcID + `","input_values":{},"output_values":{},"honeypot":"x","challenge_token":"tok","proof_of_work":"42"}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	// A quarantined submission looks accepted to the client.
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if in := svc.gotInput; in.Honeypot != "x" || in.ChallengeToken != "tok" || in.ProofOfWork != "42" {
+		t.Errorf("expected spam fields to be passed through, got %+v", in)
+	}
+}
+
+func TestCreateSubmissionHandler_Overloaded(t *testing.T) {
+	h := createSubmissionHandler(&stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID}}, &stubSubmitter{err: submission.ErrOverloaded})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newSubmissionRequest(`{"calculator_id":"`+testSubmissionCalcID+`","input_values":{},"output_values":{}}`))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != submissionRetryAfter {
+		t.Errorf("expected Retry-After %s, got %q", submissionRetryAfter, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestCreateSubmissionHandler_OriginNotAllowed(t *testing.T) {
+	svc := &stubSubmitter{}
+	configs := &stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID, EmbedDomains: []string{"shop.example.com"}}}
+	h := createSubmissionHandler(configs, svc)
+
+	req := newSubmissionRequest(`{"calculator_id":"` + testSubmissionCalcID + `","input_values":{},"output_values":{}}`)
+	req.Header.Set("Origin", "https://evil.test")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	if svc.gotInput.InputValues != nil {
+		t.Error("expected the submission not to be stored")
+	}
+}
+
+func TestCreateSubmissionHandler_Errors(t *testing.T) {
+	valid := `{"calculator_id":"` + testSubmissionCalcID + `","input_values":{},"output_values":{}}`
+	tests := []struct {
+		name       string
+		body       string
+		lookupErr  error
+		submitErr  error
+		wantStatus int
+		wantCode   string
+	}{
+		{"bad body", `{`, nil, nil, http.StatusBadRequest, ErrCodeBadRequest},
+		{"body too large", `{"referrer_url":"` + strings.Repeat("a", maxSubmissionBodyBytes) + `"}`, nil, nil, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge},
+		{"bad calculator id", `{"calculator_id":"calc-abc"}`, nil, nil, http.StatusUnprocessableEntity, ErrCodeValidation},
+		{"calculator not found", valid, calculator.ErrNotFound, nil, http.StatusNotFound, ErrCodeNotFound},
+		{"lookup error", valid, errors.New("db down"), nil, http.StatusInternalServerError, ErrCodeInternal},
+		{"invalid values", valid, nil, &configschema.ValidationError{Errors: []configschema.FieldError{{Path: "input_values.qty", Message: "must be a number"}}},
+			http.StatusUnprocessableEntity, ErrCodeValidation},
+		{"write error", valid, nil, errors.New("db down"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs := &stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID}, err: tt.lookupErr}
+			h := createSubmissionHandler(configs, &stubSubmitter{err: tt.submitErr})
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, newSubmissionRequest(tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var env Envelope[any]
+			if err := decodeEnvelope(rec, &env); err != nil {
+				t.Fatal(err)
+			}
+			if env.Error == nil || env.Error.Code != tt.wantCode {
+				t.Errorf("expected error code %q, got %+v", tt.wantCode, env.Error)
+			}
+		})
+	}
+}
+
+func TestMountSubmissions_RegistersPublicRoute(t *testing.T) {
+	s := testServer(t)
+	s.MountSubmissions(&stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID}}, &stubSubmitter{sub: &submission.Submission{ID: "sub-1"}}, nil)
+
+	req := newSubmissionRequest(`{"calculator_id":"` + testSubmissionCalcID + `","input_values":{},"output_values":{}}`)
+	req.Header.Set("Origin", "https://any-site.test")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 from mounted submissions route, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("expected wildcard CORS, got %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestMountSubmissions_ChallengeRouteOnlyWhenSpamChecked(t *testing.T) {
+	configs := &stubCalculatorService{calc: &calculator.Calculator{ID: testSubmissionCalcID}}
+	for _, tt := range []struct {
+		name       string
+		challenges SubmissionChallenger
+		wantServed bool
+	}{
+		{"spam filter on", &stubSubmissionChallenger{challenge: submission.Challenge{Token: "tok"}}, true},
+		{"spam filter off", nil, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testServer(t)
+			s.MountSubmissions(configs, &stubSubmitter{}, tt.challenges)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/calculators/"+testSubmissionCalcID+"/submission-challenge", nil)
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if served := rec.Code == http.StatusOK; served != tt.wantServed {
+				t.Errorf("expected served=%v, got status %d", tt.wantServed, rec.Code)
+			}
+		})
+	}
+}
+
+func TestSubmissionChallengeHandler_Success(t *testing.T) {
+	challenges := &stubSubmissionChallenger{challenge: submission.Challenge{Token: "tok", Difficulty: 12}}
+	h := submissionChallengeHandler(&stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc"}}, challenges)
+
+	req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submission-challenge", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	// A shared copy would hand one token to every visitor.
+	if rec.Header().Get("Cache-Control") != "private, no-store" {
+		t.Errorf("expected an uncacheable response, got Cache-Control %q", rec.Header().Get("Cache-Control"))
+	}
+	if rec.Header().Get("ETag") != "" {
+		t.Errorf("expected no ETag, got %q", rec.Header().Get("ETag"))
+	}
+	if challenges.gotCalcID != "calc-abc" {
+		t.Errorf("expected a challenge for calc-abc, got %q", challenges.gotCalcID)
+	}
+	var env Envelope[submissionChallenge]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Data.Token != "tok" || env.Data.Difficulty != 12 {
+		t.Errorf("unexpected challenge %+v", env.Data)
+	}
+}
+
+func TestSubmissionChallengeHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		configs    *stubCalculatorService
+		challenges *stubSubmissionChallenger
+		origin     string
+		wantCode   int
+	}{
+		{"not found", &stubCalculatorService{err: calculator.ErrNotFound}, &stubSubmissionChallenger{}, "", http.StatusNotFound},
+		{"config error", &stubCalculatorService{err: errors.New("db failure")}, &stubSubmissionChallenger{}, "", http.StatusInternalServerError},
+		{"origin not allowed", &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc", EmbedDomains: []string{"shop.example.com"}}}, &stubSubmissionChallenger{}, "https://evil.test", http.StatusForbidden},
+		{"challenge error", &stubCalculatorService{calc: &calculator.Calculator{ID: "calc-abc"}}, &stubSubmissionChallenger{err: errors.New("no entropy")}, "", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := submissionChallengeHandler(tt.configs, tt.challenges)
+
+			req := newChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submission-challenge", "id", "calc-abc")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+			if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "no-store") {
+				t.Errorf("expected an uncacheable response, got Cache-Control %q", cc)
+			}
+		})
+	}
+}
+
+func TestListSubmissionsHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	total := 1250.5
+	svc := &stubSubmissionReader{page: &submission.Page{
+		Submissions: []*submission.Submission{
+			{ID: "sub-2", CalculatorID: "calc-abc", Total: &total, LeadInfo: &submission.LeadInfo{Email: "sam@example.com"}, CreatedAt: now},
+			{ID: "sub-1", CalculatorID: "calc-abc", CreatedAt: now.Add(-time.Hour), SpamReason: submission.SpamTooFast},
+		},
+		NextCursor: "next-page",
+	}}
+	h := listSubmissionsHandler(svc)
+
+	path := "/v1/calculators/calc-abc/submissions?from=2026-03-01T00:00:00Z&to=2026-03-08T00:00:00-05:00&min_total=100&max_total=2000&has_email=true&q=sam&quarantined=true&limit=2&cursor=abc"
+	req := newAuthedChiRequest(http.MethodGet, path, "", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	opts := svc.gotOpts
+	if !opts.From.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !opts.To.Equal(time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date range %v to %v", opts.From, opts.To)
+	}
+	if opts.MinTotal == nil || *opts.MinTotal != 100 || opts.MaxTotal == nil || *opts.MaxTotal != 2000 {
+		t.Errorf("unexpected total range %v to %v", opts.MinTotal, opts.MaxTotal)
+	}
+	if opts.HasEmail == nil || !*opts.HasEmail || opts.Search != "sam" || !opts.Quarantined || opts.Limit != 2 || opts.Cursor != "abc" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	var env Envelope[[]submissionSummary]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.Data) != 2 || env.Meta.NextCursor != "next-page" {
+		t.Fatalf("expected 2 submissions and a cursor, got %d and %q", len(env.Data), env.Meta.NextCursor)
+	}
+	if d := env.Data[0]; d.Total == nil || *d.Total != total || d.LeadInfo == nil || d.LeadInfo.Email != "sam@example.com" || d.SpamReason != nil {
+		t.Errorf("unexpected first submission %+v", d)
+	}
+	if d := env.Data[1]; d.Total != nil || d.LeadInfo != nil {
+		t.Errorf("expected null total and lead info, got %+v", d)
+	}
+	if d := env.Data[1]; d.SpamReason == nil || *d.SpamReason != submission.SpamTooFast {
+		t.Errorf("expected spam reason %q, got %v", submission.SpamTooFast, d.SpamReason)
+	}
+}
+
+func TestListSubmissionsHandler_BadParams(t *testing.T) {
+	for _, query := range []string{"from=yesterday", "to=2026-03-01", "min_total=cheap", "max_total=Inf", "has_email=maybe", "quarantined=maybe", "limit=0", "limit=101"} {
+		t.Run(query, func(t *testing.T) {
+			h := listSubmissionsHandler(&stubSubmissionReader{page: &submission.Page{}})
+			req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submissions?"+query, "", "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestListSubmissionsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid cursor", submission.ErrInvalidCursor, http.StatusBadRequest, ErrCodeBadRequest},
+		{"not found", calculator.ErrNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden, ErrCodeForbidden},
+		{"internal", errors.New("db down"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := listSubmissionsHandler(&stubSubmissionReader{err: tt.err})
+			req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submissions", "", "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var env Envelope[any]
+			if err := decodeEnvelope(rec, &env); err != nil {
+				t.Fatal(err)
+			}
+			if env.Error == nil || env.Error.Code != tt.wantCode {
+				t.Errorf("expected error code %q, got %+v", tt.wantCode, env.Error)
+			}
+		})
+	}
+}
+
+func TestGetSubmissionHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := &stubSubmissionReader{sub: &submission.Submission{
+		ID:           testSubmissionCalcID,
+		CalculatorID: "calc-abc",
+		InputValues:  map[string]any{"qty": 2.0},
+		OutputValues: map[string]any{"total": 20.0},
+		IPAddress:    "203.0.113.7",
+		CreatedAt:    now,
+	}}
+	h := getSubmissionHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodGet, "/v1/submissions/"+testSubmissionCalcID, "", "id", testSubmissionCalcID)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	var env Envelope[submissionDetail]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	d := env.Data
+	if d.ID != testSubmissionCalcID || d.CalculatorID != "calc-abc" || d.InputValues["qty"] != 2.0 || d.OutputValues["total"] != 20.0 || d.IPAddress != "203.0.113.7" {
+		t.Errorf("unexpected detail %+v", d)
+	}
+}
+
+func TestGetSubmissionHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"malformed id", "sub-1", nil, http.StatusNotFound, ErrCodeNotFound},
+		{"not found", testSubmissionCalcID, submission.ErrNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{"calculator deleted", testSubmissionCalcID, calculator.ErrNotFound, http.StatusNotFound, ErrCodeNotFound},
+		{"forbidden", testSubmissionCalcID, calculator.ErrForbidden, http.StatusForbidden, ErrCodeForbidden},
+		{"internal", testSubmissionCalcID, errors.New("db down"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := getSubmissionHandler(&stubSubmissionReader{err: tt.err})
+			req := newAuthedChiRequest(http.MethodGet, "/v1/submissions/"+tt.id, "", "id", tt.id)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var env Envelope[any]
+			if err := decodeEnvelope(rec, &env); err != nil {
+				t.Fatal(err)
+			}
+			if env.Error == nil || env.Error.Code != tt.wantCode {
+				t.Errorf("expected error code %q, got %+v", tt.wantCode, env.Error)
+			}
+		})
+	}
+}
+
+// newTestExport returns an export of subs from a real submission.Service.
+func newTestExport(t *testing.T, subs []*submission.Submission, loc *time.Location) *submission.Export {
+	t.Helper()
+	store := &stubSubmissionStore{
+		calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{"fields": [{"id": "f1", "type": "number", "variableName": "qty"}], "outputs": []}`)},
+		subs: subs,
+	}
+	svc := submission.NewService(nil).
+		WithHistory(store, store, store, submission.FixedHistoryWindow(0)).
+		WithExport(store)
+	export, err := svc.Export(t.Context(), "calc-abc", "user-xyz", submission.ListOptions{}, loc)
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	return export
+}
+
+func TestExportSubmissionsHandler_CSV(t *testing.T) {
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("LoadLocation() failed: %v", err)
+	}
+	subs := []*submission.Submission{{
+		ID: "sub-1", CreatedAt: time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC),
+		InputValues: map[string]any{"qty": 3.0}, LeadInfo: &submission.LeadInfo{Name: "=cmd"},
+	}}
+	svc := &stubSubmissionReader{export: newTestExport(t, subs, toronto)}
+	h := exportSubmissionsHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submissions/export?format=csv&tz=America/Toronto&has_email=false&cursor=ignored", "", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotLoc.String() != "America/Toronto" || svc.gotOpts.HasEmail == nil || *svc.gotOpts.HasEmail {
+		t.Errorf("unexpected export arguments %v, %+v", svc.gotLoc, svc.gotOpts)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="submissions-calc-abc.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+	want := "Submission ID,Submitted At (America/Toronto),qty,Lead Name,Lead Email,Lead Phone,Referrer URL\n" +
+		"sub-1,2026-07-01 08:00:00,3,'=cmd,,,\n"
+	if rec.Body.String() != want {
+		t.Errorf("unexpected body:\n%s", rec.Body.String())
+	}
+}
+
+func TestExportSubmissionsHandler_XLSX(t *testing.T) {
+	svc := &stubSubmissionReader{export: newTestExport(t, nil, time.UTC)}
+	h := exportSubmissionsHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submissions/export?format=xlsx", "", "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotLoc != time.UTC {
+		t.Errorf("expected timestamps in UTC by default, got %v", svc.gotLoc)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "PK") {
+		t.Error("expected a zip archive")
+	}
+}
+
+func TestExportSubmissionsHandler_BadParams(t *testing.T) {
+	for _, query := range []string{"", "format=pdf", "format=csv&tz=Mars/Olympus", "format=csv&tz=Local", "format=csv&from=yesterday"} {
+		t.Run(query, func(t *testing.T) {
+			h := exportSubmissionsHandler(&stubSubmissionReader{})
+			req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submissions/export?"+query, "", "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestExportSubmissionsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"not found", calculator.ErrNotFound, http.StatusNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden},
+		{"internal", errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := exportSubmissionsHandler(&stubSubmissionReader{err: tt.err})
+			req := newAuthedChiRequest(http.MethodGet, "/v1/calculators/calc-abc/submissions/export?format=csv", "", "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMountSubmissionLog_RequiresAuth(t *testing.T) {
+	s := testServer(t)
+	s.MountSubmissionLog(&stubAuthService{userID: "user-xyz"}, &stubSubmissionReader{page: &submission.Page{}})
+
+	for _, path := range []string{"/v1/calculators/calc-abc/submissions", "/v1/calculators/calc-abc/submissions/export?format=csv", "/v1/submissions/" + testSubmissionCalcID} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401 without a token, got %d", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// TemplateLister lists the template library, optionally filtered to a category.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type TemplateLister interface {
+	ListTemplates(ctx context.Context, category string) ([]*calculator.Template, error)
+}
+
+// TemplateGetter retrieves a single template with its config.
+type TemplateGetter interface {
+	GetTemplate(ctx context.Context, slug string) (*calculator.Template, error)
+}
+
+// TemplateInstantiator copies a template into a user's account as a new calculator.
+type TemplateInstantiator interface {
+	InstantiateTemplate(ctx context.Context, slug, userID string) (*calculator.Calculator, error)
+}
+
+// TemplateService is the full set of template capabilities consumed by the server.
+type TemplateService interface {
+	TemplateLister
+	TemplateGetter
+	TemplateInstantiator
+}
+
+// templateCacheControl is sent with template responses. Templates only change
+// when the API is deployed, so they can be cached longer than calculator configs.
+const templateCacheControl = "public, max-age=3600"
+
+// templateSummary is the per-item shape in a template list response.
+type templateSummary struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+}
+
+// templateResponse is the data payload returned by GET /v1/templates/{slug}.
+type templateResponse struct {
+	Slug        string          `json:"slug"`
+	Name        string          `json:"name"`
+	Category    string          `json:"category"`
+	Description string          `json:"description"`
+	Config      json.RawMessage `json:"config"`
+}
+
+// listTemplatesHandler returns an http.HandlerFunc for GET /v1/templates.
+// The optional category query parameter filters the list.
+func listTemplatesHandler(svc TemplateLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		templates, err := svc.ListTemplates(r.Context(), r.URL.Query().Get("category"))
+		if err != nil {
+			LoggerFrom(r.Context()).Error("listing templates", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		resp := make([]templateSummary, len(templates))
+		for i, t := range templates {
+			resp[i] = templateSummary{Slug: t.Slug, Name: t.Name, Category: t.Category, Description: t.Description}
+		}
+		w.Header().Set("Cache-Control", templateCacheControl)
+		WriteJSON(w, http.StatusOK, resp)
+	}
+}
+
+// getTemplateHandler returns an http.HandlerFunc for GET /v1/templates/{slug}.
+// The response includes the config so the template can be previewed.
+func getTemplateHandler(svc TemplateGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := svc.GetTemplate(r.Context(), chi.URLParam(r, "slug"))
+		if err != nil {
+			if errors.Is(err, calculator.ErrTemplateNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "template not found")
+				return
+			}
+			LoggerFrom(r.Context()).Error("getting template", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("Cache-Control", templateCacheControl)
+		WriteJSON(w, http.StatusOK, templateResponse{
+			Slug:        t.Slug,
+			Name:        t.Name,
+			Category:    t.Category,
+			Description: t.Description,
+			Config:      json.RawMessage(t.Config),
+		})
+	}
+}
+
+// instantiateTemplateHandler returns an http.HandlerFunc for POST /v1/templates/{slug}/instantiate.
+// The new calculator is unpublished; the template itself is unchanged.
+func instantiateTemplateHandler(svc TemplateInstantiator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		calc, err := svc.InstantiateTemplate(r.Context(), chi.URLParam(r, "slug"), userID)
+		if err != nil {
+			if errors.Is(err, calculator.ErrTemplateNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "template not found")
+				return
+			}
+			LoggerFrom(r.Context()).Error("instantiating template", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusCreated, createCalculatorResponse{
+			ID:        calc.ID,
+			Name:      calc.Name,
+			CreatedAt: calc.CreatedAt,
+			UpdatedAt: calc.UpdatedAt,
+		})
+	}
+}
+
+// MountTemplates registers the template routes. Browsing the library is
+// public, so the marketing site's gallery can use it, and rate-limited like
+// the other public endpoints; instantiating requires authentication.
+func (s *Server) MountTemplates(validator TokenValidator, svc TemplateService) {
+	limiter := newRateLimiter(60) // 60 requests per minute per IP
+	s.publicGroup.Group(func(r chi.Router) {
+		r.Use(IPRateLimit(limiter))
+		r.Get("/templates", listTemplatesHandler(svc))
+		r.Get("/templates/{slug}", getTemplateHandler(svc))
+	})
+	protected := s.Authenticated(validator)
+	protected.Post("/templates/{slug}/instantiate", instantiateTemplateHandler(svc))
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+func TestListTemplatesHandler_Success(t *testing.T) {
+	svc := &stubTemplateService{templates: []*calculator.Template{
+		{Slug: "moving-cost-calculator", Name: "Moving Cost Calculator", Category: "home-services", Description: "Estimate a move"},
+	}}
+	h := listTemplatesHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/templates?category=home-services", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if svc.gotCategory != "home-services" {
+		t.Errorf("expected category filter %q, got %q", "home-services", svc.gotCategory)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != templateCacheControl {
+		t.Errorf("expected Cache-Control %q, got %q", templateCacheControl, cc)
+	}
+	var env Envelope[[]map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(env.Data) != 1 || env.Data[0]["slug"] != "moving-cost-calculator" || env.Data[0]["category"] != "home-services" {
+		t.Errorf("unexpected templates: %+v", env.Data)
+	}
+	if _, ok := env.Data[0]["config"]; ok {
+		t.Error("expected list items to omit config")
+	}
+}
+
+func TestListTemplatesHandler_InternalError(t *testing.T) {
+	h := listTemplatesHandler(&stubTemplateService{err: errors.New("db failure")})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/templates", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestGetTemplateHandler_Success(t *testing.T) {
+	svc := &stubTemplateService{template: &calculator.Template{
+		Slug:     "moving-cost-calculator",
+		Name:     "Moving Cost Calculator",
+		Category: "home-services",
+		Config:   []byte(`{"fields":[]}`),
+	}}
+	h := getTemplateHandler(svc)
+
+	req := newChiRequest(http.MethodGet, "/v1/templates/moving-cost-calculator", "slug", "moving-cost-calculator")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := env.Data["config"].(map[string]any); !ok {
+		t.Errorf("expected config object in response, got %v", env.Data["config"])
+	}
+}
+
+func TestGetTemplateHandler_NotFound(t *testing.T) {
+	h := getTemplateHandler(&stubTemplateService{err: fmt.Errorf("getting template: %w", calculator.ErrTemplateNotFound)})
+
+	req := newChiRequest(http.MethodGet, "/v1/templates/missing", "slug", "missing")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestInstantiateTemplateHandler(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"success", nil, http.StatusCreated},
+		{"not found", fmt.Errorf("instantiating template: %w", calculator.ErrTemplateNotFound), http.StatusNotFound},
+		{"internal", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubTemplateService{
+				calc: &calculator.Calculator{ID: "calc-new", Name: "Moving Cost Calculator", CreatedAt: now, UpdatedAt: now},
+				err:  tt.err,
+			}
+			h := instantiateTemplateHandler(svc)
+
+			req := newAuthedChiRequest(http.MethodPost, "/v1/templates/moving-cost-calculator/instantiate", "", "slug", "moving-cost-calculator")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+			if svc.gotUserID != "user-xyz" {
+				t.Errorf("expected instantiation for user-xyz, got %q", svc.gotUserID)
+			}
+		})
+	}
+}
+
+func TestInstantiateTemplateHandler_Unauthenticated(t *testing.T) {
+	h := instantiateTemplateHandler(&stubTemplateService{})
+
+	req := newChiRequest(http.MethodPost, "/v1/templates/moving-cost-calculator/instantiate", "slug", "moving-cost-calculator")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestMountTemplates_RegistersRoutes(t *testing.T) {
+	s := testServer(t)
+	authSvc := &stubAuthService{userID: "user-xyz"}
+	s.MountAuth(authSvc)
+	now := time.Now().UTC()
+	s.MountTemplates(authSvc, &stubTemplateService{
+		templates: []*calculator.Template{},
+		template:  &calculator.Template{Slug: "saas-pricing-calculator", Config: []byte(`{}`)},
+		calc:      &calculator.Calculator{ID: "calc-new", CreatedAt: now, UpdatedAt: now},
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		token    string
+		wantCode int
+	}{
+		{http.MethodGet, "/v1/templates", "", http.StatusOK},
+		{http.MethodGet, "/v1/templates/saas-pricing-calculator", "", http.StatusOK},
+		{http.MethodPost, "/v1/templates/saas-pricing-calculator/instantiate", "", http.StatusUnauthorized},
+		{http.MethodPost, "/v1/templates/saas-pricing-calculator/instantiate", "valid-token", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.wantCode, rec.Code)
+		}
+	}
+}
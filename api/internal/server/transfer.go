@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// CalculatorTransferStarter sends a calculator ownership transfer to an email address.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorTransferStarter interface {
+	StartTransfer(ctx context.Context, id, userID, toEmail string) (*calculator.Transfer, error)
+}
+
+// CalculatorTransferCanceller withdraws a pending ownership transfer.
+type CalculatorTransferCanceller interface {
+	CancelTransfer(ctx context.Context, id, userID string) error
+}
+
+// CalculatorTransferAccepter accepts an ownership transfer with its emailed token.
+type CalculatorTransferAccepter interface {
+	AcceptTransfer(ctx context.Context, rawToken, userID string) (*calculator.Calculator, error)
+}
+
+// CalculatorTransferService is the full set of ownership transfer capabilities consumed by the server.
+type CalculatorTransferService interface {
+	CalculatorTransferStarter
+	CalculatorTransferCanceller
+	CalculatorTransferAccepter
+}
+
+// startTransferRequest is the request body for POST /v1/calculators/{id}/transfer.
+type startTransferRequest struct {
+	Email string `json:"email"`
+}
+
+// acceptTransferRequest is the request body for POST /v1/calculators/transfers/accept.
+type acceptTransferRequest struct {
+	Token string `json:"token"`
+}
+
+// transferResponse is the data payload returned when a transfer is started.
+type transferResponse struct {
+	CalculatorID string    `json:"calculator_id"`
+	ToEmail      string    `json:"to_email"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// startTransferHandler returns an http.HandlerFunc for POST /v1/calculators/{id}/transfer.
+// The acceptance token is emailed to the recipient and never returned here.
+func startTransferHandler(svc CalculatorTransferStarter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		var req startTransferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		transfer, err := svc.StartTransfer(r.Context(), id, userID, req.Email)
+		if err != nil {
+			if errors.Is(err, calculator.ErrInvalidTransferEmail) {
+				WriteErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, "transfer request failed validation",
+					[]ErrorDetail{{Field: "email", Message: "must be a valid email address"}})
+				return
+			}
+			if errors.Is(err, calculator.ErrTransferToSelf) {
+				WriteErrorDetails(w, http.StatusUnprocessableEntity, ErrCodeValidation, "transfer request failed validation",
+					[]ErrorDetail{{Field: "email", Message: "must not be your own email address"}})
+				return
+			}
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("starting calculator transfer", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusCreated, transferResponse{
+			CalculatorID: transfer.CalculatorID,
+			ToEmail:      transfer.ToEmail,
+			ExpiresAt:    transfer.ExpiresAt,
+			CreatedAt:    transfer.CreatedAt,
+		})
+	}
+}
+
+// cancelTransferHandler returns an http.HandlerFunc for DELETE /v1/calculators/{id}/transfer.
+func cancelTransferHandler(svc CalculatorTransferCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		if err := svc.CancelTransfer(r.Context(), id, userID); err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			if errors.Is(err, calculator.ErrTransferNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "no transfer is pending")
+				return
+			}
+			LoggerFrom(r.Context()).Error("cancelling calculator transfer", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// acceptTransferHandler returns an http.HandlerFunc for POST /v1/calculators/transfers/accept.
+// The caller must be signed in to the account the transfer was sent to; the
+// calculator is returned as it now appears in their account.
+func acceptTransferHandler(svc CalculatorTransferAccepter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		var req acceptTransferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body")
+			return
+		}
+		calc, err := svc.AcceptTransfer(r.Context(), req.Token, userID)
+		if err != nil {
+			if errors.Is(err, calculator.ErrInvalidTransferToken) {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid or expired transfer token")
+				return
+			}
+			if errors.Is(err, calculator.ErrTransferRecipientMismatch) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "transfer was sent to a different email address")
+				return
+			}
+			if errors.Is(err, calculator.ErrTransferToSelf) {
+				WriteError(w, http.StatusConflict, ErrCodeConflict, "calculator is already yours")
+				return
+			}
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found")
+				return
+			}
+			LoggerFrom(r.Context()).Error("accepting calculator transfer", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		WriteJSON(w, http.StatusOK, newCalculatorResponse(calc))
+	}
+}
+
+// MountCalculatorTransfers registers the ownership transfer routes on the server's private authenticated group.
+func (s *Server) MountCalculatorTransfers(validator TokenValidator, svc CalculatorTransferService) {
+	protected := s.Authenticated(validator)
+	protected.Post("/calculators/{id}/transfer", startTransferHandler(svc))
+	protected.Delete("/calculators/{id}/transfer", cancelTransferHandler(svc))
+	protected.Post("/calculators/transfers/accept", acceptTransferHandler(svc))
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+func TestStartTransferHandler_Created(t *testing.T) {
+	svc := &stubTransferService{transfer: &calculator.Transfer{
+		CalculatorID: "calc-abc",
+		ToEmail:      "client@example.com",
+		ExpiresAt:    time.Now().Add(calculator.TransferTTL),
+	}}
+	h := startTransferHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/calc-abc/transfer", `{"email":"client@example.com"}`, "id", "calc-abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotEmail != "client@example.com" {
+		t.Errorf("expected email passed to service, got %q", svc.gotEmail)
+	}
+	var env Envelope[transferResponse]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Data.CalculatorID != "calc-abc" || env.Data.ToEmail != "client@example.com" {
+		t.Errorf("unexpected response: %+v", env.Data)
+	}
+}
+
+func TestStartTransferHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"invalid email", calculator.ErrInvalidTransferEmail, http.StatusUnprocessableEntity},
+		{"to self", fmt.Errorf("storing transfer: %w", calculator.ErrTransferToSelf), http.StatusUnprocessableEntity},
+		{"not found", fmt.Errorf("verifying calculator ownership: %w", calculator.ErrNotFound), http.StatusNotFound},
+		{"forbidden", fmt.Errorf("verifying calculator ownership: %w", calculator.ErrForbidden), http.StatusForbidden},
+		{"internal", errors.New("smtp down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := startTransferHandler(&stubTransferService{err: tt.err})
+
+			req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/calc-abc/transfer", `{"email":"x@example.com"}`, "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestCancelTransferHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"cancelled", nil, http.StatusNoContent},
+		{"none pending", fmt.Errorf("cancelling transfer: %w", calculator.ErrTransferNotFound), http.StatusNotFound},
+		{"forbidden", fmt.Errorf("verifying calculator ownership: %w", calculator.ErrForbidden), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := cancelTransferHandler(&stubTransferService{err: tt.err})
+
+			req := newAuthedChiRequest(http.MethodDelete, "/v1/calculators/calc-abc/transfer", "", "id", "calc-abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAcceptTransferHandler_Success(t *testing.T) {
+	svc := &stubTransferService{calc: &calculator.Calculator{ID: "calc-abc", UserID: "user-xyz", Config: []byte(`{}`)}}
+	h := acceptTransferHandler(svc)
+
+	req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/transfers/accept", `{"token":"raw-token"}`, "", "")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotToken != "raw-token" {
+		t.Errorf("expected token passed to service, got %q", svc.gotToken)
+	}
+	var env Envelope[calculatorResponse]
+	if err := decodeEnvelope(rec, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Data.ID != "calc-abc" {
+		t.Errorf("expected calculator ID to be unchanged, got %q", env.Data.ID)
+	}
+}
+
+func TestAcceptTransferHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{"missing token", `{}`, nil, http.StatusBadRequest},
+		{"invalid token", `{"token":"x"}`, fmt.Errorf("accepting transfer: %w", calculator.ErrInvalidTransferToken), http.StatusBadRequest},
+		{"wrong account", `{"token":"x"}`, fmt.Errorf("accepting transfer: %w", calculator.ErrTransferRecipientMismatch), http.StatusForbidden},
+		{"already owner", `{"token":"x"}`, fmt.Errorf("accepting transfer: %w", calculator.ErrTransferToSelf), http.StatusConflict},
+		{"calculator gone", `{"token":"x"}`, fmt.Errorf("accepting transfer: %w", calculator.ErrNotFound), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := acceptTransferHandler(&stubTransferService{err: tt.err})
+
+			req := newAuthedChiRequest(http.MethodPost, "/v1/calculators/transfers/accept", tt.body, "", "")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// CalculatorTrashLister lists the authenticated user's soft-deleted calculators.
+// Defined here at the consumer (server package) per the interfaces-where-consumed convention.
+type CalculatorTrashLister interface {
+	ListTrash(ctx context.Context, userID string) ([]*calculator.Calculator, error)
+}
+
+// CalculatorTrashRestorer takes a soft-deleted calculator out of the trash.
+type CalculatorTrashRestorer interface {
+	Restore(ctx context.Context, id, userID string) (*calculator.Calculator, error)
+}
+
+// CalculatorTrashService is the full set of trash capabilities consumed by the server.
+type CalculatorTrashService interface {
+	CalculatorTrashLister
+	CalculatorTrashRestorer
+}
+
+// trashedCalculatorSummary is the per-item shape in a trash list response.
+type trashedCalculatorSummary struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// listTrashHandler returns an http.HandlerFunc for GET /v1/calculators/trash.
+func listTrashHandler(svc CalculatorTrashLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		calcs, err := svc.ListTrash(r.Context(), userID)
+		if err != nil {
+			LoggerFrom(r.Context()).Error("listing trash", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		summaries := make([]trashedCalculatorSummary, len(calcs))
+		for i, c := range calcs {
+			summaries[i] = trashedCalculatorSummary{
+				ID:        c.ID,
+				Name:      c.Name,
+				CreatedAt: c.CreatedAt,
+				UpdatedAt: c.UpdatedAt,
+				DeletedAt: c.DeletedAt,
+			}
+		}
+		WriteJSON(w, http.StatusOK, summaries)
+	}
+}
+
+// restoreCalculatorHandler returns an http.HandlerFunc for POST /v1/calculators/{id}/restore.
+// The response is the restored calculator, in the same shape as GET /v1/calculators/{id}.
+func restoreCalculatorHandler(svc CalculatorTrashRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok {
+			WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authentication")
+			return
+		}
+		id := chi.URLParam(r, "id")
+		calc, err := svc.Restore(r.Context(), id, userID)
+		if err != nil {
+			if errors.Is(err, calculator.ErrNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "calculator not found in trash")
+				return
+			}
+			if errors.Is(err, calculator.ErrForbidden) {
+				WriteError(w, http.StatusForbidden, ErrCodeForbidden, "access forbidden")
+				return
+			}
+			LoggerFrom(r.Context()).Error("restoring calculator", "error", err)
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal error")
+			return
+		}
+		w.Header().Set("ETag", calculatorETag(calc.ID, calc.ConfigVersion))
+		WriteJSON(w, http.StatusOK, newCalculatorResponse(calc))
+	}
+}
+
+// MountCalculatorTrash registers the trash routes on the server's private authenticated group.
+func (s *Server) MountCalculatorTrash(validator TokenValidator, svc CalculatorTrashService) {
+	protected := s.Authenticated(validator)
+	protected.Get("/calculators/trash", listTrashHandler(svc))
+	protected.Post("/calculators/{id}/restore", restoreCalculatorHandler(svc))
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+func TestListTrashHandler_Success(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := &stubTrashService{calcs: []*calculator.Calculator{
+		{ID: "calc-abc", Name: "Old quote", IsDeleted: true, CreatedAt: now, UpdatedAt: now, DeletedAt: &now},
+	}}
+	h := listTrashHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/calculators/trash", nil)
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var env Envelope[[]map[string]any]
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(env.Data) != 1 || env.Data[0]["id"] != "calc-abc" || env.Data[0]["deleted_at"] == nil {
+		t.Errorf("unexpected trash listing: %v", env.Data)
+	}
+}
+
+func TestListTrashHandler_MissingAuth(t *testing.T) {
+	h := listTrashHandler(&stubTrashService{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/calculators/trash", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestListTrashHandler_InternalError(t *testing.T) {
+	h := listTrashHandler(&stubTrashService{err: errors.New("db failure")})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/calculators/trash", nil)
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestRestoreCalculatorHandler_Success(t *testing.T) {
+	svc := &stubTrashService{calc: &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`), ConfigVersion: 3}}
+	h := restoreCalculatorHandler(svc)
+
+	req := newChiRequest(http.MethodPost, "/v1/calculators/calc-abc/restore", "id", "calc-abc")
+	req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"calc-abc.3"` {
+		t.Errorf("expected ETag of the restored version, got %q", etag)
+	}
+}
+
+func TestRestoreCalculatorHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"not in trash", calculator.ErrNotFound, http.StatusNotFound},
+		{"forbidden", calculator.ErrForbidden, http.StatusForbidden},
+		{"internal", errors.New("db failure"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := restoreCalculatorHandler(&stubTrashService{err: fmt.Errorf("restoring calculator: %w", tt.err)})
+
+			req := newChiRequest(http.MethodPost, "/v1/calculators/calc-abc/restore", "id", "calc-abc")
+			req = req.WithContext(context.WithValue(req.Context(), authUserKey{}, "user-xyz"))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMountCalculatorTrash_RegistersRoutes(t *testing.T) {
+	s := testServer(t)
+	authSvc := &stubAuthService{userID: "user-xyz"}
+	// Mount the standard calculator routes too, to prove /calculators/trash is
+	// not captured by /calculators/{id}.
+	s.MountCalculators(authSvc, &stubCalculatorService{err: calculator.ErrNotFound})
+	s.MountCalculatorTrash(authSvc, &stubTrashService{
+		calcs: []*calculator.Calculator{},
+		calc:  &calculator.Calculator{ID: "calc-abc", Config: []byte(`{}`)},
+	})
+
+	tests := []struct {
+		method, path string
+	}{
+		{http.MethodGet, "/v1/calculators/trash"},
+		{http.MethodPost, "/v1/calculators/calc-abc/restore"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s: expected 200, got %d", tt.method, tt.path, rec.Code)
+		}
+	}
+}
@@ -72,6 +72,16 @@ func (a *FilesystemAdapter) GetURL(key string) string {
 	return a.baseURL + "/" + key
 }
 
+// Download opens the file at baseDir/key for reading.
+// A missing file is reported as an error matching fs.ErrNotExist.
+func (a *FilesystemAdapter) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(a.baseDir, filepath.FromSlash(key)))
+	if err != nil {
+		return nil, fmt.Errorf("opening file %q: %w", key, err)
+	}
+	return f, nil
+}
+
 // Delete removes the file at baseDir/key.
 // If the file does not exist, Delete returns nil (idempotent, matching S3 semantics).
 func (a *FilesystemAdapter) Delete(_ context.Context, key string) error {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -116,6 +117,36 @@ func TestFilesystemAdapter_GetURL(t *testing.T) {
 	}
 }
 
+func TestFilesystemAdapter_Download_Success(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte("data"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	adapter := NewFilesystemAdapter(dir, "http://localhost:8080/static")
+	rc, err := adapter.Download(context.Background(), "logo.png")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading file: %v", err)
+	}
+	if string(data) != "data" {
+		t.Errorf("Download() content = %q, want %q", data, "data")
+	}
+}
+
+func TestFilesystemAdapter_Download_NotFound(t *testing.T) {
+	adapter := NewFilesystemAdapter(t.TempDir(), "http://localhost:8080/static")
+
+	_, err := adapter.Download(context.Background(), "nonexistent.png")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got: %v", err)
+	}
+}
+
 func TestFilesystemAdapter_Delete_Success(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "logo.png")
@@ -2,13 +2,16 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	"github.com/evanisnor/quotecraft/api/internal/config"
 )
@@ -17,6 +20,7 @@ import (
 // Defined here to allow test doubles.
 type s3API interface {
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
 }
 
@@ -102,6 +106,23 @@ func (a *S3Adapter) GetURL(key string) string {
 	return a.baseURL + "/" + key
 }
 
+// Download opens the object at key for reading.
+// A missing object is reported as an error matching fs.ErrNotExist.
+func (a *S3Adapter) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := a.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("downloading object %q: %w", key, fs.ErrNotExist)
+		}
+		return nil, fmt.Errorf("downloading object %q: %w", key, err)
+	}
+	return out.Body, nil
+}
+
 // Delete removes the object identified by key.
 func (a *S3Adapter) Delete(ctx context.Context, key string) error {
 	_, err := a.client.DeleteObject(ctx, &s3.DeleteObjectInput{
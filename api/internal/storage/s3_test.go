@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"io"
+	"io/fs"
 	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	"github.com/evanisnor/quotecraft/api/internal/config"
 )
@@ -17,6 +19,8 @@ import (
 // stubS3API is a test double for s3API.
 type stubS3API struct {
 	putErr    error
+	getErr    error
+	getBody   string
 	deleteErr error
 }
 
@@ -24,6 +28,13 @@ func (s *stubS3API) PutObject(_ context.Context, _ *s3.PutObjectInput, _ ...func
 	return &s3.PutObjectOutput{}, s.putErr
 }
 
+func (s *stubS3API) GetObject(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(s.getBody))}, nil
+}
+
 func (s *stubS3API) DeleteObject(_ context.Context, _ *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
 	return &s3.DeleteObjectOutput{}, s.deleteErr
 }
@@ -66,6 +77,45 @@ func TestS3Adapter_GetURL(t *testing.T) {
 	}
 }
 
+func TestS3Adapter_Download_Success(t *testing.T) {
+	adapter := NewS3Adapter(&stubS3API{getBody: "file content"}, "test-bucket", "http://localhost:9000/test-bucket")
+
+	rc, err := adapter.Download(context.Background(), "images/logo.png")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if string(data) != "file content" {
+		t.Errorf("Download() body = %q, want %q", data, "file content")
+	}
+}
+
+func TestS3Adapter_Download_NotFound(t *testing.T) {
+	adapter := NewS3Adapter(&stubS3API{getErr: &types.NoSuchKey{}}, "test-bucket", "http://localhost:9000/test-bucket")
+
+	_, err := adapter.Download(context.Background(), "images/missing.png")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got: %v", err)
+	}
+}
+
+func TestS3Adapter_Download_Error(t *testing.T) {
+	stub := &stubS3API{getErr: errors.New("connection refused")}
+	adapter := NewS3Adapter(stub, "test-bucket", "http://localhost:9000/test-bucket")
+
+	_, err := adapter.Download(context.Background(), "images/logo.png")
+	if !errors.Is(err, stub.getErr) {
+		t.Errorf("expected wrapped getErr, got: %v", err)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected a transport error not to match fs.ErrNotExist")
+	}
+}
+
 func TestS3Adapter_Delete_Success(t *testing.T) {
 	stub := &stubS3API{}
 	adapter := NewS3Adapter(stub, "test-bucket", "http://localhost:9000/test-bucket")
@@ -204,3 +254,13 @@ var _ Storage = (*S3Adapter)(nil)
 
 // Compile-time assertion that stubS3API satisfies the s3API interface.
 var _ s3API = (*stubS3API)(nil)
+
+func TestStubStorage_Download(t *testing.T) {
+	stub := NewStubStorage()
+
+	rc, err := stub.Download(context.Background(), "key/file.png")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rc.Close()
+}
@@ -14,6 +14,11 @@ type Storage interface {
 	// GetURL returns the public URL for accessing the object at the given key.
 	GetURL(key string) string
 
+	// Download opens the object at the given key for reading. The caller must
+	// close the returned reader. If no object exists at key the error matches
+	// fs.ErrNotExist.
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+
 	// Delete removes the object identified by key.
 	Delete(ctx context.Context, key string) error
 }
@@ -3,14 +3,16 @@ package storage
 import (
 	"context"
 	"io"
+	"strings"
 )
 
 // StubStorage is a reusable test implementation of Storage.
 // Use it for tests that don't care about storage details.
 type StubStorage struct {
-	UploadFunc func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
-	GetURLFunc func(key string) string
-	DeleteFunc func(ctx context.Context, key string) error
+	UploadFunc   func(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	GetURLFunc   func(key string) string
+	DownloadFunc func(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteFunc   func(ctx context.Context, key string) error
 
 	// Captured calls for verification.
 	LastUploadKey         string
@@ -27,6 +29,9 @@ func NewStubStorage() *StubStorage {
 		GetURLFunc: func(key string) string {
 			return "http://stub/" + key
 		},
+		DownloadFunc: func(_ context.Context, _ string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("")), nil
+		},
 		DeleteFunc: func(_ context.Context, _ string) error {
 			return nil
 		},
@@ -43,6 +48,10 @@ func (s *StubStorage) GetURL(key string) string {
 	return s.GetURLFunc(key)
 }
 
+func (s *StubStorage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.DownloadFunc(ctx, key)
+}
+
 func (s *StubStorage) Delete(ctx context.Context, key string) error {
 	s.LastDeleteKey = key
 	return s.DeleteFunc(ctx, key)
@@ -0,0 +1,149 @@
+package submission
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Default Batcher sizing. A batch is written in one statement, so at a few
+// milliseconds per insert one Batcher sustains thousands of submissions per
+// second; the queue absorbs bursts while a batch is in flight.
+const (
+	defaultQueueSize  = 1024
+	defaultBatchSize  = 100
+	batchWriteTimeout = 10 * time.Second
+)
+
+// BatchInserter stores several submissions at once. Submissions whose
+// calculator no longer exists are skipped.
+type BatchInserter interface {
+	InsertSubmissions(ctx context.Context, subs []*Submission) error
+}
+
+// pendingWrite is a queued submission and the channel its writer waits on.
+type pendingWrite struct {
+	sub  *Submission
+	done chan error
+}
+
+// Batcher is a Writer that groups concurrent submissions into multi-row
+// inserts. Each WriteSubmission call still returns only after its own row is
+// committed, so an acknowledged submission is never lost; under load, callers
+// share one round trip to the database instead of queueing for connections.
+// When the queue is full WriteSubmission fails fast with ErrOverloaded rather
+// than letting requests pile up.
+type Batcher struct {
+	store     BatchInserter
+	queue     chan pendingWrite
+	batchSize int
+	logger    *slog.Logger
+}
+
+// NewBatcher creates a Batcher that writes to store. Run must be started for
+// submissions to be written.
+func NewBatcher(store BatchInserter, logger *slog.Logger) *Batcher {
+	return &Batcher{
+		store:     store,
+		queue:     make(chan pendingWrite, defaultQueueSize),
+		batchSize: defaultBatchSize,
+		logger:    logger,
+	}
+}
+
+// WriteSubmission queues sub for the next batch and waits for it to be
+// written. If ctx ends first the submission may still be written.
+// Returns ErrOverloaded without queueing sub if the queue is full.
+func (b *Batcher) WriteSubmission(ctx context.Context, sub *Submission) error {
+	p := pendingWrite{sub: sub, done: make(chan error, 1)}
+	select {
+	case b.queue <- p:
+	default:
+		return ErrOverloaded
+	}
+	select {
+	case err := <-p.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run writes queued submissions until ctx is cancelled. It does not wait to
+// fill a batch: whatever queued while the previous insert ran goes into the
+// next one, so a lone submission is written immediately. Submissions still
+// queued when ctx is cancelled are written before Run returns.
+func (b *Batcher) Run(ctx context.Context) {
+	for {
+		select {
+		case p := <-b.queue:
+			b.flush(b.collect(p))
+		case <-ctx.Done():
+			b.drain()
+			return
+		}
+	}
+}
+
+// collect returns first followed by up to batchSize-1 more queued writes,
+// without blocking.
+func (b *Batcher) collect(first pendingWrite) []pendingWrite {
+	batch := []pendingWrite{first}
+	for len(batch) < b.batchSize {
+		select {
+		case p := <-b.queue:
+			batch = append(batch, p)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// drain writes everything left in the queue.
+func (b *Batcher) drain() {
+	for {
+		select {
+		case p := <-b.queue:
+			b.flush(b.collect(p))
+		default:
+			return
+		}
+	}
+}
+
+// flush inserts batch and reports the outcome to each waiting writer. It uses
+// its own timeout rather than Run's context so that a shutdown does not
+// abandon a batch half-way. If the batch insert fails, the rows are retried
+// one at a time, so a row the database rejects fails only its own writer.
+func (b *Batcher) flush(batch []pendingWrite) {
+	subs := make([]*Submission, len(batch))
+	for i, p := range batch {
+		subs[i] = p.sub
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), batchWriteTimeout)
+	err := b.store.InsertSubmissions(ctx, subs)
+	cancel()
+	if err == nil || len(batch) == 1 {
+		if err != nil {
+			b.logger.Error("writing submissions", "count", len(subs), "error", err)
+		}
+		for _, p := range batch {
+			p.done <- err
+		}
+		return
+	}
+
+	b.logger.Warn("writing submissions batch; retrying rows individually", "count", len(subs), "error", err)
+	// The retries share one timeout, so a database that is down costs one
+	// timeout rather than one per row.
+	ctx, cancel = context.WithTimeout(context.Background(), batchWriteTimeout)
+	defer cancel()
+	for _, p := range batch {
+		err := b.store.InsertSubmissions(ctx, []*Submission{p.sub})
+		if err != nil {
+			b.logger.Error("writing submission", "submission_id", p.sub.ID, "error", err)
+		}
+		p.done <- err
+	}
+}
@@ -0,0 +1,164 @@
+package submission
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+type stubInserter struct {
+	mu      sync.Mutex
+	batches [][]*Submission
+	err     error
+	// rejectID fails any insert that includes the submission with this ID,
+	// like a row the database rejects.
+	rejectID string
+}
+
+func (s *stubInserter) InsertSubmissions(_ context.Context, subs []*Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, subs)
+	for _, sub := range subs {
+		if s.rejectID != "" && sub.ID == s.rejectID {
+			return errors.New("invalid byte sequence")
+		}
+	}
+	return s.err
+}
+
+func newTestBatcher(store BatchInserter, queueSize, batchSize int) *Batcher {
+	b := NewBatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.queue = make(chan pendingWrite, queueSize)
+	b.batchSize = batchSize
+	return b
+}
+
+func TestBatcher_GroupsQueuedWrites(t *testing.T) {
+	store := &stubInserter{}
+	b := newTestBatcher(store, 10, 2)
+
+	// Queue five writes before the batcher starts so they are all pending.
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		p := pendingWrite{sub: &Submission{ID: string(rune('a' + i))}, done: make(chan error, 1)}
+		b.queue <- p
+		go func() {
+			defer wg.Done()
+			errs <- <-p.done
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go b.Run(ctx)
+	wg.Wait()
+	cancel()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("expected every write to succeed, got %v", err)
+		}
+	}
+	if len(store.batches) != 3 || len(store.batches[0]) != 2 || len(store.batches[2]) != 1 {
+		t.Errorf("expected batches of 2, 2, and 1, got %d batches", len(store.batches))
+	}
+}
+
+func TestBatcher_WriteSubmissionWaitsForInsert(t *testing.T) {
+	store := &stubInserter{}
+	b := newTestBatcher(store, 10, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	sub := &Submission{ID: "sub-1"}
+	if err := b.WriteSubmission(context.Background(), sub); err != nil {
+		t.Fatalf("WriteSubmission() returned unexpected error: %v", err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.batches) != 1 || store.batches[0][0] != sub {
+		t.Errorf("expected the submission to be inserted before WriteSubmission returned, got %v", store.batches)
+	}
+}
+
+func TestBatcher_InsertErrorReturnedToEachWriter(t *testing.T) {
+	store := &stubInserter{err: errors.New("connection refused")}
+	b := newTestBatcher(store, 10, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	if err := b.WriteSubmission(context.Background(), &Submission{}); err == nil {
+		t.Error("expected the insert error to be returned")
+	}
+}
+
+func TestBatcher_BadRowFailsOnlyItsWriter(t *testing.T) {
+	store := &stubInserter{rejectID: "b"}
+	b := newTestBatcher(store, 10, 10)
+
+	errs := make(map[string]chan error)
+	for _, id := range []string{"a", "b", "c"} {
+		p := pendingWrite{sub: &Submission{ID: id}, done: make(chan error, 1)}
+		b.queue <- p
+		errs[id] = p.done
+	}
+	b.flush(b.collect(<-b.queue))
+
+	if err := <-errs["b"]; err == nil {
+		t.Error("expected the rejected row's writer to get an error")
+	}
+	for _, id := range []string{"a", "c"} {
+		if err := <-errs[id]; err != nil {
+			t.Errorf("expected %s to be written after the retry, got %v", id, err)
+		}
+	}
+	if len(store.batches) != 4 || len(store.batches[0]) != 3 {
+		t.Errorf("expected one batch then three single-row retries, got %d inserts", len(store.batches))
+	}
+}
+
+func TestBatcher_FullQueueOverloaded(t *testing.T) {
+	b := newTestBatcher(&stubInserter{}, 1, 1)
+	b.queue <- pendingWrite{sub: &Submission{}, done: make(chan error, 1)}
+
+	if err := b.WriteSubmission(context.Background(), &Submission{}); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("expected ErrOverloaded, got: %v", err)
+	}
+}
+
+func TestBatcher_ContextCancelledWhileWaiting(t *testing.T) {
+	b := newTestBatcher(&stubInserter{}, 1, 1) // not running, so the write never completes
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := b.WriteSubmission(ctx, &Submission{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestBatcher_DrainsOnShutdown(t *testing.T) {
+	store := &stubInserter{}
+	b := newTestBatcher(store, 10, 10)
+	for i := 0; i < 3; i++ {
+		b.queue <- pendingWrite{sub: &Submission{}, done: make(chan error, 1)}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b.Run(ctx)
+
+	if len(b.queue) != 0 {
+		t.Errorf("expected the queue to be drained, %d left", len(b.queue))
+	}
+	if len(store.batches) != 1 || len(store.batches[0]) != 3 {
+		t.Errorf("expected the queued writes in one batch, got %v", store.batches)
+	}
+}
@@ -0,0 +1,69 @@
+package submission
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvTimeLayout is how timestamps are written in CSV exports: in the export's
+// location, in a form spreadsheets parse as a date and time.
+const csvTimeLayout = "2006-01-02 15:04:05"
+
+// WriteCSV writes the export to w as CSV with a header row.
+func (e *Export) WriteCSV(ctx context.Context, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		record[i] = escapeCSVText(c.header)
+	}
+	if err := cw.Write(record); err != nil {
+		return fmt.Errorf("writing csv header: %w", err)
+	}
+
+	err := e.each(ctx, func(sub *Submission) error {
+		for i, c := range e.columns {
+			record[i] = e.csvCell(c.value(sub))
+		}
+		return cw.Write(record)
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writing csv: %w", err)
+	}
+	return nil
+}
+
+func (e *Export) csvCell(v any) string {
+	switch v := v.(type) {
+	case string:
+		return escapeCSVText(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.In(e.loc).Format(csvTimeLayout)
+	default:
+		return ""
+	}
+}
+
+// escapeCSVText guards against CSV injection: spreadsheets evaluate a cell
+// starting with =, +, -, or @ as a formula, and some also treat a leading tab
+// or carriage return that way. Such text is prefixed with an apostrophe so it
+// is shown as written. Numbers are formatted by csvCell and never escaped.
+func escapeCSVText(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
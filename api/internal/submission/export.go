@@ -0,0 +1,205 @@
+package submission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// exportPageSize is how many submissions an Export reads per query. Rows are
+// written as each page arrives, so memory use does not grow with the export.
+const exportPageSize = 500
+
+// KeyLister reports which input and output keys a calculator's submissions
+// use, so exports keep columns for fields and outputs that have since been
+// removed from the config.
+type KeyLister interface {
+	// SubmissionKeys returns the distinct input_values and output_values keys
+	// across the submissions matching q. q.After and q.Limit are ignored.
+	SubmissionKeys(ctx context.Context, q ListQuery) (inputs, outputs []string, err error)
+}
+
+// WithExport enables Export. It requires WithHistory.
+func (s *Service) WithExport(keys KeyLister) *Service {
+	s.keys = keys
+	return s
+}
+
+// Export is a prepared submission export. Its columns are fixed when it is
+// created; rows are read page by page as it is written with WriteCSV or
+// WriteXLSX.
+type Export struct {
+	lister  Lister
+	q       ListQuery
+	loc     *time.Location
+	columns []column
+}
+
+// column is one export column: its header and how to read its cell from a
+// submission. Cells are nil, a string, a float64, or a time.Time.
+type column struct {
+	header string
+	value  func(*Submission) any
+}
+
+// Export prepares an export of the submissions to calculatorID that match
+// opts' filters, newest first, limited to userID's history window.
+// opts.Cursor and opts.Limit are ignored. Timestamps are written in loc.
+//
+// Columns are the submission ID and time, one per field variable name, one
+// per output, the lead's contact details, and the referrer. Fields and
+// outputs come in the order of the calculator's current config, followed by
+// any keys the matching submissions hold that the config no longer has.
+// Returns calculator.ErrNotFound if the calculator does not exist or is
+// soft-deleted, and calculator.ErrForbidden if userID does not own it.
+func (s *Service) Export(ctx context.Context, calculatorID, userID string, opts ListOptions, loc *time.Location) (*Export, error) {
+	calc, err := s.calcs.Get(ctx, calculatorID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	cfg, err := configschema.Parse(calc.Config)
+	if err != nil {
+		return nil, fmt.Errorf("parsing calculator config: %w", err)
+	}
+	cutoff, err := s.cutoff(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := newListQuery(calculatorID, opts)
+	if cutoff.After(q.From) {
+		q.From = cutoff
+	}
+	inputs, outputs, err := s.keys.SubmissionKeys(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("listing submission keys: %w", err)
+	}
+	q.Limit = exportPageSize
+	return &Export{lister: s.lister, q: q, loc: loc, columns: exportColumns(cfg, inputs, outputs, loc)}, nil
+}
+
+// exportColumns returns the columns for cfg, extended with the stored input
+// and output keys it no longer defines.
+func exportColumns(cfg *configschema.Config, inputs, outputs []string, loc *time.Location) []column {
+	columns := []column{
+		{"Submission ID", func(s *Submission) any { return s.ID }},
+		{"Submitted At (" + loc.String() + ")", func(s *Submission) any { return s.CreatedAt }},
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range cfg.Fields {
+		if f.VariableName == "" || seen[f.VariableName] {
+			continue
+		}
+		seen[f.VariableName] = true
+		columns = append(columns, inputColumn(f.VariableName))
+	}
+	for _, key := range sortedUnseen(inputs, seen) {
+		columns = append(columns, inputColumn(key))
+	}
+
+	seen = make(map[string]bool)
+	for _, o := range cfg.Outputs {
+		if o.ID == "" || seen[o.ID] {
+			continue
+		}
+		seen[o.ID] = true
+		header := o.Label
+		if header == "" {
+			header = o.ID
+		}
+		columns = append(columns, outputColumn(header, o.ID))
+	}
+	// Removed outputs have no label left to show, so their ID stands in.
+	for _, id := range sortedUnseen(outputs, seen) {
+		columns = append(columns, outputColumn(id, id))
+	}
+
+	return append(columns,
+		column{"Lead Name", func(s *Submission) any { return leadValue(s, func(l *LeadInfo) string { return l.Name }) }},
+		column{"Lead Email", func(s *Submission) any { return leadValue(s, func(l *LeadInfo) string { return l.Email }) }},
+		column{"Lead Phone", func(s *Submission) any { return leadValue(s, func(l *LeadInfo) string { return l.Phone }) }},
+		column{"Referrer URL", func(s *Submission) any { return s.ReferrerURL }},
+	)
+}
+
+func inputColumn(name string) column {
+	return column{name, func(s *Submission) any { return exportValue(s.InputValues[name]) }}
+}
+
+func outputColumn(header, id string) column {
+	return column{header, func(s *Submission) any { return exportValue(s.OutputValues[id]) }}
+}
+
+func leadValue(s *Submission, get func(*LeadInfo) string) any {
+	if s.LeadInfo == nil {
+		return nil
+	}
+	return get(s.LeadInfo)
+}
+
+// sortedUnseen returns the keys not in seen, sorted.
+func sortedUnseen(keys []string, seen map[string]bool) []string {
+	var out []string
+	for _, k := range keys {
+		if !seen[k] {
+			out = append(out, k)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// exportValue converts a stored input or output value to a cell: numbers stay
+// numbers, checkbox lists are joined, and booleans become "true" or "false".
+func exportValue(v any) any {
+	switch v := v.(type) {
+	case nil, string, float64:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				parts = append(parts, s)
+			} else {
+				b, _ := json.Marshal(item)
+				parts = append(parts, string(b))
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		// Validation only stores the types above; anything else predates it.
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// each calls fn with every matching submission, newest first, reading a page
+// at a time.
+func (e *Export) each(ctx context.Context, fn func(*Submission) error) error {
+	q := e.q
+	for {
+		subs, err := e.lister.ListSubmissions(ctx, q)
+		if err != nil {
+			return fmt.Errorf("listing submissions: %w", err)
+		}
+		for _, sub := range subs {
+			if err := fn(sub); err != nil {
+				return err
+			}
+		}
+		if len(subs) < q.Limit {
+			return nil
+		}
+		last := subs[len(subs)-1]
+		q.After = &PageKey{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
@@ -0,0 +1,237 @@
+package submission
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// pagedLister serves subs, which must be newest first, honouring After and
+// Limit like the Postgres repository.
+type pagedLister struct {
+	subs    []*Submission
+	queries []ListQuery
+}
+
+func (l *pagedLister) ListSubmissions(_ context.Context, q ListQuery) ([]*Submission, error) {
+	l.queries = append(l.queries, q)
+	var out []*Submission
+	for _, sub := range l.subs {
+		if q.After != nil && !sub.CreatedAt.Before(q.After.CreatedAt) {
+			continue
+		}
+		if len(out) == q.Limit {
+			break
+		}
+		out = append(out, sub)
+	}
+	return out, nil
+}
+
+type stubKeyLister struct {
+	inputs, outputs []string
+	gotQuery        ListQuery
+}
+
+func (s *stubKeyLister) SubmissionKeys(_ context.Context, q ListQuery) ([]string, []string, error) {
+	s.gotQuery = q
+	return s.inputs, s.outputs, nil
+}
+
+func newExportService(lister Lister, keys KeyLister, calcs CalculatorGetter) *Service {
+	return newHistoryService(lister, &stubGetter{}, calcs).WithExport(keys)
+}
+
+func exportSubmissions() []*Submission {
+	total := 2400.0
+	return []*Submission{
+		{
+			ID: "sub-2", CreatedAt: time.Date(2026, 3, 30, 23, 30, 0, 0, time.UTC),
+			InputValues:  map[string]any{"sqft": 1200.0, "finish": "=HYPERLINK(\"http://evil\")", "extras": []any{"primer", "tape"}, "notes": "-2 coats"},
+			OutputValues: map[string]any{"total": 2400.0}, Total: &total,
+			LeadInfo:    &LeadInfo{Name: "@sam", Email: "sam@example.com"},
+			ReferrerURL: "https://example.com/pricing",
+		},
+		{
+			ID: "sub-1", CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+			InputValues:  map[string]any{"sqft": -5.0, "coats": 2.0, "extras": true},
+			OutputValues: map[string]any{"total": nil, "legacy": 7.5},
+		},
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	keys := &stubKeyLister{inputs: []string{"sqft", "coats", "extras"}, outputs: []string{"legacy", "total"}}
+	svc := newExportService(&pagedLister{subs: exportSubmissions()}, keys, &stubCalculatorGetter{config: []byte(submissionConfig)})
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("LoadLocation() failed: %v", err)
+	}
+
+	exp, err := svc.Export(context.Background(), "calc-abc", "user-xyz", ListOptions{}, toronto)
+	if err != nil {
+		t.Fatalf("Export() returned unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := exp.WriteCSV(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteCSV() returned unexpected error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"Submission ID", "Submitted At (America/Toronto)", "sqft", "finish", "extras", "notes", "coats", "Total", "legacy", "Lead Name", "Lead Email", "Lead Phone", "Referrer URL"},
+		{"sub-2", "2026-03-30 19:30:00", "1200", `'=HYPERLINK("http://evil")`, "primer, tape", "'-2 coats", "", "2400", "", "'@sam", "sam@example.com", "", "https://example.com/pricing"},
+		{"sub-1", "2026-03-01 04:00:00", "-5", "", "true", "", "2", "", "7.5", "", "", "", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %d: %q", len(want), len(records), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d:\n got %q\nwant %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestExport_AppliesHistoryWindow(t *testing.T) {
+	keys := &stubKeyLister{}
+	lister := &pagedLister{}
+	svc := newExportService(lister, keys, &stubCalculatorGetter{config: []byte(submissionConfig)})
+
+	exp, err := svc.Export(context.Background(), "calc-abc", "user-xyz", ListOptions{Search: " sam ", Cursor: "ignored", Limit: 5}, time.UTC)
+	if err != nil {
+		t.Fatalf("Export() returned unexpected error: %v", err)
+	}
+	if err := exp.WriteCSV(context.Background(), io.Discard); err != nil {
+		t.Fatalf("WriteCSV() returned unexpected error: %v", err)
+	}
+	cutoff := historyNow.Add(-FreeHistoryWindow)
+	if !keys.gotQuery.From.Equal(cutoff) || keys.gotQuery.Search != "sam" {
+		t.Errorf("expected keys to be listed within the window, got %+v", keys.gotQuery)
+	}
+	if len(lister.queries) != 1 || !lister.queries[0].From.Equal(cutoff) || lister.queries[0].Limit != exportPageSize {
+		t.Errorf("expected one windowed page query, got %+v", lister.queries)
+	}
+}
+
+func TestExport_Pages(t *testing.T) {
+	var subs []*Submission
+	for i := range exportPageSize + 1 {
+		subs = append(subs, &Submission{ID: "sub", CreatedAt: historyNow.Add(-time.Duration(i) * time.Second)})
+	}
+	lister := &pagedLister{subs: subs}
+	svc := newExportService(lister, &stubKeyLister{}, &stubCalculatorGetter{config: []byte(submissionConfig)})
+
+	exp, err := svc.Export(context.Background(), "calc-abc", "user-xyz", ListOptions{}, time.UTC)
+	if err != nil {
+		t.Fatalf("Export() returned unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := exp.WriteCSV(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteCSV() returned unexpected error: %v", err)
+	}
+	if rows := strings.Count(buf.String(), "\n"); rows != exportPageSize+2 {
+		t.Errorf("expected a header and %d rows, got %d lines", exportPageSize+1, rows)
+	}
+	if len(lister.queries) != 2 || lister.queries[1].After == nil || !lister.queries[1].After.CreatedAt.Equal(subs[exportPageSize-1].CreatedAt) {
+		t.Errorf("expected the second page to resume after the first, got %+v", lister.queries)
+	}
+}
+
+func TestExport_OwnershipChecked(t *testing.T) {
+	lister := &pagedLister{}
+	svc := newExportService(lister, &stubKeyLister{}, &stubCalculatorGetter{err: calculator.ErrForbidden})
+
+	if _, err := svc.Export(context.Background(), "calc-abc", "other-user", ListOptions{}, time.UTC); !errors.Is(err, calculator.ErrForbidden) {
+		t.Errorf("expected calculator.ErrForbidden, got: %v", err)
+	}
+	if len(lister.queries) != 0 {
+		t.Error("expected submissions not to be queried")
+	}
+}
+
+func TestExport_XLSX(t *testing.T) {
+	keys := &stubKeyLister{inputs: []string{"sqft", "coats"}, outputs: []string{"total"}}
+	svc := newExportService(&pagedLister{subs: exportSubmissions()}, keys, &stubCalculatorGetter{config: []byte(submissionConfig)})
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("LoadLocation() failed: %v", err)
+	}
+
+	exp, err := svc.Export(context.Background(), "calc-abc", "user-xyz", ListOptions{}, toronto)
+	if err != nil {
+		t.Fatalf("Export() returned unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := exp.WriteXLSX(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteXLSX() returned unexpected error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("opening workbook: %v", err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening worksheet: %v", err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		sheet = string(b)
+	}
+	if len(zr.File) != 6 || sheet == "" {
+		t.Fatalf("expected six parts including the worksheet, got %d", len(zr.File))
+	}
+
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr" s="2"><is><t xml:space="preserve">Submission ID</t></is></c>`,
+		// 2026-03-30 19:30 in Toronto.
+		`<c r="B2" s="1"><v>46111.8125</v></c>`,
+		`<c r="C2"><v>1200</v></c>`,
+		// Inline strings are never formulas, so they are kept verbatim.
+		`<c r="D2" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;http://evil&#34;)</t></is></c>`,
+		`<c r="C3"><v>-5</v></c>`,
+		`<c r="G3"><v>2</v></c>`,
+		`<row r="3">`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("expected worksheet to contain %s", want)
+		}
+	}
+	if strings.Contains(sheet, `<row r="4">`) {
+		t.Error("expected only a header and two rows")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestExcelSerial(t *testing.T) {
+	if got := excelSerial(time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)); got != 61 {
+		t.Errorf("expected 1900-03-01 to be serial 61, got %v", got)
+	}
+	if got := excelSerial(time.Date(2026, 1, 1, 18, 0, 0, 0, time.UTC)); got != 46023.75 {
+		t.Errorf("expected 2026-01-01 18:00 to be serial 46023.75, got %v", got)
+	}
+}
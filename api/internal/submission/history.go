@@ -0,0 +1,287 @@
+package submission
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+// Page size bounds for List.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// FreeHistoryWindow is how far back the free plan shows submissions when no
+// retention policy overrides it. The window is applied when reading; the
+// Retainer decides separately whether older submissions are purged.
+const FreeHistoryWindow = 30 * 24 * time.Hour
+
+// ErrNotFound is returned when a submission does not exist or is outside the
+// owner's history window.
+var ErrNotFound = errors.New("submission not found")
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// was issued for a different calculator or filter.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListOptions filters and paginates List. Zero values leave a filter off.
+type ListOptions struct {
+	// From and To bound created_at to [From, To).
+	From time.Time
+	To   time.Time
+	// MinTotal and MaxTotal bound Total, inclusively. Submissions without a
+	// total are excluded when either is set.
+	MinTotal *float64
+	MaxTotal *float64
+	// HasEmail, when non-nil, keeps only submissions with (true) or without
+	// (false) a lead email address.
+	HasEmail *bool
+	// Search, when non-empty, keeps submissions whose lead name, email, or
+	// phone contains it, case-insensitively.
+	Search string
+	// Quarantined selects the submissions the spam filter quarantined instead
+	// of those that passed it.
+	Quarantined bool
+	// Cursor is the NextCursor of the previous page, or empty for the first page.
+	Cursor string
+	// Limit is the maximum page size. Zero means DefaultPageSize; values above
+	// MaxPageSize are clamped.
+	Limit int
+}
+
+// Page is one page of List results, newest first. NextCursor is empty on the
+// last page.
+type Page struct {
+	Submissions []*Submission
+	NextCursor  string
+}
+
+// PageKey identifies the last row of a page so the next page can resume
+// strictly after it.
+type PageKey struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// ListQuery is the fully resolved query passed to a Lister. From already
+// includes the history window.
+type ListQuery struct {
+	CalculatorID string
+	From         time.Time
+	To           time.Time
+	MinTotal     *float64
+	MaxTotal     *float64
+	HasEmail     *bool
+	Search       string
+	Quarantined  bool
+	// After, when non-nil, restricts results to rows older than this key.
+	After *PageKey
+	Limit int
+}
+
+// Lister lists a calculator's submissions newest first.
+type Lister interface {
+	ListSubmissions(ctx context.Context, q ListQuery) ([]*Submission, error)
+}
+
+// Getter fetches a single submission.
+type Getter interface {
+	// GetSubmission returns ErrNotFound if no submission has id.
+	GetSubmission(ctx context.Context, id string) (*Submission, error)
+}
+
+// CalculatorGetter fetches a calculator on behalf of userID, enforcing
+// ownership. calculator.Service satisfies it.
+type CalculatorGetter interface {
+	Get(ctx context.Context, id, userID string) (*calculator.Calculator, error)
+}
+
+// HistoryWindows reports how far back a user's plan shows submissions.
+type HistoryWindows interface {
+	// HistoryWindow returns the window for userID; zero means unlimited.
+	HistoryWindow(ctx context.Context, userID string) (time.Duration, error)
+}
+
+// FixedHistoryWindow is a HistoryWindows that gives every user the same
+// window, whatever their plan.
+type FixedHistoryWindow time.Duration
+
+// HistoryWindow implements HistoryWindows.
+func (w FixedHistoryWindow) HistoryWindow(context.Context, string) (time.Duration, error) {
+	return time.Duration(w), nil
+}
+
+// WithHistory enables List and Get. calcs enforces that only a calculator's
+// owner reads its submissions, and windows limits how far back they can see.
+func (s *Service) WithHistory(lister Lister, getter Getter, calcs CalculatorGetter, windows HistoryWindows) *Service {
+	s.lister = lister
+	s.getter = getter
+	s.calcs = calcs
+	s.windows = windows
+	return s
+}
+
+// List returns a page of the submissions to calculatorID that match opts,
+// newest first, limited to userID's history window.
+// Returns calculator.ErrNotFound if the calculator does not exist or is
+// soft-deleted, and calculator.ErrForbidden if userID does not own it.
+// Returns ErrInvalidCursor if opts.Cursor is malformed or was issued for
+// another query.
+func (s *Service) List(ctx context.Context, calculatorID, userID string, opts ListOptions) (*Page, error) {
+	if _, err := s.calcs.Get(ctx, calculatorID, userID); err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	cutoff, err := s.cutoff(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := newListQuery(calculatorID, opts)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor, q)
+		if err != nil {
+			return nil, err
+		}
+		q.After = after
+	}
+	// The cursor records the filters as requested; the window is applied
+	// afterwards because it moves with the clock.
+	requested := q
+	if cutoff.After(q.From) {
+		q.From = cutoff
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	q.Limit = limit + 1
+	subs, err := s.lister.ListSubmissions(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("listing submissions: %w", err)
+	}
+	page := &Page{Submissions: subs}
+	if len(subs) > limit {
+		page.Submissions = subs[:limit]
+		page.NextCursor = encodeCursor(requested, subs[limit-1])
+	}
+	return page, nil
+}
+
+// Get returns the submission identified by id if userID owns its calculator.
+// Returns ErrNotFound if the submission does not exist or is older than
+// userID's history window, calculator.ErrNotFound if its calculator is
+// soft-deleted, and calculator.ErrForbidden if userID does not own it.
+func (s *Service) Get(ctx context.Context, id, userID string) (*Submission, error) {
+	sub, err := s.getter.GetSubmission(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting submission: %w", err)
+	}
+	if _, err := s.calcs.Get(ctx, sub.CalculatorID, userID); err != nil {
+		return nil, fmt.Errorf("verifying calculator ownership: %w", err)
+	}
+	cutoff, err := s.cutoff(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.CreatedAt.Before(cutoff) {
+		return nil, ErrNotFound
+	}
+	return sub, nil
+}
+
+// newListQuery returns the query for opts' filters, without the history
+// window, cursor, or limit.
+func newListQuery(calculatorID string, opts ListOptions) ListQuery {
+	return ListQuery{
+		CalculatorID: calculatorID,
+		From:         opts.From,
+		To:           opts.To,
+		MinTotal:     opts.MinTotal,
+		MaxTotal:     opts.MaxTotal,
+		HasEmail:     opts.HasEmail,
+		Search:       strings.TrimSpace(opts.Search),
+		Quarantined:  opts.Quarantined,
+	}
+}
+
+// cutoff returns the oldest created_at userID may see, or the zero time when
+// their window is unlimited.
+func (s *Service) cutoff(ctx context.Context, userID string) (time.Time, error) {
+	window, err := s.windows.HistoryWindow(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("getting history window: %w", err)
+	}
+	if window <= 0 {
+		return time.Time{}, nil
+	}
+	return s.now().UTC().Add(-window), nil
+}
+
+// cursor is the decoded form of an opaque pagination cursor. It records the
+// query it was issued for so it cannot be replayed against a different one.
+type cursor struct {
+	CalculatorID string    `json:"c"`
+	From         time.Time `json:"f,omitzero"`
+	To           time.Time `json:"t,omitzero"`
+	MinTotal     *float64  `json:"lo,omitempty"`
+	MaxTotal     *float64  `json:"hi,omitempty"`
+	HasEmail     *bool     `json:"e,omitempty"`
+	Search       string    `json:"q,omitempty"`
+	Quarantined  bool      `json:"sp,omitempty"`
+	CreatedAt    time.Time `json:"at"`
+	ID           string    `json:"id"`
+}
+
+// encodeCursor returns the cursor that resumes q after sub.
+func encodeCursor(q ListQuery, sub *Submission) string {
+	c := cursor{
+		CalculatorID: q.CalculatorID,
+		From:         q.From,
+		To:           q.To,
+		MinTotal:     q.MinTotal,
+		MaxTotal:     q.MaxTotal,
+		HasEmail:     q.HasEmail,
+		Search:       q.Search,
+		Quarantined:  q.Quarantined,
+		CreatedAt:    sub.CreatedAt,
+		ID:           sub.ID,
+	}
+	// Marshalling a struct of strings, numbers, bools, and times cannot fail.
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses s and checks it was issued for q.
+func decodeCursor(s string, q ListQuery) (*PageKey, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.CalculatorID != q.CalculatorID || !c.From.Equal(q.From) || !c.To.Equal(q.To) ||
+		!equalPtr(c.MinTotal, q.MinTotal) || !equalPtr(c.MaxTotal, q.MaxTotal) ||
+		!equalPtr(c.HasEmail, q.HasEmail) || c.Search != q.Search || c.Quarantined != q.Quarantined {
+		return nil, ErrInvalidCursor
+	}
+	return &PageKey{CreatedAt: c.CreatedAt, ID: c.ID}, nil
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,176 @@
+package submission
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+)
+
+var historyNow = time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+type stubLister struct {
+	subs []*Submission
+	err  error
+	// gotQuery records the query passed to ListSubmissions.
+	gotQuery ListQuery
+}
+
+func (s *stubLister) ListSubmissions(_ context.Context, q ListQuery) ([]*Submission, error) {
+	s.gotQuery = q
+	return s.subs, s.err
+}
+
+type stubGetter struct {
+	sub *Submission
+	err error
+}
+
+func (s *stubGetter) GetSubmission(_ context.Context, _ string) (*Submission, error) {
+	return s.sub, s.err
+}
+
+type stubCalculatorGetter struct {
+	config []byte
+	err    error
+}
+
+func (s *stubCalculatorGetter) Get(_ context.Context, id, _ string) (*calculator.Calculator, error) {
+	return &calculator.Calculator{ID: id, Config: s.config}, s.err
+}
+
+func newHistoryService(lister Lister, getter Getter, calcs CalculatorGetter) *Service {
+	svc := NewService(&stubWriter{}).WithHistory(lister, getter, calcs, FixedHistoryWindow(FreeHistoryWindow))
+	svc.now = func() time.Time { return historyNow }
+	return svc
+}
+
+func TestList_AppliesHistoryWindow(t *testing.T) {
+	lister := &stubLister{}
+	svc := newHistoryService(lister, &stubGetter{}, &stubCalculatorGetter{})
+
+	if _, err := svc.List(context.Background(), "calc-abc", "user-xyz", ListOptions{From: historyNow.AddDate(0, -6, 0)}); err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if want := historyNow.Add(-FreeHistoryWindow); !lister.gotQuery.From.Equal(want) {
+		t.Errorf("expected From to be clamped to %v, got %v", want, lister.gotQuery.From)
+	}
+
+	recent := historyNow.AddDate(0, 0, -2)
+	if _, err := svc.List(context.Background(), "calc-abc", "user-xyz", ListOptions{From: recent}); err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if !lister.gotQuery.From.Equal(recent) {
+		t.Errorf("expected a From inside the window to be kept, got %v", lister.gotQuery.From)
+	}
+}
+
+func TestList_UnlimitedWindow(t *testing.T) {
+	lister := &stubLister{}
+	svc := NewService(&stubWriter{}).WithHistory(lister, &stubGetter{}, &stubCalculatorGetter{}, FixedHistoryWindow(0))
+
+	if _, err := svc.List(context.Background(), "calc-abc", "user-xyz", ListOptions{}); err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if !lister.gotQuery.From.IsZero() {
+		t.Errorf("expected no lower bound, got %v", lister.gotQuery.From)
+	}
+}
+
+func TestList_Pagination(t *testing.T) {
+	subs := []*Submission{
+		{ID: "sub-3", CreatedAt: historyNow.Add(-time.Hour)},
+		{ID: "sub-2", CreatedAt: historyNow.Add(-2 * time.Hour)},
+		{ID: "sub-1", CreatedAt: historyNow.Add(-3 * time.Hour)},
+	}
+	lister := &stubLister{subs: subs}
+	svc := newHistoryService(lister, &stubGetter{}, &stubCalculatorGetter{})
+	hasEmail := true
+	opts := ListOptions{Search: " sam ", HasEmail: &hasEmail, Limit: 2}
+
+	page, err := svc.List(context.Background(), "calc-abc", "user-xyz", opts)
+	if err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if lister.gotQuery.Limit != 3 || lister.gotQuery.Search != "sam" {
+		t.Errorf("expected limit+1 and trimmed search, got %+v", lister.gotQuery)
+	}
+	if len(page.Submissions) != 2 || page.NextCursor == "" {
+		t.Fatalf("expected 2 submissions and a cursor, got %d and %q", len(page.Submissions), page.NextCursor)
+	}
+
+	lister.subs = subs[2:]
+	opts.Cursor = page.NextCursor
+	page, err = svc.List(context.Background(), "calc-abc", "user-xyz", opts)
+	if err != nil {
+		t.Fatalf("List() with cursor returned unexpected error: %v", err)
+	}
+	if after := lister.gotQuery.After; after == nil || after.ID != "sub-2" || !after.CreatedAt.Equal(subs[1].CreatedAt) {
+		t.Errorf("expected to resume after sub-2, got %+v", after)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("expected no cursor on the last page, got %q", page.NextCursor)
+	}
+
+	// A cursor cannot be replayed with different filters or for another calculator.
+	opts.Search = "alex"
+	if _, err := svc.List(context.Background(), "calc-abc", "user-xyz", opts); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for changed filters, got: %v", err)
+	}
+	opts.Search = "sam"
+	if _, err := svc.List(context.Background(), "calc-other", "user-xyz", opts); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for another calculator, got: %v", err)
+	}
+	if _, err := svc.List(context.Background(), "calc-abc", "user-xyz", ListOptions{Cursor: "!!"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for a malformed cursor, got: %v", err)
+	}
+}
+
+func TestList_OwnershipChecked(t *testing.T) {
+	lister := &stubLister{}
+	svc := newHistoryService(lister, &stubGetter{}, &stubCalculatorGetter{err: calculator.ErrForbidden})
+
+	if _, err := svc.List(context.Background(), "calc-abc", "other-user", ListOptions{}); !errors.Is(err, calculator.ErrForbidden) {
+		t.Errorf("expected calculator.ErrForbidden, got: %v", err)
+	}
+	if lister.gotQuery.CalculatorID != "" {
+		t.Error("expected submissions not to be queried")
+	}
+}
+
+func TestGet_Success(t *testing.T) {
+	sub := &Submission{ID: "sub-1", CalculatorID: "calc-abc", CreatedAt: historyNow.AddDate(0, 0, -29)}
+	svc := newHistoryService(&stubLister{}, &stubGetter{sub: sub}, &stubCalculatorGetter{})
+
+	got, err := svc.Get(context.Background(), "sub-1", "user-xyz")
+	if err != nil {
+		t.Fatalf("Get() returned unexpected error: %v", err)
+	}
+	if got != sub {
+		t.Errorf("expected the stored submission, got %+v", got)
+	}
+}
+
+func TestGet_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		getter  *stubGetter
+		calcErr error
+		want    error
+	}{
+		{"not found", &stubGetter{err: ErrNotFound}, nil, ErrNotFound},
+		{"outside window", &stubGetter{sub: &Submission{CreatedAt: historyNow.AddDate(0, 0, -31)}}, nil, ErrNotFound},
+		{"forbidden", &stubGetter{sub: &Submission{CreatedAt: historyNow}}, calculator.ErrForbidden, calculator.ErrForbidden},
+		{"calculator deleted", &stubGetter{sub: &Submission{CreatedAt: historyNow}}, calculator.ErrNotFound, calculator.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newHistoryService(&stubLister{}, tt.getter, &stubCalculatorGetter{err: tt.calcErr})
+			if _, err := svc.Get(context.Background(), "sub-1", "user-xyz"); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got: %v", tt.want, err)
+			}
+		})
+	}
+}
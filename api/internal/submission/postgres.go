@@ -0,0 +1,391 @@
+package submission
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgresSubmissionRepository implements BatchInserter against a PostgreSQL
+// database.
+type PostgresSubmissionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSubmissionRepository creates a PostgresSubmissionRepository backed by db.
+func NewPostgresSubmissionRepository(db *sql.DB) *PostgresSubmissionRepository {
+	return &PostgresSubmissionRepository{db: db}
+}
+
+// submissionColumns is the number of values bound per row by InsertSubmissions.
+const submissionColumns = 10
+
+// InsertSubmissions inserts subs in a single statement. Rows whose calculator
+// has been purged since the submission was validated are skipped rather than
+// failing the whole batch on the foreign key.
+func (r *PostgresSubmissionRepository) InsertSubmissions(ctx context.Context, subs []*Submission) error {
+	if len(subs) == 0 {
+		return nil
+	}
+	rows := make([]string, len(subs))
+	args := make([]any, 0, len(subs)*submissionColumns)
+	for i, s := range subs {
+		inputs, err := json.Marshal(s.InputValues)
+		if err != nil {
+			return fmt.Errorf("encoding input values: %w", err)
+		}
+		outputs, err := json.Marshal(s.OutputValues)
+		if err != nil {
+			return fmt.Errorf("encoding output values: %w", err)
+		}
+		var lead any // NULL when the end user left no contact details
+		if s.LeadInfo != nil {
+			b, err := json.Marshal(s.LeadInfo)
+			if err != nil {
+				return fmt.Errorf("encoding lead info: %w", err)
+			}
+			lead = b
+		}
+		ip := sql.NullString{String: s.IPAddress, Valid: s.IPAddress != ""}
+		spam := sql.NullString{String: s.SpamReason, Valid: s.SpamReason != ""}
+
+		n := i * submissionColumns
+		rows[i] = fmt.Sprintf("($%d::uuid, $%d::uuid, $%d::jsonb, $%d::jsonb, $%d::numeric, $%d::jsonb, $%d, $%d::inet, $%d::timestamptz, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10)
+		args = append(args, s.ID, s.CalculatorID, inputs, outputs, s.Total, lead, s.ReferrerURL, ip, s.CreatedAt, spam)
+	}
+
+	query := `
+		INSERT INTO submissions (id, calculator_id, input_values, output_values, total, lead_info, referrer_url, ip_address, created_at, spam_reason)
+		SELECT v.id, v.calculator_id, v.input_values, v.output_values, v.total, v.lead_info, v.referrer_url, v.ip_address, v.created_at, v.spam_reason
+		FROM (VALUES ` + strings.Join(rows, ", ") + `)
+			AS v(id, calculator_id, input_values, output_values, total, lead_info, referrer_url, ip_address, created_at, spam_reason)
+		WHERE EXISTS (SELECT 1 FROM calculators c WHERE c.id = v.calculator_id)
+	`
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting submissions: %w", err)
+	}
+	return nil
+}
+
+// submissionSelectColumns is the column list ListSubmissions and GetSubmission
+// select, in the order scanSubmission expects.
+const submissionSelectColumns = "id, calculator_id, input_values, output_values, total, lead_info, referrer_url, host(ip_address), created_at, spam_reason"
+
+// leadSearchExpr is the text searched by ListQuery.Search. It must match the
+// expression of submissions_lead_search_trgm_idx for the index to be used.
+const leadSearchExpr = `(coalesce(lead_info->>'name', '') || ' ' || coalesce(lead_info->>'email', '') || ' ' || coalesce(lead_info->>'phone', ''))`
+
+// likeEscaper escapes the LIKE wildcards and the default escape character so
+// user search text matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListSubmissions returns the submissions to q.CalculatorID that match q's
+// filters, newest first with id as a tiebreaker, starting strictly after
+// q.After, at most q.Limit rows. The calculator_id, created_at index serves
+// the ordering; lead search uses submissions_lead_search_trgm_idx.
+func (r *PostgresSubmissionRepository) ListSubmissions(ctx context.Context, q ListQuery) ([]*Submission, error) {
+	var b strings.Builder
+	b.WriteString(`
+		SELECT ` + submissionSelectColumns + `
+		FROM submissions
+		WHERE `)
+	args := writeFilters(&b, q)
+	args = append(args, q.Limit)
+	fmt.Fprintf(&b, "\n\t\tORDER BY created_at DESC, id DESC\n\t\tLIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, b.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying submissions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]*Submission, 0)
+	for rows.Next() {
+		sub, err := scanSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating submissions: %w", err)
+	}
+	return subs, nil
+}
+
+// GetSubmission fetches the submission identified by id.
+// Returns ErrNotFound if no such submission exists.
+func (r *PostgresSubmissionRepository) GetSubmission(ctx context.Context, id string) (*Submission, error) {
+	query := `SELECT ` + submissionSelectColumns + ` FROM submissions WHERE id = $1`
+	sub, err := scanSubmission(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// SubmissionKeys returns the distinct input_values and output_values keys,
+// in no particular order, across the submissions that match q's filters.
+// q.After and q.Limit are ignored. Export uses them to keep columns for fields
+// and outputs that have since been removed from the calculator.
+func (r *PostgresSubmissionRepository) SubmissionKeys(ctx context.Context, q ListQuery) (inputs, outputs []string, err error) {
+	filters := q
+	filters.After = nil
+	var where strings.Builder
+	args := writeFilters(&where, filters)
+	// Both halves bind the same arguments, so they share placeholders.
+	query := `
+		SELECT DISTINCT 'input', jsonb_object_keys(input_values) FROM submissions WHERE ` + where.String() + `
+		UNION
+		SELECT DISTINCT 'output', jsonb_object_keys(output_values) FROM submissions WHERE ` + where.String()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("querying submission keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind, key string
+		if err := rows.Scan(&kind, &key); err != nil {
+			return nil, nil, fmt.Errorf("scanning submission key: %w", err)
+		}
+		if kind == "input" {
+			inputs = append(inputs, key)
+		} else {
+			outputs = append(outputs, key)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterating submission keys: %w", err)
+	}
+	return inputs, outputs, nil
+}
+
+// writeFilters writes the WHERE conditions for q's calculator and filters,
+// including q.After when set, to b and returns their arguments.
+func writeFilters(b *strings.Builder, q ListQuery) []any {
+	args := []any{q.CalculatorID}
+	b.WriteString("calculator_id = $1")
+	if !q.From.IsZero() {
+		args = append(args, q.From)
+		fmt.Fprintf(b, "\n\t\t\tAND created_at >= $%d", len(args))
+	}
+	if !q.To.IsZero() {
+		args = append(args, q.To)
+		fmt.Fprintf(b, "\n\t\t\tAND created_at < $%d", len(args))
+	}
+	if q.MinTotal != nil {
+		args = append(args, *q.MinTotal)
+		fmt.Fprintf(b, "\n\t\t\tAND total >= $%d", len(args))
+	}
+	if q.MaxTotal != nil {
+		args = append(args, *q.MaxTotal)
+		fmt.Fprintf(b, "\n\t\t\tAND total <= $%d", len(args))
+	}
+	if q.HasEmail != nil {
+		if *q.HasEmail {
+			b.WriteString("\n\t\t\tAND coalesce(lead_info->>'email', '') <> ''")
+		} else {
+			b.WriteString("\n\t\t\tAND coalesce(lead_info->>'email', '') = ''")
+		}
+	}
+	if q.Search != "" {
+		args = append(args, "%"+likeEscaper.Replace(q.Search)+"%")
+		fmt.Fprintf(b, "\n\t\t\tAND lead_info IS NOT NULL AND %s ILIKE $%d", leadSearchExpr, len(args))
+	}
+	if q.Quarantined {
+		b.WriteString("\n\t\t\tAND spam_reason IS NOT NULL")
+	} else {
+		b.WriteString("\n\t\t\tAND spam_reason IS NULL")
+	}
+	if q.After != nil {
+		args = append(args, q.After.CreatedAt, q.After.ID)
+		fmt.Fprintf(b, "\n\t\t\tAND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	return args
+}
+
+// rowScanner is the Scan method shared by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSubmission scans submissionSelectColumns into a Submission.
+func scanSubmission(row rowScanner) (*Submission, error) {
+	var (
+		s                     Submission
+		inputs, outputs, lead []byte
+		total                 sql.NullFloat64
+		ip, spam              sql.NullString
+	)
+	if err := row.Scan(&s.ID, &s.CalculatorID, &inputs, &outputs, &total, &lead, &s.ReferrerURL, &ip, &s.CreatedAt, &spam); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scanning submission: %w", err)
+	}
+	if err := json.Unmarshal(inputs, &s.InputValues); err != nil {
+		return nil, fmt.Errorf("decoding input values: %w", err)
+	}
+	if err := json.Unmarshal(outputs, &s.OutputValues); err != nil {
+		return nil, fmt.Errorf("decoding output values: %w", err)
+	}
+	if lead != nil {
+		if err := json.Unmarshal(lead, &s.LeadInfo); err != nil {
+			return nil, fmt.Errorf("decoding lead info: %w", err)
+		}
+	}
+	if total.Valid {
+		s.Total = &total.Float64
+	}
+	s.IPAddress = ip.String
+	s.SpamReason = spam.String
+	return &s, nil
+}
+
+// retentionLockID is the Postgres advisory lock key held while the retention
+// job runs. It only needs to be distinct from other advisory locks in the
+// database.
+const retentionLockID int64 = 0x71637375626d7274 // "qcsubmrt"
+
+// LockRetention takes the retention advisory lock on a dedicated connection,
+// without waiting. The lock is tied to that connection, so it is released if
+// the instance dies mid-run.
+func (r *PostgresSubmissionRepository) LockRetention(ctx context.Context) (func(), bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("getting connection: %w", err)
+	}
+	var ok bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, retentionLockID).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("taking advisory lock: %w", err)
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	unlock := func() {
+		// The run's context may already be cancelled; the lock must still be
+		// released before the connection returns to the pool.
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, retentionLockID); err != nil {
+			// Discard the connection rather than pool it with the lock held.
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// planCutoffsJoin joins a submission aliased s to its calculator's owner,
+// aliased u, whose plan selects the cutoff that applies.
+const planCutoffsJoin = `
+		JOIN calculators c ON c.id = s.calculator_id
+		JOIN users u ON u.id = c.user_id`
+
+// planCutoffArgs returns cutoffs as the parallel text and timestamptz arrays
+// that the retention queries unnest.
+func planCutoffArgs(cutoffs []PlanCutoff) (plans, times any) {
+	p := make([]string, len(cutoffs))
+	t := make([]string, len(cutoffs))
+	for i, c := range cutoffs {
+		p[i] = c.Plan
+		t[i] = c.Cutoff.UTC().Format(time.RFC3339Nano)
+	}
+	return pq.Array(p), pq.Array(t)
+}
+
+// ExpireSubmissions implements RetentionStore. Rows locked by a concurrent
+// statement are skipped and picked up by a later run.
+func (r *PostgresSubmissionRepository) ExpireSubmissions(ctx context.Context, cutoffs []PlanCutoff, now time.Time, limit int) (int, error) {
+	const query = `
+		UPDATE submissions SET expired_at = $1
+		WHERE id IN (
+			SELECT s.id FROM submissions s` + planCutoffsJoin + `
+			JOIN unnest($2::text[], $3::timestamptz[]) AS p(plan, cutoff) ON p.plan = u.plan
+			WHERE s.expired_at IS NULL AND s.created_at < p.cutoff
+			LIMIT $4
+			FOR UPDATE OF s SKIP LOCKED
+		)`
+	plans, times := planCutoffArgs(cutoffs)
+	n, err := r.execCount(ctx, query, now, plans, times, limit)
+	if err != nil {
+		return 0, fmt.Errorf("marking expired submissions: %w", err)
+	}
+	return n, nil
+}
+
+// RestoreSubmissions implements RetentionStore.
+func (r *PostgresSubmissionRepository) RestoreSubmissions(ctx context.Context, cutoffs []PlanCutoff, limit int) (int, error) {
+	const query = `
+		UPDATE submissions SET expired_at = NULL
+		WHERE id IN (
+			SELECT s.id FROM submissions s` + planCutoffsJoin + `
+			WHERE s.expired_at IS NOT NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM unnest($1::text[], $2::timestamptz[]) AS p(plan, cutoff)
+				WHERE p.plan = u.plan AND s.created_at < p.cutoff
+			  )
+			LIMIT $3
+			FOR UPDATE OF s SKIP LOCKED
+		)`
+	plans, times := planCutoffArgs(cutoffs)
+	n, err := r.execCount(ctx, query, plans, times, limit)
+	if err != nil {
+		return 0, fmt.Errorf("unmarking expired submissions: %w", err)
+	}
+	return n, nil
+}
+
+// PurgeSubmissions implements RetentionStore. The owner's plan is checked
+// again so a row restored by a concurrent change is never deleted.
+func (r *PostgresSubmissionRepository) PurgeSubmissions(ctx context.Context, cutoffs []PlanCutoff, expiredBefore time.Time, limit int) (int, error) {
+	const query = `
+		DELETE FROM submissions
+		WHERE id IN (
+			SELECT s.id FROM submissions s` + planCutoffsJoin + `
+			JOIN unnest($1::text[], $2::timestamptz[]) AS p(plan, cutoff) ON p.plan = u.plan
+			WHERE s.expired_at < $3 AND s.created_at < p.cutoff
+			LIMIT $4
+			FOR UPDATE OF s SKIP LOCKED
+		)`
+	plans, times := planCutoffArgs(cutoffs)
+	n, err := r.execCount(ctx, query, plans, times, expiredBefore, limit)
+	if err != nil {
+		return 0, fmt.Errorf("deleting expired submissions: %w", err)
+	}
+	return n, nil
+}
+
+// execCount runs query and returns the number of rows it affected.
+func (r *PostgresSubmissionRepository) execCount(ctx context.Context, query string, args ...any) (int, error) {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("reading rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+// UserPlan implements PlanLookup.
+func (r *PostgresSubmissionRepository) UserPlan(ctx context.Context, userID string) (string, error) {
+	var plan string
+	if err := r.db.QueryRowContext(ctx, `SELECT plan FROM users WHERE id = $1`, userID).Scan(&plan); err != nil {
+		return "", fmt.Errorf("querying user plan: %w", err)
+	}
+	return plan, nil
+}
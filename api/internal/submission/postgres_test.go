@@ -0,0 +1,323 @@
+package submission
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestInsertSubmissions_Batch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	total := 20.0
+	subs := []*Submission{
+		{
+			ID: "sub-1", CalculatorID: "calc-1",
+			InputValues: map[string]any{"qty": 2.0}, OutputValues: map[string]any{"total": 20.0}, Total: &total,
+			LeadInfo: &LeadInfo{Email: "sam@example.com"}, ReferrerURL: "https://example.com", IPAddress: "203.0.113.7", CreatedAt: now,
+		},
+		{
+			ID: "sub-2", CalculatorID: "calc-2",
+			InputValues: map[string]any{}, OutputValues: map[string]any{"total": nil}, CreatedAt: now, SpamReason: SpamHoneypot,
+		},
+	}
+	mock.ExpectExec(`INSERT INTO submissions .+ FROM \(VALUES \(\$1::uuid, .+\), \(\$11::uuid, .+\$19::timestamptz, \$20\)\).+WHERE EXISTS`).
+		WithArgs(
+			"sub-1", "calc-1", []byte(`{"qty":2}`), []byte(`{"total":20}`), 20.0, []byte(`{"email":"sam@example.com"}`), "https://example.com", sql.NullString{String: "203.0.113.7", Valid: true}, now, sql.NullString{},
+			"sub-2", "calc-2", []byte(`{}`), []byte(`{"total":null}`), nil, nil, "", sql.NullString{}, now, sql.NullString{String: SpamHoneypot, Valid: true},
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	if err := repo.InsertSubmissions(context.Background(), subs); err != nil {
+		t.Fatalf("InsertSubmissions() returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestInsertSubmissions_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	if err := repo.InsertSubmissions(context.Background(), nil); err != nil {
+		t.Fatalf("InsertSubmissions() returned unexpected error: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestInsertSubmissions_DBError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	dbErr := errors.New("connection refused")
+	mock.ExpectExec(`INSERT INTO submissions`).WillReturnError(dbErr)
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	err = repo.InsertSubmissions(context.Background(), []*Submission{{ID: "sub-1", CalculatorID: "calc-1"}})
+	if !errors.Is(err, dbErr) {
+		t.Errorf("expected wrapped db error, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+var submissionRowColumns = []string{
+	"id", "calculator_id", "input_values", "output_values", "total", "lead_info", "referrer_url", "host", "created_at", "spam_reason",
+}
+
+func TestListSubmissions_Filters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	from, to := now.AddDate(0, 0, -7), now
+	minTotal, maxTotal, hasEmail := 100.0, 500.0, true
+	rows := sqlmock.NewRows(submissionRowColumns).
+		AddRow("sub-2", "calc-1", []byte(`{"qty":2}`), []byte(`{"total":200}`), 200.0, []byte(`{"email":"sam@example.com"}`), "https://example.com", "203.0.113.7", now, nil).
+		AddRow("sub-1", "calc-1", []byte(`{}`), []byte(`{}`), nil, nil, "", nil, now, nil)
+	mock.ExpectQuery(`FROM submissions\s+WHERE calculator_id = \$1\s+AND created_at >= \$2\s+AND created_at < \$3\s+AND total >= \$4\s+AND total <= \$5\s+AND coalesce\(lead_info->>'email', ''\) <> ''\s+AND lead_info IS NOT NULL AND .+ ILIKE \$6\s+AND spam_reason IS NULL\s+AND \(created_at, id\) < \(\$7, \$8\)\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$9`).
+		WithArgs("calc-1", from, to, minTotal, maxTotal, `%50\%%`, now, "sub-3", 51).
+		WillReturnRows(rows)
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	subs, err := repo.ListSubmissions(context.Background(), ListQuery{
+		CalculatorID: "calc-1", From: from, To: to, MinTotal: &minTotal, MaxTotal: &maxTotal, HasEmail: &hasEmail,
+		Search: "50%", After: &PageKey{CreatedAt: now, ID: "sub-3"}, Limit: 51,
+	})
+	if err != nil {
+		t.Fatalf("ListSubmissions() returned unexpected error: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("expected 2 submissions, got %d", len(subs))
+	}
+	if s := subs[0]; s.Total == nil || *s.Total != 200 || s.LeadInfo == nil || s.LeadInfo.Email != "sam@example.com" || s.IPAddress != "203.0.113.7" || s.InputValues["qty"] != 2.0 {
+		t.Errorf("unexpected first submission %+v", s)
+	}
+	if s := subs[1]; s.Total != nil || s.LeadInfo != nil || s.IPAddress != "" {
+		t.Errorf("expected NULL columns to scan as empty, got %+v", s)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListSubmissions_NoFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	mock.ExpectQuery(`WHERE calculator_id = \$1\s+AND spam_reason IS NULL\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$2`).
+		WithArgs("calc-1", 51).
+		WillReturnRows(sqlmock.NewRows(submissionRowColumns))
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	subs, err := repo.ListSubmissions(context.Background(), ListQuery{CalculatorID: "calc-1", Limit: 51})
+	if err != nil {
+		t.Fatalf("ListSubmissions() returned unexpected error: %v", err)
+	}
+	if subs == nil || len(subs) != 0 {
+		t.Errorf("expected an empty, non-nil slice, got %v", subs)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetSubmission(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	mock.ExpectQuery(`FROM submissions WHERE id = \$1`).
+		WithArgs("sub-1").
+		WillReturnRows(sqlmock.NewRows(submissionRowColumns).
+			AddRow("sub-1", "calc-1", []byte(`{}`), []byte(`{"total":5}`), 5.0, nil, "", nil, now, SpamTooFast))
+	mock.ExpectQuery(`FROM submissions WHERE id = \$1`).
+		WithArgs("sub-missing").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	sub, err := repo.GetSubmission(context.Background(), "sub-1")
+	if err != nil {
+		t.Fatalf("GetSubmission() returned unexpected error: %v", err)
+	}
+	if sub.ID != "sub-1" || sub.CalculatorID != "calc-1" || sub.OutputValues["total"] != 5.0 || sub.SpamReason != SpamTooFast {
+		t.Errorf("unexpected submission %+v", sub)
+	}
+	if _, err := repo.GetSubmission(context.Background(), "sub-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSubmissionKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	from := time.Now().UTC().Truncate(time.Second)
+	// Both halves of the union share the filter placeholders; After is ignored.
+	mock.ExpectQuery(`jsonb_object_keys\(input_values\) FROM submissions WHERE calculator_id = \$1\s+AND created_at >= \$2\s+AND spam_reason IS NOT NULL\s+UNION\s+SELECT DISTINCT 'output', jsonb_object_keys\(output_values\) FROM submissions WHERE calculator_id = \$1\s+AND created_at >= \$2\s+AND spam_reason IS NOT NULL$`).
+		WithArgs("calc-1", from).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "key"}).
+			AddRow("input", "sqft").
+			AddRow("output", "total").
+			AddRow("input", "coats"))
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	inputs, outputs, err := repo.SubmissionKeys(context.Background(), ListQuery{
+		CalculatorID: "calc-1", From: from, Quarantined: true, After: &PageKey{ID: "sub-1"}, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("SubmissionKeys() returned unexpected error: %v", err)
+	}
+	if len(inputs) != 2 || inputs[0] != "sqft" || inputs[1] != "coats" || len(outputs) != 1 || outputs[0] != "total" {
+		t.Errorf("unexpected keys %v and %v", inputs, outputs)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestLockRetention(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).
+		WithArgs(retentionLockID).
+		WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(true))
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).
+		WithArgs(retentionLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).
+		WithArgs(retentionLockID).
+		WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(false))
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	unlock, ok, err := repo.LockRetention(context.Background())
+	if err != nil || !ok {
+		t.Fatalf("LockRetention() = %v, %v; expected the lock", ok, err)
+	}
+	unlock()
+	if _, ok, err := repo.LockRetention(context.Background()); err != nil || ok {
+		t.Errorf("LockRetention() = %v, %v; expected the lock to be held elsewhere", ok, err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRetentionStatements(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	now := time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC)
+	cutoffs := []PlanCutoff{{Plan: "free", Cutoff: now.Add(-FreeHistoryWindow)}}
+	plans, times := planCutoffArgs(cutoffs)
+
+	mock.ExpectExec(`UPDATE submissions SET expired_at = \$1\s+WHERE id IN \(.+JOIN unnest\(\$2::text\[\], \$3::timestamptz\[\]\).+WHERE s.expired_at IS NULL AND s.created_at < p.cutoff\s+LIMIT \$4\s+FOR UPDATE OF s SKIP LOCKED`).
+		WithArgs(now, plans, times, 100).
+		WillReturnResult(sqlmock.NewResult(0, 100))
+	mock.ExpectExec(`UPDATE submissions SET expired_at = NULL\s+WHERE id IN \(.+WHERE s.expired_at IS NOT NULL\s+AND NOT EXISTS`).
+		WithArgs(plans, times, 100).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`DELETE FROM submissions\s+WHERE id IN \(.+WHERE s.expired_at < \$3 AND s.created_at < p.cutoff\s+LIMIT \$4`).
+		WithArgs(plans, times, now.Add(-time.Hour), 100).
+		WillReturnError(errors.New("db down"))
+	mock.ExpectClose()
+
+	repo := NewPostgresSubmissionRepository(db)
+	if n, err := repo.ExpireSubmissions(context.Background(), cutoffs, now, 100); err != nil || n != 100 {
+		t.Errorf("ExpireSubmissions() = %d, %v; expected 100", n, err)
+	}
+	if n, err := repo.RestoreSubmissions(context.Background(), cutoffs, 100); err != nil || n != 3 {
+		t.Errorf("RestoreSubmissions() = %d, %v; expected 3", n, err)
+	}
+	if _, err := repo.PurgeSubmissions(context.Background(), cutoffs, now.Add(-time.Hour), 100); err == nil {
+		t.Error("expected PurgeSubmissions() to return the database error")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUserPlan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() failed: %v", err)
+	}
+	mock.ExpectQuery(`SELECT plan FROM users WHERE id = \$1`).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"plan"}).AddRow("pro"))
+	mock.ExpectClose()
+
+	plan, err := NewPostgresSubmissionRepository(db).UserPlan(context.Background(), "user-1")
+	if err != nil || plan != "pro" {
+		t.Errorf("UserPlan() = %q, %v; expected pro", plan, err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
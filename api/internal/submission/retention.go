@@ -0,0 +1,276 @@
+package submission
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultPlan is the plan every user starts on, and the one whose policy
+// applies to users on a plan that has none configured.
+const DefaultPlan = "free"
+
+// RetentionPolicy is how long one plan keeps submissions.
+type RetentionPolicy struct {
+	// Window is how far back the plan shows submissions. Zero is unlimited.
+	Window time.Duration
+	// Purge, when true, deletes submissions once they have been outside Window
+	// for the Retainer's purge delay. When false they are only hidden, so they
+	// reappear if the owner moves to a plan with a longer window. Lapsed
+	// subscriptions use a policy with Purge off.
+	Purge bool
+}
+
+// PlanLookup reports which plan a user is on.
+type PlanLookup interface {
+	UserPlan(ctx context.Context, userID string) (string, error)
+}
+
+// PlanHistoryWindows is a HistoryWindows that gives each user the window of
+// their plan's policy.
+type PlanHistoryWindows struct {
+	plans    PlanLookup
+	policies map[string]RetentionPolicy
+}
+
+// NewPlanHistoryWindows creates a PlanHistoryWindows. Users on a plan missing
+// from policies get DefaultPlan's window, or FreeHistoryWindow if that is
+// missing too.
+func NewPlanHistoryWindows(plans PlanLookup, policies map[string]RetentionPolicy) *PlanHistoryWindows {
+	return &PlanHistoryWindows{plans: plans, policies: policies}
+}
+
+// HistoryWindow implements HistoryWindows.
+func (w *PlanHistoryWindows) HistoryWindow(ctx context.Context, userID string) (time.Duration, error) {
+	plan, err := w.plans.UserPlan(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("getting user plan: %w", err)
+	}
+	if p, ok := w.policies[plan]; ok {
+		return p.Window, nil
+	}
+	if p, ok := w.policies[DefaultPlan]; ok {
+		return p.Window, nil
+	}
+	return FreeHistoryWindow, nil
+}
+
+// PlanCutoff is the oldest created_at a plan keeps. Submissions created
+// before it are outside the plan's window.
+type PlanCutoff struct {
+	Plan   string
+	Cutoff time.Time
+}
+
+// RetentionStore marks, unmarks, and purges expired submissions. Each call
+// handles at most limit rows and returns how many it changed.
+type RetentionStore interface {
+	// LockRetention takes the lock that keeps the retention job to one
+	// instance at a time. ok is false if another instance holds it; otherwise
+	// unlock must be called when the run is done.
+	LockRetention(ctx context.Context) (unlock func(), ok bool, err error)
+	// ExpireSubmissions marks unmarked submissions whose calculator's owner is
+	// on one of cutoffs' plans and that were created before its cutoff as
+	// expired at now.
+	ExpireSubmissions(ctx context.Context, cutoffs []PlanCutoff, now time.Time, limit int) (int, error)
+	// RestoreSubmissions clears the mark from expired submissions that no
+	// longer match any of cutoffs, because the owner changed plan or the
+	// plan's window grew.
+	RestoreSubmissions(ctx context.Context, cutoffs []PlanCutoff, limit int) (int, error)
+	// PurgeSubmissions deletes submissions marked expired before expiredBefore
+	// that still match one of cutoffs.
+	PurgeSubmissions(ctx context.Context, cutoffs []PlanCutoff, expiredBefore time.Time, limit int) (int, error)
+}
+
+// RetentionSchedule controls how a Retainer spreads its work.
+type RetentionSchedule struct {
+	// BatchSize bounds how many rows one statement touches, so a large
+	// backlog does not hold row locks for long.
+	BatchSize int
+	// PurgeDelay is how long a submission stays marked expired before it is
+	// deleted. An owner who upgrades within it gets the submission back.
+	PurgeDelay time.Duration
+	// PurgeStartHour and PurgeEndHour bound the low-traffic hours, in UTC,
+	// during which expired rows are deleted: [start, end), wrapping past
+	// midnight when end is before start. Equal hours allow purging at any time.
+	PurgeStartHour int
+	PurgeEndHour   int
+}
+
+// RetentionStats is the outcome of one retention run.
+type RetentionStats struct {
+	// Locked is true when another instance held the lock and nothing was done.
+	Locked bool
+	// Purging is false when the run fell outside the purge hours.
+	Purging  bool
+	Restored int
+	Expired  int
+	Purged   int
+	Duration time.Duration
+	// Failed is true when the run stopped on an error. The counts cover the
+	// batches completed before it.
+	Failed bool
+}
+
+// RetentionMetrics records the outcome of each retention run.
+type RetentionMetrics interface {
+	RecordRetentionRun(ctx context.Context, stats RetentionStats)
+}
+
+// Retainer enforces each plan's retention policy on stored submissions. A
+// submission outside its owner's window, which the submission log already
+// hides, is first marked expired and deleted in batches during low-traffic
+// hours once it has stayed expired for the purge delay. Only plans whose
+// policy has Purge set are marked; the rest are hidden by the read path alone.
+type Retainer struct {
+	store    RetentionStore
+	policies map[string]RetentionPolicy
+	schedule RetentionSchedule
+	metrics  RetentionMetrics
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewRetainer creates a Retainer that applies policies, keyed by plan name.
+func NewRetainer(store RetentionStore, policies map[string]RetentionPolicy, schedule RetentionSchedule, metrics RetentionMetrics, logger *slog.Logger) *Retainer {
+	return &Retainer{store: store, policies: policies, schedule: schedule, metrics: metrics, logger: logger, now: time.Now}
+}
+
+// Enforce runs one retention pass: it unmarks submissions whose owner's plan
+// no longer expires them, marks newly expired ones, and, inside the purge
+// hours, deletes those that have been expired for the purge delay. It does
+// nothing if another instance is already running a pass.
+func (r *Retainer) Enforce(ctx context.Context) (RetentionStats, error) {
+	start := r.now()
+	stats := RetentionStats{}
+	err := r.enforce(ctx, start, &stats)
+	stats.Duration = r.now().Sub(start)
+	stats.Failed = err != nil
+	r.metrics.RecordRetentionRun(ctx, stats)
+	return stats, err
+}
+
+func (r *Retainer) enforce(ctx context.Context, now time.Time, stats *RetentionStats) error {
+	unlock, ok, err := r.store.LockRetention(ctx)
+	if err != nil {
+		return fmt.Errorf("taking retention lock: %w", err)
+	}
+	if !ok {
+		stats.Locked = true
+		return nil
+	}
+	defer unlock()
+
+	cutoffs := r.cutoffs(now)
+	// Restoring first means an owner who upgraded since the last run is never
+	// purged by this one.
+	if stats.Restored, err = r.batches(func(limit int) (int, error) {
+		return r.store.RestoreSubmissions(ctx, cutoffs, limit)
+	}); err != nil {
+		return fmt.Errorf("restoring submissions: %w", err)
+	}
+	if len(cutoffs) == 0 {
+		return nil
+	}
+	if stats.Expired, err = r.batches(func(limit int) (int, error) {
+		return r.store.ExpireSubmissions(ctx, cutoffs, now, limit)
+	}); err != nil {
+		return fmt.Errorf("expiring submissions: %w", err)
+	}
+	if !r.inPurgeHours(now) {
+		return nil
+	}
+	stats.Purging = true
+	expiredBefore := now.Add(-r.schedule.PurgeDelay)
+	if stats.Purged, err = r.batches(func(limit int) (int, error) {
+		return r.store.PurgeSubmissions(ctx, cutoffs, expiredBefore, limit)
+	}); err != nil {
+		return fmt.Errorf("purging submissions: %w", err)
+	}
+	return nil
+}
+
+// cutoffs returns the cutoff of every plan whose policy purges, sorted by
+// plan so runs issue identical queries.
+func (r *Retainer) cutoffs(now time.Time) []PlanCutoff {
+	var cutoffs []PlanCutoff
+	for plan, p := range r.policies {
+		if p.Purge && p.Window > 0 {
+			cutoffs = append(cutoffs, PlanCutoff{Plan: plan, Cutoff: now.UTC().Add(-p.Window)})
+		}
+	}
+	slices.SortFunc(cutoffs, func(a, b PlanCutoff) int { return strings.Compare(a.Plan, b.Plan) })
+	return cutoffs
+}
+
+// batches calls fn with the batch size until it handles fewer rows than that,
+// and returns the total handled.
+func (r *Retainer) batches(fn func(limit int) (int, error)) (int, error) {
+	total := 0
+	for {
+		n, err := fn(r.schedule.BatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < r.schedule.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (r *Retainer) inPurgeHours(now time.Time) bool {
+	start, end, h := r.schedule.PurgeStartHour, r.schedule.PurgeEndHour, now.UTC().Hour()
+	switch {
+	case start == end:
+		return true
+	case start < end:
+		return h >= start && h < end
+	default:
+		return h >= start || h < end
+	}
+}
+
+// Run calls Enforce immediately and then every interval until ctx is
+// cancelled. Errors are logged and retried on the next tick.
+func (r *Retainer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Enforce(ctx); err != nil {
+			r.logger.Error("enforcing submission retention", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// LogRetentionMetrics is a RetentionMetrics that writes each run as a
+// structured log line, for log-based metric collection.
+type LogRetentionMetrics struct {
+	logger *slog.Logger
+}
+
+// NewLogRetentionMetrics creates a LogRetentionMetrics that logs to logger.
+func NewLogRetentionMetrics(logger *slog.Logger) *LogRetentionMetrics {
+	return &LogRetentionMetrics{logger: logger}
+}
+
+// RecordRetentionRun implements RetentionMetrics.
+func (m *LogRetentionMetrics) RecordRetentionRun(ctx context.Context, stats RetentionStats) {
+	m.logger.InfoContext(ctx, "submission retention run",
+		"locked", stats.Locked,
+		"purging", stats.Purging,
+		"restored", stats.Restored,
+		"expired", stats.Expired,
+		"purged", stats.Purged,
+		"duration_ms", stats.Duration.Milliseconds(),
+		"failed", stats.Failed,
+	)
+}
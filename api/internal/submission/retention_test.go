@@ -0,0 +1,210 @@
+package submission
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+// stubRetentionStore hands out the counts in its queues, one per call, and
+// records the calls it received.
+type stubRetentionStore struct {
+	locked   bool
+	restored []int
+	expired  []int
+	purged   []int
+	err      error
+
+	calls        []string
+	unlocked     bool
+	gotCutoffs   []PlanCutoff
+	gotExpiredBy time.Time
+}
+
+func (s *stubRetentionStore) LockRetention(context.Context) (func(), bool, error) {
+	if s.locked {
+		return nil, false, nil
+	}
+	return func() { s.unlocked = true }, true, nil
+}
+
+func (s *stubRetentionStore) next(name string, queue *[]int) (int, error) {
+	s.calls = append(s.calls, name)
+	if s.err != nil {
+		return 0, s.err
+	}
+	if len(*queue) == 0 {
+		return 0, nil
+	}
+	n := (*queue)[0]
+	*queue = (*queue)[1:]
+	return n, nil
+}
+
+func (s *stubRetentionStore) ExpireSubmissions(_ context.Context, cutoffs []PlanCutoff, _ time.Time, _ int) (int, error) {
+	s.gotCutoffs = cutoffs
+	return s.next("expire", &s.expired)
+}
+
+func (s *stubRetentionStore) RestoreSubmissions(_ context.Context, _ []PlanCutoff, _ int) (int, error) {
+	return s.next("restore", &s.restored)
+}
+
+func (s *stubRetentionStore) PurgeSubmissions(_ context.Context, _ []PlanCutoff, expiredBefore time.Time, _ int) (int, error) {
+	s.gotExpiredBy = expiredBefore
+	return s.next("purge", &s.purged)
+}
+
+type stubRetentionMetrics struct {
+	runs []RetentionStats
+}
+
+func (m *stubRetentionMetrics) RecordRetentionRun(_ context.Context, stats RetentionStats) {
+	m.runs = append(m.runs, stats)
+}
+
+var retentionPolicies = map[string]RetentionPolicy{
+	"free":   {Window: FreeHistoryWindow, Purge: true},
+	"lapsed": {Window: FreeHistoryWindow},
+	"pro":    {},
+}
+
+func newTestRetainer(store RetentionStore, metrics RetentionMetrics, now time.Time) *Retainer {
+	schedule := RetentionSchedule{BatchSize: 10, PurgeDelay: 7 * 24 * time.Hour, PurgeStartHour: 2, PurgeEndHour: 6}
+	r := NewRetainer(store, retentionPolicies, schedule, metrics, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func TestEnforce_InPurgeHours(t *testing.T) {
+	now := time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC)
+	store := &stubRetentionStore{restored: []int{1}, expired: []int{10, 10, 4}, purged: []int{10, 2}}
+	metrics := &stubRetentionMetrics{}
+
+	stats, err := newTestRetainer(store, metrics, now).Enforce(context.Background())
+	if err != nil {
+		t.Fatalf("Enforce() returned unexpected error: %v", err)
+	}
+	want := []string{"restore", "expire", "expire", "expire", "purge", "purge"}
+	if !slices.Equal(store.calls, want) {
+		t.Errorf("expected calls %v, got %v", want, store.calls)
+	}
+	if stats.Restored != 1 || stats.Expired != 24 || stats.Purged != 12 || !stats.Purging || stats.Locked || stats.Failed {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if !store.unlocked {
+		t.Error("expected the lock to be released")
+	}
+	// Only the free plan purges; lapsed submissions are hidden by the window alone.
+	if len(store.gotCutoffs) != 1 || store.gotCutoffs[0].Plan != "free" || !store.gotCutoffs[0].Cutoff.Equal(now.Add(-FreeHistoryWindow)) {
+		t.Errorf("unexpected cutoffs %+v", store.gotCutoffs)
+	}
+	if !store.gotExpiredBy.Equal(now.Add(-7 * 24 * time.Hour)) {
+		t.Errorf("expected the purge delay to apply, got %v", store.gotExpiredBy)
+	}
+	if len(metrics.runs) != 1 || metrics.runs[0].Purged != 12 {
+		t.Errorf("expected the run to be recorded, got %+v", metrics.runs)
+	}
+}
+
+func TestEnforce_OutsidePurgeHours(t *testing.T) {
+	store := &stubRetentionStore{expired: []int{3}}
+
+	stats, err := newTestRetainer(store, &stubRetentionMetrics{}, time.Date(2026, 4, 1, 14, 0, 0, 0, time.UTC)).Enforce(context.Background())
+	if err != nil {
+		t.Fatalf("Enforce() returned unexpected error: %v", err)
+	}
+	if !slices.Equal(store.calls, []string{"restore", "expire"}) || stats.Purging || stats.Expired != 3 {
+		t.Errorf("expected expiry without purging, got calls %v and stats %+v", store.calls, stats)
+	}
+}
+
+func TestEnforce_LockHeldElsewhere(t *testing.T) {
+	store := &stubRetentionStore{locked: true}
+	metrics := &stubRetentionMetrics{}
+
+	stats, err := newTestRetainer(store, metrics, time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC)).Enforce(context.Background())
+	if err != nil {
+		t.Fatalf("Enforce() returned unexpected error: %v", err)
+	}
+	if !stats.Locked || len(store.calls) != 0 {
+		t.Errorf("expected the run to be skipped, got calls %v and stats %+v", store.calls, stats)
+	}
+	if len(metrics.runs) != 1 || !metrics.runs[0].Locked {
+		t.Errorf("expected the skipped run to be recorded, got %+v", metrics.runs)
+	}
+}
+
+func TestEnforce_Error(t *testing.T) {
+	store := &stubRetentionStore{err: errors.New("db down")}
+	metrics := &stubRetentionMetrics{}
+
+	if _, err := newTestRetainer(store, metrics, time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC)).Enforce(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !store.unlocked {
+		t.Error("expected the lock to be released after a failure")
+	}
+	if len(metrics.runs) != 1 || !metrics.runs[0].Failed {
+		t.Errorf("expected the failed run to be recorded, got %+v", metrics.runs)
+	}
+}
+
+func TestInPurgeHours(t *testing.T) {
+	tests := []struct {
+		start, end, hour int
+		want             bool
+	}{
+		{2, 6, 2, true},
+		{2, 6, 6, false},
+		{22, 4, 23, true},
+		{22, 4, 3, true},
+		{22, 4, 12, false},
+		{0, 0, 12, true},
+	}
+	for _, tt := range tests {
+		r := &Retainer{schedule: RetentionSchedule{PurgeStartHour: tt.start, PurgeEndHour: tt.end}}
+		if got := r.inPurgeHours(time.Date(2026, 4, 1, tt.hour, 30, 0, 0, time.UTC)); got != tt.want {
+			t.Errorf("inPurgeHours(%d) with [%d, %d) = %v, want %v", tt.hour, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+type stubPlanLookup struct {
+	plan string
+	err  error
+}
+
+func (s *stubPlanLookup) UserPlan(context.Context, string) (string, error) {
+	return s.plan, s.err
+}
+
+func TestPlanHistoryWindows(t *testing.T) {
+	tests := []struct {
+		plan string
+		want time.Duration
+	}{
+		{"free", FreeHistoryWindow},
+		{"pro", 0},
+		{"enterprise", FreeHistoryWindow},
+	}
+	for _, tt := range tests {
+		w := NewPlanHistoryWindows(&stubPlanLookup{plan: tt.plan}, retentionPolicies)
+		got, err := w.HistoryWindow(context.Background(), "user-xyz")
+		if err != nil {
+			t.Fatalf("HistoryWindow() returned unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.plan, tt.want, got)
+		}
+	}
+
+	w := NewPlanHistoryWindows(&stubPlanLookup{err: errors.New("db down")}, retentionPolicies)
+	if _, err := w.HistoryWindow(context.Background(), "user-xyz"); err == nil {
+		t.Error("expected an error when the plan cannot be read")
+	}
+}
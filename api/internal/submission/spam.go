@@ -0,0 +1,253 @@
+package submission
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons the spam filter records on a quarantined submission.
+const (
+	// SpamHoneypot means the hidden honeypot field was filled in.
+	SpamHoneypot = "honeypot"
+	// SpamInvalidToken means the challenge token was missing, forged, or
+	// issued for another calculator.
+	SpamInvalidToken = "invalid_token"
+	// SpamTooFast means the submission arrived sooner after the challenge was
+	// issued than a person could fill in the form.
+	SpamTooFast = "too_fast"
+	// SpamProofOfWork means the challenge required proof of work and the
+	// nonce did not solve it, or the challenge had expired.
+	SpamProofOfWork = "proof_of_work"
+	// SpamRateLimited means the client had already sent more submissions to
+	// the calculator this hour than the limit allows.
+	SpamRateLimited = "rate_limited"
+)
+
+// proofOfWorkMaxAge is how long a proof-of-work challenge can be solved.
+// Tokens are otherwise accepted at any age, since an older token only makes
+// the fill time check more lenient, but a solution to an old challenge could
+// have been computed once and replayed.
+const proofOfWorkMaxAge = time.Hour
+
+// maxNonceLength bounds the proof-of-work nonce hashed per submission.
+const maxNonceLength = 64
+
+// maxLimiterKeys bounds the rate limiter's memory. When it is reached,
+// expired windows are dropped, and if none have ended new keys go untracked
+// until some do.
+const maxLimiterKeys = 100_000
+
+// limiterPruneInterval is the least time between prunes of a full limiter, so
+// a flood of new keys does not scan every window on each submission.
+const limiterPruneInterval = time.Minute
+
+// SpamChecker decides whether a submission to a calculator is spam.
+type SpamChecker interface {
+	// Check returns one of the Spam* reasons, or "" if in looks legitimate.
+	Check(calculatorID string, in Input) string
+}
+
+// WithSpamFilter makes Submit quarantine the submissions checker flags.
+func (s *Service) WithSpamFilter(checker SpamChecker) *Service {
+	s.spam = checker
+	return s
+}
+
+// Challenge is issued to the widget when it renders a calculator. The widget
+// returns Token with its submission and, when Difficulty is non-zero, a nonce
+// such that SHA-256(Token + ":" + nonce) starts with Difficulty zero bits.
+type Challenge struct {
+	Token      string
+	Difficulty int
+}
+
+// SpamFilter issues challenges and checks submissions against them. The
+// checks are layered: a honeypot field, a minimum fill time measured from a
+// signed render timestamp, an optional proof-of-work challenge, and an
+// hourly limit per IP address and calculator. The limit is best-effort: it is
+// kept in memory, so each API instance enforces it separately; the address
+// comes from X-Real-IP or X-Forwarded-For when present, which a client can
+// set itself; and once the limiter is tracking maxLimiterKeys windows, new
+// addresses are not counted until old windows end.
+type SpamFilter struct {
+	secret      []byte
+	minFillTime time.Duration
+	difficulty  int
+	limiter     *windowLimiter
+	random      io.Reader
+	now         func() time.Time
+}
+
+// NewSpamFilter creates a SpamFilter that signs tokens with secret. Every
+// instance serving the same calculators must share the secret. difficulty is
+// the number of leading zero bits proof of work must reach, or zero to issue
+// no proof-of-work challenge. hourlyLimit is the number of submissions per IP
+// address per calculator per hour, or zero for no limit.
+func NewSpamFilter(secret []byte, minFillTime time.Duration, difficulty, hourlyLimit int) *SpamFilter {
+	f := &SpamFilter{
+		secret:      secret,
+		minFillTime: minFillTime,
+		difficulty:  difficulty,
+		random:      rand.Reader,
+		now:         time.Now,
+	}
+	if hourlyLimit > 0 {
+		f.limiter = newWindowLimiter(hourlyLimit, time.Hour)
+	}
+	return f
+}
+
+// Challenge returns a new challenge for calculatorID. Its token is
+// "<issued unix seconds>.<difficulty>.<random>.<signature>", where the
+// signature also covers calculatorID so a token cannot be reused on another
+// calculator.
+func (f *SpamFilter) Challenge(calculatorID string) (Challenge, error) {
+	var b [12]byte
+	if _, err := io.ReadFull(f.random, b[:]); err != nil {
+		return Challenge{}, fmt.Errorf("generating challenge: %w", err)
+	}
+	payload := strconv.FormatInt(f.now().Unix(), 10) + "." + strconv.Itoa(f.difficulty) + "." + base64.RawURLEncoding.EncodeToString(b[:])
+	return Challenge{Token: payload + "." + f.sign(calculatorID, payload), Difficulty: f.difficulty}, nil
+}
+
+// Check implements SpamChecker. Every submission counts towards the hourly
+// limit, including those quarantined for another reason, so a bot cannot
+// spend its allowance on failed attempts and stay under it.
+func (f *SpamFilter) Check(calculatorID string, in Input) string {
+	now := f.now()
+	limited := f.limiter != nil && in.IPAddress != "" && !f.limiter.allow(in.IPAddress+"|"+calculatorID, now)
+
+	if in.Honeypot != "" {
+		return SpamHoneypot
+	}
+	issued, difficulty, ok := f.verify(calculatorID, in.ChallengeToken)
+	if !ok {
+		return SpamInvalidToken
+	}
+	age := now.Sub(issued)
+	if age < f.minFillTime {
+		return SpamTooFast
+	}
+	if difficulty > 0 && (age > proofOfWorkMaxAge || !solves(in.ChallengeToken, in.ProofOfWork, difficulty)) {
+		return SpamProofOfWork
+	}
+	if limited {
+		return SpamRateLimited
+	}
+	return ""
+}
+
+// verify checks token's signature for calculatorID and returns when it was
+// issued and the proof-of-work difficulty it set.
+func (f *SpamFilter) verify(calculatorID, token string) (time.Time, int, bool) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return time.Time{}, 0, false
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(f.sign(calculatorID, payload))) {
+		return time.Time{}, 0, false
+	}
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return time.Time{}, 0, false
+	}
+	unix, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return time.Unix(unix, 0), difficulty, true
+}
+
+func (f *SpamFilter) sign(calculatorID, payload string) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte(calculatorID + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// solves reports whether SHA-256(token + ":" + nonce) starts with at least
+// difficulty zero bits.
+func solves(token, nonce string, difficulty int) bool {
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return false
+	}
+	sum := sha256.Sum256([]byte(token + ":" + nonce))
+	zeros := 0
+	for _, b := range sum {
+		if b != 0 {
+			zeros += bits.LeadingZeros8(b)
+			break
+		}
+		zeros += 8
+	}
+	return zeros >= difficulty
+}
+
+// windowLimiter counts events per key in fixed windows of period, allowing
+// at most limit per window. It tracks at most maxKeys windows.
+type windowLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	period  time.Duration
+	maxKeys int
+	windows map[string]*limitWindow
+	pruned  time.Time
+}
+
+type limitWindow struct {
+	start time.Time
+	count int
+}
+
+func newWindowLimiter(limit int, period time.Duration) *windowLimiter {
+	return &windowLimiter{limit: limit, period: period, maxKeys: maxLimiterKeys, windows: make(map[string]*limitWindow)}
+}
+
+// allow counts an event for key at now and reports whether it is within the
+// limit. A new key that would take the limiter past maxKeys is allowed
+// without being counted, so a flood of distinct keys cannot grow memory
+// without bound or push out the windows already being counted.
+func (l *windowLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok && len(l.windows) >= l.maxKeys {
+		if now.Sub(l.pruned) >= limiterPruneInterval {
+			l.prune(now)
+		}
+		if len(l.windows) >= l.maxKeys {
+			return true
+		}
+	}
+	if !ok || now.Sub(w.start) >= l.period {
+		w = &limitWindow{start: now}
+		l.windows[key] = w
+	}
+	w.count++
+	return w.count <= l.limit
+}
+
+// prune drops windows that have ended.
+func (l *windowLimiter) prune(now time.Time) {
+	l.pruned = now
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.period {
+			delete(l.windows, key)
+		}
+	}
+}
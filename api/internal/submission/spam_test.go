@@ -0,0 +1,169 @@
+package submission
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var spamNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestSpamFilter(difficulty, hourlyLimit int) *SpamFilter {
+	f := NewSpamFilter([]byte("test-secret"), 3*time.Second, difficulty, hourlyLimit)
+	f.random = bytes.NewReader(bytes.Repeat([]byte{0x42}, 1024))
+	f.now = func() time.Time { return spamNow }
+	return f
+}
+
+// issue returns a token for calculatorID issued age before spamNow.
+func issue(t *testing.T, f *SpamFilter, calculatorID string, age time.Duration) string {
+	t.Helper()
+	now := f.now
+	f.now = func() time.Time { return spamNow.Add(-age) }
+	defer func() { f.now = now }()
+	c, err := f.Challenge(calculatorID)
+	if err != nil {
+		t.Fatalf("Challenge() returned unexpected error: %v", err)
+	}
+	return c.Token
+}
+
+// solve finds a nonce for token at difficulty by brute force.
+func solve(t *testing.T, token string, difficulty int) string {
+	t.Helper()
+	for i := range 1 << 20 {
+		if nonce := strconv.Itoa(i); solves(token, nonce, difficulty) {
+			return nonce
+		}
+	}
+	t.Fatal("no nonce found")
+	return ""
+}
+
+func spamInput(token string) Input {
+	return Input{IPAddress: "203.0.113.7", ChallengeToken: token}
+}
+
+func TestSpamFilter_Check(t *testing.T) {
+	f := newTestSpamFilter(0, 0)
+	valid := issue(t, f, "calc-abc", time.Minute)
+
+	tests := []struct {
+		name string
+		in   Input
+		want string
+	}{
+		{"legitimate", spamInput(valid), ""},
+		{"honeypot", Input{Honeypot: "x", ChallengeToken: valid}, SpamHoneypot},
+		{"missing token", spamInput(""), SpamInvalidToken},
+		{"tampered token", spamInput(strings.Replace(valid, strconv.FormatInt(spamNow.Add(-time.Minute).Unix(), 10), strconv.FormatInt(spamNow.Add(-time.Hour).Unix(), 10), 1)), SpamInvalidToken},
+		{"other calculator", spamInput(issue(t, f, "calc-other", time.Minute)), SpamInvalidToken},
+		{"too fast", spamInput(issue(t, f, "calc-abc", time.Second)), SpamTooFast},
+		{"old token", spamInput(issue(t, f, "calc-abc", 48*time.Hour)), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Check("calc-abc", tt.in); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSpamFilter_ProofOfWork(t *testing.T) {
+	f := newTestSpamFilter(8, 0)
+	token := issue(t, f, "calc-abc", time.Minute)
+
+	c, err := f.Challenge("calc-abc")
+	if err != nil {
+		t.Fatalf("Challenge() returned unexpected error: %v", err)
+	}
+	if c.Difficulty != 8 {
+		t.Errorf("expected difficulty 8, got %d", c.Difficulty)
+	}
+
+	in := spamInput(token)
+	if got := f.Check("calc-abc", in); got != SpamProofOfWork {
+		t.Errorf("expected a missing nonce to fail, got %q", got)
+	}
+	in.ProofOfWork = solve(t, token, 8)
+	if got := f.Check("calc-abc", in); got != "" {
+		t.Errorf("expected a solved challenge to pass, got %q", got)
+	}
+
+	stale := issue(t, f, "calc-abc", 2*time.Hour)
+	in = spamInput(stale)
+	in.ProofOfWork = solve(t, stale, 8)
+	if got := f.Check("calc-abc", in); got != SpamProofOfWork {
+		t.Errorf("expected an expired challenge to fail, got %q", got)
+	}
+}
+
+func TestSpamFilter_HourlyLimit(t *testing.T) {
+	f := newTestSpamFilter(0, 2)
+	token := issue(t, f, "calc-abc", time.Minute)
+
+	// Spam counts towards the limit too.
+	if got := f.Check("calc-abc", Input{IPAddress: "203.0.113.7", Honeypot: "x"}); got != SpamHoneypot {
+		t.Fatalf("expected honeypot, got %q", got)
+	}
+	if got := f.Check("calc-abc", spamInput(token)); got != "" {
+		t.Errorf("expected the second submission to pass, got %q", got)
+	}
+	if got := f.Check("calc-abc", spamInput(token)); got != SpamRateLimited {
+		t.Errorf("expected the third submission to be rate limited, got %q", got)
+	}
+
+	other := spamInput(issue(t, f, "calc-other", time.Minute))
+	if got := f.Check("calc-other", other); got != "" {
+		t.Errorf("expected the limit to be per calculator, got %q", got)
+	}
+
+	f.now = func() time.Time { return spamNow.Add(time.Hour) }
+	if got := f.Check("calc-abc", spamInput(token)); got != "" {
+		t.Errorf("expected the limit to reset after an hour, got %q", got)
+	}
+}
+
+func TestWindowLimiter_Prune(t *testing.T) {
+	l := newWindowLimiter(1, time.Hour)
+	l.allow("a", spamNow)
+	l.allow("b", spamNow.Add(30*time.Minute))
+	l.prune(spamNow.Add(time.Hour))
+	if _, ok := l.windows["a"]; ok {
+		t.Error("expected the ended window to be pruned")
+	}
+	if _, ok := l.windows["b"]; !ok {
+		t.Error("expected the open window to be kept")
+	}
+}
+
+func TestWindowLimiter_KeyCap(t *testing.T) {
+	l := newWindowLimiter(1, time.Hour)
+	l.maxKeys = 2
+	l.allow("a", spamNow)
+	l.allow("b", spamNow.Add(30*time.Minute))
+
+	// Full with open windows: a new key is let through untracked, and the
+	// keys already counted stay limited.
+	if !l.allow("c", spamNow.Add(45*time.Minute)) || !l.allow("c", spamNow.Add(45*time.Minute)) {
+		t.Error("expected an untracked key to be allowed")
+	}
+	if _, ok := l.windows["c"]; ok || len(l.windows) != 2 {
+		t.Errorf("expected the cap to hold, got %d windows", len(l.windows))
+	}
+	if l.allow("a", spamNow.Add(45*time.Minute)) {
+		t.Error("expected a tracked key to stay limited")
+	}
+
+	// Once a window ends it is pruned to make room.
+	l.allow("d", spamNow.Add(time.Hour))
+	if _, ok := l.windows["a"]; ok {
+		t.Error("expected the ended window to be pruned")
+	}
+	if _, ok := l.windows["d"]; !ok {
+		t.Error("expected the new key to be tracked once there was room")
+	}
+}
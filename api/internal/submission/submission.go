@@ -0,0 +1,156 @@
+// Package submission records the values end users submit from published
+// calculators: their inputs, the outputs the widget calculated, optional lead
+// details, and where the submission came from.
+package submission
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// ErrOverloaded is returned when submissions are arriving faster than they
+// can be written. Nothing was stored; the client should retry shortly.
+var ErrOverloaded = errors.New("submission queue is full")
+
+// Submission is a snapshot of a calculator's inputs and outputs at the moment
+// an end user submitted it. Later edits to the calculator do not change it.
+type Submission struct {
+	ID           string
+	CalculatorID string
+	// InputValues maps field variable names to the submitted values: a number
+	// for number and slider fields, an option value for choice fields, a list
+	// of option values or a boolean for checkboxes, and a string for text.
+	InputValues map[string]any
+	// OutputValues maps output IDs to the values the widget calculated, or nil
+	// for an output that failed to evaluate.
+	OutputValues map[string]any
+	// Total is the value of the calculator's first output, which the
+	// submission log shows and filters by, or nil if it had no value.
+	Total *float64
+	// LeadInfo is nil when the end user left no contact details.
+	LeadInfo    *LeadInfo
+	ReferrerURL string
+	// IPAddress is the submitting client's address, or empty if unknown.
+	IPAddress string
+	CreatedAt time.Time
+	// SpamReason is one of the Spam* reasons if the spam filter quarantined
+	// the submission, or empty if it passed.
+	SpamReason string
+}
+
+// LeadInfo holds the contact details an end user chose to leave.
+type LeadInfo struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// Input is an unvalidated submission as received from the widget.
+type Input struct {
+	InputValues  map[string]any
+	OutputValues map[string]any
+	LeadInfo     *LeadInfo
+	ReferrerURL  string
+	IPAddress    string
+	// Honeypot is the value of the widget's hidden honeypot field, which a
+	// person never fills in.
+	Honeypot string
+	// ChallengeToken is the token issued to the widget when it rendered the
+	// calculator, and ProofOfWork the nonce that solves its challenge.
+	ChallengeToken string
+	ProofOfWork    string
+}
+
+// Writer stores a single submission durably.
+type Writer interface {
+	// WriteSubmission returns once sub has been stored.
+	// Returns ErrOverloaded if it cannot accept more submissions right now.
+	WriteSubmission(ctx context.Context, sub *Submission) error
+}
+
+// Service validates and records submissions, and reads them back for the
+// calculator's owner once WithHistory is configured.
+type Service struct {
+	writer  Writer
+	lister  Lister
+	getter  Getter
+	calcs   CalculatorGetter
+	windows HistoryWindows
+	keys    KeyLister
+	spam    SpamChecker
+	random  io.Reader
+	now     func() time.Time
+}
+
+// NewService creates a Service that stores submissions with writer.
+func NewService(writer Writer) *Service {
+	return &Service{writer: writer, random: rand.Reader, now: time.Now}
+}
+
+// Submit validates in against the fields and outputs of calc's published
+// config and stores it as a new submission. calc must be the published view
+// returned by calculator.Service.GetPublicConfig, so submissions are checked
+// against what the end user was shown rather than an unpublished draft.
+// A valid submission that the spam filter flags is still stored, with its
+// SpamReason set, so it can be reviewed; the caller is not told.
+// Returns a *configschema.ValidationError if in does not match the config.
+// Returns ErrOverloaded if the submission could not be queued for writing.
+func (s *Service) Submit(ctx context.Context, calc *calculator.Calculator, in Input) (*Submission, error) {
+	cfg, err := configschema.Parse(calc.Config)
+	if err != nil {
+		return nil, fmt.Errorf("parsing calculator config: %w", err)
+	}
+	if verr := validate(cfg, in); verr != nil {
+		return nil, verr
+	}
+
+	id, err := newID(s.random)
+	if err != nil {
+		return nil, fmt.Errorf("generating submission id: %w", err)
+	}
+	sub := &Submission{
+		ID:           id,
+		CalculatorID: calc.ID,
+		InputValues:  in.InputValues,
+		OutputValues: in.OutputValues,
+		LeadInfo:     in.LeadInfo,
+		ReferrerURL:  in.ReferrerURL,
+		IPAddress:    in.IPAddress,
+		CreatedAt:    s.now().UTC(),
+	}
+	if len(cfg.Outputs) > 0 {
+		if v, ok := in.OutputValues[cfg.Outputs[0].ID].(float64); ok {
+			sub.Total = &v
+		}
+	}
+	if sub.LeadInfo != nil && *sub.LeadInfo == (LeadInfo{}) {
+		sub.LeadInfo = nil
+	}
+	if s.spam != nil {
+		sub.SpamReason = s.spam.Check(calc.ID, in)
+	}
+	if err := s.writer.WriteSubmission(ctx, sub); err != nil {
+		return nil, fmt.Errorf("writing submission: %w", err)
+	}
+	return sub, nil
+}
+
+// newID returns a random (version 4) UUID read from r. IDs are assigned before
+// the row is written so a submission can be acknowledged by ID as soon as its
+// batch commits.
+func newID(r io.Reader) (string, error) {
+	var b [16]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
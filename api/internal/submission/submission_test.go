@@ -0,0 +1,213 @@
+package submission
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evanisnor/quotecraft/api/internal/calculator"
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+const submissionConfig = `{
+	"fields": [
+		{"id": "f1", "type": "number", "variableName": "sqft"},
+		{"id": "f2", "type": "dropdown", "variableName": "finish", "options": [{"id": "o1", "value": "matte"}, {"id": "o2", "value": "gloss"}]},
+		{"id": "f3", "type": "checkbox", "variableName": "extras", "options": [{"id": "o3", "value": "primer"}]},
+		{"id": "f4", "type": "text", "variableName": "notes"}
+	],
+	"outputs": [{"id": "total", "label": "Total", "expression": "{sqft} * 2"}]
+}`
+
+type stubWriter struct {
+	got *Submission
+	err error
+}
+
+func (s *stubWriter) WriteSubmission(_ context.Context, sub *Submission) error {
+	s.got = sub
+	return s.err
+}
+
+func newTestService(w Writer) *Service {
+	svc := NewService(w)
+	svc.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func validInput() Input {
+	return Input{
+		InputValues:  map[string]any{"sqft": 1200.0, "finish": "gloss", "extras": []any{"primer"}, "notes": "north wall"},
+		OutputValues: map[string]any{"total": 2400.0},
+		LeadInfo:     &LeadInfo{Name: "Sam", Email: "sam@example.com"},
+		ReferrerURL:  "https://example.com/pricing",
+		IPAddress:    "203.0.113.7",
+	}
+}
+
+func TestSubmit_Success(t *testing.T) {
+	w := &stubWriter{}
+	svc := newTestService(w)
+	calc := &calculator.Calculator{ID: "calc-abc", Config: []byte(submissionConfig)}
+
+	sub, err := svc.Submit(context.Background(), calc, validInput())
+	if err != nil {
+		t.Fatalf("Submit() returned unexpected error: %v", err)
+	}
+	if w.got != sub {
+		t.Fatal("expected the returned submission to be written")
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(sub.ID) {
+		t.Errorf("expected a version 4 UUID, got %q", sub.ID)
+	}
+	if sub.CalculatorID != "calc-abc" || sub.IPAddress != "203.0.113.7" || sub.ReferrerURL != "https://example.com/pricing" {
+		t.Errorf("unexpected submission %+v", sub)
+	}
+	if sub.Total == nil || *sub.Total != 2400 {
+		t.Errorf("expected the first output as the total, got %v", sub.Total)
+	}
+	if !sub.CreatedAt.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected created_at from the clock, got %v", sub.CreatedAt)
+	}
+}
+
+func TestSubmit_EmptyLeadInfoDropped(t *testing.T) {
+	svc := newTestService(&stubWriter{})
+	in := validInput()
+	in.LeadInfo = &LeadInfo{}
+
+	sub, err := svc.Submit(context.Background(), &calculator.Calculator{Config: []byte(submissionConfig)}, in)
+	if err != nil {
+		t.Fatalf("Submit() returned unexpected error: %v", err)
+	}
+	if sub.LeadInfo != nil {
+		t.Errorf("expected empty lead info to be stored as null, got %+v", sub.LeadInfo)
+	}
+}
+
+func TestSubmit_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Input)
+		wantPath string
+	}{
+		{"missing inputs", func(in *Input) { in.InputValues = nil }, "input_values"},
+		{"missing outputs", func(in *Input) { in.OutputValues = nil }, "output_values"},
+		{"unknown variable", func(in *Input) { in.InputValues["sqfeet"] = 1.0 }, "input_values.sqfeet"},
+		{"number as string", func(in *Input) { in.InputValues["sqft"] = "1200" }, "input_values.sqft"},
+		{"unknown option", func(in *Input) { in.InputValues["finish"] = "satin" }, "input_values.finish"},
+		{"checkbox item", func(in *Input) { in.InputValues["extras"] = []any{"primer", "tape"} }, "input_values.extras"},
+		{"text too long", func(in *Input) { in.InputValues["notes"] = strings.Repeat("a", maxTextValueLength+1) }, "input_values.notes"},
+		{"unknown output", func(in *Input) { in.OutputValues["subtotal"] = 1.0 }, "output_values.subtotal"},
+		{"output not a number", func(in *Input) { in.OutputValues["total"] = "2400" }, "output_values.total"},
+		{"bad email", func(in *Input) { in.LeadInfo.Email = "Sam <sam@example.com>" }, "lead_info.email"},
+		{"long phone", func(in *Input) { in.LeadInfo.Phone = strings.Repeat("1", maxLeadPhoneLength+1) }, "lead_info.phone"},
+		{"bad referrer", func(in *Input) { in.ReferrerURL = "javascript:alert(1)" }, "referrer_url"},
+		{"NUL in text", func(in *Input) { in.InputValues["notes"] = "north\x00wall" }, "input_values.notes"},
+		{"invalid UTF-8 in text", func(in *Input) { in.InputValues["notes"] = "north\xffwall" }, "input_values.notes"},
+		{"NUL in lead name", func(in *Input) { in.LeadInfo.Name = "Sam\x00" }, "lead_info.name"},
+		{"NUL in lead phone", func(in *Input) { in.LeadInfo.Phone = "555\x00" }, "lead_info.phone"},
+		{"NUL in referrer", func(in *Input) { in.ReferrerURL = "https://example.com/\x00" }, "referrer_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &stubWriter{}
+			svc := newTestService(w)
+			in := validInput()
+			tt.modify(&in)
+
+			_, err := svc.Submit(context.Background(), &calculator.Calculator{Config: []byte(submissionConfig)}, in)
+			var verr *configschema.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected *configschema.ValidationError, got: %v", err)
+			}
+			if len(verr.Errors) != 1 || verr.Errors[0].Path != tt.wantPath {
+				t.Errorf("expected one error at %q, got %+v", tt.wantPath, verr.Errors)
+			}
+			if w.got != nil {
+				t.Error("expected an invalid submission not to be written")
+			}
+		})
+	}
+}
+
+func TestSubmit_AcceptsNullOutputAndBooleanCheckbox(t *testing.T) {
+	svc := newTestService(&stubWriter{})
+	in := validInput()
+	in.OutputValues["total"] = nil
+	in.InputValues["extras"] = true
+
+	if _, err := svc.Submit(context.Background(), &calculator.Calculator{Config: []byte(submissionConfig)}, in); err != nil {
+		t.Errorf("Submit() returned unexpected error: %v", err)
+	}
+}
+
+func TestSubmit_WriteError(t *testing.T) {
+	svc := newTestService(&stubWriter{err: ErrOverloaded})
+
+	_, err := svc.Submit(context.Background(), &calculator.Calculator{Config: []byte(submissionConfig)}, validInput())
+	if !errors.Is(err, ErrOverloaded) {
+		t.Errorf("expected ErrOverloaded, got: %v", err)
+	}
+}
+
+type stubSpamChecker struct {
+	reason    string
+	gotCalcID string
+	gotInput  Input
+}
+
+func (s *stubSpamChecker) Check(calculatorID string, in Input) string {
+	s.gotCalcID, s.gotInput = calculatorID, in
+	return s.reason
+}
+
+func TestSubmit_SpamQuarantined(t *testing.T) {
+	w := &stubWriter{}
+	spam := &stubSpamChecker{reason: SpamHoneypot}
+	svc := newTestService(w).WithSpamFilter(spam)
+	in := validInput()
+	in.Honeypot = "https://spam.example"
+
+	sub, err := svc.Submit(context.Background(), &calculator.Calculator{ID: "calc-abc", Config: []byte(submissionConfig)}, in)
+	if err != nil {
+		t.Fatalf("Submit() returned unexpected error: %v", err)
+	}
+	if w.got != sub || sub.SpamReason != SpamHoneypot {
+		t.Errorf("expected the submission to be stored as spam, got %+v", w.got)
+	}
+	if spam.gotCalcID != "calc-abc" || spam.gotInput.Honeypot != "https://spam.example" {
+		t.Errorf("expected the checker to see the calculator and input, got %q %+v", spam.gotCalcID, spam.gotInput)
+	}
+}
+
+func TestSubmit_InvalidNotCheckedForSpam(t *testing.T) {
+	spam := &stubSpamChecker{}
+	svc := newTestService(&stubWriter{}).WithSpamFilter(spam)
+	in := validInput()
+	in.InputValues["sqft"] = "lots"
+
+	if _, err := svc.Submit(context.Background(), &calculator.Calculator{ID: "calc-abc", Config: []byte(submissionConfig)}, in); err == nil {
+		t.Fatal("expected a validation error")
+	}
+	if spam.gotCalcID != "" {
+		t.Error("expected an invalid submission not to reach the spam filter")
+	}
+}
+
+func TestNewID(t *testing.T) {
+	id, err := newID(bytes.NewReader(bytes.Repeat([]byte{0xff}, 16)))
+	if err != nil {
+		t.Fatalf("newID() returned unexpected error: %v", err)
+	}
+	if id != "ffffffff-ffff-4fff-bfff-ffffffffffff" {
+		t.Errorf("expected version and variant bits to be set, got %q", id)
+	}
+	if _, err := newID(bytes.NewReader(nil)); err == nil {
+		t.Error("expected an error from a short read")
+	}
+}
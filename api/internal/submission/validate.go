@@ -0,0 +1,176 @@
+package submission
+
+import (
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/evanisnor/quotecraft/api/internal/configschema"
+)
+
+// Length limits on the free-text parts of a submission, in bytes.
+const (
+	maxTextValueLength = 1000
+	maxLeadNameLength  = 200
+	maxLeadEmailLength = 254
+	maxLeadPhoneLength = 50
+	maxReferrerLength  = 2048
+)
+
+// validate checks in against cfg and returns every problem found, or nil.
+// Input values must name a field's variable and have the shape its type
+// expects; output values must name an output and be a number or null.
+// Required fields are not enforced, since visibility rules may have hidden
+// them from the end user.
+func validate(cfg *configschema.Config, in Input) *configschema.ValidationError {
+	var verr configschema.ValidationError
+	add := func(path, message string) {
+		verr.Errors = append(verr.Errors, configschema.FieldError{Path: path, Message: message})
+	}
+
+	if in.InputValues == nil {
+		add("input_values", "is required")
+	}
+	fields := make(map[string]configschema.Field, len(cfg.Fields))
+	for _, f := range cfg.Fields {
+		fields[f.VariableName] = f
+	}
+	for _, name := range sortedKeys(in.InputValues) {
+		path := "input_values." + name
+		f, ok := fields[name]
+		if !ok {
+			add(path, "does not match any field variable")
+			continue
+		}
+		if msg := checkInputValue(f, in.InputValues[name]); msg != "" {
+			add(path, msg)
+		}
+	}
+
+	if in.OutputValues == nil {
+		add("output_values", "is required")
+	}
+	outputs := make(map[string]bool, len(cfg.Outputs))
+	for _, o := range cfg.Outputs {
+		outputs[o.ID] = true
+	}
+	for _, id := range sortedKeys(in.OutputValues) {
+		path := "output_values." + id
+		if !outputs[id] {
+			add(path, "does not match any output")
+			continue
+		}
+		if v := in.OutputValues[id]; v != nil {
+			if _, ok := v.(float64); !ok {
+				add(path, "must be a number or null")
+			}
+		}
+	}
+
+	if lead := in.LeadInfo; lead != nil {
+		if len(lead.Name) > maxLeadNameLength {
+			add("lead_info.name", "is too long")
+		} else if !isStorableText(lead.Name) {
+			add("lead_info.name", invalidTextMessage)
+		}
+		if len(lead.Email) > maxLeadEmailLength {
+			add("lead_info.email", "is too long")
+		} else if lead.Email != "" {
+			if addr, err := mail.ParseAddress(lead.Email); err != nil || addr.Address != lead.Email || !isStorableText(lead.Email) {
+				add("lead_info.email", "must be a valid email address")
+			}
+		}
+		if len(lead.Phone) > maxLeadPhoneLength {
+			add("lead_info.phone", "is too long")
+		} else if !isStorableText(lead.Phone) {
+			add("lead_info.phone", invalidTextMessage)
+		}
+	}
+
+	if len(in.ReferrerURL) > maxReferrerLength {
+		add("referrer_url", "is too long")
+	} else if !isStorableText(in.ReferrerURL) {
+		add("referrer_url", "must be an http or https URL")
+	} else if in.ReferrerURL != "" {
+		if u, err := url.Parse(in.ReferrerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("referrer_url", "must be an http or https URL")
+		}
+	}
+
+	if len(verr.Errors) > 0 {
+		return &verr
+	}
+	return nil
+}
+
+// checkInputValue returns why v is not a valid value for f, or "" if it is.
+// v is a value decoded by encoding/json into an interface, so numbers are
+// float64 and lists are []any.
+func checkInputValue(f configschema.Field, v any) string {
+	switch f.Type {
+	case configschema.FieldTypeNumber, configschema.FieldTypeSlider:
+		if _, ok := v.(float64); !ok {
+			return "must be a number"
+		}
+	case configschema.FieldTypeDropdown, configschema.FieldTypeRadio, configschema.FieldTypeImageSelect:
+		if s, ok := v.(string); !ok || !hasOption(f, s) {
+			return "must be one of the field's option values"
+		}
+	case configschema.FieldTypeCheckbox:
+		if _, ok := v.(bool); ok {
+			return ""
+		}
+		list, ok := v.([]any)
+		if !ok {
+			return "must be a boolean or a list of the field's option values"
+		}
+		for _, item := range list {
+			if s, ok := item.(string); !ok || !hasOption(f, s) {
+				return "must be a boolean or a list of the field's option values"
+			}
+		}
+	case configschema.FieldTypeText:
+		s, ok := v.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if len(s) > maxTextValueLength {
+			return "is too long"
+		}
+		if !isStorableText(s) {
+			return invalidTextMessage
+		}
+	}
+	return ""
+}
+
+// invalidTextMessage is the error for text isStorableText rejects.
+const invalidTextMessage = "must be valid UTF-8 without NUL characters"
+
+// isStorableText reports whether Postgres can store s in a text or jsonb
+// column. Both reject NUL, and one row that fails fails its whole batch.
+func isStorableText(s string) bool {
+	return utf8.ValidString(s) && !strings.ContainsRune(s, 0)
+}
+
+func hasOption(f configschema.Field, value string) bool {
+	for _, o := range f.Options {
+		if o.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in order, so errors are reported in a
+// stable order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
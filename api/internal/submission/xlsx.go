@@ -0,0 +1,182 @@
+package submission
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// The fixed parts of an XLSX workbook with a single worksheet. Strings are
+// written inline in the worksheet rather than in a shared strings table,
+// which would have to be complete before the first row could be written.
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+	xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Submissions" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+	// xlsxStyles defines the cell formats referenced by the s attribute:
+	// 0 is the default, 1 is a date and time, and 2 is bold for the header.
+	xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+	xlsxSheetStart = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd   = `</sheetData></worksheet>`
+)
+
+// Cell style indexes into xlsxStyles' cellXfs.
+const (
+	xlsxStyleDateTime = 1
+	xlsxStyleHeader   = 2
+)
+
+// excelEpoch is day zero of Excel's serial dates. Day 60 is the nonexistent
+// 1900-02-29, so serials from 1900-03-01 onwards count from 1899-12-30.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// WriteXLSX writes the export to w as an XLSX workbook with one worksheet.
+// The workbook is streamed: the worksheet is the last zip entry and grows one
+// row at a time.
+func (e *Export) WriteXLSX(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", p.name, err)
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return fmt.Errorf("writing %s: %w", p.name, err)
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return fmt.Errorf("creating worksheet: %w", err)
+	}
+	sw := &sheetWriter{w: bufio.NewWriter(f), loc: e.loc}
+	sw.w.WriteString(xlsxSheetStart)
+	sw.startRow()
+	for i, c := range e.columns {
+		sw.text(i, c.header, xlsxStyleHeader)
+	}
+	sw.endRow()
+	err = e.each(ctx, func(sub *Submission) error {
+		sw.startRow()
+		for i, c := range e.columns {
+			sw.cell(i, c.value(sub))
+		}
+		sw.endRow()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sw.w.WriteString(xlsxSheetEnd)
+	if err := sw.w.Flush(); err != nil {
+		return fmt.Errorf("writing worksheet: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finishing workbook: %w", err)
+	}
+	return nil
+}
+
+// sheetWriter writes worksheet rows. Write errors are sticky in the
+// bufio.Writer and surface when it is flushed.
+type sheetWriter struct {
+	w   *bufio.Writer
+	loc *time.Location
+	row int
+}
+
+func (sw *sheetWriter) startRow() {
+	sw.row++
+	fmt.Fprintf(sw.w, `<row r="%d">`, sw.row)
+}
+
+func (sw *sheetWriter) endRow() {
+	sw.w.WriteString(`</row>`)
+}
+
+// cell writes v to column col of the current row. Empty cells are omitted.
+func (sw *sheetWriter) cell(col int, v any) {
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			sw.text(col, v, 0)
+		}
+	case float64:
+		fmt.Fprintf(sw.w, `<c r="%s"><v>%s</v></c>`, sw.ref(col), strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		fmt.Fprintf(sw.w, `<c r="%s" s="%d"><v>%s</v></c>`, sw.ref(col), xlsxStyleDateTime, strconv.FormatFloat(excelSerial(v.In(sw.loc)), 'f', -1, 64))
+	}
+}
+
+// text writes an inline string cell. Inline strings are never evaluated as
+// formulas, so unlike CSV they need no escaping beyond XML's.
+func (sw *sheetWriter) text(col int, s string, style int) {
+	fmt.Fprintf(sw.w, `<c r="%s" t="inlineStr"`, sw.ref(col))
+	if style != 0 {
+		fmt.Fprintf(sw.w, ` s="%d"`, style)
+	}
+	sw.w.WriteString(`><is><t xml:space="preserve">`)
+	// EscapeText only fails if the writer does, which Flush reports.
+	_ = xml.EscapeText(sw.w, []byte(s))
+	sw.w.WriteString(`</t></is></c>`)
+}
+
+func (sw *sheetWriter) ref(col int) string {
+	return columnName(col) + strconv.Itoa(sw.row)
+}
+
+// columnName returns the spreadsheet letters for the zero-based column index
+// i: A through Z, then AA, AB, and so on.
+func columnName(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// excelSerial returns t's wall clock time as an Excel serial date: days since
+// excelEpoch, with the time of day as the fraction. Excel dates carry no
+// time zone, so t should already be in the location to show.
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return float64(wall.Unix()-excelEpoch.Unix()) / 86400
+}
@@ -0,0 +1,19 @@
+-- Append-only history of calculator configs. Every config write inserts the
+-- new head here; older rows are pruned per calculator by the application so
+-- the table stays bounded.
+CREATE TABLE calculator_revisions (
+    id                    UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    calculator_id         UUID        NOT NULL REFERENCES calculators(id) ON DELETE CASCADE,
+    config_version        INTEGER     NOT NULL,
+    config                JSONB       NOT NULL,
+    author_id             UUID        REFERENCES users(id) ON DELETE SET NULL,
+    restored_from_version INTEGER,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (calculator_id, config_version)
+);
+
+-- Seed history with the current head of every calculator so the first
+-- restore after deploy has something to return to.
+INSERT INTO calculator_revisions (calculator_id, config_version, config, author_id, created_at)
+SELECT id, config_version, config, user_id, updated_at
+FROM calculators;
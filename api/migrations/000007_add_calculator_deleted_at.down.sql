@@ -0,0 +1,2 @@
+DROP INDEX calculators_deleted_at_idx;
+ALTER TABLE calculators DROP COLUMN deleted_at;
@@ -0,0 +1,14 @@
+-- Records when a calculator was moved to the trash so the purge job can
+-- hard-delete it once the retention period has passed.
+ALTER TABLE calculators ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Calculators deleted before this column existed were last written by the
+-- soft delete itself, so updated_at is the best available deletion time. The
+-- trigger is disabled so the backfill does not bump updated_at.
+ALTER TABLE calculators DISABLE TRIGGER calculators_set_updated_at;
+UPDATE calculators SET deleted_at = updated_at WHERE is_deleted = TRUE;
+ALTER TABLE calculators ENABLE TRIGGER calculators_set_updated_at;
+
+-- Support the trash listing and the purge job's scan for expired rows.
+CREATE INDEX calculators_deleted_at_idx ON calculators (deleted_at)
+    WHERE is_deleted = TRUE;
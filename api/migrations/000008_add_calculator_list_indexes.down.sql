@@ -0,0 +1,4 @@
+DROP INDEX calculators_user_name_idx;
+DROP INDEX calculators_user_created_at_idx;
+DROP INDEX calculators_user_updated_at_idx;
+DROP INDEX calculators_name_trgm_idx;
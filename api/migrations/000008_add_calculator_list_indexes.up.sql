@@ -0,0 +1,15 @@
+-- Trigram matching lets the calculator search's ILIKE '%term%' use an index
+-- instead of scanning every row.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX calculators_name_trgm_idx ON calculators
+    USING gin (name gin_trgm_ops)
+    WHERE is_deleted = FALSE;
+
+-- Keyset pagination orders by (sort column, id) within a user's calculators.
+CREATE INDEX calculators_user_updated_at_idx ON calculators (user_id, updated_at, id)
+    WHERE is_deleted = FALSE;
+CREATE INDEX calculators_user_created_at_idx ON calculators (user_id, created_at, id)
+    WHERE is_deleted = FALSE;
+CREATE INDEX calculators_user_name_idx ON calculators (user_id, name, id)
+    WHERE is_deleted = FALSE;
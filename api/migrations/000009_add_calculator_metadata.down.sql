@@ -0,0 +1,9 @@
+DROP INDEX calculators_folder_id_idx;
+DROP INDEX calculators_tags_idx;
+DROP INDEX calculators_description_trgm_idx;
+ALTER TABLE calculators
+    DROP COLUMN metadata_version,
+    DROP COLUMN folder_id,
+    DROP COLUMN tags,
+    DROP COLUMN description;
+DROP TABLE IF EXISTS calculator_folders;
@@ -0,0 +1,33 @@
+-- Folders group a user's calculators in the dashboard. Names are unique per
+-- user so a folder can be found by name.
+CREATE TABLE calculator_folders (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TRIGGER calculator_folders_set_updated_at
+    BEFORE UPDATE ON calculator_folders
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Metadata is versioned separately from config so renaming or retagging a
+-- calculator does not bump config_version or invalidate widget caches.
+-- Deleting a folder leaves its calculators unfiled.
+ALTER TABLE calculators
+    ADD COLUMN description      TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN tags             TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN folder_id        UUID    REFERENCES calculator_folders(id) ON DELETE SET NULL,
+    ADD COLUMN metadata_version INTEGER NOT NULL DEFAULT 1;
+
+-- Support the list filters: search now matches descriptions as well as names,
+-- tag filters use array containment, and folder filters an equality match.
+CREATE INDEX calculators_description_trgm_idx ON calculators
+    USING gin (description gin_trgm_ops)
+    WHERE is_deleted = FALSE;
+CREATE INDEX calculators_tags_idx ON calculators USING gin (tags)
+    WHERE is_deleted = FALSE;
+CREATE INDEX calculators_folder_id_idx ON calculators (folder_id)
+    WHERE is_deleted = FALSE;
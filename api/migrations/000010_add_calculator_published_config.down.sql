@@ -0,0 +1,4 @@
+ALTER TABLE calculators
+    DROP COLUMN published_at,
+    DROP COLUMN published_version,
+    DROP COLUMN published_config;
@@ -0,0 +1,16 @@
+-- The public config endpoint serves a published snapshot rather than the
+-- draft in config, so saves are not live until the calculator is published.
+-- published_version is the config_version that was published; 0 means the
+-- calculator has never been published.
+ALTER TABLE calculators
+    ADD COLUMN published_config  JSONB,
+    ADD COLUMN published_version INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN published_at      TIMESTAMPTZ;
+
+-- Every existing calculator was live before this migration, so publish its
+-- current config to keep embedded widgets working. The trigger is disabled so
+-- the backfill does not bump updated_at.
+ALTER TABLE calculators DISABLE TRIGGER calculators_set_updated_at;
+UPDATE calculators
+SET published_config = config, published_version = config_version, published_at = updated_at;
+ALTER TABLE calculators ENABLE TRIGGER calculators_set_updated_at;
@@ -0,0 +1,18 @@
+-- Built-in starting points for new calculators. Rows are owned by the
+-- application: the API replaces the table contents with the templates embedded
+-- in its binary at startup, so edits belong in the fixture files, not here.
+CREATE TABLE calculator_templates (
+    slug        TEXT        PRIMARY KEY,
+    name        TEXT        NOT NULL,
+    category    TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    config      JSONB       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX calculator_templates_category_idx ON calculator_templates (category, name);
+
+CREATE TRIGGER calculator_templates_set_updated_at
+    BEFORE UPDATE ON calculator_templates
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
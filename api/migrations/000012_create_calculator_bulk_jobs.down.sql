@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS calculator_bulk_job_items;
+DROP TABLE IF EXISTS calculator_bulk_jobs;
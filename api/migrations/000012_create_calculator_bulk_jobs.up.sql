@@ -0,0 +1,32 @@
+-- Background jobs that apply one operation to many calculators. A job is
+-- claimed by one API instance at a time; claimed_at is refreshed as each item
+-- completes, so a job whose instance died is reclaimed once it goes quiet.
+CREATE TABLE calculator_bulk_jobs (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    operation   TEXT        NOT NULL CHECK (operation IN ('merge_patch', 'json_patch', 'publish')),
+    patch       JSONB,
+    publish     BOOLEAN     NOT NULL DEFAULT FALSE,
+    status      TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed')),
+    claimed_at  TIMESTAMPTZ,
+    started_at  TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Workers poll for unfinished jobs oldest first.
+CREATE INDEX calculator_bulk_jobs_unfinished_idx ON calculator_bulk_jobs (created_at)
+    WHERE status <> 'completed';
+
+-- One row per targeted calculator, holding its outcome. calculator_id is not
+-- a foreign key so results outlive calculators purged from the trash.
+CREATE TABLE calculator_bulk_job_items (
+    job_id           UUID    NOT NULL REFERENCES calculator_bulk_jobs(id) ON DELETE CASCADE,
+    calculator_id    UUID    NOT NULL,
+    position         INTEGER NOT NULL,
+    status           TEXT    NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    error            TEXT,
+    previous_version INTEGER,
+    config_version   INTEGER,
+    PRIMARY KEY (job_id, calculator_id)
+);
@@ -0,0 +1,12 @@
+-- Pending ownership transfers. Like password_reset_tokens, only the SHA-256
+-- hash of the emailed token is stored and a row is deleted when it is used.
+-- A calculator has at most one pending transfer; starting another replaces it.
+CREATE TABLE calculator_transfers (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    calculator_id UUID        NOT NULL UNIQUE REFERENCES calculators(id) ON DELETE CASCADE,
+    from_user_id  UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_email      TEXT        NOT NULL,
+    token_hash    TEXT        NOT NULL UNIQUE,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE calculators
+    DROP COLUMN embed_domains;
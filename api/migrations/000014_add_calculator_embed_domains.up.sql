@@ -0,0 +1,3 @@
+-- Host patterns the widget may be embedded on, such as "example.com" or
+-- "*.example.com". An empty list allows embedding anywhere.
+ALTER TABLE calculators ADD COLUMN embed_domains TEXT[] NOT NULL DEFAULT '{}';
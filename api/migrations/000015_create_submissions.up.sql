@@ -0,0 +1,15 @@
+-- Values end users submit from published calculators. Rows are written by the
+-- public POST /v1/submissions endpoint and read back by calculator, newest
+-- first, so the index leads with calculator_id and orders by created_at.
+CREATE TABLE submissions (
+    id             UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    calculator_id  UUID        NOT NULL REFERENCES calculators(id) ON DELETE CASCADE,
+    input_values   JSONB       NOT NULL,
+    output_values  JSONB       NOT NULL,
+    lead_info      JSONB,
+    referrer_url   TEXT        NOT NULL DEFAULT '',
+    ip_address     INET,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX submissions_calculator_id_created_at_idx ON submissions (calculator_id, created_at DESC);
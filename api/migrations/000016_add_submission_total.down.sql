@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS submissions_lead_search_trgm_idx;
+ALTER TABLE submissions
+    DROP COLUMN total;
@@ -0,0 +1,11 @@
+-- The value of the calculator's first output when the submission was made,
+-- copied out of output_values so the submission log can show and filter by
+-- it. NULL when that output had no numeric value.
+ALTER TABLE submissions ADD COLUMN total NUMERIC;
+
+-- The submission log's text search matches lead names, emails, and phone
+-- numbers with ILIKE '%term%'. The expression must match the one in
+-- PostgresSubmissionRepository.ListSubmissions for the index to be used.
+CREATE INDEX submissions_lead_search_trgm_idx ON submissions
+    USING gin ((coalesce(lead_info->>'name', '') || ' ' || coalesce(lead_info->>'email', '') || ' ' || coalesce(lead_info->>'phone', '')) gin_trgm_ops)
+    WHERE lead_info IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS submissions_expired_at_idx;
+ALTER TABLE submissions DROP COLUMN IF EXISTS expired_at;
+ALTER TABLE users DROP COLUMN IF EXISTS plan;
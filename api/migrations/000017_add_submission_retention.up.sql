@@ -0,0 +1,13 @@
+-- The billing plan the user is on. It selects the user's submission history
+-- window and whether submissions past it are purged. Plan names are defined
+-- in config (submissions.retention.plans); until billing exists every user is
+-- on the free plan.
+ALTER TABLE users ADD COLUMN plan TEXT NOT NULL DEFAULT 'free';
+
+-- When the retention job marked the submission as past its owner's history
+-- window. Marked rows are deleted in batches once they have been expired for
+-- the configured delay, unless the owner's plan changes first.
+ALTER TABLE submissions ADD COLUMN expired_at TIMESTAMPTZ;
+
+CREATE INDEX submissions_expired_at_idx ON submissions (expired_at)
+    WHERE expired_at IS NOT NULL;
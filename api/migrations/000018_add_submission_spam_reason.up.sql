@@ -0,0 +1,5 @@
+-- Why the spam filter quarantined the submission, such as 'honeypot' or
+-- 'too_fast'. NULL for submissions that passed. Quarantined submissions are
+-- kept so builders can review false positives, but the submission log and
+-- exports leave them out unless asked for them.
+ALTER TABLE submissions ADD COLUMN spam_reason TEXT;
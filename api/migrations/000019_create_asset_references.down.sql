@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS calculator_revisions_record_asset_references ON calculator_revisions;
+DROP TRIGGER IF EXISTS calculators_record_asset_references ON calculators;
+DROP FUNCTION IF EXISTS calculator_revisions_record_asset_references();
+DROP FUNCTION IF EXISTS calculators_record_asset_references();
+DROP FUNCTION IF EXISTS record_asset_references(UUID, UUID, TEXT, JSONB);
+DROP FUNCTION IF EXISTS asset_keys(JSONB);
+DROP TABLE IF EXISTS asset_references;
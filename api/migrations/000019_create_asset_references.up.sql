@@ -0,0 +1,87 @@
+-- Which calculators reference each uploaded asset, so the trash purge can tell
+-- whether an asset is still in use with an index lookup instead of scanning
+-- every config. A row records one key found in a calculator's draft, its
+-- published snapshot, or one of its retained revisions. Triggers keep the
+-- table in step with every config write, so no write path can miss it, and
+-- the foreign keys drop a source's rows along with the source.
+CREATE TABLE asset_references (
+    asset_key     TEXT NOT NULL,
+    calculator_id UUID NOT NULL REFERENCES calculators(id) ON DELETE CASCADE,
+    revision_id   UUID REFERENCES calculator_revisions(id) ON DELETE CASCADE,
+    source        TEXT NOT NULL CHECK (source IN ('draft', 'published', 'revision'))
+);
+
+CREATE INDEX asset_references_asset_key_idx ON asset_references (asset_key);
+CREATE INDEX asset_references_calculator_id_idx ON asset_references (calculator_id);
+CREATE INDEX asset_references_revision_id_idx ON asset_references (revision_id)
+    WHERE revision_id IS NOT NULL;
+
+-- The distinct asset keys embedded in doc. The pattern must match
+-- assetKeyPattern in internal/calculator/trash.go.
+CREATE FUNCTION asset_keys(doc JSONB)
+RETURNS SETOF TEXT AS $$
+    SELECT DISTINCT m[1]
+    FROM regexp_matches(COALESCE(doc::text, ''), '([0-9a-f]{64}\.(?:jpg|png|webp|gif))', 'g') AS m;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Replaces the references held by one source: a calculator's draft or
+-- published snapshot (rev IS NULL) or a revision. Each key is share-locked
+-- until the writing transaction ends; the purge takes the same lock
+-- exclusively before deleting an asset, so it waits for an uncommitted
+-- reference instead of missing it.
+CREATE FUNCTION record_asset_references(calc UUID, rev UUID, src TEXT, doc JSONB)
+RETURNS VOID AS $$
+DECLARE
+    key TEXT;
+BEGIN
+    IF rev IS NULL THEN
+        DELETE FROM asset_references WHERE calculator_id = calc AND source = src;
+    ELSE
+        DELETE FROM asset_references WHERE revision_id = rev;
+    END IF;
+    FOR key IN SELECT asset_keys(doc) LOOP
+        PERFORM pg_advisory_xact_lock_shared(hashtextextended(key, 0));
+        INSERT INTO asset_references (asset_key, calculator_id, revision_id, source)
+        VALUES (key, calc, rev, src);
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION calculators_record_asset_references()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.config IS DISTINCT FROM OLD.config THEN
+        PERFORM record_asset_references(NEW.id, NULL, 'draft', NEW.config);
+    END IF;
+    IF TG_OP = 'INSERT' OR NEW.published_config IS DISTINCT FROM OLD.published_config THEN
+        PERFORM record_asset_references(NEW.id, NULL, 'published', NEW.published_config);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER calculators_record_asset_references
+    AFTER INSERT OR UPDATE OF config, published_config ON calculators
+    FOR EACH ROW EXECUTE FUNCTION calculators_record_asset_references();
+
+CREATE FUNCTION calculator_revisions_record_asset_references()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM record_asset_references(NEW.calculator_id, NEW.id, 'revision', NEW.config);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER calculator_revisions_record_asset_references
+    AFTER INSERT OR UPDATE OF config ON calculator_revisions
+    FOR EACH ROW EXECUTE FUNCTION calculator_revisions_record_asset_references();
+
+-- Backfill from existing rows.
+INSERT INTO asset_references (asset_key, calculator_id, source)
+SELECT key, c.id, 'draft' FROM calculators c, asset_keys(c.config) AS key;
+
+INSERT INTO asset_references (asset_key, calculator_id, source)
+SELECT key, c.id, 'published' FROM calculators c, asset_keys(c.published_config) AS key;
+
+INSERT INTO asset_references (asset_key, calculator_id, revision_id, source)
+SELECT key, r.calculator_id, r.id, 'revision' FROM calculator_revisions r, asset_keys(r.config) AS key;
@@ -2,11 +2,12 @@ import {
   listCalculators,
   createCalculator,
   deleteCalculator,
+  publishCalculator,
   updateCalculatorConfig,
   fetchPublicConfig,
 } from './calculators';
 import { StubApiClient } from '@/shared/api/testing';
-import { DEFAULT_THEME } from '@/shared/config';
+import { DEFAULT_THEME, CONFIG_SCHEMA_VERSION } from '@/shared/config';
 
 describe('listCalculators', () => {
   it('returns an array of CalculatorSummary on success', async () => {
@@ -35,6 +36,37 @@ describe('listCalculators', () => {
     expect(result[1].id).toBe('def-456');
   });
 
+  it('follows next_cursor until the last page', async () => {
+    const client = new StubApiClient();
+    client.enqueuePage(
+      [
+        {
+          id: 'abc-123',
+          name: 'First',
+          created_at: '2024-01-01T00:00:00Z',
+          updated_at: '2024-01-02T00:00:00Z',
+        },
+      ],
+      'cursor/1',
+    );
+    client.enqueueSuccess([
+      {
+        id: 'def-456',
+        name: 'Second',
+        created_at: '2024-02-01T00:00:00Z',
+        updated_at: '2024-02-02T00:00:00Z',
+      },
+    ]);
+
+    const result = await listCalculators(client);
+
+    expect(result.map((c) => c.id)).toEqual(['abc-123', 'def-456']);
+    expect(client.calls.map((c) => c.path)).toEqual([
+      '/v1/calculators?limit=100',
+      '/v1/calculators?limit=100&cursor=cursor%2F1',
+    ]);
+  });
+
   it('returns an empty array when the list is empty', async () => {
     const client = new StubApiClient();
     client.enqueueSuccess([]);
@@ -93,6 +125,30 @@ describe('deleteCalculator', () => {
   });
 });
 
+describe('publishCalculator', () => {
+  it('POSTs to the publish endpoint', async () => {
+    const client = new StubApiClient();
+    client.enqueueSuccess({ id: 'calc-id', published_version: 2 });
+
+    await publishCalculator(client, 'calc-id');
+
+    expect(client.calls[0]).toEqual({
+      method: 'POST',
+      path: '/v1/calculators/calc-id/publish',
+      body: undefined,
+    });
+  });
+
+  it('throws with the API error message on failure', async () => {
+    const client = new StubApiClient();
+    client.enqueueError('draft config is incomplete');
+
+    await expect(publishCalculator(client, 'calc-id')).rejects.toThrow(
+      'draft config is incomplete',
+    );
+  });
+});
+
 describe('updateCalculatorConfig', () => {
   it('resolves without error on successful PUT', async () => {
     const client = new StubApiClient();
@@ -106,6 +162,7 @@ describe('updateCalculatorConfig', () => {
 
     await expect(
       updateCalculatorConfig(client, 'calc-id', {
+        schemaVersion: CONFIG_SCHEMA_VERSION,
         fields: [],
         outputs: [],
         layoutMode: 'single-page',
@@ -127,6 +184,7 @@ describe('updateCalculatorConfig', () => {
     });
 
     const config = {
+      schemaVersion: CONFIG_SCHEMA_VERSION,
       fields: [
         {
           id: 'f1',
@@ -150,12 +208,37 @@ describe('updateCalculatorConfig', () => {
     expect(client.calls[0].body).toEqual({ config });
   });
 
+  it('marks the write as an autosave when asked', async () => {
+    const client = new StubApiClient();
+    client.enqueueSuccess({
+      id: 'calc-id',
+      config: {},
+      config_version: 2,
+      created_at: '',
+      updated_at: '',
+    });
+
+    const config = {
+      schemaVersion: CONFIG_SCHEMA_VERSION,
+      fields: [],
+      outputs: [],
+      layoutMode: 'single-page' as const,
+      steps: [],
+      theme: DEFAULT_THEME,
+      visibilityRules: [],
+    };
+    await updateCalculatorConfig(client, 'calc-id', config, true);
+
+    expect(client.calls[0].body).toEqual({ config, autosave: true });
+  });
+
   it('throws with the API error message on failure', async () => {
     const client = new StubApiClient();
     client.enqueueError('access forbidden');
 
     await expect(
       updateCalculatorConfig(client, 'bad-id', {
+        schemaVersion: CONFIG_SCHEMA_VERSION,
         fields: [],
         outputs: [],
         layoutMode: 'single-page',
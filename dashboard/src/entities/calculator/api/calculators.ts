@@ -1,4 +1,4 @@
-import type { ApiClient } from '@/shared/api';
+import type { ApiClient, ApiPage } from '@/shared/api';
 import type { CalculatorEditorConfig, FeatureFlags } from '@/shared/config';
 import type { CalculatorSummary } from '../model/types';
 
@@ -18,9 +18,22 @@ function parseCalculatorSummary(data: CalculatorData): CalculatorSummary {
   };
 }
 
+// LIST_PAGE_SIZE is the largest page the API serves.
+const LIST_PAGE_SIZE = 100;
+
 export async function listCalculators(client: ApiClient): Promise<CalculatorSummary[]> {
-  const data = await client.get<CalculatorData[]>('/v1/calculators');
-  return data.map(parseCalculatorSummary);
+  const summaries: CalculatorSummary[] = [];
+  let cursor: string | null = null;
+  do {
+    const path: string =
+      cursor === null
+        ? `/v1/calculators?limit=${LIST_PAGE_SIZE}`
+        : `/v1/calculators?limit=${LIST_PAGE_SIZE}&cursor=${encodeURIComponent(cursor)}`;
+    const page: ApiPage<CalculatorData[]> = await client.getPage<CalculatorData[]>(path);
+    summaries.push(...page.data.map(parseCalculatorSummary));
+    cursor = page.nextCursor;
+  } while (cursor !== null);
+  return summaries;
 }
 
 export async function createCalculator(client: ApiClient): Promise<CalculatorSummary> {
@@ -32,6 +45,10 @@ export async function deleteCalculator(client: ApiClient, id: string): Promise<v
   await client.delete(`/v1/calculators/${id}`);
 }
 
+export async function publishCalculator(client: ApiClient, id: string): Promise<void> {
+  await client.post(`/v1/calculators/${id}/publish`);
+}
+
 interface PublicConfigData {
   id: string;
   config: unknown;
@@ -52,6 +69,7 @@ export async function updateCalculatorConfig(
   client: ApiClient,
   id: string,
   config: CalculatorEditorConfig,
+  autosave = false,
 ): Promise<void> {
-  await client.put(`/v1/calculators/${id}`, { config });
+  await client.put(`/v1/calculators/${id}`, autosave ? { config, autosave } : { config });
 }
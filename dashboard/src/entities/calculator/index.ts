@@ -3,6 +3,7 @@ export {
   listCalculators,
   createCalculator,
   deleteCalculator,
+  publishCalculator,
   updateCalculatorConfig,
   fetchPublicConfig,
 } from './api/calculators';
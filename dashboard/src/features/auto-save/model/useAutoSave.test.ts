@@ -1,10 +1,11 @@
 import { renderHook, act } from '@testing-library/react';
 import { useAutoSave } from './useAutoSave';
 import { StubApiClient } from '@/shared/api/testing';
-import { DEFAULT_THEME } from '@/shared/config';
+import { DEFAULT_THEME, CONFIG_SCHEMA_VERSION } from '@/shared/config';
 import type { CalculatorEditorConfig } from '@/shared/config';
 
 const EMPTY_CONFIG: CalculatorEditorConfig = {
+  schemaVersion: CONFIG_SCHEMA_VERSION,
   fields: [],
   outputs: [],
   layoutMode: 'single-page',
@@ -16,6 +17,7 @@ const DELAY_MS = 500;
 
 function makeConfig(id: string): CalculatorEditorConfig {
   return {
+    schemaVersion: CONFIG_SCHEMA_VERSION,
     fields: [{ id, type: 'number', label: 'Count', required: false, variableName: 'count' }],
     outputs: [],
     layoutMode: 'single-page',
@@ -76,6 +78,7 @@ describe('useAutoSave', () => {
     expect(client.calls).toHaveLength(1);
     expect(client.calls[0].method).toBe('PUT');
     expect(client.calls[0].path).toBe('/v1/calculators/calc-1');
+    expect(client.calls[0].body).toMatchObject({ autosave: true });
   });
 
   it('debounces: only one PUT when config changes multiple times within the delay', async () => {
@@ -58,7 +58,7 @@ export function useAutoSave(
     let attempt = 0;
     while (attempt <= MAX_RETRIES) {
       try {
-        await updateCalculatorConfig(clientRef.current, calculatorIdRef.current, configRef.current);
+        await updateCalculatorConfig(clientRef.current, calculatorIdRef.current, configRef.current, true);
         setStatus('saved');
         isSaving.current = false;
         return;
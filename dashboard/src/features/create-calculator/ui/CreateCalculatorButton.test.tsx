@@ -26,6 +26,7 @@ describe('CreateCalculatorButton', () => {
   it('disables the button while creating', async () => {
     const neverResolvingPost: ApiClient = {
       get: () => new Promise(() => undefined),
+      getPage: () => new Promise(() => undefined),
       post: () => new Promise(() => undefined),
       put: () => new Promise(() => undefined),
       delete: () => new Promise(() => undefined),
@@ -0,0 +1,2 @@
+export { usePublishCalculator } from './model/usePublishCalculator';
+export { PublishButton } from './ui/PublishButton';
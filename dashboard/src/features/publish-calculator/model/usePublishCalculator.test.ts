@@ -0,0 +1,54 @@
+import { renderHook, act, waitFor } from '@testing-library/react';
+import { usePublishCalculator } from './usePublishCalculator';
+import { StubApiClient } from '@/shared/api/testing';
+
+describe('usePublishCalculator', () => {
+  it('starts in idle state', () => {
+    const client = new StubApiClient();
+
+    const { result } = renderHook(() => usePublishCalculator(client, 'calc-1'));
+
+    expect(result.current.status).toBe('idle');
+    expect(result.current.errorMessage).toBeNull();
+  });
+
+  it('publishes the calculator and reports success', async () => {
+    const client = new StubApiClient();
+    client.enqueueSuccess({ id: 'calc-1', published_version: 3 });
+    const onPublished = jest.fn();
+
+    const { result } = renderHook(() => usePublishCalculator(client, 'calc-1', onPublished));
+
+    act(() => {
+      result.current.handlePublish();
+    });
+
+    await waitFor(() => {
+      expect(result.current.status).toBe('published');
+    });
+
+    expect(onPublished).toHaveBeenCalledTimes(1);
+    expect(client.calls).toEqual([
+      { method: 'POST', path: '/v1/calculators/calc-1/publish', body: undefined },
+    ]);
+  });
+
+  it('sets error state when the draft cannot be published', async () => {
+    const client = new StubApiClient();
+    client.enqueueError('draft config is incomplete');
+    const onPublished = jest.fn();
+
+    const { result } = renderHook(() => usePublishCalculator(client, 'calc-1', onPublished));
+
+    act(() => {
+      result.current.handlePublish();
+    });
+
+    await waitFor(() => {
+      expect(result.current.status).toBe('error');
+    });
+
+    expect(result.current.errorMessage).toBe('draft config is incomplete');
+    expect(onPublished).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,40 @@
+'use client';
+
+import { useState } from 'react';
+import type { ApiClient } from '@/shared/api';
+import { publishCalculator } from '@/entities/calculator';
+
+type PublishStatus = 'idle' | 'publishing' | 'published' | 'error';
+
+interface UsePublishCalculatorResult {
+  status: PublishStatus;
+  errorMessage: string | null;
+  handlePublish: () => void;
+}
+
+export function usePublishCalculator(
+  client: ApiClient,
+  calculatorId: string,
+  onPublished?: () => void,
+): UsePublishCalculatorResult {
+  const [status, setStatus] = useState<PublishStatus>('idle');
+  const [errorMessage, setErrorMessage] = useState<string | null>(null);
+
+  function handlePublish(): void {
+    setStatus('publishing');
+    setErrorMessage(null);
+
+    publishCalculator(client, calculatorId)
+      .then(() => {
+        setStatus('published');
+        onPublished?.();
+      })
+      .catch((err: unknown) => {
+        const message = err instanceof Error ? err.message : 'An unexpected error occurred';
+        setStatus('error');
+        setErrorMessage(message);
+      });
+  }
+
+  return { status, errorMessage, handlePublish };
+}
@@ -0,0 +1,38 @@
+import { render, screen } from '@testing-library/react';
+import userEvent from '@testing-library/user-event';
+import { StubApiClient } from '@/shared/api/testing';
+import { PublishButton } from './PublishButton';
+
+describe('PublishButton', () => {
+  it('is disabled while the editor is saving', () => {
+    const client = new StubApiClient();
+
+    render(<PublishButton client={client} calculatorId="calc-1" disabled />);
+
+    expect(screen.getByRole('button', { name: /publish/i })).toBeDisabled();
+  });
+
+  it('confirms a successful publish', async () => {
+    const client = new StubApiClient();
+    client.enqueueSuccess({ id: 'calc-1', published_version: 1 });
+    const user = userEvent.setup();
+
+    render(<PublishButton client={client} calculatorId="calc-1" />);
+
+    await user.click(screen.getByRole('button', { name: /publish/i }));
+
+    expect(await screen.findByRole('status')).toHaveTextContent('Published');
+  });
+
+  it('shows an error alert when publishing fails', async () => {
+    const client = new StubApiClient();
+    client.enqueueError('draft config is incomplete');
+    const user = userEvent.setup();
+
+    render(<PublishButton client={client} calculatorId="calc-1" />);
+
+    await user.click(screen.getByRole('button', { name: /publish/i }));
+
+    expect(await screen.findByRole('alert')).toHaveTextContent('draft config is incomplete');
+  });
+});
@@ -0,0 +1,34 @@
+'use client';
+
+import type { ApiClient } from '@/shared/api';
+import { usePublishCalculator } from '../model/usePublishCalculator';
+
+interface PublishButtonProps {
+  client: ApiClient;
+  calculatorId: string;
+  disabled?: boolean;
+  onPublished?: () => void;
+}
+
+export function PublishButton({
+  client,
+  calculatorId,
+  disabled = false,
+  onPublished,
+}: PublishButtonProps) {
+  const { status, errorMessage, handlePublish } = usePublishCalculator(
+    client,
+    calculatorId,
+    onPublished,
+  );
+
+  return (
+    <div>
+      <button type="button" onClick={handlePublish} disabled={disabled || status === 'publishing'}>
+        Publish
+      </button>
+      {status === 'published' && <p role="status">Published</p>}
+      {status === 'error' && errorMessage !== null && <p role="alert">{errorMessage}</p>}
+    </div>
+  );
+}
@@ -56,6 +56,31 @@ describe('ApiClient.get', () => {
   });
 });
 
+describe('ApiClient.getPage', () => {
+  it('returns the data with the next cursor from meta', async () => {
+    const stub = stubFetchWith([
+      {
+        status: 200,
+        body: { data: [{ id: 'abc' }], error: null, meta: { next_cursor: 'next' } },
+      },
+    ]);
+    const client = createApiClient(BASE_URL, TOKEN, stub.fetch);
+
+    const result = await client.getPage<{ id: string }[]>('/v1/things');
+
+    expect(result).toEqual({ data: [{ id: 'abc' }], nextCursor: 'next' });
+  });
+
+  it('returns a null cursor on the last page', async () => {
+    const stub = stubFetchWith([{ status: 200, body: { data: [], error: null, meta: {} } }]);
+    const client = createApiClient(BASE_URL, TOKEN, stub.fetch);
+
+    const result = await client.getPage<unknown[]>('/v1/things');
+
+    expect(result.nextCursor).toBeNull();
+  });
+});
+
 describe('ApiClient.post', () => {
   it('returns parsed data on 201, sends body as JSON with Content-Type header', async () => {
     let capturedHeaders: HeadersInit | undefined;
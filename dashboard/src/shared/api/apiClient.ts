@@ -4,8 +4,14 @@ export interface ApiEnvelope<T> {
   meta: Record<string, unknown>;
 }
 
+export interface ApiPage<T> {
+  data: T;
+  nextCursor: string | null;
+}
+
 export interface ApiClient {
   get<T>(path: string): Promise<T>;
+  getPage<T>(path: string): Promise<ApiPage<T>>;
   post<T>(path: string, body?: unknown): Promise<T>;
   put<T>(path: string, body?: unknown): Promise<T>;
   delete(path: string): Promise<void>;
@@ -20,6 +26,18 @@ async function request<T>(
   path: string,
   body?: unknown,
 ): Promise<T> {
+  const envelope = await requestEnvelope<T>(baseUrl, token, fetcher, method, path, body);
+  return envelope.data as T;
+}
+
+async function requestEnvelope<T>(
+  baseUrl: string,
+  token: string,
+  fetcher: typeof globalThis.fetch,
+  method: string,
+  path: string,
+  body?: unknown,
+): Promise<ApiEnvelope<T>> {
   const headers: Record<string, string> = {
     Authorization: `Bearer ${token}`,
   };
@@ -44,7 +62,7 @@ async function request<T>(
     throw new Error('Unexpected response: missing data');
   }
 
-  return envelope.data;
+  return envelope;
 }
 
 export function createApiClient(
@@ -57,6 +75,15 @@ export function createApiClient(
       return request<T>(baseUrl, token, fetcher, 'GET', path);
     },
 
+    async getPage<T>(path: string): Promise<ApiPage<T>> {
+      const envelope = await requestEnvelope<T>(baseUrl, token, fetcher, 'GET', path);
+      const nextCursor = envelope.meta?.next_cursor;
+      return {
+        data: envelope.data as T,
+        nextCursor: typeof nextCursor === 'string' && nextCursor !== '' ? nextCursor : null,
+      };
+    },
+
     post<T>(path: string, body?: unknown): Promise<T> {
       return request<T>(baseUrl, token, fetcher, 'POST', path, body);
     },
@@ -1,2 +1,2 @@
 export { createApiClient } from './apiClient';
-export type { ApiClient, ApiEnvelope } from './apiClient';
+export type { ApiClient, ApiEnvelope, ApiPage } from './apiClient';
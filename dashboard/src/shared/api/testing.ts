@@ -1,4 +1,4 @@
-import type { ApiClient } from './apiClient';
+import type { ApiClient, ApiPage } from './apiClient';
 
 interface StubResponse {
   status: number;
@@ -38,6 +38,7 @@ export function stubFetchWith(responses: StubResponse[]): StubFetch {
 
 interface QueueEntry {
   data?: unknown;
+  nextCursor?: string;
   error?: Error;
 }
 
@@ -60,14 +61,23 @@ export class StubApiClient implements ApiClient {
     this.queue.push({ data });
   }
 
+  enqueuePage<T>(data: T, nextCursor: string): void {
+    this.queue.push({ data, nextCursor });
+  }
+
   enqueueError(message: string): void {
     this.queue.push({ error: new Error(message) });
   }
 
-  private async dequeue<T>(): Promise<T> {
+  private async dequeueEntry(): Promise<QueueEntry> {
     const entry = this.queue.shift();
     if (!entry) throw new Error('StubApiClient: no more queued responses');
     if (entry.error) throw entry.error;
+    return entry;
+  }
+
+  private async dequeue<T>(): Promise<T> {
+    const entry = await this.dequeueEntry();
     return entry.data as T;
   }
 
@@ -76,6 +86,12 @@ export class StubApiClient implements ApiClient {
     return this.dequeue<T>();
   }
 
+  async getPage<T>(path: string): Promise<ApiPage<T>> {
+    this.calls.push({ method: 'GET', path });
+    const entry = await this.dequeueEntry();
+    return { data: entry.data as T, nextCursor: entry.nextCursor ?? null };
+  }
+
   async post<T>(path: string, body?: unknown): Promise<T> {
     this.calls.push({ method: 'POST', path, body });
     return this.dequeue<T>();
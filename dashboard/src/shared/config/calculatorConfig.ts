@@ -64,10 +64,16 @@ export interface ImageSelectFieldConfig extends BaseFieldConfig {
   options: ImageSelectOption[];
 }
 
+export type RoundingMode = 'half_even' | 'half_up' | 'floor' | 'ceil';
+
 export interface ResultOutputConfig {
   id: string;
   label: string;
   expression: string;
+  /** How the API rounds the exact result. Defaults to 'half_up'. */
+  rounding?: RoundingMode;
+  /** ISO 4217 code; the API rounds the result to the currency's minor unit. */
+  currency?: string;
 }
 
 export type LayoutMode = 'single-page' | 'multi-step';
@@ -108,12 +114,21 @@ export interface VisibilityRule {
   combinator: 'AND' | 'OR';
 }
 
+/**
+ * The config schema version this dashboard writes. It must match
+ * configschema.Version in the API. The API treats a saved config without
+ * schemaVersion as current, so the editor must always send it.
+ */
+export const CONFIG_SCHEMA_VERSION = 1;
+
 /**
  * The full calculator configuration payload sent to and stored by the API.
  * Contains all editor state: input fields, formula output configurations,
  * layout mode, step definitions, theme settings, and visibility rules.
  */
 export interface CalculatorEditorConfig {
+  /** The schema version the config is written against. */
+  schemaVersion: number;
   fields: BaseFieldConfig[];
   outputs: ResultOutputConfig[];
   layoutMode: LayoutMode;
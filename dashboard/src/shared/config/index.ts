@@ -12,6 +12,7 @@ export type {
   ImageSelectOption,
   ImageSelectFieldConfig,
   ResultOutputConfig,
+  RoundingMode,
   LayoutMode,
   Step,
   ThemeConfig,
@@ -21,4 +22,4 @@ export type {
   VisibilityCondition,
   VisibilityRule,
 } from './calculatorConfig';
-export { DEFAULT_THEME } from './calculatorConfig';
+export { DEFAULT_THEME, CONFIG_SCHEMA_VERSION } from './calculatorConfig';
@@ -2,7 +2,7 @@ import { render, screen, fireEvent, waitFor } from '@testing-library/react';
 import userEvent from '@testing-library/user-event';
 import { EditorPage } from './EditorPage';
 import { StubApiClient } from '@/shared/api/testing';
-import { DEFAULT_THEME } from '@/shared/config';
+import { DEFAULT_THEME, CONFIG_SCHEMA_VERSION } from '@/shared/config';
 
 function renderEditor(calculatorId = 'calc-1') {
   const client = new StubApiClient();
@@ -771,6 +771,34 @@ describe('EditorPage', () => {
     expect(badge).not.toBeNull();
   });
 
+  it('publishes the calculator and reloads its public config', async () => {
+    const user = userEvent.setup();
+    const client = new StubApiClient();
+    client.enqueueError('calculator not found'); // not yet published
+    client.enqueueSuccess({ id: 'calc-1', published_version: 1 });
+    client.enqueueSuccess({
+      id: 'calc-1',
+      config: {},
+      config_version: 1,
+      feature_flags: { branding_removable: true },
+    });
+    for (let i = 0; i < 20; i++) client.enqueueSuccess({});
+    render(<EditorPage calculatorId="calc-1" client={client} />);
+
+    await user.click(screen.getByRole('button', { name: 'Publish' }));
+
+    const host = screen.getByTestId('preview-shadow-host');
+    await waitFor(() => {
+      const badge = host.shadowRoot?.querySelector('a[href="https://quotecraft.io"]');
+      expect(badge).toBeNull();
+    });
+    expect(client.calls.map((c) => `${c.method} ${c.path}`)).toEqual([
+      'GET /v1/calculators/calc-1/config',
+      'POST /v1/calculators/calc-1/publish',
+      'GET /v1/calculators/calc-1/config',
+    ]);
+  });
+
   describe('conditional visibility rules', () => {
     it('renders the conditional visibility rules section', () => {
       renderEditor();
@@ -809,6 +837,24 @@ describe('EditorPage', () => {
     });
   });
 
+  describe('schema version', () => {
+    it('schemaVersion is included in the saved config payload', async () => {
+      const user = userEvent.setup();
+      const { client } = renderEditor();
+
+      await user.click(screen.getByRole('button', { name: 'Save' }));
+
+      await waitFor(() => {
+        const saveCalls = client.calls.filter((c) => c.method === 'PUT');
+        expect(saveCalls).toHaveLength(1);
+        const { config } = saveCalls[0].body as {
+          config: { schemaVersion: number };
+        };
+        expect(config.schemaVersion).toBe(CONFIG_SCHEMA_VERSION);
+      });
+    });
+  });
+
   describe('theme color pickers', () => {
     it('renders the theme colors section', () => {
       renderEditor();
@@ -1,6 +1,6 @@
 'use client';
 
-import { useEffect, useMemo, useState } from 'react';
+import { useCallback, useEffect, useMemo, useState } from 'react';
 import type { ApiClient } from '@/shared/api';
 import { FieldTypePalette } from '@/features/add-field';
 import { LayoutModeToggle } from '@/features/toggle-layout';
@@ -8,6 +8,7 @@ import { StepManager } from '@/features/manage-steps';
 import { ColorPickerPanel } from '@/features/style-theme';
 import { DraggableFieldList } from '@/features/reorder-fields';
 import { useAutoSave, SaveStatusIndicator } from '@/features/auto-save';
+import { PublishButton } from '@/features/publish-calculator';
 import { OutputList, FormulaInput } from '@/features/manage-outputs';
 import { ConditionalRuleEditor } from '@/features/manage-conditional-rules';
 import { FieldEditorWidget } from '@/widgets/field-editor';
@@ -26,7 +27,7 @@ import type {
   FeatureFlags,
   VisibilityRule,
 } from '@/shared/config';
-import { FIELD_TYPE_LABELS, DEFAULT_THEME } from '@/shared/config';
+import { FIELD_TYPE_LABELS, DEFAULT_THEME, CONFIG_SCHEMA_VERSION } from '@/shared/config';
 import { fetchPublicConfig } from '@/entities/calculator';
 import { generateId, generateVariableName, buildFieldDefaults } from '@/shared/lib';
 
@@ -69,16 +70,30 @@ export function EditorPage({ calculatorId, client }: EditorPageProps) {
   const [featureFlags, setFeatureFlags] = useState<FeatureFlags>({ brandingRemovable: false });
   const [visibilityRules, setVisibilityRules] = useState<VisibilityRule[]>([]);
 
-  useEffect(() => {
+  // A calculator has no public config until it is first published, so the
+  // default flags stand until then and are reloaded after each publish.
+  const loadFeatureFlags = useCallback(() => {
     fetchPublicConfig(client, calculatorId)
       .then(setFeatureFlags)
       .catch(() => {});
   }, [client, calculatorId]);
 
+  useEffect(() => {
+    loadFeatureFlags();
+  }, [loadFeatureFlags]);
+
   const selectedField = fields.find((f) => f.id === selectedFieldId) ?? null;
 
   const config = useMemo(
-    () => ({ fields, outputs, layoutMode, steps, theme, visibilityRules }),
+    () => ({
+      schemaVersion: CONFIG_SCHEMA_VERSION,
+      fields,
+      outputs,
+      layoutMode,
+      steps,
+      theme,
+      visibilityRules,
+    }),
     [fields, outputs, layoutMode, steps, theme, visibilityRules],
   );
   const fieldDefaults = useMemo(() => buildFieldDefaults(fields), [fields]);
@@ -227,6 +242,12 @@ export function EditorPage({ calculatorId, client }: EditorPageProps) {
     <main data-calculator-id={calculatorId}>
       <h1>Calculator Editor</h1>
       <SaveStatusIndicator status={saveStatus} onSave={save} />
+      <PublishButton
+        client={client}
+        calculatorId={calculatorId}
+        disabled={saveStatus === 'saving'}
+        onPublished={loadFeatureFlags}
+      />
       <div className="flex gap-6">
         <div className="flex-1">
           <LayoutModeToggle mode={layoutMode} onChange={handleLayoutModeChange} />
@@ -0,0 +1,45 @@
+import { evaluate } from './evaluate';
+import type { FormulaContext } from './types';
+import fixture from '../conformance/cases.json';
+
+/**
+ * Shared conformance cases. The Go port in api/internal/formula runs the same
+ * file, so a change to either engine's semantics must be reflected here.
+ *
+ * Non-finite results are encoded as the strings "Infinity", "-Infinity", and
+ * "NaN" because JSON cannot represent them.
+ */
+interface ConformanceCase {
+  name: string;
+  expression: string;
+  context: FormulaContext;
+  value?: number | string;
+  error?: string;
+}
+
+const cases = (fixture as { cases: ConformanceCase[] }).cases;
+
+function decode(value: number | string): number {
+  return typeof value === 'number' ? value : Number(value);
+}
+
+describe('conformance', () => {
+  test.each(cases.map((c) => [c.name, c] as const))('%s', (_name, c) => {
+    const result = evaluate(c.expression, c.context);
+
+    if (c.error !== undefined) {
+      expect(result).toEqual({ value: 0, error: c.error });
+      return;
+    }
+
+    expect(result.error).toBeUndefined();
+    // toBe uses Object.is, which treats -0 and 0 as distinct; JSON cannot
+    // carry the sign of zero, so compare with == for zero results.
+    const expected = decode(c.value as number | string);
+    if (expected === 0) {
+      expect(result.value === 0).toBe(true);
+    } else {
+      expect(result.value).toBe(expected);
+    }
+  });
+});